@@ -0,0 +1,83 @@
+package btc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/threefoldtech/atomicswap/chain"
+)
+
+type (
+	// AuditContractOutput is the result of the AuditContract call
+	AuditContractOutput struct {
+		ContractAddress  string            `json:"contractAddress"`
+		ContractValue    btcutil.Amount    `json:"contractValue"`
+		RecipientAddress string            `json:"recipientAddress"`
+		RefundAddress    string            `json:"refundAddress"`
+		SecretHash       [sha256.Size]byte `json:"secretHash"`
+		// Locktime is a unix timestamp,
+		// or a block height if it is below txscript.LockTimeThreshold
+		Locktime int64 `json:"locktime"`
+	}
+)
+
+// AuditContract verifies the contract is an atomic swap contract paid to by the contract transaction,
+// and returns its details. It does not require a wallet.
+func AuditContract(chainParams *chaincfg.Params, contract []byte, contractTx *wire.MsgTx) (AuditContractOutput, error) {
+	contractOut, contractType := findContractOutput(chainParams, contract, contractTx)
+	if contractOut == -1 {
+		return AuditContractOutput{}, errors.New("transaction does not contain the contract output")
+	}
+
+	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	if pushes == nil {
+		return AuditContractOutput{}, errors.New("contract is not an atomic swap script recognized by this tool")
+	}
+	if pushes.SecretSize != secretSize {
+		return AuditContractOutput{}, fmt.Errorf("contract specifies strange secret size %v", pushes.SecretSize)
+	}
+
+	contractAddr, err := contractAddress(chainParams, contract, contractType)
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	recipientAddr, err := btcutil.NewAddressPubKeyHash(pushes.RecipientHash160[:],
+		chainParams)
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	refundAddr, err := btcutil.NewAddressPubKeyHash(pushes.RefundHash160[:],
+		chainParams)
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	return AuditContractOutput{
+		ContractAddress:  contractAddr.EncodeAddress(),
+		ContractValue:    btcutil.Amount(contractTx.TxOut[contractOut].Value),
+		RecipientAddress: recipientAddr.EncodeAddress(),
+		RefundAddress:    refundAddr.EncodeAddress(),
+		SecretHash:       pushes.SecretHash,
+		Locktime:         pushes.LockTime,
+	}, nil
+}
+
+// AuditResult converts the output to the chain agnostic chain.AuditResult
+func (o AuditContractOutput) AuditResult() chain.AuditResult {
+	return chain.AuditResult{
+		ContractAddress:  o.ContractAddress,
+		ContractValue:    chain.AmountFromInt64(int64(o.ContractValue)),
+		RecipientAddress: o.RecipientAddress,
+		RefundAddress:    o.RefundAddress,
+		SecretHash:       o.SecretHash,
+		LockTime:         chain.LockTime(o.Locktime),
+		Asset:            "BTC",
+	}
+}
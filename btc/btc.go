@@ -0,0 +1,187 @@
+// Copyright (c) 2017 The Decred developers
+// Copyright (c) 2018 The Rivine developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package btc provides the Bitcoin side of an atomic swap,
+// using an Electrum wallet to fund and sign the transactions.
+package btc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+)
+
+const verify = true
+
+const secretSize = 32
+
+const txVersion = 2
+
+// witnessScaleFactor is the weight of a byte outside of the witness data,
+// compared to a byte of witness data
+const witnessScaleFactor = 4
+
+// Wallet is the subset of the Electrum JSON-RPC interface used to
+// fund, sign and publish the atomic swap transactions.
+// It is implemented by *rpcclient.Client.
+type Wallet interface {
+	// PayTo creates a funded (and signed unless unsigned is true)
+	// transaction paying amount to destination.
+	PayTo(destination btcutil.Address, amount btcutil.Amount, unsigned bool) (tx *wire.MsgTx, complete bool, err error)
+	// ListUnspent returns the unspent outputs of the wallet.
+	ListUnspent() ([]*rpc.UnspentOutput, error)
+	// GetFeeRate returns the current optimal fee rate per kilobyte.
+	GetFeeRate() (btcutil.Amount, error)
+	// GetUnusedAddress returns the first unused address of the wallet.
+	GetUnusedAddress() (btcutil.Address, error)
+	// DumpPrivKey returns the private key of a wallet address.
+	DumpPrivKey(address btcutil.Address) (*btcutil.WIF, error)
+	// SendRawTransaction publishes a transaction to the network.
+	SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error)
+}
+
+// PublishTransaction publishes a transaction using the wallet
+func PublishTransaction(w Wallet, tx *wire.MsgTx) (*chainhash.Hash, error) {
+	txHash, err := w.SendRawTransaction(tx, false)
+	if err != nil {
+		return nil, fmt.Errorf("sendrawtransaction: %v", err)
+	}
+	return txHash, nil
+}
+
+// createSig creates and returns the serialized raw signature and compressed
+// pubkey for a transaction input signature.  Due to limitations of the Bitcoin
+// Core RPC API, this requires dumping a private key and signing in the client,
+// rather than letting the wallet sign.
+func createSig(tx *wire.MsgTx, idx int, pkScript []byte, addr btcutil.Address,
+	w Wallet) (sig, pubkey []byte, err error) {
+
+	wif, err := w.DumpPrivKey(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err = txscript.RawTxInSignature(tx, idx, pkScript, txscript.SigHashAll, wif.PrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sig, wif.PrivKey.PubKey().SerializeCompressed(), nil
+}
+
+// createWitnessSig is createSig for an input spending a witness output of the given value,
+// with witnessScript being the script of the P2WSH output.
+func createWitnessSig(tx *wire.MsgTx, idx int, witnessScript []byte, value int64, addr btcutil.Address,
+	w Wallet) (sig, pubkey []byte, err error) {
+
+	wif, err := w.DumpPrivKey(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err = txscript.RawTxInWitnessSignature(tx, txscript.NewTxSigHashes(tx), idx, value,
+		witnessScript, txscript.SigHashAll, wif.PrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sig, wif.PrivKey.PubKey().SerializeCompressed(), nil
+}
+
+// payTo calls a the payto JSON-RPC method,
+// It creates a funded ,signed transaction.
+func payTo(w Wallet, destination btcutil.Address, amount btcutil.Amount) (fundedTx *wire.MsgTx, fee btcutil.Amount, err error) {
+	fundedTx, complete, err := w.PayTo(destination, amount, false)
+	if err != nil {
+		return
+	}
+	if !complete {
+		err = errors.New("payto:Created transaction is not complete")
+	}
+	//Fetch all unspent outputs from the wallet in order to calculate the fee
+	utxos, err := w.ListUnspent()
+	if err != nil {
+		return
+	}
+	findUtxofunc := func(outPoint wire.OutPoint) (*rpc.UnspentOutput, error) {
+		for _, utxo := range utxos {
+			if outPoint.Hash.IsEqual(&utxo.OutPoint.Hash) && outPoint.Index == utxo.OutPoint.Index {
+				return utxo, nil
+			}
+		}
+		return nil, fmt.Errorf("no utxo found for used input %s", outPoint)
+	}
+	var rawfee int64
+	for _, txin := range fundedTx.TxIn {
+		utxo, err := findUtxofunc(txin.PreviousOutPoint)
+		if err != nil {
+			return nil, 0, err
+		}
+		rawfee += int64(utxo.Value)
+	}
+	for _, txout := range fundedTx.TxOut {
+		rawfee -= txout.Value
+	}
+	fee = btcutil.Amount(rawfee)
+	return
+}
+
+// getFeePerKb queries the wallet for the current optimal fee rate per kilobyte,
+// according to config settings(static/dynamic).
+func getFeePerKb(w Wallet) (feerate btcutil.Amount, err error) {
+	return w.GetFeeRate()
+}
+
+// getUnusedAddress uses the getunusedeaddress JSON-RPC method.
+func getUnusedAddress(chainParams *chaincfg.Params, w Wallet) (btcutil.Address, error) {
+	addr, err := w.GetUnusedAddress()
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IsForNet(chainParams) {
+		return nil, fmt.Errorf("address %v is not intended for use on %v",
+			addr, chainParams.Name)
+	}
+	if _, ok := addr.(*btcutil.AddressPubKeyHash); !ok {
+		return nil, fmt.Errorf("address %v is not P2PKH",
+			addr)
+	}
+	return addr, nil
+}
+
+// verifyInput executes the script of the spent contract output,
+// to make sure the input at index idx of tx is able to spend it.
+func verifyInput(contractTx *wire.MsgTx, contractOut uint32, tx *wire.MsgTx, idx int) error {
+	e, err := txscript.NewEngine(contractTx.TxOut[contractOut].PkScript,
+		tx, idx, txscript.StandardVerifyFlags, txscript.NewSigCache(10),
+		txscript.NewTxSigHashes(tx), contractTx.TxOut[contractOut].Value)
+	if err != nil {
+		return err
+	}
+	return e.Execute()
+}
+
+func sha256Hash(x []byte) []byte {
+	h := sha256.Sum256(x)
+	return h[:]
+}
+
+// CalcFeePerKb returns the fee rate in BTC/kB of a transaction
+// with the given absolute fee and serialize size.
+// Use the virtual size of transactions with witness data.
+func CalcFeePerKb(absoluteFee btcutil.Amount, serializeSize int) float64 {
+	return float64(absoluteFee) / float64(serializeSize) / 1e5
+}
+
+// VirtualSize returns the virtual size of a transaction,
+// its weight divided by 4 and rounded up,
+// which is its serialize size if it has no witness data.
+func VirtualSize(tx *wire.MsgTx) int {
+	weight := tx.SerializeSizeStripped()*(witnessScaleFactor-1) + tx.SerializeSize()
+	return (weight + witnessScaleFactor - 1) / witnessScaleFactor
+}
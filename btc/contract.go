@@ -0,0 +1,368 @@
+// Copyright (c) 2017 The Decred developers
+// Copyright (c) 2018 The Rivine developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package btc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// ContractType is the type of the output locking the funds to a contract
+type ContractType int
+
+const (
+	// P2SH locks the funds to the hash of the contract,
+	// which is pushed with the signature in the signature script of the spending input
+	P2SH ContractType = iota
+	// P2WSH locks the funds to the witness script hash of the contract,
+	// which is in the witness of the spending input with the signature
+	P2WSH
+	// P2SHP2WSH locks the funds to a P2WSH witness program nested in a P2SH output,
+	// for wallets unable to pay to native segwit addresses
+	P2SHP2WSH
+)
+
+// String implements fmt.Stringer.String
+func (t ContractType) String() string {
+	switch t {
+	case P2SH:
+		return "P2SH"
+	case P2WSH:
+		return "P2WSH"
+	case P2SHP2WSH:
+		return "P2SH-P2WSH"
+	default:
+		return fmt.Sprintf("ContractType(%d)", int(t))
+	}
+}
+
+// contractArgs specifies the common parameters used to create the initiator's
+// and participant's contract.
+type contractArgs struct {
+	them         *btcutil.AddressPubKeyHash
+	amount       btcutil.Amount
+	locktime     int64
+	secretHash   []byte
+	contractType ContractType
+}
+
+// BuiltContract houses the details regarding a contract and the contract
+// payment transaction, as well as the transaction to perform a refund.
+type BuiltContract struct {
+	Contract []byte `json:"contract"`
+	// ContractAddress is the P2SH or P2WSH address the contract transaction pays to
+	ContractAddress btcutil.Address `json:"-"`
+	ContractTxHash  *chainhash.Hash `json:"contractTransactionHash"`
+	ContractTx      *wire.MsgTx     `json:"contractTransaction"`
+	ContractFee     btcutil.Amount  `json:"contractFee"`
+	RefundTx        *wire.MsgTx     `json:"refundTransaction"`
+	RefundFee       btcutil.Amount  `json:"refundFee"`
+}
+
+// buildContract creates a contract for the parameters specified in args, using
+// wallet RPC to generate an internal address to redeem the refund and to sign
+// the payment to the contract transaction.
+func buildContract(chainParams *chaincfg.Params, w Wallet, args *contractArgs) (*BuiltContract, error) {
+	refundAddr, err := getUnusedAddress(chainParams, w)
+	if err != nil {
+		return nil, fmt.Errorf("getunusedaddress: %v", err)
+	}
+	refundAddrH, ok := refundAddr.(interface {
+		Hash160() *[ripemd160.Size]byte
+	})
+	if !ok {
+		return nil, errors.New("unable to create hash160 from change address")
+	}
+
+	contract, err := atomicSwapContract(refundAddrH.Hash160(), args.them.Hash160(),
+		args.locktime, args.secretHash)
+	if err != nil {
+		return nil, err
+	}
+	contractAddr, err := contractAddress(chainParams, contract, args.contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	feePerKb, err := getFeePerKb(w)
+	if err != nil {
+		return nil, err
+	}
+
+	contractTx, contractFee, err := payTo(w, contractAddr, args.amount)
+	if err != nil {
+		return nil, fmt.Errorf("payTo: %v", err)
+	}
+
+	contractTxHash := contractTx.TxHash()
+
+	refundTx, refundFee, err := buildRefund(chainParams, w, contract, contractTx, feePerKb)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BuiltContract{
+		Contract:        contract,
+		ContractAddress: contractAddr,
+		ContractTxHash:  &contractTxHash,
+		ContractTx:      contractTx,
+		ContractFee:     contractFee,
+		RefundTx:        refundTx,
+		RefundFee:       refundFee,
+	}, nil
+}
+
+func buildRefund(chainParams *chaincfg.Params, w Wallet, contract []byte, contractTx *wire.MsgTx, feePerKb btcutil.Amount) (
+	refundTx *wire.MsgTx, refundFee btcutil.Amount, err error) {
+
+	contractOut, contractType := findContractOutput(chainParams, contract, contractTx)
+	if contractOut == -1 {
+		return nil, 0, errors.New("contract tx does not contain a contract payment")
+	}
+	contractOutPoint := wire.OutPoint{Hash: contractTx.TxHash(), Index: uint32(contractOut)}
+
+	refundAddress, err := getUnusedAddress(chainParams, w)
+	if err != nil {
+		return nil, 0, fmt.Errorf("getunusedaddress: %v", err)
+	}
+	refundOutScript, err := txscript.PayToAddrScript(refundAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pushes == nil {
+		return nil, 0, errors.New("contract is not an atomic swap script recognized by this tool")
+	}
+
+	refundAddr, err := btcutil.NewAddressPubKeyHash(pushes.RefundHash160[:], chainParams)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	refundTx = wire.NewMsgTx(txVersion)
+	refundTx.LockTime = uint32(pushes.LockTime)
+	refundTx.AddTxOut(wire.NewTxOut(0, refundOutScript)) // amount set below
+	refundSize := estimateRefundVirtualSize(contract, contractType, refundTx.TxOut)
+	refundFee = txrules.FeeForSerializeSize(feePerKb, refundSize)
+	refundTx.TxOut[0].Value = contractTx.TxOut[contractOutPoint.Index].Value - int64(refundFee)
+	if txrules.IsDustOutput(refundTx.TxOut[0], feePerKb) {
+		return nil, 0, fmt.Errorf("refund output value of %v is dust", btcutil.Amount(refundTx.TxOut[0].Value))
+	}
+
+	txIn := wire.NewTxIn(&contractOutPoint, nil, nil)
+	txIn.Sequence = 0
+	refundTx.AddTxIn(txIn)
+
+	err = signContractInput(refundTx, 0, contract, contractType, contractTx.TxOut[contractOut].Value, refundAddr, w, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if verify {
+		err = verifyInput(contractTx, contractOutPoint.Index, refundTx, 0)
+		if err != nil {
+			return nil, 0, fmt.Errorf("refund transaction does not spend the contract: %v", err)
+		}
+	}
+
+	return refundTx, refundFee, nil
+}
+
+// findContractOutput returns the index and type of the output paying to the contract,
+// or -1 if the transaction does not contain such an output.
+func findContractOutput(chainParams *chaincfg.Params, contract []byte, contractTx *wire.MsgTx) (int, ContractType) {
+	for _, contractType := range []ContractType{P2SH, P2WSH, P2SHP2WSH} {
+		addr, err := contractAddress(chainParams, contract, contractType)
+		if err != nil {
+			continue
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			continue
+		}
+		for i, out := range contractTx.TxOut {
+			if bytes.Equal(out.PkScript, pkScript) {
+				return i, contractType
+			}
+		}
+	}
+	return -1, P2SH
+}
+
+// contractAddress returns the address of the output of the given type locking funds to the contract
+func contractAddress(chainParams *chaincfg.Params, contract []byte, contractType ContractType) (btcutil.Address, error) {
+	switch contractType {
+	case P2SH:
+		return btcutil.NewAddressScriptHash(contract, chainParams)
+	case P2WSH:
+		return btcutil.NewAddressWitnessScriptHash(sha256Hash(contract), chainParams)
+	case P2SHP2WSH:
+		program, err := witnessProgram(contract)
+		if err != nil {
+			return nil, err
+		}
+		return btcutil.NewAddressScriptHash(program, chainParams)
+	default:
+		return nil, fmt.Errorf("unknown contract type %v", contractType)
+	}
+}
+
+// witnessProgram returns the version 0 witness program of the contract,
+// which is the redeem script of a P2SH-P2WSH output
+func witnessProgram(contract []byte) ([]byte, error) {
+	return txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(sha256Hash(contract)).Script()
+}
+
+// atomicSwapContract returns an output script that may be redeemed by one of
+// two signature scripts:
+//
+//	<their sig> <their pubkey> <initiator secret> 1
+//
+//	<my sig> <my pubkey> 0
+//
+// The first signature script is the normal redemption path done by the other
+// party and requires the initiator's secret.  The second signature script is
+// the refund path performed by us, but the refund can only be performed after
+// locktime.
+func atomicSwapContract(pkhMe, pkhThem *[ripemd160.Size]byte, locktime int64, secretHash []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+
+	b.AddOp(txscript.OP_IF) // Normal redeem path
+	{
+		// Require initiator's secret to be a known length that the redeeming
+		// party can audit.  This is used to prevent fraud attacks between two
+		// currencies that have different maximum data sizes.
+		b.AddOp(txscript.OP_SIZE)
+		b.AddInt64(secretSize)
+		b.AddOp(txscript.OP_EQUALVERIFY)
+
+		// Require initiator's secret to be known to redeem the output.
+		b.AddOp(txscript.OP_SHA256)
+		b.AddData(secretHash)
+		b.AddOp(txscript.OP_EQUALVERIFY)
+
+		// Verify their signature is being used to redeem the output.  This
+		// would normally end with OP_EQUALVERIFY OP_CHECKSIG but this has been
+		// moved outside of the branch to save a couple bytes.
+		b.AddOp(txscript.OP_DUP)
+		b.AddOp(txscript.OP_HASH160)
+		b.AddData(pkhThem[:])
+	}
+	b.AddOp(txscript.OP_ELSE) // Refund path
+	{
+		// Verify locktime and drop it off the stack (which is not done by
+		// CLTV).
+		b.AddInt64(locktime)
+		b.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
+		b.AddOp(txscript.OP_DROP)
+
+		// Verify our signature is being used to redeem the output.  This would
+		// normally end with OP_EQUALVERIFY OP_CHECKSIG but this has been moved
+		// outside of the branch to save a couple bytes.
+		b.AddOp(txscript.OP_DUP)
+		b.AddOp(txscript.OP_HASH160)
+		b.AddData(pkhMe[:])
+	}
+	b.AddOp(txscript.OP_ENDIF)
+
+	// Complete the signature check.
+	b.AddOp(txscript.OP_EQUALVERIFY)
+	b.AddOp(txscript.OP_CHECKSIG)
+
+	return b.Script()
+}
+
+// redeemP2SHContract returns the signature script to redeem a contract output
+// using the redeemer's signature and the initiator's secret.  This function
+// assumes P2SH and appends the contract as the final data push.
+func redeemP2SHContract(contract, sig, pubkey, secret []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+	b.AddData(sig)
+	b.AddData(pubkey)
+	b.AddData(secret)
+	b.AddInt64(1)
+	b.AddData(contract)
+	return b.Script()
+}
+
+// refundP2SHContract returns the signature script to refund a contract output
+// using the contract author's signature after the locktime has been reached.
+// This function assumes P2SH and appends the contract as the final data push.
+func refundP2SHContract(contract, sig, pubkey []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+	b.AddData(sig)
+	b.AddData(pubkey)
+	b.AddInt64(0)
+	b.AddData(contract)
+	return b.Script()
+}
+
+// redeemP2WSHContract returns the witness to redeem a P2WSH contract output
+// using the redeemer's signature and the initiator's secret.
+// The contract is the final item of the witness.
+func redeemP2WSHContract(contract, sig, pubkey, secret []byte) wire.TxWitness {
+	return wire.TxWitness{sig, pubkey, secret, {1}, contract}
+}
+
+// refundP2WSHContract returns the witness to refund a P2WSH contract output
+// using the contract author's signature after the locktime has been reached.
+// The contract is the final item of the witness.
+func refundP2WSHContract(contract, sig, pubkey []byte) wire.TxWitness {
+	return wire.TxWitness{sig, pubkey, nil, contract}
+}
+
+// signContractInput signs the input at index idx of tx, spending a contract output of the given type and value,
+// with the key of addr. The input redeems the contract using the secret, or refunds it if the secret is nil.
+func signContractInput(tx *wire.MsgTx, idx int, contract []byte, contractType ContractType, value int64,
+	addr btcutil.Address, w Wallet, secret []byte) error {
+
+	txIn := tx.TxIn[idx]
+	if contractType == P2SH {
+		sig, pubkey, err := createSig(tx, idx, contract, addr, w)
+		if err != nil {
+			return err
+		}
+		if secret != nil {
+			txIn.SignatureScript, err = redeemP2SHContract(contract, sig, pubkey, secret)
+		} else {
+			txIn.SignatureScript, err = refundP2SHContract(contract, sig, pubkey)
+		}
+		return err
+	}
+
+	sig, pubkey, err := createWitnessSig(tx, idx, contract, value, addr, w)
+	if err != nil {
+		return err
+	}
+	if secret != nil {
+		txIn.Witness = redeemP2WSHContract(contract, sig, pubkey, secret)
+	} else {
+		txIn.Witness = refundP2WSHContract(contract, sig, pubkey)
+	}
+	if contractType == P2SHP2WSH {
+		// the signature script only pushes the witness program
+		program, err := witnessProgram(contract)
+		if err != nil {
+			return err
+		}
+		txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(program).Script()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Package electrumtest provides a Bitcoin chain kept in memory,
+// with Electrum wallets serving the JSON-RPC methods used by the btc package,
+// so atomic swaps can be tested without a Bitcoin network.
+package electrumtest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+)
+
+// Chain is an in-memory Bitcoin chain of the unspent outputs of the transactions
+// broadcast by its wallets, each transaction being mined in its own block.
+// The scripts of the inputs of broadcast transactions are executed by the btcd script engine.
+type Chain struct {
+	params *chaincfg.Params
+
+	mu     sync.Mutex
+	offset time.Duration
+	height int32
+	utxos  map[wire.OutPoint]*wire.TxOut
+	txs    map[chainhash.Hash]*wire.MsgTx
+	// heights of the blocks of the transactions
+	heights map[chainhash.Hash]int32
+	// history of the transactions paying to or spending from an output script,
+	// by output script
+	history map[string][]chainhash.Hash
+}
+
+// NewChain creates an empty chain of the network
+func NewChain(params *chaincfg.Params) *Chain {
+	return &Chain{
+		params:  params,
+		utxos:   make(map[wire.OutPoint]*wire.TxOut),
+		txs:     make(map[chainhash.Hash]*wire.MsgTx),
+		heights: make(map[chainhash.Hash]int32),
+		history: make(map[string][]chainhash.Hash),
+	}
+}
+
+// Params returns the parameters of the network of the chain
+func (c *Chain) Params() *chaincfg.Params {
+	return c.params
+}
+
+// AdjustTime moves the clock of the chain,
+// which is used to check time locks of transactions
+func (c *Chain) AdjustTime(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset += d
+}
+
+// Now returns the time of the chain
+func (c *Chain) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now()
+}
+
+func (c *Chain) now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+// Height returns the height of the last block
+func (c *Chain) Height() int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.height
+}
+
+// Transaction returns a mined transaction
+func (c *Chain) Transaction(txHash chainhash.Hash) (*wire.MsgTx, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, ok := c.txs[txHash]
+	return tx, ok
+}
+
+// Unspent reports if the output is unspent
+func (c *Chain) Unspent(outPoint wire.OutPoint) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.utxos[outPoint]
+	return ok
+}
+
+// Broadcast validates the transaction and mines it in a new block
+func (c *Chain) Broadcast(tx *wire.MsgTx) (*chainhash.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	txHash := tx.TxHash()
+	if _, ok := c.txs[txHash]; ok {
+		return nil, errors.New("transaction already in block chain")
+	}
+	if err := c.validate(tx); err != nil {
+		return nil, err
+	}
+	c.mine(tx)
+	return &txHash, nil
+}
+
+// validate the transaction against the unspent outputs and the time of the chain
+func (c *Chain) validate(tx *wire.MsgTx) error {
+	if len(tx.TxIn) == 0 {
+		return errors.New("bad-txns-vin-empty")
+	}
+	if len(tx.TxOut) == 0 {
+		return errors.New("bad-txns-vout-empty")
+	}
+	if !c.isFinal(tx) {
+		return errors.New("non-final")
+	}
+
+	var inputValue, outputValue int64
+	spent := make(map[wire.OutPoint]bool, len(tx.TxIn))
+	for _, in := range tx.TxIn {
+		if spent[in.PreviousOutPoint] {
+			return errors.New("bad-txns-inputs-duplicate")
+		}
+		spent[in.PreviousOutPoint] = true
+		prevOut, ok := c.utxos[in.PreviousOutPoint]
+		if !ok {
+			return errors.New("bad-txns-inputs-missingorspent")
+		}
+		inputValue += prevOut.Value
+	}
+	for _, out := range tx.TxOut {
+		if out.Value < 0 {
+			return errors.New("bad-txns-vout-negative")
+		}
+		outputValue += out.Value
+	}
+	if outputValue > inputValue {
+		return errors.New("bad-txns-in-belowout")
+	}
+	minFee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, virtualSize(tx))
+	if fee := btcutil.Amount(inputValue - outputValue); fee < minFee {
+		return fmt.Errorf("min relay fee not met, %v < %v", fee, minFee)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i, in := range tx.TxIn {
+		prevOut := c.utxos[in.PreviousOutPoint]
+		e, err := txscript.NewEngine(prevOut.PkScript, tx, i,
+			txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value)
+		if err != nil {
+			return fmt.Errorf("mandatory-script-verify-flag-failed (input %d): %v", i, err)
+		}
+		if err = e.Execute(); err != nil {
+			return fmt.Errorf("mandatory-script-verify-flag-failed (input %d): %v", i, err)
+		}
+	}
+	return nil
+}
+
+// virtualSize returns the size of the transaction its fee is paid for,
+// counting the bytes of witness data for a quarter
+func virtualSize(tx *wire.MsgTx) int {
+	weight := tx.SerializeSizeStripped()*3 + tx.SerializeSize()
+	return (weight + 3) / 4
+}
+
+// isFinal reports if the transaction can be included in the next block,
+// comparing its lock time to the height of the block or the time of the chain
+func (c *Chain) isFinal(tx *wire.MsgTx) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+	lockTime := int64(tx.LockTime)
+	blockTimeOrHeight := int64(c.height + 1)
+	if lockTime >= txscript.LockTimeThreshold {
+		blockTimeOrHeight = c.now().Unix()
+	}
+	if lockTime < blockTimeOrHeight {
+		return true
+	}
+	for _, in := range tx.TxIn {
+		if in.Sequence != wire.MaxTxInSequenceNum {
+			return false
+		}
+	}
+	return true
+}
+
+// mine the transaction in a new block
+func (c *Chain) mine(tx *wire.MsgTx) {
+	c.height++
+	txHash := tx.TxHash()
+	c.txs[txHash] = tx
+	c.heights[txHash] = c.height
+	if !isCoinbase(tx) {
+		for _, in := range tx.TxIn {
+			prevOut := c.utxos[in.PreviousOutPoint]
+			c.addHistory(prevOut.PkScript, txHash)
+			delete(c.utxos, in.PreviousOutPoint)
+		}
+	}
+	for i, out := range tx.TxOut {
+		c.utxos[wire.OutPoint{Hash: txHash, Index: uint32(i)}] = out
+		c.addHistory(out.PkScript, txHash)
+	}
+}
+
+func (c *Chain) addHistory(pkScript []byte, txHash chainhash.Hash) {
+	history := c.history[string(pkScript)]
+	if len(history) > 0 && history[len(history)-1] == txHash {
+		return
+	}
+	c.history[string(pkScript)] = append(history, txHash)
+}
+
+// mint mines a coinbase transaction paying the value to the output script
+func (c *Chain) mint(pkScript []byte, value btcutil.Amount) *wire.MsgTx {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the height in the coinbase script makes every coinbase transaction unique
+	coinbaseScript, err := txscript.NewScriptBuilder().AddInt64(int64(c.height + 1)).AddInt64(0).Script()
+	if err != nil {
+		panic(err)
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), coinbaseScript, nil))
+	tx.AddTxOut(wire.NewTxOut(int64(value), pkScript))
+	c.mine(tx)
+	return tx
+}
+
+func isCoinbase(tx *wire.MsgTx) bool {
+	return len(tx.TxIn) == 1 && tx.TxIn[0].PreviousOutPoint.Index == wire.MaxPrevOutIndex &&
+		tx.TxIn[0].PreviousOutPoint.Hash == chainhash.Hash{}
+}
+
+// unspent is an unspent output
+type unspent struct {
+	outPoint wire.OutPoint
+	out      *wire.TxOut
+	height   int32
+}
+
+// unspentOutputs returns the unspent outputs paying to the output scripts, in the order they were mined
+func (c *Chain) unspentOutputs(pkScripts map[string]bool) []unspent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var outputs []unspent
+	for outPoint, out := range c.utxos {
+		if pkScripts[string(out.PkScript)] {
+			outputs = append(outputs, unspent{outPoint: outPoint, out: out, height: c.heights[outPoint.Hash]})
+		}
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].height != outputs[j].height {
+			return outputs[i].height < outputs[j].height
+		}
+		return outputs[i].outPoint.Index < outputs[j].outPoint.Index
+	})
+	return outputs
+}
+
+// historyEntry is a transaction of the history of an output script
+type historyEntry struct {
+	txHash chainhash.Hash
+	height int32
+}
+
+// addressHistory returns the transactions paying to or spending from the output script
+func (c *Chain) addressHistory(pkScript []byte) []historyEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	history := make([]historyEntry, 0, len(c.history[string(pkScript)]))
+	for _, txHash := range c.history[string(pkScript)] {
+		history = append(history, historyEntry{txHash: txHash, height: c.heights[txHash]})
+	}
+	return history
+}
@@ -0,0 +1,104 @@
+package electrumtest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+)
+
+func TestPayTo(t *testing.T) {
+	c := NewChain(&chaincfg.MainNetParams)
+	w := c.NewWallet()
+	w.Fund(btcutil.SatoshiPerBitcoin)
+	server := httptest.NewServer(w)
+	defer server.Close()
+	client, err := rpc.New(&rpc.ConnConfig{
+		Host:         server.Listener.Addr().String(),
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		client.Shutdown()
+		client.WaitForShutdown()
+	}()
+
+	destination := c.NewWallet().NewAddress()
+	tx, complete, err := client.PayTo(destination, btcutil.SatoshiPerBitcoin/10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Fatal("signed transaction is not complete")
+	}
+	// the inputs are only spent once the transaction is broadcast
+	utxos, err := client.ListUnspent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 || utxos[0].Value != btcutil.SatoshiPerBitcoin {
+		t.Fatalf("unexpected unspent outputs before broadcast: %v", utxos)
+	}
+
+	unsigned, _, err := client.PayTo(destination, btcutil.SatoshiPerBitcoin/10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Broadcast(unsigned); err == nil {
+		t.Error("unsigned transaction broadcast")
+	}
+	txHash, err := client.Broadcast(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *txHash != tx.TxHash() {
+		t.Errorf("broadcast returned %v, expected %v", txHash, tx.TxHash())
+	}
+	if _, err = client.Broadcast(tx); err == nil {
+		t.Error("transaction broadcast twice")
+	}
+
+	fee := btcutil.Amount(btcutil.SatoshiPerBitcoin - tx.TxOut[0].Value - tx.TxOut[1].Value)
+	if minFee := btcutil.Amount(tx.SerializeSize()) * DefaultFeeRate / 1000; fee < minFee || fee > 2*minFee {
+		t.Errorf("paid a fee of %v for %d bytes", fee, tx.SerializeSize())
+	}
+	if balance := w.Balance(); balance != btcutil.SatoshiPerBitcoin*9/10-fee {
+		t.Errorf("wallet balance is %v after paying %v with a fee of %v", balance, btcutil.Amount(btcutil.SatoshiPerBitcoin/10), fee)
+	}
+
+	// the change address is used, the first unused address is a new one
+	address, err := client.GetUnusedAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changePkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range tx.TxOut {
+		if string(out.PkScript) == string(changePkScript) {
+			t.Errorf("used address %v returned as unused", address)
+		}
+	}
+	wif, err := client.DumpPrivKey(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkh := btcutil.Hash160(wif.SerializePubKey()); string(pkh) != string(address.ScriptAddress()) {
+		t.Errorf("private key of another address returned for %v", address)
+	}
+
+	history, err := client.GetAddressHistory(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || *history[0].TxHash != tx.TxHash() || history[0].Height != int64(c.Height()) {
+		t.Errorf("unexpected history of the destination: %v", history)
+	}
+}
@@ -0,0 +1,416 @@
+package electrumtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+)
+
+// DefaultFeeRate is the fee rate per kilobyte returned by getfeerate,
+// the minimum relay fee so transactions with underestimated sizes are rejected
+const DefaultFeeRate = txrules.DefaultRelayFeePerKb
+
+// p2pkhSigScriptSize is the worst case size of a signature script spending a P2PKH output:
+// a 72 bytes DER signature and sighash type, a 33 bytes compressed public key and their pushes
+const p2pkhSigScriptSize = 1 + 73 + 1 + 33
+
+// JSON-RPC error codes
+const (
+	errCodeMethodNotFound rpc.RPCErrorCode = -32601
+	errCodeInvalidParams  rpc.RPCErrorCode = -32602
+	errCodeWallet         rpc.RPCErrorCode = 1
+)
+
+// Wallet is an Electrum wallet of P2PKH addresses on a chain,
+// serving the Electrum JSON-RPC methods used by the btc package over HTTP.
+type Wallet struct {
+	chain *Chain
+	// FeeRate is returned by getfeerate and used to fund payto transactions
+	FeeRate btcutil.Amount
+
+	mu sync.Mutex
+	// keys of the addresses, in the order they were created
+	keys      []*btcutil.WIF
+	addresses []*btcutil.AddressPubKeyHash
+}
+
+// NewWallet creates a wallet without addresses on the chain
+func (c *Chain) NewWallet() *Wallet {
+	return &Wallet{
+		chain:   c,
+		FeeRate: DefaultFeeRate,
+	}
+}
+
+// NewAddress adds a new address to the wallet
+func (w *Wallet) NewAddress() *btcutil.AddressPubKeyHash {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.newAddress()
+}
+
+func (w *Wallet) newAddress() *btcutil.AddressPubKeyHash {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		panic(err)
+	}
+	wif, err := btcutil.NewWIF(privKey, w.chain.params, true)
+	if err != nil {
+		panic(err)
+	}
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), w.chain.params)
+	if err != nil {
+		panic(err)
+	}
+	w.keys = append(w.keys, wif)
+	w.addresses = append(w.addresses, addr)
+	return addr
+}
+
+// Fund mines a coinbase transaction paying the amount to a new address of the wallet
+func (w *Wallet) Fund(amount btcutil.Amount) *wire.MsgTx {
+	pkScript, err := txscript.PayToAddrScript(w.NewAddress())
+	if err != nil {
+		panic(err)
+	}
+	return w.chain.mint(pkScript, amount)
+}
+
+// Balance returns the total value of the unspent outputs of the wallet
+func (w *Wallet) Balance() btcutil.Amount {
+	var balance btcutil.Amount
+	for _, u := range w.unspentOutputs() {
+		balance += btcutil.Amount(u.out.Value)
+	}
+	return balance
+}
+
+// pkScripts returns the output scripts of the addresses of the wallet
+func (w *Wallet) pkScripts() map[string]bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pkScripts := make(map[string]bool, len(w.addresses))
+	for _, addr := range w.addresses {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			panic(err)
+		}
+		pkScripts[string(pkScript)] = true
+	}
+	return pkScripts
+}
+
+func (w *Wallet) unspentOutputs() []unspent {
+	return w.chain.unspentOutputs(w.pkScripts())
+}
+
+// key returns the key of an address of the wallet
+func (w *Wallet) key(address string) (*btcutil.WIF, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, addr := range w.addresses {
+		if addr.EncodeAddress() == address {
+			return w.keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("address %s is not in the wallet", address)
+}
+
+// keyForScript returns the key of the address an output script pays to
+func (w *Wallet) keyForScript(pkScript []byte) (*btcutil.WIF, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, w.chain.params)
+	if err != nil || len(addrs) != 1 {
+		return nil, errors.New("output script does not pay to an address")
+	}
+	return w.key(addrs[0].EncodeAddress())
+}
+
+// request is a JSON-RPC request, with positional or named parameters
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     interface{}     `json:"id"`
+}
+
+type response struct {
+	Result interface{}   `json:"result"`
+	Error  *rpc.RPCError `json:"error"`
+	ID     interface{}   `json:"id"`
+}
+
+// ServeHTTP serves the JSON-RPC methods of the wallet
+func (w *Wallet) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := w.call(req.Method, req.Params)
+	resp := response{Result: result, ID: req.ID}
+	if err != nil {
+		rpcErr, ok := err.(*rpc.RPCError)
+		if !ok {
+			rpcErr = &rpc.RPCError{Code: errCodeWallet, Message: err.Error()}
+		}
+		resp.Result, resp.Error = nil, rpcErr
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(resp)
+}
+
+func (w *Wallet) call(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "payto":
+		var args struct {
+			Destination string  `json:"destination"`
+			Amount      float64 `json:"amount"`
+			Unsigned    bool    `json:"unsigned"`
+		}
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, invalidParams(err)
+		}
+		return w.payTo(args.Destination, args.Amount, args.Unsigned)
+	case "listunspent":
+		return w.listUnspent(), nil
+	case "getfeerate":
+		return int64(w.FeeRate), nil
+	case "getunusedaddress":
+		return w.unusedAddress().EncodeAddress(), nil
+	case "getprivatekeys":
+		var address string
+		if err := positional(params, &address); err != nil {
+			return nil, err
+		}
+		wif, err := w.key(address)
+		if err != nil {
+			return nil, err
+		}
+		return "p2pkh:" + wif.String(), nil
+	case "broadcast":
+		var txHex string
+		if err := positional(params, &txHex); err != nil {
+			return nil, err
+		}
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+		txHash, err := w.chain.Broadcast(tx)
+		if err != nil {
+			return nil, err
+		}
+		return txHash.String(), nil
+	case "getaddresshistory":
+		var address string
+		if err := positional(params, &address); err != nil {
+			return nil, err
+		}
+		return w.addressHistory(address)
+	case "gettransaction":
+		var txID string
+		if err := positional(params, &txID); err != nil {
+			return nil, err
+		}
+		txHash, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+		tx, ok := w.chain.Transaction(*txHash)
+		if !ok {
+			return nil, fmt.Errorf("transaction %s not found", txID)
+		}
+		return encodeTx(tx), nil
+	default:
+		return nil, &rpc.RPCError{Code: errCodeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+// positional decodes the positional parameters
+func positional(params json.RawMessage, args ...interface{}) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(params, &raw); err != nil {
+		return invalidParams(err)
+	}
+	if len(raw) != len(args) {
+		return invalidParams(fmt.Errorf("expected %d parameters, got %d", len(args), len(raw)))
+	}
+	for i, arg := range args {
+		if err := json.Unmarshal(raw[i], arg); err != nil {
+			return invalidParams(err)
+		}
+	}
+	return nil
+}
+
+func invalidParams(err error) error {
+	return &rpc.RPCError{Code: errCodeInvalidParams, Message: err.Error()}
+}
+
+// payTo creates a transaction paying the amount to the destination,
+// funded by the unspent outputs of the wallet with change to a new address.
+// The transaction is not broadcast, so its inputs remain unspent.
+func (w *Wallet) payTo(destination string, amountBTC float64, unsigned bool) (interface{}, error) {
+	addr, err := btcutil.DecodeAddress(destination, w.chain.params)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	amount, err := btcutil.NewAmount(amountBTC)
+	if err != nil || amount <= 0 {
+		return nil, invalidParams(fmt.Errorf("invalid amount %v", amountBTC))
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxOut(wire.NewTxOut(int64(amount), pkScript))
+	// the change output is removed if it turns out to be dust
+	changePkScript, err := txscript.PayToAddrScript(w.NewAddress())
+	if err != nil {
+		return nil, err
+	}
+	change := wire.NewTxOut(0, changePkScript)
+	tx.AddTxOut(change)
+
+	var inputValue btcutil.Amount
+	var prevOuts []*wire.TxOut
+	var fee btcutil.Amount
+	for _, u := range w.unspentOutputs() {
+		tx.AddTxIn(wire.NewTxIn(&u.outPoint, nil, nil))
+		prevOuts = append(prevOuts, u.out)
+		inputValue += btcutil.Amount(u.out.Value)
+		fee = txrules.FeeForSerializeSize(w.FeeRate, tx.SerializeSize()+len(tx.TxIn)*p2pkhSigScriptSize)
+		if inputValue >= amount+fee {
+			break
+		}
+	}
+	if inputValue < amount+fee {
+		return nil, fmt.Errorf("insufficient funds: %v available to pay %v", inputValue, amount+fee)
+	}
+	change.Value = int64(inputValue - amount - fee)
+	if txrules.IsDustOutput(change, w.FeeRate) {
+		tx.TxOut = tx.TxOut[:1]
+	}
+
+	if !unsigned {
+		for i, in := range tx.TxIn {
+			key, err := w.keyForScript(prevOuts[i].PkScript)
+			if err != nil {
+				return nil, err
+			}
+			in.SignatureScript, err = txscript.SignatureScript(tx, i, prevOuts[i].PkScript, txscript.SigHashAll, key.PrivKey, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return map[string]interface{}{
+		"complete": !unsigned,
+		"final":    true,
+		"hex":      encodeTx(tx),
+	}, nil
+}
+
+type unspentOutput struct {
+	Address     string `json:"address"`
+	Value       string `json:"value"`
+	PrevoutN    uint32 `json:"prevout_n"`
+	PrevoutHash string `json:"prevout_hash"`
+	Height      int32  `json:"height"`
+	Coinbase    bool   `json:"coinbase"`
+}
+
+func (w *Wallet) listUnspent() []unspentOutput {
+	outputs := make([]unspentOutput, 0)
+	for _, u := range w.unspentOutputs() {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(u.out.PkScript, w.chain.params)
+		if err != nil || len(addrs) != 1 {
+			continue
+		}
+		tx, _ := w.chain.Transaction(u.outPoint.Hash)
+		outputs = append(outputs, unspentOutput{
+			Address:     addrs[0].EncodeAddress(),
+			Value:       strconv.FormatFloat(btcutil.Amount(u.out.Value).ToBTC(), 'f', -1, 64),
+			PrevoutN:    u.outPoint.Index,
+			PrevoutHash: u.outPoint.Hash.String(),
+			Height:      u.height,
+			Coinbase:    tx != nil && isCoinbase(tx),
+		})
+	}
+	return outputs
+}
+
+// unusedAddress returns the first address of the wallet without history,
+// adding a new address if all addresses are used
+func (w *Wallet) unusedAddress() *btcutil.AddressPubKeyHash {
+	w.mu.Lock()
+	addresses := append([]*btcutil.AddressPubKeyHash(nil), w.addresses...)
+	w.mu.Unlock()
+	for _, addr := range addresses {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			panic(err)
+		}
+		if len(w.chain.addressHistory(pkScript)) == 0 {
+			return addr
+		}
+	}
+	return w.NewAddress()
+}
+
+type historyResult struct {
+	TxHash string `json:"tx_hash"`
+	Height int32  `json:"height"`
+}
+
+// addressHistory returns the history of any address, not only the ones of the wallet
+func (w *Wallet) addressHistory(address string) ([]historyResult, error) {
+	addr, err := btcutil.DecodeAddress(address, w.chain.params)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	history := make([]historyResult, 0)
+	for _, entry := range w.chain.addressHistory(pkScript) {
+		history = append(history, historyResult{TxHash: entry.txHash.String(), Height: entry.height})
+	}
+	return history, nil
+}
+
+func encodeTx(tx *wire.MsgTx) string {
+	var buf bytes.Buffer
+	buf.Grow(tx.SerializeSize())
+	if err := tx.Serialize(&buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf.Bytes())
+}
+
+func decodeTx(txHex string) (*wire.MsgTx, error) {
+	b, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(wire.MsgTx)
+	if err = tx.Deserialize(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,84 @@
+package btc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+)
+
+// ErrNotSpent is returned when the contract output has not been spent yet
+var ErrNotSpent = errors.New("contract output has not been spent yet")
+
+// ExtractSecret from a transaction redeeming a contract with the given secret hash
+func ExtractSecret(redemptionTx *wire.MsgTx, secretHash []byte) ([]byte, error) {
+	// Loop over all pushed data from all inputs, searching for one that hashes
+	// to the expected hash.  By searching through all data pushes, we avoid any
+	// issues that could be caused by the initiator redeeming the participant's
+	// contract with some "nonstandard" or unrecognized transaction or script
+	// type.
+	// The witness items of the inputs are searched as well, for contracts locked to P2WSH outputs.
+	for _, in := range redemptionTx.TxIn {
+		pushes, err := txscript.PushedData(in.SignatureScript)
+		if err != nil {
+			return nil, err
+		}
+		pushes = append(pushes, in.Witness...)
+		for _, push := range pushes {
+			if bytes.Equal(sha256Hash(push), secretHash) {
+				return push, nil
+			}
+		}
+	}
+	return nil, errors.New("transaction does not contain the secret")
+}
+
+// TransactionLookup is implemented by wallets which can look up
+// transactions that are not related to the wallet itself,
+// such as *rpcclient.Client.
+type TransactionLookup interface {
+	// GetAddressHistory returns the transactions involving an address.
+	GetAddressHistory(address btcutil.Address) ([]rpc.AddressHistoryEntry, error)
+	// GetTransaction retrieves a transaction from the network.
+	GetTransaction(txHash *chainhash.Hash) (*wire.MsgTx, error)
+}
+
+// FindRedemptionTransaction looks up the transaction spending the contract output
+// of contractTx using the history of the contract's P2SH or P2WSH address.
+// It returns ErrNotSpent if the contract output has not been spent yet.
+func FindRedemptionTransaction(chainParams *chaincfg.Params, l TransactionLookup, contract []byte, contractTx *wire.MsgTx) (*wire.MsgTx, error) {
+	contractOut, contractType := findContractOutput(chainParams, contract, contractTx)
+	if contractOut < 0 {
+		return nil, errors.New("transaction does not contain the contract output")
+	}
+	contractAddr, err := contractAddress(chainParams, contract, contractType)
+	if err != nil {
+		return nil, err
+	}
+	history, err := l.GetAddressHistory(contractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("getaddresshistory: %v", err)
+	}
+	contractOutPoint := wire.OutPoint{Hash: contractTx.TxHash(), Index: uint32(contractOut)}
+	for _, entry := range history {
+		if entry.TxHash.IsEqual(&contractOutPoint.Hash) {
+			continue
+		}
+		tx, err := l.GetTransaction(entry.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("gettransaction: %v", err)
+		}
+		for _, in := range tx.TxIn {
+			if in.PreviousOutPoint == contractOutPoint {
+				return tx, nil
+			}
+		}
+	}
+	return nil, ErrNotSpent
+}
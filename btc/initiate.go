@@ -0,0 +1,66 @@
+package btc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+type (
+	// InitiateOutput is the result of the Initiate call
+	InitiateOutput struct {
+		// Secret is the secret of the atomic swap
+		Secret [secretSize]byte `json:"secret"`
+		// SecretHash is the SHA256 hash of the secret
+		SecretHash [sha256.Size]byte `json:"secretHash"`
+		BuiltContract
+	}
+)
+
+// Initiate an atomic swap by creating a contract paying amount to the participant,
+// refundable to an address of the wallet after the initiator lock time of the policy,
+// locked to an output of the given contract type.
+// The contract transaction is created but not published,
+// use PublishTransaction to do so.
+func Initiate(chainParams *chaincfg.Params, w Wallet, participant *btcutil.AddressPubKeyHash, amount btcutil.Amount, policy timings.Policy, contractType ContractType) (InitiateOutput, error) {
+	var secret [secretSize]byte
+	_, err := rand.Read(secret[:])
+	if err != nil {
+		return InitiateOutput{}, err
+	}
+	return InitiateWithSecret(chainParams, w, participant, amount, secret, policy, contractType)
+}
+
+// InitiateWithSecret is Initiate using a secret generated by the caller,
+// e.g. so it can be stored before the contract is created.
+func InitiateWithSecret(chainParams *chaincfg.Params, w Wallet, participant *btcutil.AddressPubKeyHash, amount btcutil.Amount, secret [secretSize]byte, policy timings.Policy, contractType ContractType) (InitiateOutput, error) {
+	if err := policy.Validate(); err != nil {
+		return InitiateOutput{}, err
+	}
+	secretHash := sha256.Sum256(secret[:])
+
+	// locktime after 500,000,000 (Tue Nov  5 00:53:20 1985 UTC) is interpreted
+	// as a unix time rather than a block height.
+	locktime := time.Now().Add(policy.Initiator).Unix()
+
+	b, err := buildContract(chainParams, w, &contractArgs{
+		them:         participant,
+		amount:       amount,
+		locktime:     locktime,
+		secretHash:   secretHash[:],
+		contractType: contractType,
+	})
+	if err != nil {
+		return InitiateOutput{}, err
+	}
+
+	return InitiateOutput{
+		Secret:        secret,
+		SecretHash:    secretHash,
+		BuiltContract: *b,
+	}, nil
+}
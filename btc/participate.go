@@ -0,0 +1,45 @@
+package btc
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+type (
+	// ParticipateOutput is the result of the Participate call
+	ParticipateOutput struct {
+		BuiltContract
+	}
+)
+
+// Participate in an atomic swap by creating a contract paying amount to the initiator
+// using the secret hash of the initiator's contract,
+// refundable after the participant lock time of the policy,
+// locked to an output of the given contract type.
+// The contract transaction is created but not published,
+// use PublishTransaction to do so.
+func Participate(chainParams *chaincfg.Params, w Wallet, initiator *btcutil.AddressPubKeyHash, amount btcutil.Amount, secretHash []byte, policy timings.Policy, contractType ContractType) (ParticipateOutput, error) {
+	if err := policy.Validate(); err != nil {
+		return ParticipateOutput{}, err
+	}
+	// locktime after 500,000,000 (Tue Nov  5 00:53:20 1985 UTC) is interpreted
+	// as a unix time rather than a block height.
+	locktime := time.Now().Add(policy.Participant).Unix()
+
+	b, err := buildContract(chainParams, w, &contractArgs{
+		them:         initiator,
+		amount:       amount,
+		locktime:     locktime,
+		secretHash:   secretHash,
+		contractType: contractType,
+	})
+	if err != nil {
+		return ParticipateOutput{}, err
+	}
+	return ParticipateOutput{
+		BuiltContract: *b,
+	}, nil
+}
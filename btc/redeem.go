@@ -0,0 +1,91 @@
+package btc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+)
+
+type (
+	// RedeemOutput is the result of the Redeem call
+	RedeemOutput struct {
+		RedeemTx  *wire.MsgTx    `json:"redeemTransaction"`
+		RedeemFee btcutil.Amount `json:"redeemFee"`
+	}
+)
+
+// Redeem creates a transaction spending the contract output to an address of the wallet,
+// using the secret of the atomic swap.
+// The redeem transaction is created but not published,
+// use PublishTransaction to do so.
+func Redeem(chainParams *chaincfg.Params, w Wallet, contract []byte, contractTx *wire.MsgTx, secret []byte) (RedeemOutput, error) {
+	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
+	if err != nil {
+		return RedeemOutput{}, err
+	}
+	if pushes == nil {
+		return RedeemOutput{}, errors.New("contract is not an atomic swap script recognized by this tool")
+	}
+	recipientAddr, err := btcutil.NewAddressPubKeyHash(pushes.RecipientHash160[:],
+		chainParams)
+	if err != nil {
+		return RedeemOutput{}, err
+	}
+	contractOut, contractType := findContractOutput(chainParams, contract, contractTx)
+	if contractOut == -1 {
+		return RedeemOutput{}, errors.New("transaction does not contain a contract output")
+	}
+
+	addr, err := getUnusedAddress(chainParams, w)
+	if err != nil {
+		return RedeemOutput{}, fmt.Errorf("getrawchangeaddres: %v", err)
+	}
+	outScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return RedeemOutput{}, err
+	}
+
+	contractTxHash := contractTx.TxHash()
+	contractOutPoint := wire.OutPoint{
+		Hash:  contractTxHash,
+		Index: uint32(contractOut),
+	}
+
+	feePerKb, err := getFeePerKb(w)
+	if err != nil {
+		return RedeemOutput{}, err
+	}
+
+	redeemTx := wire.NewMsgTx(txVersion)
+	redeemTx.LockTime = uint32(pushes.LockTime)
+	redeemTx.AddTxIn(wire.NewTxIn(&contractOutPoint, nil, nil))
+	redeemTx.AddTxOut(wire.NewTxOut(0, outScript)) // amount set below
+	redeemSize := estimateRedeemVirtualSize(contract, contractType, redeemTx.TxOut)
+	fee := txrules.FeeForSerializeSize(feePerKb, redeemSize)
+	redeemTx.TxOut[0].Value = contractTx.TxOut[contractOut].Value - int64(fee)
+	if txrules.IsDustOutput(redeemTx.TxOut[0], feePerKb) {
+		return RedeemOutput{}, fmt.Errorf("redeem output value of %v is dust", btcutil.Amount(redeemTx.TxOut[0].Value))
+	}
+
+	err = signContractInput(redeemTx, 0, contract, contractType, contractTx.TxOut[contractOut].Value, recipientAddr, w, secret)
+	if err != nil {
+		return RedeemOutput{}, err
+	}
+
+	if verify {
+		err = verifyInput(contractTx, contractOutPoint.Index, redeemTx, 0)
+		if err != nil {
+			return RedeemOutput{}, fmt.Errorf("redeem transaction does not spend the contract: %v", err)
+		}
+	}
+
+	return RedeemOutput{
+		RedeemTx:  redeemTx,
+		RedeemFee: fee,
+	}, nil
+}
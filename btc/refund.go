@@ -0,0 +1,46 @@
+package btc
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+type (
+	// RefundOutput is the result of the Refund call
+	RefundOutput struct {
+		RefundTx  *wire.MsgTx    `json:"refundTransaction"`
+		RefundFee btcutil.Amount `json:"refundFee"`
+	}
+)
+
+// Refund creates a transaction spending the contract output back to an address of the wallet,
+// which is only valid once the locktime of the contract is reached.
+// The refund transaction is created but not published,
+// use PublishTransaction to do so.
+func Refund(chainParams *chaincfg.Params, w Wallet, contract []byte, contractTx *wire.MsgTx) (RefundOutput, error) {
+	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
+	if err != nil {
+		return RefundOutput{}, err
+	}
+	if pushes == nil {
+		return RefundOutput{}, errors.New("contract is not an atomic swap script recognized by this tool")
+	}
+
+	feePerKb, err := getFeePerKb(w)
+	if err != nil {
+		return RefundOutput{}, err
+	}
+
+	refundTx, refundFee, err := buildRefund(chainParams, w, contract, contractTx, feePerKb)
+	if err != nil {
+		return RefundOutput{}, err
+	}
+	return RefundOutput{
+		RefundTx:  refundTx,
+		RefundFee: refundFee,
+	}, nil
+}
@@ -35,7 +35,7 @@ func (r FutureGetUnusedAddressResult) Receive() (btcutil.Address, error) {
 
 	}
 
-	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	return decodeAddress(addr)
 }
 
 // GetUnusedAddressCmd defines the getunusedaddress JSON-RPC command.
@@ -294,7 +294,7 @@ func (r FutureListUnspentResult) Receive() (utxos []*UnspentOutput, err error) {
 		if err != nil {
 			return nil, err
 		}
-		utxo.Address, err = btcutil.DecodeAddress(respUtxo.Address, nil)
+		utxo.Address, err = decodeAddress(respUtxo.Address)
 		if err != nil {
 			return nil, err
 		}
@@ -384,6 +384,132 @@ func (c *Client) Broadcast(tx *wire.MsgTx) (*chainhash.Hash, error) {
 	return c.BroadcastAsync(tx).Receive()
 }
 
+// AddressHistoryEntry is a transaction involving an address
+type AddressHistoryEntry struct {
+	TxHash *chainhash.Hash
+	Height int64
+}
+
+// GetAddressHistoryCmd defines the getaddresshistory RPC command.
+type GetAddressHistoryCmd struct {
+	Address string
+}
+
+// NewGetAddressHistoryCmd returns a new instance which can be used to issue a
+// getaddresshistory JSON-RPC command.
+func NewGetAddressHistoryCmd(address btcutil.Address) *GetAddressHistoryCmd {
+	return &GetAddressHistoryCmd{
+		Address: address.EncodeAddress(),
+	}
+}
+
+// FutureGetAddressHistoryResult is a future promise to deliver the result of
+// a getaddresshistory RPC invocation (or an applicable error).
+type FutureGetAddressHistoryResult chan *response
+
+// Receive waits for the response promised by the future and returns the history of the address.
+func (r FutureGetAddressHistoryResult) Receive() (history []AddressHistoryEntry, err error) {
+	rawResp, err := receiveFuture(r)
+	if err != nil {
+		return
+	}
+	var resp []struct {
+		TxHash string `json:"tx_hash"`
+		Height int64  `json:"height"`
+	}
+	err = json.Unmarshal(rawResp, &resp)
+	if err != nil {
+		err = errors.New("GetAddressHistory: " + err.Error() + ":" + string(rawResp))
+		return
+	}
+	history = make([]AddressHistoryEntry, len(resp))
+	for i, entry := range resp {
+		history[i].Height = entry.Height
+		history[i].TxHash, err = chainhash.NewHashFromStr(entry.TxHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// GetAddressHistoryAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAddressHistory for the blocking version and more details.
+func (c *Client) GetAddressHistoryAsync(address btcutil.Address) FutureGetAddressHistoryResult {
+	cmd := NewGetAddressHistoryCmd(address)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressHistory returns the transactions involving an address,
+// including the ones that are not related to the wallet.
+func (c *Client) GetAddressHistory(address btcutil.Address) ([]AddressHistoryEntry, error) {
+	return c.GetAddressHistoryAsync(address).Receive()
+}
+
+// GetTransactionCmd defines the gettransaction RPC command.
+type GetTransactionCmd struct {
+	TxID string
+}
+
+// NewGetTransactionCmd returns a new instance which can be used to issue a
+// gettransaction JSON-RPC command.
+func NewGetTransactionCmd(txHash *chainhash.Hash) *GetTransactionCmd {
+	return &GetTransactionCmd{
+		TxID: txHash.String(),
+	}
+}
+
+// FutureGetTransactionResult is a future promise to deliver the result of
+// a gettransaction RPC invocation (or an applicable error).
+type FutureGetTransactionResult chan *response
+
+// Receive waits for the response promised by the future and returns the decoded transaction.
+func (r FutureGetTransactionResult) Receive() (*wire.MsgTx, error) {
+	rawResp, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	// Older Electrum versions return an object, newer ones the raw hex string.
+	var txHex string
+	if err = json.Unmarshal(rawResp, &txHex); err != nil {
+		var resp struct {
+			Hex string `json:"hex"`
+		}
+		if err = json.Unmarshal(rawResp, &resp); err != nil {
+			return nil, errors.New("GetTransaction: " + err.Error() + ":" + string(rawResp))
+		}
+		txHex = resp.Hex
+	}
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	tx := &wire.MsgTx{}
+	err = tx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetTransactionAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetTransaction for the blocking version and more details.
+func (c *Client) GetTransactionAsync(txHash *chainhash.Hash) FutureGetTransactionResult {
+	cmd := NewGetTransactionCmd(txHash)
+	return c.sendCmd(cmd)
+}
+
+// GetTransaction retrieves a transaction from the network.
+func (c *Client) GetTransaction(txHash *chainhash.Hash) (*wire.MsgTx, error) {
+	return c.GetTransactionAsync(txHash).Receive()
+}
+
 //-----------------------
 // Btc-Core compatibility
 //-----------------------
@@ -402,4 +528,16 @@ func init() {
 	RegisterCmd("payto", (*PayToCmd)(nil), true)
 	RegisterCmd("listunspent", (*ListUnspentCmd)(nil), false)
 	RegisterCmd("broadcast", (*BroadcastCmd)(nil), false)
+	RegisterCmd("getaddresshistory", (*GetAddressHistoryCmd)(nil), false)
+	RegisterCmd("gettransaction", (*GetTransactionCmd)(nil), false)
+}
+
+// decodeAddress decodes an address of the main or the test network,
+// the wallet responses do not tell which network the wallet uses.
+func decodeAddress(addr string) (btcutil.Address, error) {
+	decoded, err := btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	if err == btcutil.ErrUnknownAddressType {
+		return btcutil.DecodeAddress(addr, &chaincfg.TestNet3Params)
+	}
+	return decoded, err
 }
@@ -3,7 +3,7 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-package main
+package btc
 
 import (
 	"github.com/btcsuite/btcd/txscript"
@@ -35,6 +35,37 @@ const (
 	//   - 33 bytes serialized compressed pubkey
 	//   - OP_FALSE
 	refundAtomicSwapSigScriptSize = 1 + 73 + 1 + 33 + 1
+
+	// redeemAtomicSwapWitnessSize is the worst case (largest) serialize size
+	// of the witness redeeming a P2WSH atomic swap output.  This does not
+	// include the final item for the contract itself.
+	//
+	//   - Compact int encoding of the number of items (5)
+	//   - Compact int encoding 73
+	//   - 72 bytes DER signature + 1 byte sighash
+	//   - Compact int encoding 33
+	//   - 33 bytes serialized compressed pubkey
+	//   - Compact int encoding 32
+	//   - 32 bytes secret
+	//   - Compact int encoding 1
+	//   - 1 byte true
+	redeemAtomicSwapWitnessSize = 1 + 1 + 73 + 1 + 33 + 1 + 32 + 1 + 1
+
+	// refundAtomicSwapWitnessSize is the worst case (largest) serialize size
+	// of the witness refunding a P2WSH atomic swap output.  This does not
+	// include the final item for the contract itself.
+	//
+	//   - Compact int encoding of the number of items (4)
+	//   - Compact int encoding 73
+	//   - 72 bytes DER signature + 1 byte sighash
+	//   - Compact int encoding 33
+	//   - 33 bytes serialized compressed pubkey
+	//   - Compact int encoding 0 for the empty false item
+	refundAtomicSwapWitnessSize = 1 + 1 + 73 + 1 + 33 + 1
+
+	// nestedWitnessSigScriptSize is the size of the signature script
+	// spending a P2SH-P2WSH output, pushing the 34 bytes witness program.
+	nestedWitnessSigScriptSize = 1 + 34
 )
 
 func sumOutputSerializeSizes(outputs []*wire.TxOut) (serializeSize int) {
@@ -89,3 +120,40 @@ func estimateRefundSerializeSize(contract []byte, txOuts []*wire.TxOut) int {
 		inputSize(refundAtomicSwapSigScriptSize+contractPushSize) +
 		sumOutputSerializeSizes(txOuts)
 }
+
+// estimateRedeemVirtualSize returns a worst case virtual size estimate for
+// a transaction that redeems an atomic swap output of the given type.
+func estimateRedeemVirtualSize(contract []byte, contractType ContractType, txOuts []*wire.TxOut) int {
+	if contractType == P2SH {
+		return estimateRedeemSerializeSize(contract, txOuts)
+	}
+	return estimateWitnessVirtualSize(contract, contractType, redeemAtomicSwapWitnessSize, txOuts)
+}
+
+// estimateRefundVirtualSize returns a worst case virtual size estimate for
+// a transaction that refunds an atomic swap output of the given type.
+func estimateRefundVirtualSize(contract []byte, contractType ContractType, txOuts []*wire.TxOut) int {
+	if contractType == P2SH {
+		return estimateRefundSerializeSize(contract, txOuts)
+	}
+	return estimateWitnessVirtualSize(contract, contractType, refundAtomicSwapWitnessSize, txOuts)
+}
+
+// estimateWitnessVirtualSize returns a worst case virtual size estimate for
+// a transaction spending a P2WSH or P2SH-P2WSH atomic swap output,
+// with a witness of witnessSize bytes before the contract.
+func estimateWitnessVirtualSize(contract []byte, contractType ContractType, witnessSize int, txOuts []*wire.TxOut) int {
+	sigScriptSize := 0
+	if contractType == P2SHP2WSH {
+		sigScriptSize = nestedWitnessSigScriptSize
+	}
+	// 8 additional bytes are for version and locktime.
+	baseSize := 8 + wire.VarIntSerializeSize(1) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputSize(sigScriptSize) +
+		sumOutputSerializeSizes(txOuts)
+	// 2 additional bytes are for the segwit marker and flag.
+	witnessSize += 2 + wire.VarIntSerializeSize(uint64(len(contract))) + len(contract)
+
+	return baseSize + (witnessSize+witnessScaleFactor-1)/witnessScaleFactor
+}
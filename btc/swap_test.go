@@ -0,0 +1,204 @@
+package btc
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threefoldtech/atomicswap/btc/electrumtest"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+var testParams = &chaincfg.MainNetParams
+
+// testWallet is a wallet of the test chain and the client of its Electrum RPC server
+type testWallet struct {
+	*electrumtest.Wallet
+	client *rpc.Client
+}
+
+func newTestWallet(t *testing.T, c *electrumtest.Chain, funds btcutil.Amount) *testWallet {
+	w := c.NewWallet()
+	w.Fund(funds)
+	server := httptest.NewServer(w)
+	client, err := rpc.New(&rpc.ConnConfig{
+		Host:         server.Listener.Addr().String(),
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		client.Shutdown()
+		client.WaitForShutdown()
+		server.Close()
+	})
+	return &testWallet{Wallet: w, client: client}
+}
+
+var contractTypes = []ContractType{P2SH, P2WSH, P2SHP2WSH}
+
+func TestSwap(t *testing.T) {
+	for _, contractType := range contractTypes {
+		t.Run(contractType.String(), func(t *testing.T) {
+			testSwap(t, contractType)
+		})
+	}
+}
+
+func testSwap(t *testing.T, contractType ContractType) {
+	c := electrumtest.NewChain(testParams)
+	alice := newTestWallet(t, c, btcutil.SatoshiPerBitcoin)
+	bob := newTestWallet(t, c, btcutil.SatoshiPerBitcoin)
+	policy := timings.DefaultPolicy
+
+	// alice initiates, the refund transaction is not valid before the lock time
+	initiation, err := Initiate(testParams, alice.client, bob.NewAddress(), btcutil.SatoshiPerBitcoin/2, policy, contractType)
+	require.NoError(t, err)
+	_, err = PublishTransaction(alice.client, initiation.ContractTx)
+	require.NoError(t, err)
+	_, err = PublishTransaction(alice.client, initiation.RefundTx)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "non-final")
+	}
+
+	// bob audits the contract and participates
+	audit, err := AuditContract(testParams, initiation.Contract, initiation.ContractTx)
+	require.NoError(t, err)
+	assert.Equal(t, btcutil.Amount(btcutil.SatoshiPerBitcoin/2), audit.ContractValue)
+	assert.Equal(t, initiation.ContractAddress.EncodeAddress(), audit.ContractAddress)
+	assert.Equal(t, initiation.SecretHash, audit.SecretHash)
+	assert.InDelta(t, c.Now().Add(policy.Initiator).Unix(), audit.Locktime, 5)
+	participation, err := Participate(testParams, bob.client, alice.NewAddress(), btcutil.SatoshiPerBitcoin/4, audit.SecretHash[:], policy, contractType)
+	require.NoError(t, err)
+	_, err = PublishTransaction(bob.client, participation.ContractTx)
+	require.NoError(t, err)
+
+	_, err = FindRedemptionTransaction(testParams, bob.client, participation.Contract, participation.ContractTx)
+	assert.Equal(t, ErrNotSpent, err)
+
+	// only the secret redeems the contract, and only alice can sign the redemption
+	wrongSecret := initiation.Secret
+	wrongSecret[0] ^= 1
+	_, err = Redeem(testParams, alice.client, participation.Contract, participation.ContractTx, wrongSecret[:])
+	assert.Error(t, err)
+	_, err = Redeem(testParams, bob.client, participation.Contract, participation.ContractTx, initiation.Secret[:])
+	assert.Error(t, err)
+	aliceRedemption, err := Redeem(testParams, alice.client, participation.Contract, participation.ContractTx, initiation.Secret[:])
+	require.NoError(t, err)
+	_, err = PublishTransaction(alice.client, aliceRedemption.RedeemTx)
+	require.NoError(t, err)
+	// the size estimate used for the fee is a close upper bound
+	estimate := estimateRedeemVirtualSize(participation.Contract, contractType, aliceRedemption.RedeemTx.TxOut)
+	size := VirtualSize(aliceRedemption.RedeemTx)
+	assert.True(t, estimate >= size && estimate <= size+8, "estimated %d vbytes for %d", estimate, size)
+
+	// bob finds alice's redemption, extracts the secret and redeems
+	redemptionTx, err := FindRedemptionTransaction(testParams, bob.client, participation.Contract, participation.ContractTx)
+	require.NoError(t, err)
+	assert.Equal(t, aliceRedemption.RedeemTx.TxHash(), redemptionTx.TxHash())
+	secret, err := ExtractSecret(redemptionTx, initiation.SecretHash[:])
+	require.NoError(t, err)
+	assert.Equal(t, initiation.Secret[:], secret)
+	bobRedemption, err := Redeem(testParams, bob.client, initiation.Contract, initiation.ContractTx, secret)
+	require.NoError(t, err)
+	_, err = PublishTransaction(bob.client, bobRedemption.RedeemTx)
+	require.NoError(t, err)
+
+	// the contracts are spent
+	_, err = PublishTransaction(alice.client, initiation.RefundTx)
+	assert.Error(t, err)
+	_, err = PublishTransaction(alice.client, aliceRedemption.RedeemTx)
+	assert.Error(t, err)
+
+	assert.Equal(t, btcutil.Amount(btcutil.SatoshiPerBitcoin/2+btcutil.SatoshiPerBitcoin/4)-initiation.ContractFee-aliceRedemption.RedeemFee,
+		alice.Balance())
+	assert.Equal(t, btcutil.Amount(btcutil.SatoshiPerBitcoin*3/4+btcutil.SatoshiPerBitcoin/2)-participation.ContractFee-bobRedemption.RedeemFee,
+		bob.Balance())
+}
+
+func TestRefund(t *testing.T) {
+	for _, contractType := range contractTypes {
+		t.Run(contractType.String(), func(t *testing.T) {
+			testRefund(t, contractType)
+		})
+	}
+}
+
+func testRefund(t *testing.T, contractType ContractType) {
+	c := electrumtest.NewChain(testParams)
+	alice := newTestWallet(t, c, btcutil.SatoshiPerBitcoin)
+	bob := newTestWallet(t, c, btcutil.SatoshiPerBitcoin)
+	policy := timings.DefaultPolicy
+
+	initiation, err := Initiate(testParams, alice.client, bob.NewAddress(), btcutil.SatoshiPerBitcoin/2, policy, contractType)
+	require.NoError(t, err)
+	_, err = PublishTransaction(alice.client, initiation.ContractTx)
+	require.NoError(t, err)
+
+	// the refund can only be signed by the wallet of the refund address
+	_, err = Refund(testParams, bob.client, initiation.Contract, initiation.ContractTx)
+	assert.Error(t, err)
+
+	c.AdjustTime(policy.Initiator + time.Minute)
+	refund, err := Refund(testParams, alice.client, initiation.Contract, initiation.ContractTx)
+	require.NoError(t, err)
+	_, err = PublishTransaction(alice.client, refund.RefundTx)
+	require.NoError(t, err)
+	estimate := estimateRefundVirtualSize(initiation.Contract, contractType, refund.RefundTx.TxOut)
+	size := VirtualSize(refund.RefundTx)
+	assert.True(t, estimate >= size && estimate <= size+8, "estimated %d vbytes for %d", estimate, size)
+	assert.Equal(t, btcutil.Amount(btcutil.SatoshiPerBitcoin)-initiation.ContractFee-refund.RefundFee, alice.Balance())
+
+	// the refund transaction built with the contract is the same, signatures being deterministic
+	assert.Equal(t, initiation.RefundTx.TxHash(), refund.RefundTx.TxHash())
+
+	redemption, err := Redeem(testParams, bob.client, initiation.Contract, initiation.ContractTx, initiation.Secret[:])
+	require.NoError(t, err)
+	_, err = PublishTransaction(bob.client, redemption.RedeemTx)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missingorspent")
+	}
+
+	// the contract was spent by the refund, which does not reveal the secret
+	spendingTx, err := FindRedemptionTransaction(testParams, bob.client, initiation.Contract, initiation.ContractTx)
+	require.NoError(t, err)
+	assert.Equal(t, refund.RefundTx.TxHash(), spendingTx.TxHash())
+	_, err = ExtractSecret(spendingTx, initiation.SecretHash[:])
+	assert.Error(t, err)
+}
+
+func TestInsufficientFunds(t *testing.T) {
+	c := electrumtest.NewChain(testParams)
+	alice := newTestWallet(t, c, btcutil.SatoshiPerBitcoin)
+	bob := newTestWallet(t, c, 0)
+
+	_, err := Initiate(testParams, alice.client, bob.NewAddress(), btcutil.SatoshiPerBitcoin, timings.DefaultPolicy, P2SH)
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "insufficient funds"), err.Error())
+	}
+}
+
+func TestSegWitFees(t *testing.T) {
+	var pkh [20]byte
+	contract, err := atomicSwapContract(&pkh, &pkh, time.Now().Unix(), make([]byte, 32))
+	require.NoError(t, err)
+	txOuts := []*wire.TxOut{wire.NewTxOut(0, make([]byte, 25))}
+
+	// spending witness outputs is cheaper, even nested in P2SH
+	p2sh := estimateRedeemVirtualSize(contract, P2SH, txOuts)
+	p2wsh := estimateRedeemVirtualSize(contract, P2WSH, txOuts)
+	nested := estimateRedeemVirtualSize(contract, P2SHP2WSH, txOuts)
+	assert.True(t, p2wsh < nested && nested < p2sh, "redeem sizes: P2SH %d, P2WSH %d, P2SH-P2WSH %d", p2sh, p2wsh, nested)
+	p2sh = estimateRefundVirtualSize(contract, P2SH, txOuts)
+	p2wsh = estimateRefundVirtualSize(contract, P2WSH, txOuts)
+	nested = estimateRefundVirtualSize(contract, P2SHP2WSH, txOuts)
+	assert.True(t, p2wsh < nested && nested < p2sh, "refund sizes: P2SH %d, P2WSH %d, P2SH-P2WSH %d", p2sh, p2wsh, nested)
+}
@@ -0,0 +1,247 @@
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// Swapper implements chain.Swapper using an Electrum wallet
+type Swapper struct {
+	chainParams  *chaincfg.Params
+	wallet       Wallet
+	contractType ContractType
+}
+
+var _ chain.Swapper = (*Swapper)(nil)
+
+// NewSwapper creates a chain.Swapper for Bitcoin.
+// Contrary to the functions of this package,
+// the Swapper publishes the transactions it creates.
+// The contracts it creates are locked to outputs of the given type.
+func NewSwapper(chainParams *chaincfg.Params, w Wallet, contractType ContractType) *Swapper {
+	return &Swapper{
+		chainParams:  chainParams,
+		wallet:       w,
+		contractType: contractType,
+	}
+}
+
+// Chain implements chain.Swapper.Chain
+func (s *Swapper) Chain() string {
+	return "btc"
+}
+
+// Decimals implements chain.Swapper.Decimals
+func (s *Swapper) Decimals() int {
+	return 8
+}
+
+// Address implements chain.Swapper.Address
+func (s *Swapper) Address(ctx context.Context) (string, error) {
+	addr, err := getUnusedAddress(s.chainParams, s.wallet)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// Initiate implements chain.Swapper.Initiate
+func (s *Swapper) Initiate(ctx context.Context, participant string, amount chain.Amount, secret chain.Secret, policy timings.Policy) (chain.Contract, error) {
+	participantAddr, err := DecodeP2PKHAddress(s.chainParams, "participant", participant)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	output, err := InitiateWithSecret(s.chainParams, s.wallet, participantAddr, btcutil.Amount(amount.Int64()), secret, policy, s.contractType)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	return s.publishContract(ctx, output.SecretHash, output.BuiltContract)
+}
+
+// Participate implements chain.Swapper.Participate
+func (s *Swapper) Participate(ctx context.Context, initiator string, amount chain.Amount, secretHash chain.SecretHash, policy timings.Policy) (chain.Contract, error) {
+	initiatorAddr, err := DecodeP2PKHAddress(s.chainParams, "initiator", initiator)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	output, err := Participate(s.chainParams, s.wallet, initiatorAddr, btcutil.Amount(amount.Int64()), secretHash[:], policy, s.contractType)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	return s.publishContract(ctx, secretHash, output.BuiltContract)
+}
+
+func (s *Swapper) publishContract(ctx context.Context, secretHash chain.SecretHash, b BuiltContract) (chain.Contract, error) {
+	contract := chain.Contract{
+		SecretHash:        secretHash,
+		Address:           b.ContractAddress.EncodeAddress(),
+		Script:            hex.EncodeToString(b.Contract),
+		Transaction:       EncodeTransaction(b.ContractTx),
+		RefundTransaction: EncodeTransaction(b.RefundTx),
+	}
+	err := chain.RecordContract(ctx, contract)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	_, err = PublishTransaction(s.wallet, b.ContractTx)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	return contract, nil
+}
+
+// Redeem implements chain.Swapper.Redeem
+func (s *Swapper) Redeem(ctx context.Context, contract chain.Contract, secret chain.Secret) (string, error) {
+	script, contractTx, err := decodeContract(contract)
+	if err != nil {
+		return "", err
+	}
+	output, err := Redeem(s.chainParams, s.wallet, script, contractTx, secret[:])
+	if err != nil {
+		return "", err
+	}
+	txHash, err := PublishTransaction(s.wallet, output.RedeemTx)
+	if err != nil {
+		return "", err
+	}
+	return txHash.String(), nil
+}
+
+// Refund implements chain.Swapper.Refund,
+// publishing the presigned refund transaction if the contract has one.
+func (s *Swapper) Refund(ctx context.Context, contract chain.Contract) (string, error) {
+	var refundTx *wire.MsgTx
+	if contract.RefundTransaction != "" {
+		var err error
+		refundTx, err = DecodeTransaction(contract.RefundTransaction)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode refund transaction: %v", err)
+		}
+	} else {
+		script, contractTx, err := decodeContract(contract)
+		if err != nil {
+			return "", err
+		}
+		output, err := Refund(s.chainParams, s.wallet, script, contractTx)
+		if err != nil {
+			return "", err
+		}
+		refundTx = output.RefundTx
+	}
+	txHash, err := PublishTransaction(s.wallet, refundTx)
+	if err != nil {
+		return "", err
+	}
+	return txHash.String(), nil
+}
+
+// AuditContract implements chain.Swapper.AuditContract
+func (s *Swapper) AuditContract(ctx context.Context, contract chain.Contract) (chain.AuditResult, error) {
+	script, contractTx, err := decodeContract(contract)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	output, err := AuditContract(s.chainParams, script, contractTx)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	return output.AuditResult(), nil
+}
+
+// ExtractSecret implements chain.Swapper.ExtractSecret.
+// If no redemption transaction is given, it is looked up
+// when the wallet implements TransactionLookup.
+func (s *Swapper) ExtractSecret(ctx context.Context, contract chain.Contract, redemptionTx string) (chain.Secret, error) {
+	var tx *wire.MsgTx
+	if redemptionTx != "" {
+		var err error
+		tx, err = DecodeTransaction(redemptionTx)
+		if err != nil {
+			return chain.Secret{}, fmt.Errorf("failed to decode redemption transaction: %v", err)
+		}
+	} else {
+		l, ok := s.wallet.(TransactionLookup)
+		if !ok {
+			return chain.Secret{}, errors.New("the redemption transaction is required to extract the secret")
+		}
+		script, contractTx, err := decodeContract(contract)
+		if err != nil {
+			return chain.Secret{}, err
+		}
+		tx, err = FindRedemptionTransaction(s.chainParams, l, script, contractTx)
+		if err == ErrNotSpent {
+			return chain.Secret{}, chain.ErrNotRedeemed
+		}
+		if err != nil {
+			return chain.Secret{}, err
+		}
+	}
+	secret, err := ExtractSecret(tx, contract.SecretHash[:])
+	if err != nil {
+		return chain.Secret{}, err
+	}
+	var output chain.Secret
+	copy(output[:], secret)
+	return output, nil
+}
+
+func decodeContract(contract chain.Contract) ([]byte, *wire.MsgTx, error) {
+	script, err := hex.DecodeString(contract.Script)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode contract: %v", err)
+	}
+	contractTx, err := DecodeTransaction(contract.Transaction)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode contract transaction: %v", err)
+	}
+	return script, contractTx, nil
+}
+
+// DecodeP2PKHAddress decodes an address,
+// making sure it is a P2PKH address for the given network.
+func DecodeP2PKHAddress(chainParams *chaincfg.Params, name, str string) (*btcutil.AddressPubKeyHash, error) {
+	addr, err := btcutil.DecodeAddress(str, chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s address: %v", name, err)
+	}
+	if !addr.IsForNet(chainParams) {
+		return nil, fmt.Errorf("%s address is not "+
+			"intended for use on %v", name, chainParams.Name)
+	}
+	addrP2PKH, ok := addr.(*btcutil.AddressPubKeyHash)
+	if !ok {
+		return nil, fmt.Errorf("%s address is not P2PKH", name)
+	}
+	return addrP2PKH, nil
+}
+
+// EncodeTransaction returns the hex encoded serialization of a transaction
+func EncodeTransaction(tx *wire.MsgTx) string {
+	var buf bytes.Buffer
+	buf.Grow(tx.SerializeSize())
+	tx.Serialize(&buf)
+	return hex.EncodeToString(buf.Bytes())
+}
+
+// DecodeTransaction decodes a hex encoded transaction
+func DecodeTransaction(str string) (*wire.MsgTx, error) {
+	b, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	var tx wire.MsgTx
+	err = tx.Deserialize(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
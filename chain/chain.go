@@ -0,0 +1,82 @@
+// Package chain defines the chain agnostic interface implemented by
+// the eth, stellar and btc packages, so both legs of an atomic swap
+// can be driven without knowing which chains are involved.
+package chain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// ErrNotRedeemed is returned by ExtractSecret
+// when the contract has not been redeemed yet.
+var ErrNotRedeemed = errors.New("contract has not been redeemed yet")
+
+// Swapper performs the atomic swap actions on a single chain.
+//
+// All amounts are expressed in the smallest unit of the chain,
+// see Decimals to convert them from and to a human readable form.
+type Swapper interface {
+	// Chain returns the short name of the chain, e.g. "btc", "eth" or "xlm".
+	Chain() string
+	// Decimals returns the number of decimals of the swapped asset.
+	Decimals() int
+	// Address returns an address of ours the counterparty can lock funds to.
+	Address(ctx context.Context) (string, error)
+
+	// Initiate creates and publishes a contract locking amount for the participant,
+	// using the hash of the given secret, locked for the initiator lock time of the policy.
+	// The contract is passed to the Recorder of ctx, if any, before it is published.
+	Initiate(ctx context.Context, participant string, amount Amount, secret Secret, policy timings.Policy) (Contract, error)
+	// Participate creates and publishes a contract locking amount for the initiator,
+	// using the secret hash of the initiator's contract,
+	// locked for the participant lock time of the policy.
+	// The contract is passed to the Recorder of ctx, if any, before it is published.
+	Participate(ctx context.Context, initiator string, amount Amount, secretHash SecretHash, policy timings.Policy) (Contract, error)
+	// Redeem claims the funds of a contract locked for us, revealing the secret.
+	// It returns the ID of the redeem transaction.
+	Redeem(ctx context.Context, contract Contract, secret Secret) (string, error)
+	// Refund returns the funds of a contract we created, once its lock time has passed.
+	// It returns the ID of the refund transaction.
+	Refund(ctx context.Context, contract Contract) (string, error)
+	// AuditContract returns the on chain details of a contract.
+	AuditContract(ctx context.Context, contract Contract) (AuditResult, error)
+	// ExtractSecret returns the secret revealed by the redemption of a contract.
+	// The redemption transaction is only required by chains which
+	// can not look it up themselves and is encoded like the contract transaction.
+	// ErrNotRedeemed is returned if the contract has not been redeemed yet.
+	ExtractSecret(ctx context.Context, contract Contract, redemptionTx string) (Secret, error)
+}
+
+// Contract identifies a published atomic swap contract,
+// with the chain specific data required to audit, redeem or refund it.
+//
+// Transactions are encoded the way the chain's CLI prints them:
+// hex encoded for Bitcoin and Ethereum and base64 encoded XDR for Stellar.
+type Contract struct {
+	// SecretHash is the hash of the secret unlocking the contract.
+	SecretHash SecretHash `json:"secretHash"`
+	// Address of the contract: the P2SH address for Bitcoin,
+	// the holding account for Stellar and the AtomicSwap contract for Ethereum.
+	Address string `json:"address"`
+	// Script is the hex encoded Bitcoin contract script.
+	Script string `json:"script,omitempty"`
+	// Transaction is the transaction which created the contract.
+	Transaction string `json:"transaction,omitempty"`
+	// RefundTransaction is the presigned refund transaction, if the chain requires one.
+	RefundTransaction string `json:"refundTransaction,omitempty"`
+}
+
+// AuditResult contains the on chain details of a contract.
+type AuditResult struct {
+	ContractAddress  string     `json:"contractAddress"`
+	ContractValue    Amount     `json:"contractValue"`
+	RecipientAddress string     `json:"recipientAddress"`
+	RefundAddress    string     `json:"refundAddress"`
+	SecretHash       SecretHash `json:"secretHash"`
+	LockTime         LockTime   `json:"locktime"`
+	// Asset locked by the contract, e.g. BTC, ETH, XLM or code:issuer for Stellar assets
+	Asset string `json:"asset"`
+}
@@ -0,0 +1,38 @@
+package chain
+
+import "context"
+
+// Recorder persists the data a Swapper generates while creating a contract,
+// before it is used on chain, so an interrupted swap can be recovered.
+type Recorder interface {
+	// RecordKey records a chain specific key,
+	// e.g. the seed of a Stellar holding account.
+	RecordKey(name, key string) error
+	// RecordContract records a contract before it is published.
+	RecordContract(contract Contract) error
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context passing the recorder to the Swapper calls it is used for.
+func WithRecorder(ctx context.Context, r Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecordKey records a key using the Recorder of the context, if any.
+// Swappers must not use the key on chain if an error is returned.
+func RecordKey(ctx context.Context, name, key string) error {
+	if r, ok := ctx.Value(recorderKey{}).(Recorder); ok {
+		return r.RecordKey(name, key)
+	}
+	return nil
+}
+
+// RecordContract records a contract using the Recorder of the context, if any.
+// Swappers must not publish the contract if an error is returned.
+func RecordContract(ctx context.Context, contract Contract) error {
+	if r, ok := ctx.Value(recorderKey{}).(Recorder); ok {
+		return r.RecordContract(contract)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package chain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Terms are the agreed upon terms of a swap,
+// the counterparty's contract has to match.
+// Empty fields are not verified.
+type Terms struct {
+	// Amount is the minimum amount the contract has to lock
+	Amount Amount `json:"amount"`
+	// Decimals of the amount, used to report violations
+	Decimals int `json:"decimals"`
+	// Asset the contract has to lock, as reported by AuditContract
+	Asset string `json:"asset,omitempty"`
+	// Recipient is our address the contract has to pay to
+	Recipient string `json:"recipient,omitempty"`
+	// SecretHash the contract has to be locked with
+	SecretHash SecretHash `json:"secretHash"`
+	// MinRemaining is the minimum time left before the contract can be refunded
+	MinRemaining time.Duration `json:"minRemaining"`
+
+	// OurLockTime is the lock time of our own contract of the swap,
+	// zero if we did not lock any funds yet.
+	OurLockTime LockTime `json:"ourLockTime,omitempty"`
+	// Initiator is true if we initiated the swap:
+	// the counterparty's contract then has to expire at least Margin before ours,
+	// otherwise it has to expire at least Margin after ours,
+	// so we still have the time to redeem it once the secret is revealed.
+	Initiator bool `json:"initiator"`
+	// Margin between the lock times of both contracts
+	Margin time.Duration `json:"margin"`
+}
+
+// Violation is a term of a swap a contract does not match
+type Violation struct {
+	// Term is the violated term: amount, asset, recipient, secrethash or locktime
+	Term     string `json:"term"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// String implements fmt.Stringer
+func (v Violation) String() string {
+	return fmt.Sprintf("%s is %s, expected %s", v.Term, v.Actual, v.Expected)
+}
+
+// Violations is returned as an error by VerifySwapTerms
+type Violations []Violation
+
+// Error implements error
+func (vs Violations) Error() string {
+	strs := make([]string, len(vs))
+	for i, v := range vs {
+		strs[i] = v.String()
+	}
+	return "contract does not match the swap terms: " + strings.Join(strs, ", ")
+}
+
+// VerifySwapTerms verifies the audit of the counterparty's contract matches the terms.
+// It returns nil or the Violations of the terms.
+func VerifySwapTerms(audit AuditResult, terms Terms) error {
+	var violations Violations
+	add := func(term, expected, actual string) {
+		violations = append(violations, Violation{Term: term, Expected: expected, Actual: actual})
+	}
+	if audit.ContractValue.Cmp(terms.Amount) < 0 {
+		add("amount", "at least "+terms.Amount.Format(terms.Decimals), audit.ContractValue.Format(terms.Decimals))
+	}
+	if terms.Asset != "" && !strings.EqualFold(audit.Asset, terms.Asset) {
+		add("asset", terms.Asset, audit.Asset)
+	}
+	if terms.Recipient != "" && !sameAddress(audit.RecipientAddress, terms.Recipient) {
+		add("recipient", terms.Recipient, audit.RecipientAddress)
+	}
+	if terms.SecretHash != (SecretHash{}) && audit.SecretHash != terms.SecretHash {
+		add("secrethash", terms.SecretHash.String(), audit.SecretHash.String())
+	}
+	if remaining := audit.LockTime.Remaining(); remaining < terms.MinRemaining {
+		add("locktime", fmt.Sprintf("at least %v remaining", terms.MinRemaining),
+			fmt.Sprintf("%v remaining", remaining.Round(time.Second)))
+	}
+	if terms.OurLockTime != 0 {
+		formatTime := func(lt LockTime) string { return lt.Time().UTC().Format(time.RFC3339) }
+		if terms.Initiator {
+			if latest := terms.OurLockTime.Time().Add(-terms.Margin); audit.LockTime.Time().After(latest) {
+				add("locktime", fmt.Sprintf("at the latest %s, %v before ours", formatTime(LockTime(latest.Unix())), terms.Margin),
+					formatTime(audit.LockTime))
+			}
+		} else {
+			if earliest := terms.OurLockTime.Time().Add(terms.Margin); audit.LockTime.Time().Before(earliest) {
+				add("locktime", fmt.Sprintf("at the earliest %s, %v after ours", formatTime(LockTime(earliest.Unix())), terms.Margin),
+					formatTime(audit.LockTime))
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return violations
+}
+
+// sameAddress compares addresses exactly, except for hex addresses such as the ones of Ethereum,
+// of which the case is only a checksum (EIP-55) and the 0x prefix optional
+func sameAddress(a, b string) bool {
+	if a == b {
+		return true
+	}
+	hexA, okA := hexAddress(a)
+	hexB, okB := hexAddress(b)
+	return okA && okB && hexA == hexB
+}
+
+// hexAddress returns the lower case hex digits of a 20 byte hex address
+func hexAddress(str string) (string, bool) {
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		str = str[2:]
+	}
+	if len(str) != 40 {
+		return "", false
+	}
+	for _, c := range str {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return "", false
+		}
+	}
+	return strings.ToLower(str), true
+}
+
+// ParseTerms parses terms from a comma separated list of key=value pairs,
+// e.g. "amount=1.5,recipient=<address>,secrethash=<hash>,minlocktime=12h".
+// The keys are amount, asset, recipient, secrethash, minlocktime,
+// ourlocktime (unix timestamp), role (initiator or participant) and margin.
+func ParseTerms(str string, decimals int) (Terms, error) {
+	terms := Terms{Decimals: decimals}
+	for _, pair := range strings.Split(str, ",") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return Terms{}, fmt.Errorf("invalid term %q, expected key=value", pair)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+		var err error
+		switch key {
+		case "amount":
+			terms.Amount, err = ParseAmount(value, decimals)
+		case "asset":
+			terms.Asset = value
+		case "recipient":
+			terms.Recipient = value
+		case "secrethash":
+			terms.SecretHash, err = ParseSecretHash(value)
+		case "minlocktime":
+			terms.MinRemaining, err = time.ParseDuration(value)
+		case "ourlocktime":
+			var lt int64
+			lt, err = strconv.ParseInt(value, 10, 64)
+			terms.OurLockTime = LockTime(lt)
+		case "role":
+			switch value {
+			case "initiator":
+				terms.Initiator = true
+			case "participant":
+				terms.Initiator = false
+			default:
+				err = fmt.Errorf("unknown role %q", value)
+			}
+		case "margin":
+			terms.Margin, err = time.ParseDuration(value)
+		default:
+			err = fmt.Errorf("unknown term %q", key)
+		}
+		if err != nil {
+			return Terms{}, fmt.Errorf("invalid %s term: %v", key, err)
+		}
+	}
+	return terms, nil
+}
@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifySwapTerms(t *testing.T) {
+	secretHash := Secret{1}.Hash()
+	now := time.Now()
+	audit := AuditResult{
+		ContractValue:    AmountFromInt64(1000),
+		RecipientAddress: "alice",
+		SecretHash:       secretHash,
+		LockTime:         LockTime(now.Add(24 * time.Hour).Unix()),
+		Asset:            "XLM",
+	}
+	testCases := []struct {
+		Terms      Terms
+		Violations []string
+	}{
+		{Terms{}, nil},
+		{Terms{Amount: AmountFromInt64(1000), Asset: "xlm", Recipient: "alice", SecretHash: secretHash, MinRemaining: 12 * time.Hour}, nil},
+		{Terms{Amount: AmountFromInt64(1001)}, []string{"amount"}},
+		{Terms{Asset: "BTC", Recipient: "bob"}, []string{"asset", "recipient"}},
+		{Terms{SecretHash: Secret{2}.Hash(), MinRemaining: 25 * time.Hour}, []string{"secrethash", "locktime"}},
+		// as initiator, their contract has to expire at least the margin before ours
+		{Terms{Initiator: true, OurLockTime: LockTime(now.Add(48 * time.Hour).Unix()), Margin: time.Hour}, nil},
+		{Terms{Initiator: true, OurLockTime: LockTime(now.Add(24 * time.Hour).Unix()), Margin: time.Hour}, []string{"locktime"}},
+		// as participant, their contract has to expire at least the margin after ours
+		{Terms{OurLockTime: LockTime(now.Add(12 * time.Hour).Unix()), Margin: time.Hour}, nil},
+		{Terms{OurLockTime: LockTime(now.Add(24 * time.Hour).Unix()), Margin: time.Hour}, []string{"locktime"}},
+	}
+	for idx, testCase := range testCases {
+		err := VerifySwapTerms(audit, testCase.Terms)
+		violations, _ := err.(Violations)
+		if err != nil && violations == nil {
+			t.Errorf("testCase #%d: unexpected error type: %v", idx, err)
+			continue
+		}
+		if len(violations) != len(testCase.Violations) {
+			t.Errorf("testCase #%d: violations = %v, expected %v", idx, violations, testCase.Violations)
+			continue
+		}
+		for i, violation := range violations {
+			if violation.Term != testCase.Violations[i] {
+				t.Errorf("testCase #%d: violation #%d is of term %s, expected %s", idx, i, violation.Term, testCase.Violations[i])
+			}
+		}
+	}
+}
+
+func TestVerifySwapTermsRecipient(t *testing.T) {
+	// Ethereum addresses are audited with their EIP-55 checksum case
+	lockTime := LockTime(time.Now().Add(time.Hour).Unix())
+	audit := AuditResult{RecipientAddress: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", LockTime: lockTime}
+	testCases := []struct {
+		Recipient string
+		Expected  bool
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaee", false},
+	}
+	for idx, testCase := range testCases {
+		err := VerifySwapTerms(audit, Terms{Recipient: testCase.Recipient})
+		if matches := err == nil; matches != testCase.Expected {
+			t.Errorf("testCase #%d: recipient %s matches = %v, expected %v: %v", idx, testCase.Recipient, matches, testCase.Expected, err)
+		}
+	}
+	// the case of other addresses is significant
+	audit = AuditResult{RecipientAddress: "mjSk1Ny9spzU2fouzYgLqGUD8U41iR35QN", LockTime: lockTime}
+	if err := VerifySwapTerms(audit, Terms{Recipient: "mjsk1ny9spzu2fouzyglqgud8u41ir35qn"}); err == nil {
+		t.Error("recipient matched with another case")
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	terms, err := ParseTerms("amount=1.5, recipient=alice,secrethash="+Secret{1}.Hash().String()+",minlocktime=12h,ourlocktime=100,role=initiator,margin=1h", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terms.Amount.Int64() != 150000000 || terms.Recipient != "alice" || terms.SecretHash != (Secret{1}.Hash()) ||
+		terms.MinRemaining != 12*time.Hour || terms.OurLockTime != 100 || !terms.Initiator || terms.Margin != time.Hour {
+		t.Errorf("unexpected terms: %+v", terms)
+	}
+	for _, str := range []string{"amount", "amount=abc", "foo=bar", "role=both", "minlocktime=12"} {
+		if _, err := ParseTerms(str, 8); err == nil {
+			t.Errorf("parsed invalid terms %q", str)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package chain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// SecretSize is the size in bytes of an atomic swap secret
+const SecretSize = 32
+
+type (
+	// Secret is the preimage which unlocks both contracts of an atomic swap
+	Secret [SecretSize]byte
+	// SecretHash is the SHA256 hash of a Secret
+	SecretHash [sha256.Size]byte
+)
+
+// NewSecret generates a new random secret
+func NewSecret() (secret Secret, err error) {
+	_, err = rand.Read(secret[:])
+	return
+}
+
+// Hash returns the SHA256 hash of the secret
+func (s Secret) Hash() SecretHash {
+	return sha256.Sum256(s[:])
+}
+
+// String returns the hex encoded secret
+func (s Secret) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Secret) UnmarshalText(text []byte) error {
+	return decodeHex("secret", string(text), s[:])
+}
+
+// ParseSecret decodes a hex encoded secret
+func ParseSecret(str string) (secret Secret, err error) {
+	err = decodeHex("secret", str, secret[:])
+	return
+}
+
+// String returns the hex encoded secret hash
+func (h SecretHash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (h SecretHash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (h *SecretHash) UnmarshalText(text []byte) error {
+	return decodeHex("secret hash", string(text), h[:])
+}
+
+// ParseSecretHash decodes a hex encoded secret hash
+func ParseSecretHash(str string) (hash SecretHash, err error) {
+	err = decodeHex("secret hash", str, hash[:])
+	return
+}
+
+func decodeHex(name, str string, dst []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return errors.New(name + " must be hex encoded")
+	}
+	if len(b) != len(dst) {
+		return errors.New(name + " has wrong size")
+	}
+	copy(dst, b)
+	return nil
+}
+
+// Amount is a value expressed in the smallest unit of a chain,
+// e.g. satoshi for Bitcoin, wei for Ethereum and stroops for Stellar.
+// The zero value is an amount of 0.
+type Amount struct {
+	i *big.Int
+}
+
+// NewAmount creates an amount from a big integer
+func NewAmount(i *big.Int) Amount {
+	if i == nil {
+		return Amount{}
+	}
+	return Amount{i: new(big.Int).Set(i)}
+}
+
+// AmountFromInt64 creates an amount from an int64
+func AmountFromInt64(i int64) Amount {
+	return Amount{i: big.NewInt(i)}
+}
+
+// ParseAmount parses a decimal string, e.g. "1.05",
+// into an amount of an asset with the given amount of decimals.
+func ParseAmount(str string, decimals int) (Amount, error) {
+	parts := strings.SplitN(str, ".", 2)
+	whole := parts[0]
+	fraction := ""
+	if len(parts) == 2 {
+		fraction = strings.TrimRight(parts[1], "0")
+	}
+	if len(fraction) > decimals {
+		return Amount{}, fmt.Errorf("invalid amount %q: too precise", str)
+	}
+	i, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount %q", str)
+	}
+	if i.Sign() <= 0 {
+		return Amount{}, fmt.Errorf("invalid amount %q: has to be positive", str)
+	}
+	return Amount{i: i}, nil
+}
+
+// BigInt returns a copy of the amount as a big integer
+func (a Amount) BigInt() *big.Int {
+	if a.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.i)
+}
+
+// Int64 returns the amount as an int64,
+// the result is undefined if it does not fit.
+func (a Amount) Int64() int64 {
+	if a.i == nil {
+		return 0
+	}
+	return a.i.Int64()
+}
+
+// Cmp compares 2 amounts, returning -1, 0 or 1,
+// if a is respectively less, equal to or greater than b.
+func (a Amount) Cmp(b Amount) int {
+	return a.BigInt().Cmp(b.BigInt())
+}
+
+// Sign returns -1, 0 or 1 if the amount is respectively negative, zero or positive
+func (a Amount) Sign() int {
+	if a.i == nil {
+		return 0
+	}
+	return a.i.Sign()
+}
+
+// String returns the amount in the smallest unit of the chain
+func (a Amount) String() string {
+	return a.BigInt().String()
+}
+
+// Format returns the amount as a decimal string,
+// for an asset with the given amount of decimals.
+func (a Amount) Format(decimals int) string {
+	str := a.BigInt().String()
+	if len(str) <= decimals {
+		str = strings.Repeat("0", decimals-len(str)+1) + str
+	}
+	whole, fraction := str[:len(str)-decimals], strings.TrimRight(str[len(str)-decimals:], "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (a *Amount) UnmarshalText(text []byte) error {
+	i, ok := new(big.Int).SetString(string(text), 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", text)
+	}
+	a.i = i
+	return nil
+}
+
+// LockTime is the unix timestamp after which a contract can be refunded
+type LockTime int64
+
+// Time returns the lock time as a time.Time
+func (lt LockTime) Time() time.Time {
+	return time.Unix(int64(lt), 0)
+}
+
+// Expired returns true if the lock time has passed
+func (lt LockTime) Expired() bool {
+	return !time.Now().Before(lt.Time())
+}
+
+// Remaining returns the duration until the lock time passes,
+// which is negative if it already has.
+func (lt LockTime) Remaining() time.Duration {
+	return time.Until(lt.Time()).Truncate(time.Second)
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bgentry/speakeasy"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+
+	"github.com/threefoldtech/atomicswap/btc"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/eth"
+	"github.com/threefoldtech/atomicswap/stellar"
+	"github.com/threefoldtech/atomicswap/store"
+	"github.com/threefoldtech/atomicswap/swap"
+	"github.com/threefoldtech/atomicswap/timings"
+	"github.com/threefoldtech/atomicswap/watch"
+)
+
+var (
+	flagset       = flag.NewFlagSet("", flag.ExitOnError)
+	testnetFlag   = flagset.Bool("testnet", false, "use the test networks")
+	automatedFlag = flagset.Bool("automated", false, "Use automated/unattended version with json output")
+	redeemFlag    = flagset.String("redeemaddress", "", "our address on their chain, defaults to an address of the configured wallet")
+	pollFlag      = flagset.Duration("poll", swap.DefaultPollInterval, "interval between audits and secret lookups")
+	storeFlag     = flagset.String("store", defaultStoreDir(), "directory of the encrypted swap store")
+
+	btcConnectFlag = flagset.String("btc.s", "localhost", "host[:port] of Electrum wallet RPC server")
+	btcUserFlag    = flagset.String("btc.rpcuser", "", "username for Electrum wallet RPC authentication")
+	btcPassFlag    = flagset.String("btc.rpcpass", "", "password for Electrum wallet RPC authentication")
+	btcSegwitFlag  = flagset.Bool("btc.segwit", false, "lock our Bitcoin contracts to a P2WSH output")
+	btcNestedFlag  = flagset.Bool("btc.nested", false, "with -btc.segwit, nest the P2WSH output in a P2SH output")
+
+	ethConnectFlag  = flagset.String("eth.s", "http://localhost:8545", "endpoint of Ethereum RPC server")
+	ethContractFlag = flagset.String("eth.c", "", "hex-enoded address of the deployed AtomicSwap contract")
+	ethNetworkFlag  = flagset.String("eth.network", "", "name of the Ethereum network profile: mainnet, sepolia, holesky or one defined in the -eth.networks file, setting the defaults of -eth.s and -eth.c and verifying the chain ID of the node")
+	ethNetworksFlag = flagset.String("eth.networks", "", "JSON file with Ethereum network profiles to add to (or replace) the built-in ones")
+	ethAccountFlag  = flagset.String("eth.account", "", "account file, account address when using an external signer, or nothing for the daemon's first account")
+	ethClefFlag     = flagset.String("eth.clef", "", "sign the transactions using Clef at this IPC path or URL")
+	ethRemoteFlag   = flagset.String("eth.remotesigner", "", "sign the transactions using the remote signing service at this URL for the account address given by -eth.account, authenticating with $ETH_SIGNER_TOKEN")
+	ethMaxFeeFlag   = flagset.String("eth.maxfee", "", "maximum fee per gas in Gwei, defaults to twice the base fee plus the tip")
+	ethTipCapFlag   = flagset.String("eth.tipcap", "", "maximum priority fee per gas in Gwei, defaults to the tip suggested by the node")
+	ethVersionsFlag = flagset.String("eth.contractversions", "", "JSON file with versions of the atomic swap contracts to add to the built-in ones, such as older or self-compiled deployments")
+	ethLogRangeFlag = flagset.Uint64("eth.logrange", eth.DefaultLogRange, "number of blocks of which the logs are requested at once when looking for the secret, lowered when the node rejects the range")
+	ethTokenFlag    = flagset.String("eth.token", "", "hex-encoded address of the ERC-20 token to swap instead of Ether, -eth.c then has to be a TokenAtomicSwap contract")
+
+	xlmSeedFlag  = flagset.String("xlm.seed", "", "seed of the Stellar account to swap from and to")
+	xlmAssetFlag = flagset.String("xlm.asset", "", "The asset to transfer in case of non native XLM, format: `code:issuer`")
+)
+
+// lockTimes of the contracts, set using the lock time flags
+var lockTimes = timings.DefaultPolicy
+
+// The swap command runs our side of an atomic swap on both chains,
+// see the swap package for the steps taken by the initiator and the participant.
+// Contracts are exchanged with the counterparty by copy pasting them,
+// either to another atomicswap command or to the single chain tools.
+//
+// The state of every swap, including its secret and keys, is saved in an
+// encrypted store before every step, so an interrupted swap can be resumed.
+// The passphrase of the store is asked for,
+// or taken from the ATOMICSWAP_PASSPHRASE environment variable.
+//
+// The watch command keeps running and refunds our contracts of the swaps
+// in the store as soon as their lock time has passed. For the swaps we
+// participate in, it redeems the initiator's contract as soon as the secret
+// is revealed by the redemption of ours.
+
+func init() {
+	lockTimes.AddFlags(flagset)
+	flagset.Usage = func() {
+		fmt.Println("Cross-chain atomic swaps between Bitcoin, Ethereum and Stellar")
+		fmt.Println("Usage: atomicswap [flags] cmd [cmd args]")
+		fmt.Println()
+		fmt.Println("Commands:")
+		fmt.Println("  run <initiator|participant> <my chain> <my amount> <their chain> <their amount> <counterparty address>")
+		fmt.Println("  resume <swap id>")
+		fmt.Println("  list")
+		fmt.Println("  show <swap id>")
+		fmt.Println("  watch")
+		fmt.Println()
+		fmt.Println("Chains: btc, eth, xlm")
+		fmt.Println("The counterparty address is its address on my chain.")
+		fmt.Println()
+		fmt.Println("Flags:")
+		flagset.PrintDefaults()
+	}
+}
+
+func main() {
+	showUsage, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if showUsage {
+		flagset.Usage()
+	}
+	if err != nil || showUsage {
+		os.Exit(1)
+	}
+}
+
+func checkCmdArgLength(args []string, required int) (nArgs int) {
+	if len(args) < required {
+		return 0
+	}
+	for i, arg := range args[:required] {
+		if len(arg) != 1 && strings.HasPrefix(arg, "-") {
+			return i
+		}
+	}
+	return required
+}
+
+func run() (showUsage bool, err error) {
+	flagset.Parse(os.Args[1:])
+	args := flagset.Args()
+	if len(args) == 0 {
+		return true, nil
+	}
+	cmdArgs := 0
+	switch args[0] {
+	case "run":
+		cmdArgs = 6
+	case "resume", "show":
+		cmdArgs = 1
+	case "list", "watch":
+		cmdArgs = 0
+	default:
+		return true, fmt.Errorf("unknown command %v", args[0])
+	}
+	nArgs := checkCmdArgLength(args[1:], cmdArgs)
+	flagset.Parse(args[1+nArgs:])
+	if nArgs < cmdArgs {
+		return true, fmt.Errorf("%s: too few arguments", args[0])
+	}
+	if flagset.NArg() != 0 {
+		return true, fmt.Errorf("unexpected argument: %s", flagset.Arg(0))
+	}
+
+	var spec swap.Spec
+	if args[0] == "run" {
+		spec.Role, err = swap.ParseRole(args[1])
+		if err != nil {
+			return true, err
+		}
+		spec.MyChain, spec.TheirChain = args[2], args[4]
+		spec.CounterpartyAddress, spec.RedeemAddress = args[6], *redeemFlag
+		spec.LockTimes = lockTimes
+	}
+
+	st, err := openStore()
+	if err != nil {
+		return false, err
+	}
+
+	var state *swap.State
+	switch args[0] {
+	case "list":
+		return false, listSwaps(st)
+	case "watch":
+		return false, watchSwaps(st)
+	case "show":
+		state, err = st.Load(args[1])
+		if err != nil {
+			return false, fmt.Errorf("failed to load swap %s: %v", args[1], err)
+		}
+		jsonoutput, _ := json.MarshalIndent(state, "", "  ")
+		fmt.Println(string(jsonoutput))
+		return false, nil
+	case "resume":
+		state, err = st.Load(args[1])
+		if err != nil {
+			return false, fmt.Errorf("failed to load swap %s: %v", args[1], err)
+		}
+		spec = state.Spec
+	}
+
+	ctx := context.Background()
+	mine, err := newSwapper(ctx, spec.MyChain)
+	if err != nil {
+		return false, err
+	}
+	theirs, err := newSwapper(ctx, spec.TheirChain)
+	if err != nil {
+		return false, err
+	}
+	if args[0] == "run" {
+		spec.MyAmount, err = chain.ParseAmount(args[3], mine.Decimals())
+		if err != nil {
+			return true, err
+		}
+		spec.TheirAmount, err = chain.ParseAmount(args[5], theirs.Decimals())
+		if err != nil {
+			return true, err
+		}
+	}
+
+	s := &swap.Swap{
+		Spec:         spec,
+		State:        state,
+		Mine:         mine,
+		Theirs:       theirs,
+		Counterparty: swap.NewStreamCounterparty(os.Stdin, os.Stdout),
+		Journal:      st,
+		PollInterval: *pollFlag,
+	}
+	if !*automatedFlag {
+		s.Logf = func(format string, args ...interface{}) {
+			fmt.Printf("%s "+format+"\n", append([]interface{}{time.Now().Format(time.RFC3339)}, args...)...)
+		}
+	}
+	state, err = s.Run(ctx)
+	if state != nil {
+		printState(state)
+	}
+	return false, err
+}
+
+func printState(state *swap.State) {
+	if *automatedFlag {
+		jsonoutput, _ := json.Marshal(state)
+		fmt.Println(string(jsonoutput))
+		return
+	}
+	fmt.Printf("Swap %s: %s\n", state.ID, state.Step)
+	if state.RedeemTransaction != "" {
+		fmt.Printf("Secret: %s\n", state.Secret)
+		fmt.Printf("Redeem transaction: %s\n", state.RedeemTransaction)
+	}
+	if state.RefundTransaction != "" {
+		fmt.Printf("Refund transaction: %s\n", state.RefundTransaction)
+	}
+	if !state.Step.Done() {
+		fmt.Printf("Continue the swap with: atomicswap resume %s\n", state.ID)
+	}
+}
+
+func listSwaps(st *store.Store) error {
+	states, err := st.List()
+	if err != nil {
+		return err
+	}
+	if *automatedFlag {
+		jsonoutput, _ := json.Marshal(states)
+		fmt.Println(string(jsonoutput))
+		return nil
+	}
+	for _, state := range states {
+		fmt.Printf("%s %-11s %-11s %s %s -> %s %s (%s)\n", state.ID, state.Step, state.Spec.Role,
+			state.Spec.MyAmount, state.Spec.MyChain, state.Spec.TheirAmount, state.Spec.TheirChain,
+			state.UpdatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// watchSwaps refunds the expired contracts of the swaps in the store,
+// until the process is interrupted.
+func watchSwaps(st *store.Store) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	w := &watch.Watcher{
+		Store:    st,
+		Swapper:  newSwapper,
+		Interval: *pollFlag,
+		Report: func(e watch.Event) {
+			if *automatedFlag {
+				jsonoutput, _ := json.Marshal(e)
+				fmt.Println(string(jsonoutput))
+				return
+			}
+			fmt.Println(e)
+		},
+	}
+	err := w.Run(ctx)
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+func openStore() (*store.Store, error) {
+	passphrase := os.Getenv("ATOMICSWAP_PASSPHRASE")
+	if passphrase == "" {
+		var err error
+		passphrase, err = speakeasy.Ask("Store passphrase: ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get passphrase from STDIN: %v", err)
+		}
+	}
+	return store.Open(*storeFlag, []byte(passphrase))
+}
+
+func defaultStoreDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".atomicswap"
+	}
+	return filepath.Join(home, ".atomicswap")
+}
+
+// newSwapper creates the swapper of a chain using the flags of that chain
+func newSwapper(ctx context.Context, name string) (chain.Swapper, error) {
+	switch name {
+	case "btc":
+		return newBTCSwapper()
+	case "eth":
+		return newETHSwapper(ctx)
+	case "xlm":
+		return newXLMSwapper()
+	}
+	return nil, fmt.Errorf("unknown chain %q", name)
+}
+
+func newBTCSwapper() (chain.Swapper, error) {
+	// the type only applies to the contracts we create,
+	// the type of an existing contract is found from its output
+	contractType := btc.P2SH
+	if *btcSegwitFlag {
+		contractType = btc.P2WSH
+		if *btcNestedFlag {
+			contractType = btc.P2SHP2WSH
+		}
+	} else if *btcNestedFlag {
+		return nil, errors.New("-btc.nested requires -btc.segwit")
+	}
+	chainParams := &chaincfg.MainNetParams
+	if *testnetFlag {
+		chainParams = &chaincfg.TestNet3Params
+	}
+	connect, err := normalizeAddress(*btcConnectFlag, walletPort(chainParams))
+	if err != nil {
+		return nil, fmt.Errorf("wallet server address: %v", err)
+	}
+	client, err := rpc.New(&rpc.ConnConfig{
+		Host:         connect,
+		User:         *btcUserFlag,
+		Pass:         *btcPassFlag,
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rpc connect: %v", err)
+	}
+	return closingSwapper{btc.NewSwapper(chainParams, client, contractType), client.Shutdown}, nil
+}
+
+func newETHSwapper(ctx context.Context) (swapper chain.Swapper, err error) {
+	connect, contractAddr := *ethConnectFlag, *ethContractFlag
+	var network *eth.Network
+	if *ethNetworkFlag != "" {
+		networks := eth.DefaultNetworks()
+		if *ethNetworksFlag != "" {
+			if err := networks.Load(*ethNetworksFlag); err != nil {
+				return nil, err
+			}
+		}
+		n, err := networks.Get(*ethNetworkFlag)
+		if err != nil {
+			return nil, err
+		}
+		network = &n
+		if !isFlagSet("eth.s") {
+			connect = n.RPCURL
+		}
+		if contractAddr == "" {
+			contract := n.Contract
+			if *ethTokenFlag != "" {
+				contract = n.TokenContract
+			}
+			if contract != (common.Address{}) {
+				contractAddr = contract.Hex()
+			}
+		}
+	}
+	if contractAddr == "" {
+		return nil, errors.New("the address of the AtomicSwap contract is required (-eth.c)")
+	}
+	client, err := eth.DialClient(ctx, connect)
+	if err != nil {
+		return nil, fmt.Errorf("rpc connect: %v", err)
+	}
+	closers := []func(){client.Close}
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	defer func() {
+		if err != nil {
+			closeAll()
+		}
+	}()
+	if network != nil {
+		if err = network.CheckChainID(ctx, client); err != nil {
+			return nil, err
+		}
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %v", err)
+	}
+	var signer eth.Signer
+	switch {
+	case *ethClefFlag != "":
+		var address common.Address
+		if common.IsHexAddress(*ethAccountFlag) {
+			address = common.HexToAddress(*ethAccountFlag)
+		}
+		clefSigner, err := eth.NewClefSigner(ctx, *ethClefFlag, address)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, clefSigner.Close)
+		signer = clefSigner
+	case *ethRemoteFlag != "":
+		if !common.IsHexAddress(*ethAccountFlag) {
+			return nil, errors.New("the remote signer requires the account address (-eth.account)")
+		}
+		signer = eth.NewRemoteSigner(*ethRemoteFlag, common.HexToAddress(*ethAccountFlag), os.Getenv("ETH_SIGNER_TOKEN"))
+	case *ethAccountFlag != "":
+		key, err := loadAccount(*ethAccountFlag)
+		if err != nil {
+			return nil, fmt.Errorf("could not load account key: %v", err)
+		}
+		signer = eth.NewKeySigner(key)
+	}
+	sct, err := eth.NewSwapContractTransactorWithSigner(ctx, client, common.HexToAddress(contractAddr), signer, chainID)
+	if err != nil {
+		return nil, err
+	}
+	sct.LogRange = *ethLogRangeFlag
+	if *ethVersionsFlag != "" {
+		sct.Versions = eth.DefaultContractVersions()
+		if err = sct.Versions.Load(*ethVersionsFlag); err != nil {
+			return nil, err
+		}
+	}
+	if *ethMaxFeeFlag != "" {
+		maxFee, err := chain.ParseAmount(*ethMaxFeeFlag, 9)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max fee (-eth.maxfee): %v", err)
+		}
+		sct.GasFeeCap = maxFee.BigInt()
+	}
+	if *ethTipCapFlag != "" {
+		tipCap, err := chain.ParseAmount(*ethTipCapFlag, 9)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tip cap (-eth.tipcap): %v", err)
+		}
+		sct.GasTipCap = tipCap.BigInt()
+	}
+	if *ethTokenFlag != "" {
+		if !common.IsHexAddress(*ethTokenFlag) {
+			return nil, fmt.Errorf("invalid token address (-eth.token): %s", *ethTokenFlag)
+		}
+		tokenSwapper, err := eth.NewTokenSwapper(ctx, sct, common.HexToAddress(*ethTokenFlag))
+		if err != nil {
+			return nil, err
+		}
+		return closingSwapper{tokenSwapper, closeAll}, nil
+	}
+	return closingSwapper{eth.NewSwapper(sct), closeAll}, nil
+}
+
+// closingSwapper closes the connections of a swapper once it is no longer used,
+// see watch.Watcher
+type closingSwapper struct {
+	chain.Swapper
+	close func()
+}
+
+// Close implements io.Closer
+func (s closingSwapper) Close() error {
+	s.close()
+	return nil
+}
+
+// isFlagSet reports whether the flag was passed on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flagset.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func newXLMSwapper() (chain.Swapper, error) {
+	targetNetwork := network.PublicNetworkPassphrase
+	client := horizonclient.DefaultPublicNetClient
+	if *testnetFlag {
+		targetNetwork = network.TestNetworkPassphrase
+		client = horizonclient.DefaultTestNetClient
+	}
+	kp, err := keypair.Parse(*xlmSeedFlag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Stellar seed (-xlm.seed): %v", err)
+	}
+	fullKeyPair, ok := kp.(*keypair.Full)
+	if !ok {
+		return nil, errors.New("a Stellar seed is required (-xlm.seed), not an address")
+	}
+	var asset txnbuild.Asset = txnbuild.NativeAsset{}
+	if *xlmAssetFlag != "" {
+		assetparts := strings.SplitN(*xlmAssetFlag, ":", 2)
+		if len(assetparts) != 2 {
+			return nil, errors.New("Invalid asset format")
+		}
+		asset = txnbuild.CreditAsset{
+			Code:   assetparts[0],
+			Issuer: assetparts[1],
+		}
+	}
+	return stellar.NewSwapper(targetNetwork, fullKeyPair, asset, client), nil
+}
+
+func loadAccount(path string) (*ecdsa.PrivateKey, error) {
+	json, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted account/key file (%s) content: %v", path, err)
+	}
+	passphrase, err := speakeasy.Ask("Account passphrase: ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passphrase from STDIN: %v", err)
+	}
+	key, err := keystore.DecryptKey(json, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt (JSON) account/key file (%s): %v", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+func normalizeAddress(addr string, defaultPort string) (hostport string, err error) {
+	host, port, origErr := net.SplitHostPort(addr)
+	if origErr == nil {
+		return net.JoinHostPort(host, port), nil
+	}
+	addr = net.JoinHostPort(addr, defaultPort)
+	_, _, err = net.SplitHostPort(addr)
+	if err != nil {
+		return "", origErr
+	}
+	return addr, nil
+}
+
+func walletPort(params *chaincfg.Params) string {
+	switch params {
+	case &chaincfg.MainNetParams:
+		return "8332"
+	case &chaincfg.TestNet3Params:
+		return "18332"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+
+	"github.com/threefoldtech/atomicswap/btc/electrumtest"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// testEnv runs the commands against the Electrum wallets of alice and bob
+// on an in-memory chain, alice initiates the swaps and bob participates
+type testEnv struct {
+	t     *testing.T
+	chain *electrumtest.Chain
+
+	alice, bob *electrumtest.Wallet
+	// hosts of the RPC servers of the wallets
+	aliceHost, bobHost string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	env := &testEnv{
+		t:     t,
+		chain: electrumtest.NewChain(&chaincfg.MainNetParams),
+	}
+	env.alice, env.aliceHost = env.newWallet()
+	env.bob, env.bobHost = env.newWallet()
+	return env
+}
+
+// newWallet creates a wallet funded with 1 BTC and serves it
+func (env *testEnv) newWallet() (*electrumtest.Wallet, string) {
+	w := env.chain.NewWallet()
+	w.Fund(btcutil.SatoshiPerBitcoin)
+	server := httptest.NewServer(w)
+	env.t.Cleanup(server.Close)
+	return w, server.Listener.Addr().String()
+}
+
+// run runs the command with the flags reset and the given input,
+// returning its output
+func (env *testEnv) run(stdin string, args ...string) (string, error) {
+	flagset.VisitAll(func(f *flag.Flag) {
+		f.Value.Set(f.DefValue)
+	})
+	lockTimes = timings.DefaultPolicy
+	chainParams = &chaincfg.MainNetParams
+	os.Args = append([]string{"btcatomicswap"}, args...)
+
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	origStdin, origStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdinR, stdoutW
+	defer func() {
+		os.Stdin, os.Stdout = origStdin, origStdout
+		stdinR.Close()
+	}()
+	go func() {
+		io.WriteString(stdinW, stdin)
+		stdinW.Close()
+	}()
+	output := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, stdoutR)
+		output <- b.String()
+	}()
+
+	showUsage, err := run()
+	stdoutW.Close()
+	out := <-output
+	if err == nil && showUsage {
+		err = fmt.Errorf("usage shown")
+	}
+	return out, err
+}
+
+// mustRun runs the command, failing the test if it fails
+func (env *testEnv) mustRun(stdin string, args ...string) string {
+	env.t.Helper()
+	out, err := env.run(stdin, args...)
+	if err != nil {
+		env.t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// match returns the first submatch of the pattern in the output
+func (env *testEnv) match(out, pattern string) string {
+	env.t.Helper()
+	m := regexp.MustCompile(pattern).FindStringSubmatch(out)
+	if m == nil {
+		env.t.Fatalf("%q not found in output:\n%s", pattern, out)
+	}
+	return m[1]
+}
+
+// contract returns the contract and contract transaction printed in the output
+func (env *testEnv) contract(out string) (contract, contractTx string) {
+	env.t.Helper()
+	return env.match(out, `Contract \([0-9a-zA-Z]+\):\n([0-9a-f]+)`),
+		env.match(out, `Contract transaction \([0-9a-f]{64}\):\n([0-9a-f]+)`)
+}
+
+// mined reports if the transaction with the hash printed in the output is mined
+func (env *testEnv) mined(out, pattern string) bool {
+	env.t.Helper()
+	txHash, err := chainhash.NewHashFromStr(env.match(out, pattern))
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	_, ok := env.chain.Transaction(*txHash)
+	return ok
+}
+
+func TestSwapCommands(t *testing.T) {
+	env := newTestEnv(t)
+	bobAddr := env.bob.NewAddress().EncodeAddress()
+	aliceAddr := env.alice.NewAddress().EncodeAddress()
+
+	out := env.mustRun("y\n", "-s", env.aliceHost, "initiate", bobAddr, "0.5")
+	secret := env.match(out, `Secret:      ([0-9a-f]{64})`)
+	secretHash := env.match(out, `Secret hash: ([0-9a-f]{64})`)
+	initiateContract, initiateTx := env.contract(out)
+	if !env.mined(out, `Published contract transaction \(([0-9a-f]{64})\)`) {
+		t.Fatalf("contract transaction not mined:\n%s", out)
+	}
+
+	// auditcontract does not need the wallet
+	out = env.mustRun("", "-s", "127.0.0.1:1", "auditcontract", initiateContract, initiateTx,
+		"-expect", "amount=0.5,recipient="+bobAddr+",secrethash="+secretHash)
+	for _, expected := range []string{"Contract value:          0.5 BTC", "Recipient address:       " + bobAddr, "Contract matches the swap terms"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("auditcontract output misses %q:\n%s", expected, out)
+		}
+	}
+	if _, err := env.run("", "auditcontract", initiateContract, initiateTx, "-expect", "amount=1"); err == nil {
+		t.Error("audited contract matches terms of another amount")
+	}
+
+	// the contract transaction is not published without confirmation
+	out = env.mustRun("n\n", "-s", env.bobHost, "participate", aliceAddr, "0.25", secretHash)
+	if strings.Contains(out, "Published") || env.mined(out, `Contract transaction \(([0-9a-f]{64})\)`) {
+		t.Fatalf("contract transaction published without confirmation:\n%s", out)
+	}
+	out = env.mustRun("y\n", "-s", env.bobHost, "participate", aliceAddr, "0.25", secretHash)
+	participateContract, participateTx := env.contract(out)
+
+	if _, err := env.run("y\n", "-s", env.aliceHost, "redeem", participateContract, participateTx, secretHash); err == nil {
+		t.Error("redeemed with the secret hash as secret")
+	}
+	out = env.mustRun("y\n", "-s", env.aliceHost, "redeem", participateContract, participateTx, secret)
+	if !env.mined(out, `Published redeem transaction \(([0-9a-f]{64})\)`) {
+		t.Fatalf("redeem transaction not mined:\n%s", out)
+	}
+	redeemTx := env.match(out, `Redeem transaction \([0-9a-f]{64}\):\n([0-9a-f]+)`)
+
+	out = env.mustRun("", "extractsecret", redeemTx, secretHash)
+	if extracted := env.match(out, `Secret: ([0-9a-f]{64})`); extracted != secret {
+		t.Errorf("extracted secret %s from the redeem transaction, expected %s", extracted, secret)
+	}
+
+	balance := env.bob.Balance()
+	env.mustRun("y\n", "-s", env.bobHost, "redeem", initiateContract, initiateTx, secret)
+	if received := env.bob.Balance() - balance; received <= btcutil.SatoshiPerBitcoin/2*99/100 || received >= btcutil.SatoshiPerBitcoin/2 {
+		t.Errorf("bob received %v redeeming 0.5 BTC", received)
+	}
+	if _, err := env.run("y\n", "-s", env.bobHost, "redeem", initiateContract, initiateTx, secret); err == nil {
+		t.Error("redeemed twice")
+	}
+}
+
+func TestRefundCommands(t *testing.T) {
+	env := newTestEnv(t)
+	bobAddr := env.bob.NewAddress().EncodeAddress()
+
+	// in automated mode the contract transaction is published without confirmation
+	out := env.mustRun("", "-s", env.aliceHost, "-automated", "initiate", bobAddr, "0.5")
+	var initiation struct {
+		Contract            string `json:"contract"`
+		ContractTransaction string `json:"contractTransaction"`
+	}
+	if err := json.Unmarshal([]byte(out), &initiation); err != nil {
+		t.Fatalf("unexpected automated initiate output: %v\n%s", err, out)
+	}
+
+	if _, err := env.run("y\n", "-s", env.bobHost, "refund", initiation.Contract, initiation.ContractTransaction); err == nil {
+		t.Error("refunded by the participant")
+	}
+	out, err := env.run("y\n", "-s", env.aliceHost, "refund", initiation.Contract, initiation.ContractTransaction)
+	if err == nil || !strings.Contains(err.Error(), "non-final") {
+		t.Errorf("refunded before the lock time: %v\n%s", err, out)
+	}
+
+	env.chain.AdjustTime(timings.DefaultPolicy.Initiator + time.Minute)
+	balance := env.alice.Balance()
+	out = env.mustRun("y\n", "-s", env.aliceHost, "refund", initiation.Contract, initiation.ContractTransaction)
+	if !env.mined(out, `Published refund transaction \(([0-9a-f]{64})\)`) {
+		t.Fatalf("refund transaction not mined:\n%s", out)
+	}
+	if refunded := env.alice.Balance() - balance; refunded <= btcutil.SatoshiPerBitcoin/2*99/100 || refunded >= btcutil.SatoshiPerBitcoin/2 {
+		t.Errorf("alice got %v refunding 0.5 BTC", refunded)
+	}
+}
+
+func TestSegWitSwapCommands(t *testing.T) {
+	env := newTestEnv(t)
+	bobAddr := env.bob.NewAddress().EncodeAddress()
+	aliceAddr := env.alice.NewAddress().EncodeAddress()
+
+	if _, err := env.run("y\n", "-s", env.aliceHost, "-nested", "initiate", bobAddr, "0.5"); err == nil {
+		t.Error("initiated a nested contract without -segwit")
+	}
+
+	out := env.mustRun("y\n", "-s", env.aliceHost, "-segwit", "initiate", bobAddr, "0.5")
+	secret := env.match(out, `Secret:      ([0-9a-f]{64})`)
+	secretHash := env.match(out, `Secret hash: ([0-9a-f]{64})`)
+	initiateContract, initiateTx := env.contract(out)
+	if address := env.match(out, `Contract \(([0-9a-zA-Z]+)\)`); !strings.HasPrefix(address, "bc1q") {
+		t.Errorf("contract locked to %s, expected a P2WSH address", address)
+	}
+	out = env.mustRun("", "auditcontract", initiateContract, initiateTx,
+		"-expect", "amount=0.5,recipient="+bobAddr+",secrethash="+secretHash)
+	if !strings.Contains(out, "Contract matches the swap terms") {
+		t.Errorf("unexpected auditcontract output:\n%s", out)
+	}
+
+	out = env.mustRun("y\n", "-s", env.bobHost, "-segwit", "-nested", "participate", aliceAddr, "0.25", secretHash)
+	participateContract, participateTx := env.contract(out)
+	if address := env.match(out, `Contract \(([0-9a-zA-Z]+)\)`); !strings.HasPrefix(address, "3") {
+		t.Errorf("contract locked to %s, expected a P2SH address", address)
+	}
+
+	// the contract types are found by the commands spending the contracts
+	out = env.mustRun("y\n", "-s", env.aliceHost, "redeem", participateContract, participateTx, secret)
+	if !env.mined(out, `Published redeem transaction \(([0-9a-f]{64})\)`) {
+		t.Fatalf("redeem transaction not mined:\n%s", out)
+	}
+	redeemTx := env.match(out, `Redeem transaction \([0-9a-f]{64}\):\n([0-9a-f]+)`)
+	out = env.mustRun("", "extractsecret", redeemTx, secretHash)
+	if extracted := env.match(out, `Secret: ([0-9a-f]{64})`); extracted != secret {
+		t.Errorf("extracted secret %s from the witness of the redeem transaction, expected %s", extracted, secret)
+	}
+	out = env.mustRun("y\n", "-s", env.bobHost, "redeem", initiateContract, initiateTx, secret)
+	if !env.mined(out, `Published redeem transaction \(([0-9a-f]{64})\)`) {
+		t.Fatalf("redeem transaction not mined:\n%s", out)
+	}
+}
@@ -8,7 +8,6 @@ package main
 import (
 	"bufio"
 	"bytes"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -22,22 +21,15 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
-	"github.com/btcsuite/btcwallet/wallet/txrules"
-	rpc "github.com/threefoldtech/atomicswap/cmd/btcatomicswap/rpcclient"
+	"github.com/threefoldtech/atomicswap/btc"
+	rpc "github.com/threefoldtech/atomicswap/btc/rpcclient"
+	"github.com/threefoldtech/atomicswap/chain"
 	"github.com/threefoldtech/atomicswap/timings"
-	"golang.org/x/crypto/ripemd160"
 )
 
-const verify = true
-
-const secretSize = 32
-
-const txVersion = 2
-
 var (
 	chainParams = &chaincfg.MainNetParams
 )
@@ -49,8 +41,14 @@ var (
 	rpcpassFlag   = flagset.String("rpcpass", "", "password for wallet RPC authentication")
 	testnetFlag   = flagset.Bool("testnet", false, "use testnet network")
 	automatedFlag = flagset.Bool("automated", false, "Use automated/unattended version with json output")
+	expectFlag    = flagset.String("expect", "", "auditcontract: verify the contract matches the swap terms, e.g. amount=0.1,recipient=<address>,secrethash=<hash>,minlocktime=12h")
+	segwitFlag    = flagset.Bool("segwit", false, "initiate/participate: lock the contract to a P2WSH output")
+	nestedFlag    = flagset.Bool("nested", false, "initiate/participate: with -segwit, nest the P2WSH output in a P2SH output")
 )
 
+// lockTimes of the contracts, set using the lock time flags
+var lockTimes = timings.DefaultPolicy
+
 // There are two directions that the atomic swap can be performed, as the
 // initiator can be on either chain.  This tool only deals with creating the
 // Bitcoin transactions for these swaps.  A second tool should be used for the
@@ -74,6 +72,7 @@ var (
 //   cp2 redeems btc with S
 
 func init() {
+	lockTimes.AddFlags(flagset)
 	flagset.Usage = func() {
 		fmt.Println("Atomic swaps for Bitcoin using the Electrum wallet")
 		fmt.Println("Usage: btcatomicswap [flags] cmd [cmd args]")
@@ -102,14 +101,16 @@ type offlineCommand interface {
 }
 
 type initiateCmd struct {
-	cp2Addr *btcutil.AddressPubKeyHash
-	amount  btcutil.Amount
+	cp2Addr      *btcutil.AddressPubKeyHash
+	amount       btcutil.Amount
+	contractType btc.ContractType
 }
 
 type participateCmd struct {
-	cp1Addr    *btcutil.AddressPubKeyHash
-	amount     btcutil.Amount
-	secretHash []byte
+	cp1Addr      *btcutil.AddressPubKeyHash
+	amount       btcutil.Amount
+	secretHash   []byte
+	contractType btc.ContractType
 }
 
 type redeemCmd struct {
@@ -194,20 +195,23 @@ func run() (showUsage bool, err error) {
 		chainParams = &chaincfg.TestNet3Params
 	}
 
+	// redeem, refund, auditcontract and extractsecret find the type of the contract output
+	contractType := btc.P2SH
+	if *segwitFlag {
+		contractType = btc.P2WSH
+		if *nestedFlag {
+			contractType = btc.P2SHP2WSH
+		}
+	} else if *nestedFlag {
+		return true, errors.New("-nested requires -segwit")
+	}
+
 	var cmd command
 	switch args[0] {
 	case "initiate":
-		cp2Addr, err := btcutil.DecodeAddress(args[1], chainParams)
+		cp2AddrP2PKH, err := btc.DecodeP2PKHAddress(chainParams, "participant", args[1])
 		if err != nil {
-			return true, fmt.Errorf("failed to decode participant address: %v", err)
-		}
-		if !cp2Addr.IsForNet(chainParams) {
-			return true, fmt.Errorf("participant address is not "+
-				"intended for use on %v", chainParams.Name)
-		}
-		cp2AddrP2PKH, ok := cp2Addr.(*btcutil.AddressPubKeyHash)
-		if !ok {
-			return true, errors.New("participant address is not P2PKH")
+			return true, err
 		}
 
 		amountF64, err := strconv.ParseFloat(args[2], 64)
@@ -219,20 +223,12 @@ func run() (showUsage bool, err error) {
 			return true, err
 		}
 
-		cmd = &initiateCmd{cp2Addr: cp2AddrP2PKH, amount: amount}
+		cmd = &initiateCmd{cp2Addr: cp2AddrP2PKH, amount: amount, contractType: contractType}
 
 	case "participate":
-		cp1Addr, err := btcutil.DecodeAddress(args[1], chainParams)
+		cp1AddrP2PKH, err := btc.DecodeP2PKHAddress(chainParams, "initiator", args[1])
 		if err != nil {
-			return true, fmt.Errorf("failed to decode initiator address: %v", err)
-		}
-		if !cp1Addr.IsForNet(chainParams) {
-			return true, fmt.Errorf("initiator address is not "+
-				"intended for use on %v", chainParams.Name)
-		}
-		cp1AddrP2PKH, ok := cp1Addr.(*btcutil.AddressPubKeyHash)
-		if !ok {
-			return true, errors.New("initiator address is not P2PKH")
+			return true, err
 		}
 
 		amountF64, err := strconv.ParseFloat(args[2], 64)
@@ -252,7 +248,7 @@ func run() (showUsage bool, err error) {
 			return true, errors.New("secret hash has wrong size")
 		}
 
-		cmd = &participateCmd{cp1Addr: cp1AddrP2PKH, amount: amount, secretHash: secretHash}
+		cmd = &participateCmd{cp1Addr: cp1AddrP2PKH, amount: amount, secretHash: secretHash, contractType: contractType}
 
 	case "redeem":
 		contract, err := hex.DecodeString(args[1])
@@ -389,85 +385,6 @@ func walletPort(params *chaincfg.Params) string {
 	}
 }
 
-// createSig creates and returns the serialized raw signature and compressed
-// pubkey for a transaction input signature.  Due to limitations of the Bitcoin
-// Core RPC API, this requires dumping a private key and signing in the client,
-// rather than letting the wallet sign.
-func createSig(tx *wire.MsgTx, idx int, pkScript []byte, addr btcutil.Address,
-	c *rpc.Client) (sig, pubkey []byte, err error) {
-
-	wif, err := c.DumpPrivKey(addr)
-	if err != nil {
-		return nil, nil, err
-	}
-	sig, err = txscript.RawTxInSignature(tx, idx, pkScript, txscript.SigHashAll, wif.PrivKey)
-	if err != nil {
-		return nil, nil, err
-	}
-	return sig, wif.PrivKey.PubKey().SerializeCompressed(), nil
-}
-
-// payTo calls a the payto JSON-RPC method,
-//It creates a funded ,signed transaction.
-func payTo(c *rpc.Client, destination btcutil.Address, amount btcutil.Amount) (fundedTx *wire.MsgTx, fee btcutil.Amount, err error) {
-	fundedTx, complete, err := c.PayTo(destination, amount, false)
-	if err != nil {
-		return
-	}
-	if !complete {
-		err = errors.New("payto:Created transaction is not complete")
-	}
-	//Fetch all unspent outputs from the wallet in order to calculate the fee
-	utxos, err := c.ListUnspent()
-	if err != nil {
-		return
-	}
-	findUtxofunc := func(outPoint wire.OutPoint) (*rpc.UnspentOutput, error) {
-		for _, utxo := range utxos {
-			if outPoint.Hash.IsEqual(&utxo.OutPoint.Hash) && outPoint.Index == utxo.OutPoint.Index {
-				return utxo, nil
-			}
-		}
-		return nil, fmt.Errorf("no utxo found for used input %s", outPoint)
-	}
-	var rawfee int64
-	for _, txin := range fundedTx.TxIn {
-		utxo, err := findUtxofunc(txin.PreviousOutPoint)
-		if err != nil {
-			return nil, 0, err
-		}
-		rawfee += int64(utxo.Value)
-	}
-	for _, txout := range fundedTx.TxOut {
-		rawfee -= txout.Value
-	}
-	fee = btcutil.Amount(rawfee)
-	return
-}
-
-// getFeePerKb queries the wallet for the current optimal fee rate per kilobyte,
-// according to config settings(static/dynamic).
-func getFeePerKb(c *rpc.Client) (feerate btcutil.Amount, err error) {
-	return c.GetFeeRate()
-}
-
-// getUnusedAddress uses the getunusedeaddress JSON-RPC method.
-func getUnusedAddress(c *rpc.Client) (btcutil.Address, error) {
-	addr, err := c.GetUnusedAddress()
-	if err != nil {
-		return nil, err
-	}
-	if !addr.IsForNet(chainParams) {
-		return nil, fmt.Errorf("address %v is not intended for use on %v",
-			addr, chainParams.Name)
-	}
-	if _, ok := addr.(*btcutil.AddressPubKeyHash); !ok {
-		return nil, fmt.Errorf("address %v is not P2PKH",
-			addr)
-	}
-	return addr, nil
-}
-
 func promptPublishTx(c *rpc.Client, tx *wire.MsgTx, name string) error {
 	if !*automatedFlag {
 		reader := bufio.NewReader(os.Stdin)
@@ -493,9 +410,9 @@ func promptPublishTx(c *rpc.Client, tx *wire.MsgTx, name string) error {
 		}
 	}
 
-	txHash, err := c.SendRawTransaction(tx, false)
+	txHash, err := btc.PublishTransaction(c, tx)
 	if err != nil {
-		return fmt.Errorf("sendrawtransaction: %v", err)
+		return err
 	}
 	if !*automatedFlag {
 		fmt.Printf("Published %s transaction (%v)\n", name, txHash)
@@ -503,224 +420,31 @@ func promptPublishTx(c *rpc.Client, tx *wire.MsgTx, name string) error {
 	return nil
 }
 
-// contractArgs specifies the common parameters used to create the initiator's
-// and participant's contract.
-type contractArgs struct {
-	them       *btcutil.AddressPubKeyHash
-	amount     btcutil.Amount
-	locktime   int64
-	secretHash []byte
-}
-
-// builtContract houses the details regarding a contract and the contract
-// payment transaction, as well as the transaction to perform a refund.
-type builtContract struct {
-	contract       []byte
-	contractP2SH   btcutil.Address
-	contractTxHash *chainhash.Hash
-	contractTx     *wire.MsgTx
-	contractFee    btcutil.Amount
-	refundTx       *wire.MsgTx
-	refundFee      btcutil.Amount
-}
-
-// buildContract creates a contract for the parameters specified in args, using
-// wallet RPC to generate an internal address to redeem the refund and to sign
-// the payment to the contract transaction.
-func buildContract(c *rpc.Client, args *contractArgs) (*builtContract, error) {
-	refundAddr, err := getUnusedAddress(c)
-	if err != nil {
-		return nil, fmt.Errorf("getunusedaddress: %v", err)
-	}
-	refundAddrH, ok := refundAddr.(interface {
-		Hash160() *[ripemd160.Size]byte
-	})
-	if !ok {
-		return nil, errors.New("unable to create hash160 from change address")
-	}
-
-	contract, err := atomicSwapContract(refundAddrH.Hash160(), args.them.Hash160(),
-		args.locktime, args.secretHash)
-	if err != nil {
-		return nil, err
-	}
-	contractP2SH, err := btcutil.NewAddressScriptHash(contract, chainParams)
-	if err != nil {
-		return nil, err
-	}
-	//contractP2SHPkScript, err := txscript.PayToAddrScript(contractP2SH)
-	//if err != nil {
-	//	return nil, err
-	//}
-
-	feePerKb, err := getFeePerKb(c)
-	if err != nil {
-		return nil, err
-	}
-
-	contractTx, contractFee, err := payTo(c, contractP2SH, args.amount)
-	// unsignedContract := wire.NewMsgTx(txVersion)
-	// unsignedContract.AddTxOut(wire.NewTxOut(int64(args.amount), contractP2SHPkScript))
-	// unsignedContract, contractFee, err := fundRawTransaction(c, unsignedContract, feePerKb)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("fundrawtransaction: %v", err)
-	// }
-	// contractTx, complete, err := c.SignRawTransaction(unsignedContract)
-	if err != nil {
-		return nil, fmt.Errorf("payTo: %v", err)
-	}
-
-	contractTxHash := contractTx.TxHash()
-
-	refundTx, refundFee, err := buildRefund(c, contract, contractTx, feePerKb)
-	if err != nil {
-		return nil, err
-	}
-
-	return &builtContract{
-		contract,
-		contractP2SH,
-		&contractTxHash,
-		contractTx,
-		contractFee,
-		refundTx,
-		refundFee,
-	}, nil
-}
-
-func buildRefund(c *rpc.Client, contract []byte, contractTx *wire.MsgTx, feePerKb btcutil.Amount) (
-	refundTx *wire.MsgTx, refundFee btcutil.Amount, err error) {
-
-	contractP2SH, err := btcutil.NewAddressScriptHash(contract, chainParams)
-	if err != nil {
-		return nil, 0, err
-	}
-	contractP2SHPkScript, err := txscript.PayToAddrScript(contractP2SH)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	contractTxHash := contractTx.TxHash()
-	contractOutPoint := wire.OutPoint{Hash: contractTxHash, Index: ^uint32(0)}
-	for i, o := range contractTx.TxOut {
-		if bytes.Equal(o.PkScript, contractP2SHPkScript) {
-			contractOutPoint.Index = uint32(i)
-			break
-		}
-	}
-	if contractOutPoint.Index == ^uint32(0) {
-		return nil, 0, errors.New("contract tx does not contain a P2SH contract payment")
-	}
-
-	refundAddress, err := getUnusedAddress(c)
-	if err != nil {
-		return nil, 0, fmt.Errorf("getunusedaddress: %v", err)
-	}
-	refundOutScript, err := txscript.PayToAddrScript(refundAddress)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
-	if err != nil {
-		// expected to only be called with good input
-		panic(err)
-	}
-
-	refundAddr, err := btcutil.NewAddressPubKeyHash(pushes.RefundHash160[:], chainParams)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	refundTx = wire.NewMsgTx(txVersion)
-	refundTx.LockTime = uint32(pushes.LockTime)
-	refundTx.AddTxOut(wire.NewTxOut(0, refundOutScript)) // amount set below
-	refundSize := estimateRefundSerializeSize(contract, refundTx.TxOut)
-	refundFee = txrules.FeeForSerializeSize(feePerKb, refundSize)
-	refundTx.TxOut[0].Value = contractTx.TxOut[contractOutPoint.Index].Value - int64(refundFee)
-	if txrules.IsDustOutput(refundTx.TxOut[0], feePerKb) {
-		return nil, 0, fmt.Errorf("refund output value of %v is dust", btcutil.Amount(refundTx.TxOut[0].Value))
-	}
-
-	txIn := wire.NewTxIn(&contractOutPoint, nil, nil)
-	txIn.Sequence = 0
-	refundTx.AddTxIn(txIn)
-
-	refundSig, refundPubKey, err := createSig(refundTx, 0, contract, refundAddr, c)
-	if err != nil {
-		return nil, 0, err
-	}
-	refundSigScript, err := refundP2SHContract(contract, refundSig, refundPubKey)
-	if err != nil {
-		return nil, 0, err
-	}
-	refundTx.TxIn[0].SignatureScript = refundSigScript
-
-	if verify {
-		e, err := txscript.NewEngine(contractTx.TxOut[contractOutPoint.Index].PkScript,
-			refundTx, 0, txscript.StandardVerifyFlags, txscript.NewSigCache(10),
-			txscript.NewTxSigHashes(refundTx), contractTx.TxOut[contractOutPoint.Index].Value)
-		if err != nil {
-			panic(err)
-		}
-		err = e.Execute()
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	return refundTx, refundFee, nil
-}
-
-func sha256Hash(x []byte) []byte {
-	h := sha256.Sum256(x)
-	return h[:]
-}
-
-func calcFeePerKb(absoluteFee btcutil.Amount, serializeSize int) float64 {
-	return float64(absoluteFee) / float64(serializeSize) / 1e5
-}
-
 func (cmd *initiateCmd) runCommand(c *rpc.Client) error {
-	var secret [secretSize]byte
-	_, err := rand.Read(secret[:])
-	if err != nil {
-		return err
-	}
-	secretHash := sha256Hash(secret[:])
-
-	// locktime after 500,000,000 (Tue Nov  5 00:53:20 1985 UTC) is interpreted
-	// as a unix time rather than a block height.
-	locktime := time.Now().Add(timings.LockTime).Unix()
-
-	b, err := buildContract(c, &contractArgs{
-		them:       cmd.cp2Addr,
-		amount:     cmd.amount,
-		locktime:   locktime,
-		secretHash: secretHash,
-	})
+	output, err := btc.Initiate(chainParams, c, cmd.cp2Addr, cmd.amount, lockTimes, cmd.contractType)
 	if err != nil {
 		return err
 	}
+	b := output.BuiltContract
 
-	refundTxHash := b.refundTx.TxHash()
-	contractFeePerKb := calcFeePerKb(b.contractFee, b.contractTx.SerializeSize())
-	refundFeePerKb := calcFeePerKb(b.refundFee, b.refundTx.SerializeSize())
+	refundTxHash := b.RefundTx.TxHash()
+	contractFeePerKb := btc.CalcFeePerKb(b.ContractFee, btc.VirtualSize(b.ContractTx))
+	refundFeePerKb := btc.CalcFeePerKb(b.RefundFee, btc.VirtualSize(b.RefundTx))
 
 	var contractBuf bytes.Buffer
-	contractBuf.Grow(b.contractTx.SerializeSize())
-	b.contractTx.Serialize(&contractBuf)
+	contractBuf.Grow(b.ContractTx.SerializeSize())
+	b.ContractTx.Serialize(&contractBuf)
 	var refundBuf bytes.Buffer
-	refundBuf.Grow(b.refundTx.SerializeSize())
-	b.refundTx.Serialize(&refundBuf)
+	refundBuf.Grow(b.RefundTx.SerializeSize())
+	b.RefundTx.Serialize(&refundBuf)
 	if !*automatedFlag {
-		fmt.Printf("Secret:      %x\n", secret)
-		fmt.Printf("Secret hash: %x\n\n", secretHash)
-		fmt.Printf("Contract fee: %v (%0.8f BTC/kB)\n", b.contractFee, contractFeePerKb)
-		fmt.Printf("Refund fee:   %v (%0.8f BTC/kB)\n\n", b.refundFee, refundFeePerKb)
-		fmt.Printf("Contract (%v):\n", b.contractP2SH)
-		fmt.Printf("%x\n\n", b.contract)
-		fmt.Printf("Contract transaction (%v):\n", b.contractTxHash)
+		fmt.Printf("Secret:      %x\n", output.Secret)
+		fmt.Printf("Secret hash: %x\n\n", output.SecretHash)
+		fmt.Printf("Contract fee: %v (%0.8f BTC/kB)\n", b.ContractFee, contractFeePerKb)
+		fmt.Printf("Refund fee:   %v (%0.8f BTC/kB)\n\n", b.RefundFee, refundFeePerKb)
+		fmt.Printf("Contract (%v):\n", b.ContractAddress)
+		fmt.Printf("%x\n\n", b.Contract)
+		fmt.Printf("Contract transaction (%v):\n", b.ContractTxHash)
 		fmt.Printf("%x\n\n", contractBuf.Bytes())
 		fmt.Printf("Refund transaction (%v):\n", &refundTxHash)
 		fmt.Printf("%x\n\n", refundBuf.Bytes())
@@ -738,13 +462,13 @@ func (cmd *initiateCmd) runCommand(c *rpc.Client) error {
 			RefundTransactionHash   string `json:"refundTransactionHash"`
 			RefundTransaction       string `json:"refundTransaction"`
 		}{
-			fmt.Sprintf("%x", secret),
-			fmt.Sprintf("%x", secretHash),
-			fmt.Sprintf("%v", b.contractFee),
-			fmt.Sprintf("%v", b.refundFee),
-			fmt.Sprintf("%v", b.contractP2SH),
-			fmt.Sprintf("%x", b.contract),
-			fmt.Sprintf("%v", b.contractTxHash),
+			fmt.Sprintf("%x", output.Secret),
+			fmt.Sprintf("%x", output.SecretHash),
+			fmt.Sprintf("%v", b.ContractFee),
+			fmt.Sprintf("%v", b.RefundFee),
+			fmt.Sprintf("%v", b.ContractAddress),
+			fmt.Sprintf("%x", b.Contract),
+			fmt.Sprintf("%v", b.ContractTxHash),
 			fmt.Sprintf("%x", contractBuf.Bytes()),
 			fmt.Sprintf("%v", &refundTxHash),
 			fmt.Sprintf("%x", refundBuf.Bytes()),
@@ -753,44 +477,35 @@ func (cmd *initiateCmd) runCommand(c *rpc.Client) error {
 		fmt.Println(string(jsonoutput))
 	}
 
-	return promptPublishTx(c, b.contractTx, "contract")
+	return promptPublishTx(c, b.ContractTx, "contract")
 
 }
 
 func (cmd *participateCmd) runCommand(c *rpc.Client) error {
-	// locktime after 500,000,000 (Tue Nov  5 00:53:20 1985 UTC) is interpreted
-	// as a unix time rather than a block height.
-
-	locktime := time.Now().Add(timings.LockTime / 2).Unix()
-
-	b, err := buildContract(c, &contractArgs{
-		them:       cmd.cp1Addr,
-		amount:     cmd.amount,
-		locktime:   locktime,
-		secretHash: cmd.secretHash,
-	})
+	output, err := btc.Participate(chainParams, c, cmd.cp1Addr, cmd.amount, cmd.secretHash, lockTimes, cmd.contractType)
 	if err != nil {
 		return err
 	}
+	b := output.BuiltContract
 
-	refundTxHash := b.refundTx.TxHash()
-	contractFeePerKb := calcFeePerKb(b.contractFee, b.contractTx.SerializeSize())
-	refundFeePerKb := calcFeePerKb(b.refundFee, b.refundTx.SerializeSize())
+	refundTxHash := b.RefundTx.TxHash()
+	contractFeePerKb := btc.CalcFeePerKb(b.ContractFee, btc.VirtualSize(b.ContractTx))
+	refundFeePerKb := btc.CalcFeePerKb(b.RefundFee, btc.VirtualSize(b.RefundTx))
 
 	var contractBuf bytes.Buffer
-	contractBuf.Grow(b.contractTx.SerializeSize())
-	b.contractTx.Serialize(&contractBuf)
+	contractBuf.Grow(b.ContractTx.SerializeSize())
+	b.ContractTx.Serialize(&contractBuf)
 
 	var refundBuf bytes.Buffer
-	refundBuf.Grow(b.refundTx.SerializeSize())
-	b.refundTx.Serialize(&refundBuf)
+	refundBuf.Grow(b.RefundTx.SerializeSize())
+	b.RefundTx.Serialize(&refundBuf)
 	if !*automatedFlag {
 
-		fmt.Printf("Contract fee: %v (%0.8f BTC/kB)\n", b.contractFee, contractFeePerKb)
-		fmt.Printf("Refund fee:   %v (%0.8f BTC/kB)\n\n", b.refundFee, refundFeePerKb)
-		fmt.Printf("Contract (%v):\n", b.contractP2SH)
-		fmt.Printf("%x\n\n", b.contract)
-		fmt.Printf("Contract transaction (%v):\n", b.contractTxHash)
+		fmt.Printf("Contract fee: %v (%0.8f BTC/kB)\n", b.ContractFee, contractFeePerKb)
+		fmt.Printf("Refund fee:   %v (%0.8f BTC/kB)\n\n", b.RefundFee, refundFeePerKb)
+		fmt.Printf("Contract (%v):\n", b.ContractAddress)
+		fmt.Printf("%x\n\n", b.Contract)
+		fmt.Printf("Contract transaction (%v):\n", b.ContractTxHash)
 		fmt.Printf("%x\n\n", contractBuf.Bytes())
 		fmt.Printf("Refund transaction (%v):\n", &refundTxHash)
 		fmt.Printf("%x\n\n", refundBuf.Bytes())
@@ -802,94 +517,32 @@ func (cmd *participateCmd) runCommand(c *rpc.Client) error {
 			ContractTransaction   string `json:"contractTransaction"`
 			RefundTransactionHash string `json:"refundTransaction"`
 		}{
-			fmt.Sprintf("%v", b.contractFee),
-			fmt.Sprintf("%v", b.refundFee),
-			fmt.Sprintf("%v", b.contractP2SH),
-			fmt.Sprintf("%v", b.contractTxHash),
+			fmt.Sprintf("%v", b.ContractFee),
+			fmt.Sprintf("%v", b.RefundFee),
+			fmt.Sprintf("%v", b.ContractAddress),
+			fmt.Sprintf("%v", b.ContractTxHash),
 			fmt.Sprintf("%v", &refundTxHash),
 		}
 		jsonoutput, _ := json.Marshal(output)
 		fmt.Println(string(jsonoutput))
 	}
-	return promptPublishTx(c, b.contractTx, "contract")
+	return promptPublishTx(c, b.ContractTx, "contract")
 }
 
 func (cmd *redeemCmd) runCommand(c *rpc.Client) error {
-	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, cmd.contract)
-	if err != nil {
-		return err
-	}
-	if pushes == nil {
-		return errors.New("contract is not an atomic swap script recognized by this tool")
-	}
-	recipientAddr, err := btcutil.NewAddressPubKeyHash(pushes.RecipientHash160[:],
-		chainParams)
-	if err != nil {
-		return err
-	}
-	contractHash := btcutil.Hash160(cmd.contract)
-	contractOut := -1
-	for i, out := range cmd.contractTx.TxOut {
-		sc, addrs, _, _ := txscript.ExtractPkScriptAddrs(out.PkScript, chainParams)
-		if sc == txscript.ScriptHashTy &&
-			bytes.Equal(addrs[0].(*btcutil.AddressScriptHash).Hash160()[:], contractHash) {
-			contractOut = i
-			break
-		}
-	}
-	if contractOut == -1 {
-		return errors.New("transaction does not contain a contract output")
-	}
-
-	addr, err := getUnusedAddress(c)
-	if err != nil {
-		return fmt.Errorf("getrawchangeaddres: %v", err)
-	}
-	outScript, err := txscript.PayToAddrScript(addr)
+	output, err := btc.Redeem(chainParams, c, cmd.contract, cmd.contractTx, cmd.secret)
 	if err != nil {
 		return err
 	}
-
-	contractTxHash := cmd.contractTx.TxHash()
-	contractOutPoint := wire.OutPoint{
-		Hash:  contractTxHash,
-		Index: uint32(contractOut),
-	}
-
-	feePerKb, err := getFeePerKb(c)
-	if err != nil {
-		return err
-	}
-
-	redeemTx := wire.NewMsgTx(txVersion)
-	redeemTx.LockTime = uint32(pushes.LockTime)
-	redeemTx.AddTxIn(wire.NewTxIn(&contractOutPoint, nil, nil))
-	redeemTx.AddTxOut(wire.NewTxOut(0, outScript)) // amount set below
-	redeemSize := estimateRedeemSerializeSize(cmd.contract, redeemTx.TxOut)
-	fee := txrules.FeeForSerializeSize(feePerKb, redeemSize)
-	redeemTx.TxOut[0].Value = cmd.contractTx.TxOut[contractOut].Value - int64(fee)
-	if txrules.IsDustOutput(redeemTx.TxOut[0], feePerKb) {
-		return fmt.Errorf("redeem output value of %v is dust", btcutil.Amount(redeemTx.TxOut[0].Value))
-	}
-
-	redeemSig, redeemPubKey, err := createSig(redeemTx, 0, cmd.contract, recipientAddr, c)
-	if err != nil {
-		return err
-	}
-	redeemSigScript, err := redeemP2SHContract(cmd.contract, redeemSig, redeemPubKey, cmd.secret)
-	if err != nil {
-		return err
-	}
-	redeemTx.TxIn[0].SignatureScript = redeemSigScript
-
+	redeemTx := output.RedeemTx
 	redeemTxHash := redeemTx.TxHash()
-	redeemFeePerKb := calcFeePerKb(fee, redeemTx.SerializeSize())
+	redeemFeePerKb := btc.CalcFeePerKb(output.RedeemFee, btc.VirtualSize(redeemTx))
 
 	var buf bytes.Buffer
 	buf.Grow(redeemTx.SerializeSize())
 	redeemTx.Serialize(&buf)
 	if !*automatedFlag {
-		fmt.Printf("Redeem fee: %v (%0.8f BTC/kB)\n\n", fee, redeemFeePerKb)
+		fmt.Printf("Redeem fee: %v (%0.8f BTC/kB)\n\n", output.RedeemFee, redeemFeePerKb)
 		fmt.Printf("Redeem transaction (%v):\n", &redeemTxHash)
 		fmt.Printf("%x\n\n", buf.Bytes())
 	} else {
@@ -897,54 +550,30 @@ func (cmd *redeemCmd) runCommand(c *rpc.Client) error {
 			RedeemFee               string `json:"redeemFee"`
 			RedeemTransactionTxHash string `json:"redeemTransaction"`
 		}{
-			fmt.Sprintf("%v", fee),
+			fmt.Sprintf("%v", output.RedeemFee),
 			fmt.Sprintf("%v", &redeemTxHash),
 		}
 		jsonoutput, _ := json.Marshal(output)
 		fmt.Println(string(jsonoutput))
 	}
-	if verify {
-		e, err := txscript.NewEngine(cmd.contractTx.TxOut[contractOutPoint.Index].PkScript,
-			redeemTx, 0, txscript.StandardVerifyFlags, txscript.NewSigCache(10),
-			txscript.NewTxSigHashes(redeemTx), cmd.contractTx.TxOut[contractOut].Value)
-		if err != nil {
-			panic(err)
-		}
-		err = e.Execute()
-		if err != nil {
-			panic(err)
-		}
-	}
 
 	return promptPublishTx(c, redeemTx, "redeem")
 }
 
 func (cmd *refundCmd) runCommand(c *rpc.Client) error {
-	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, cmd.contract)
-	if err != nil {
-		return err
-	}
-	if pushes == nil {
-		return errors.New("contract is not an atomic swap script recognized by this tool")
-	}
-
-	feePerKb, err := getFeePerKb(c)
-	if err != nil {
-		return err
-	}
-
-	refundTx, refundFee, err := buildRefund(c, cmd.contract, cmd.contractTx, feePerKb)
+	output, err := btc.Refund(chainParams, c, cmd.contract, cmd.contractTx)
 	if err != nil {
 		return err
 	}
+	refundTx := output.RefundTx
 	refundTxHash := refundTx.TxHash()
 	var buf bytes.Buffer
 	buf.Grow(refundTx.SerializeSize())
 	refundTx.Serialize(&buf)
 
-	refundFeePerKb := calcFeePerKb(refundFee, refundTx.SerializeSize())
+	refundFeePerKb := btc.CalcFeePerKb(output.RefundFee, btc.VirtualSize(refundTx))
 	if !*automatedFlag {
-		fmt.Printf("Refund fee: %v (%0.8f BTC/kB)\n\n", refundFee, refundFeePerKb)
+		fmt.Printf("Refund fee: %v (%0.8f BTC/kB)\n\n", output.RefundFee, refundFeePerKb)
 		fmt.Printf("Refund transaction (%v):\n", &refundTxHash)
 		fmt.Printf("%x\n\n", buf.Bytes())
 	} else {
@@ -952,7 +581,7 @@ func (cmd *refundCmd) runCommand(c *rpc.Client) error {
 			RefundFee               string `json:"refundFee"`
 			RefundTransactionTxHash string `json:"refundTransaction"`
 		}{
-			fmt.Sprintf("%v", refundFee),
+			fmt.Sprintf("%v", output.RefundFee),
 			fmt.Sprintf("%v", &refundTxHash),
 		}
 		jsonoutput, _ := json.Marshal(output)
@@ -966,24 +595,12 @@ func (cmd *extractSecretCmd) runCommand(c *rpc.Client) error {
 }
 
 func (cmd *extractSecretCmd) runOfflineCommand() error {
-	// Loop over all pushed data from all inputs, searching for one that hashes
-	// to the expected hash.  By searching through all data pushes, we avoid any
-	// issues that could be caused by the initiator redeeming the participant's
-	// contract with some "nonstandard" or unrecognized transaction or script
-	// type.
-	for _, in := range cmd.redemptionTx.TxIn {
-		pushes, err := txscript.PushedData(in.SignatureScript)
-		if err != nil {
-			return err
-		}
-		for _, push := range pushes {
-			if bytes.Equal(sha256Hash(push), cmd.secretHash) {
-				fmt.Printf("Secret: %x\n", push)
-				return nil
-			}
-		}
+	secret, err := btc.ExtractSecret(cmd.redemptionTx, cmd.secretHash)
+	if err != nil {
+		return err
 	}
-	return errors.New("transaction does not contain the secret")
+	fmt.Printf("Secret: %x\n", secret)
+	return nil
 }
 
 func (cmd *auditContractCmd) runCommand(c *rpc.Client) error {
@@ -991,57 +608,20 @@ func (cmd *auditContractCmd) runCommand(c *rpc.Client) error {
 }
 
 func (cmd *auditContractCmd) runOfflineCommand() error {
-	contractHash160 := btcutil.Hash160(cmd.contract)
-	contractOut := -1
-	for i, out := range cmd.contractTx.TxOut {
-		sc, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript, chainParams)
-		if err != nil || sc != txscript.ScriptHashTy {
-			continue
-		}
-		if bytes.Equal(addrs[0].(*btcutil.AddressScriptHash).Hash160()[:], contractHash160) {
-			contractOut = i
-			break
-		}
-	}
-	if contractOut == -1 {
-		return errors.New("transaction does not contain the contract output")
-	}
-
-	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, cmd.contract)
-	if err != nil {
-		return err
-	}
-	if pushes == nil {
-		return errors.New("contract is not an atomic swap script recognized by this tool")
-	}
-	if pushes.SecretSize != secretSize {
-		return fmt.Errorf("contract specifies strange secret size %v", pushes.SecretSize)
-	}
-
-	contractAddr, err := btcutil.NewAddressScriptHash(cmd.contract, chainParams)
-	if err != nil {
-		return err
-	}
-	recipientAddr, err := btcutil.NewAddressPubKeyHash(pushes.RecipientHash160[:],
-		chainParams)
-	if err != nil {
-		return err
-	}
-	refundAddr, err := btcutil.NewAddressPubKeyHash(pushes.RefundHash160[:],
-		chainParams)
+	output, err := btc.AuditContract(chainParams, cmd.contract, cmd.contractTx)
 	if err != nil {
 		return err
 	}
 	if !*automatedFlag {
-		fmt.Printf("Contract address:        %v\n", contractAddr)
-		fmt.Printf("Contract value:          %v\n", btcutil.Amount(cmd.contractTx.TxOut[contractOut].Value))
-		fmt.Printf("Recipient address:       %v\n", recipientAddr)
-		fmt.Printf("Refund address: %v\n\n", refundAddr)
+		fmt.Printf("Contract address:        %v\n", output.ContractAddress)
+		fmt.Printf("Contract value:          %v\n", output.ContractValue)
+		fmt.Printf("Recipient address:       %v\n", output.RecipientAddress)
+		fmt.Printf("Refund address: %v\n\n", output.RefundAddress)
 
-		fmt.Printf("Secret hash: %x\n\n", pushes.SecretHash[:])
+		fmt.Printf("Secret hash: %x\n\n", output.SecretHash[:])
 
-		if pushes.LockTime >= int64(txscript.LockTimeThreshold) {
-			t := time.Unix(pushes.LockTime, 0)
+		if output.Locktime >= int64(txscript.LockTimeThreshold) {
+			t := time.Unix(output.Locktime, 0)
 			fmt.Printf("Locktime: %v\n", t.UTC())
 			reachedAt := time.Until(t).Truncate(time.Second)
 			if reachedAt > 0 {
@@ -1050,10 +630,10 @@ func (cmd *auditContractCmd) runOfflineCommand() error {
 				fmt.Printf("Contract refund time lock has expired\n")
 			}
 		} else {
-			fmt.Printf("Locktime: block %v\n", pushes.LockTime)
+			fmt.Printf("Locktime: block %v\n", output.Locktime)
 		}
 	} else {
-		output := struct {
+		jsonOutput := struct {
 			ContractAddress  string `json:"contractAddress"`
 			ContractValue    string `json:"contractValue"`
 			RecipientAddress string `json:"recipientAddress"`
@@ -1061,107 +641,48 @@ func (cmd *auditContractCmd) runOfflineCommand() error {
 			SecretHash       string `json:"secretHash"`
 			Locktime         string `json:"Locktime"`
 		}{
-			fmt.Sprintf("%v", contractAddr),
-			fmt.Sprintf("%v", btcutil.Amount(cmd.contractTx.TxOut[contractOut].Value)),
-			fmt.Sprintf("%v", recipientAddr),
-			fmt.Sprintf("%v", refundAddr),
-			fmt.Sprintf("%x", pushes.SecretHash[:]),
+			output.ContractAddress,
+			fmt.Sprintf("%v", output.ContractValue),
+			output.RecipientAddress,
+			output.RefundAddress,
+			fmt.Sprintf("%x", output.SecretHash[:]),
 			"",
 		}
 
-		if pushes.LockTime >= int64(txscript.LockTimeThreshold) {
-			t := time.Unix(pushes.LockTime, 0)
-			output.Locktime = fmt.Sprintf("%v", t.UTC())
+		if output.Locktime >= int64(txscript.LockTimeThreshold) {
+			t := time.Unix(output.Locktime, 0)
+			jsonOutput.Locktime = fmt.Sprintf("%v", t.UTC())
 		} else {
-			output.Locktime = fmt.Sprintf("block %v", pushes.LockTime)
+			jsonOutput.Locktime = fmt.Sprintf("block %v", output.Locktime)
 		}
-		jsonoutput, _ := json.Marshal(output)
+		jsonoutput, _ := json.Marshal(jsonOutput)
 		fmt.Println(string(jsonoutput))
 	}
 
-	return nil
+	return verifyTerms(output.AuditResult())
 }
 
-// atomicSwapContract returns an output script that may be redeemed by one of
-// two signature scripts:
-//
-//   <their sig> <their pubkey> <initiator secret> 1
-//
-//   <my sig> <my pubkey> 0
-//
-// The first signature script is the normal redemption path done by the other
-// party and requires the initiator's secret.  The second signature script is
-// the refund path performed by us, but the refund can only be performed after
-// locktime.
-func atomicSwapContract(pkhMe, pkhThem *[ripemd160.Size]byte, locktime int64, secretHash []byte) ([]byte, error) {
-	b := txscript.NewScriptBuilder()
-
-	b.AddOp(txscript.OP_IF) // Normal redeem path
-	{
-		// Require initiator's secret to be a known length that the redeeming
-		// party can audit.  This is used to prevent fraud attacks between two
-		// currencies that have different maximum data sizes.
-		b.AddOp(txscript.OP_SIZE)
-		b.AddInt64(secretSize)
-		b.AddOp(txscript.OP_EQUALVERIFY)
-
-		// Require initiator's secret to be known to redeem the output.
-		b.AddOp(txscript.OP_SHA256)
-		b.AddData(secretHash)
-		b.AddOp(txscript.OP_EQUALVERIFY)
-
-		// Verify their signature is being used to redeem the output.  This
-		// would normally end with OP_EQUALVERIFY OP_CHECKSIG but this has been
-		// moved outside of the branch to save a couple bytes.
-		b.AddOp(txscript.OP_DUP)
-		b.AddOp(txscript.OP_HASH160)
-		b.AddData(pkhThem[:])
+// verifyTerms verifies an audited contract matches the terms of the expect flag, if any
+func verifyTerms(audit chain.AuditResult) error {
+	if *expectFlag == "" {
+		return nil
 	}
-	b.AddOp(txscript.OP_ELSE) // Refund path
-	{
-		// Verify locktime and drop it off the stack (which is not done by
-		// CLTV).
-		b.AddInt64(locktime)
-		b.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
-		b.AddOp(txscript.OP_DROP)
-
-		// Verify our signature is being used to redeem the output.  This would
-		// normally end with OP_EQUALVERIFY OP_CHECKSIG but this has been moved
-		// outside of the branch to save a couple bytes.
-		b.AddOp(txscript.OP_DUP)
-		b.AddOp(txscript.OP_HASH160)
-		b.AddData(pkhMe[:])
+	terms, err := chain.ParseTerms(*expectFlag, 8)
+	if err != nil {
+		return err
 	}
-	b.AddOp(txscript.OP_ENDIF)
-
-	// Complete the signature check.
-	b.AddOp(txscript.OP_EQUALVERIFY)
-	b.AddOp(txscript.OP_CHECKSIG)
-
-	return b.Script()
-}
-
-// redeemP2SHContract returns the signature script to redeem a contract output
-// using the redeemer's signature and the initiator's secret.  This function
-// assumes P2SH and appends the contract as the final data push.
-func redeemP2SHContract(contract, sig, pubkey, secret []byte) ([]byte, error) {
-	b := txscript.NewScriptBuilder()
-	b.AddData(sig)
-	b.AddData(pubkey)
-	b.AddData(secret)
-	b.AddInt64(1)
-	b.AddData(contract)
-	return b.Script()
-}
-
-// refundP2SHContract returns the signature script to refund a contract output
-// using the contract author's signature after the locktime has been reached.
-// This function assumes P2SH and appends the contract as the final data push.
-func refundP2SHContract(contract, sig, pubkey []byte) ([]byte, error) {
-	b := txscript.NewScriptBuilder()
-	b.AddData(sig)
-	b.AddData(pubkey)
-	b.AddInt64(0)
-	b.AddData(contract)
-	return b.Script()
+	err = chain.VerifySwapTerms(audit, terms)
+	if *automatedFlag {
+		violations, _ := err.(chain.Violations)
+		if violations == nil {
+			violations = chain.Violations{}
+		}
+		jsonoutput, _ := json.Marshal(struct {
+			Violations chain.Violations `json:"violations"`
+		}{violations})
+		fmt.Println(string(jsonoutput))
+	} else if err == nil {
+		fmt.Println("Contract matches the swap terms")
+	}
+	return err
 }
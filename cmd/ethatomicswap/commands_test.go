@@ -0,0 +1,459 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/threefoldtech/atomicswap/eth"
+	"github.com/threefoldtech/atomicswap/eth/ethtest"
+)
+
+const testPassphrase = "passphrase"
+
+// testRPCURL is the RPC URL of the test network, dialing it connects to the simulated backend
+const testRPCURL = "http://simulated.backend"
+
+// testBackend is the simulated backend shared by the commands,
+// it is not closed with the clients of the commands
+type testBackend struct {
+	*ethtest.Backend
+}
+
+func (testBackend) Close() error {
+	return nil
+}
+
+// testEnv runs the commands against a simulated backend,
+// with the AtomicSwap contract deployed by deploycontract.
+// The two contracts of a swap are created in different contracts,
+// as if they were on different chains.
+type testEnv struct {
+	t        *testing.T
+	backend  *ethtest.Backend
+	dir      string
+	networks string // path of the networks file with the test network
+	contract common.Address
+	// other AtomicSwap contract, for the contracts of the participant
+	other common.Address
+
+	// accounts funded in the genesis block, alice initiates the swaps and bob participates
+	alice, bob common.Address
+	// files of the keys of the accounts
+	aliceFile, bobFile string
+}
+
+// newTestEnv creates the test environment,
+// with the clock of the chain moved by the offset before deploying the contracts
+func newTestEnv(t *testing.T, offset time.Duration) *testEnv {
+	env := &testEnv{
+		t:   t,
+		dir: t.TempDir(),
+	}
+	aliceKey, bobKey := env.newKey(), env.newKey()
+	env.alice, env.aliceFile = env.keyFile(aliceKey)
+	env.bob, env.bobFile = env.keyFile(bobKey)
+	balance := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	env.backend = ethtest.NewBackend(core.GenesisAlloc{
+		env.alice: {Balance: balance},
+		env.bob:   {Balance: balance},
+	})
+	t.Cleanup(func() {
+		env.backend.Close()
+	})
+	// the audits compare the lock times with the local clock, the clock of the chain
+	// starts an hour before it, leaving time for the blocks mined by the test
+	// as their time can not be ahead of the current time
+	env.adjustTime(time.Since(time.Unix(0, 0)) + offset - time.Hour)
+	askPassphrase = func(string) (string, error) {
+		return testPassphrase, nil
+	}
+	dialClient = func(ctx context.Context, url string) (*eth.EthClient, error) {
+		if url != testRPCURL {
+			return eth.DialClient(ctx, url)
+		}
+		return eth.NewClient(testBackend{env.backend}), nil
+	}
+
+	env.writeNetworks(testRPCURL)
+	env.contract = env.deploy()
+	env.other = env.deploy()
+	env.writeNetworks(testRPCURL)
+	return env
+}
+
+// deploy deploys an AtomicSwap contract using deploycontract
+func (env *testEnv) deploy() common.Address {
+	env.t.Helper()
+	out := env.mustRun("y\n", "-account", env.aliceFile, "deploycontract")
+	deployTx := env.match(out, `Published deploy transaction \(([0-9a-f]{64})\)`)
+	receipt, err := env.backend.TransactionReceipt(context.Background(), common.HexToHash(deployTx))
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	if printed := env.match(out, `Contract Address: ([0-9a-f]{40})`); common.HexToAddress(printed) != receipt.ContractAddress {
+		env.t.Fatalf("deploycontract printed contract address %s, deployed at %x", printed, receipt.ContractAddress)
+	}
+	return receipt.ContractAddress
+}
+
+func (env *testEnv) newKey() *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	return key
+}
+
+// adjustTime moves the clock of the chain forward
+func (env *testEnv) adjustTime(d time.Duration) {
+	if err := env.backend.AdjustTime(d); err != nil {
+		env.t.Fatal(err)
+	}
+}
+
+// keyFile stores the key in a key file encrypted with the test passphrase
+func (env *testEnv) keyFile(key *ecdsa.PrivateKey) (common.Address, string) {
+	ks := keystore.NewKeyStore(filepath.Join(env.dir, "keys"), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(key, testPassphrase)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	return account.Address, account.URL.Path
+}
+
+// writeNetworks writes the profile of the test network,
+// with the deployed contract if any
+func (env *testEnv) writeNetworks(url string) {
+	b, err := json.Marshal([]eth.Network{{
+		Name:          "test",
+		ChainID:       env.backend.Blockchain().Config().ChainID,
+		RPCURL:        url,
+		Contract:      env.contract,
+		Confirmations: 1,
+	}})
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	env.networks = env.writeFile("networks.json", string(b))
+}
+
+func (env *testEnv) writeFile(name, content string) string {
+	path := filepath.Join(env.dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		env.t.Fatal(err)
+	}
+	return path
+}
+
+// run runs the command on the test network, with the flags reset and the given input,
+// returning its output
+func (env *testEnv) run(stdin string, args ...string) (string, error) {
+	flagset.VisitAll(func(f *flag.Flag) {
+		f.Value.Set(f.DefValue)
+	})
+	token = eth.TokenInfo{Symbol: "ETH", Decimals: weiPrecision}
+	contractVersions = eth.DefaultContractVersions()
+	os.Args = append([]string{"ethatomicswap", "-networks", env.networks, "-network", "test"}, args...)
+
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	origStdin, origStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdinR, stdoutW
+	defer func() {
+		os.Stdin, os.Stdout = origStdin, origStdout
+		stdinR.Close()
+	}()
+	go func() {
+		io.WriteString(stdinW, stdin)
+		stdinW.Close()
+	}()
+	output := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, stdoutR)
+		output <- b.String()
+	}()
+
+	err, showUsage := run()
+	stdoutW.Close()
+	out := <-output
+	if err == nil && showUsage {
+		err = fmt.Errorf("usage shown")
+	}
+	return out, err
+}
+
+// mustRun runs the command, failing the test if it fails
+func (env *testEnv) mustRun(stdin string, args ...string) string {
+	env.t.Helper()
+	out, err := env.run(stdin, args...)
+	if err != nil {
+		env.t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// match returns the first submatch of the pattern in the output
+func (env *testEnv) match(out, pattern string) string {
+	env.t.Helper()
+	m := regexp.MustCompile(pattern).FindStringSubmatch(out)
+	if m == nil {
+		env.t.Fatalf("%q not found in output:\n%s", pattern, out)
+	}
+	return m[1]
+}
+
+// contractTx returns the contract transaction printed in the output
+func (env *testEnv) contractTx(out string) string {
+	env.t.Helper()
+	return env.match(out, `Contract transaction \([0-9a-f]{64}\):\n([0-9a-f]+)`)
+}
+
+// minedTx returns the RLP of the mined transaction, as printed by the commands
+func (env *testEnv) minedTx(hash string) string {
+	env.t.Helper()
+	tx, _, err := env.backend.TransactionByHash(context.Background(), common.HexToHash(hash))
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func TestSwapCommands(t *testing.T) {
+	env := newTestEnv(t, 0)
+
+	out := env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), "1")
+	secret := env.match(out, `Secret:      ([0-9a-f]{64})`)
+	secretHash := env.match(out, `Secret hash: ([0-9a-f]{64})`)
+	initiateTx := env.contractTx(out)
+
+	out = env.mustRun("", "-account", env.bobFile, "auditcontract", initiateTx,
+		"-expect", "amount=1,recipient="+env.bob.Hex()+",secrethash="+secretHash)
+	for _, expected := range []string{"Contract value:          1 ETH", "Contract version:        AtomicSwap-v1", "Contract matches the swap terms"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("auditcontract output misses %q:\n%s", expected, out)
+		}
+	}
+	if _, err := env.run("", "auditcontract", initiateTx, "-expect", "amount=2"); err == nil {
+		t.Error("audited contract matches terms of another amount")
+	}
+
+	out = env.mustRun("", "-account", env.bobFile, "-c", env.other.Hex(), "participate", env.alice.Hex(), "0.5", secretHash)
+	participateTx := env.contractTx(out)
+
+	if _, err := env.run("", "-account", env.aliceFile, "-c", env.other.Hex(), "redeem", participateTx, secretHash); err == nil {
+		t.Error("redeemed with the secret hash as secret")
+	}
+	out = env.mustRun("", "-account", env.aliceFile, "-c", env.other.Hex(), "-wait", "redeem", participateTx, secret)
+	redeemTx := env.minedTx(env.match(out, `Redeem transaction \(([0-9a-f]{64})\)`))
+	if !strings.Contains(out, "mined in block") {
+		t.Errorf("redeem not waited for:\n%s", out)
+	}
+
+	out = env.mustRun("", "extractsecret", redeemTx, secretHash)
+	if extracted := env.match(out, `Secret: ([0-9a-f]{64})`); extracted != secret {
+		t.Errorf("extracted secret %s from the redeem transaction, expected %s", extracted, secret)
+	}
+	out = env.mustRun("", "-c", env.other.Hex(), "extractsecret", secretHash)
+	if extracted := env.match(out, `Secret: ([0-9a-f]{64})`); extracted != secret {
+		t.Errorf("extracted secret %s by hash, expected %s", extracted, secret)
+	}
+
+	balance := env.backend.Balance(env.bob)
+	env.mustRun("", "-account", env.bobFile, "redeem", initiateTx, secret)
+	received := new(big.Int).Sub(env.backend.Balance(env.bob), balance)
+	if received.Cmp(big.NewInt(params.Ether*99/100)) < 0 || received.Cmp(big.NewInt(params.Ether)) >= 0 {
+		t.Errorf("bob received %s Wei redeeming 1 ETH", received)
+	}
+	if _, err := env.run("", "-account", env.bobFile, "redeem", initiateTx, secret); err == nil {
+		t.Error("redeemed twice")
+	}
+}
+
+func TestRefundCommands(t *testing.T) {
+	// initiate the contracts more than the lock time ago
+	env := newTestEnv(t, -49*time.Hour)
+	out := env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), "1")
+	initiateTx := env.contractTx(out)
+	out = env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), "0.1")
+	batchTx := env.contractTx(out)
+	out = env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), "0.2")
+	batchTx2 := env.contractTx(out)
+	env.adjustTime(49 * time.Hour)
+
+	if _, err := env.run("", "-account", env.bobFile, "refund", initiateTx); err == nil {
+		t.Error("refunded by the participant")
+	}
+	balance := env.backend.Balance(env.alice)
+	out = env.mustRun("", "-account", env.aliceFile, "refund", initiateTx)
+	env.match(out, `Refund transaction \(([0-9a-f]{64})\)`)
+	if refunded := new(big.Int).Sub(env.backend.Balance(env.alice), balance); refunded.Cmp(big.NewInt(params.Ether*99/100)) < 0 {
+		t.Errorf("alice got %s Wei refunding 1 ETH", refunded)
+	}
+
+	batchFile := env.writeFile("refunds", batchTx+"\n"+batchTx2+"\n"+initiateTx+"\n")
+	out, err := env.run("", "-account", env.aliceFile, "batchrefund", batchFile)
+	if err == nil {
+		t.Errorf("batch refunding a refunded contract succeeded:\n%s", out)
+	}
+	if n := strings.Count(out, "Refund transaction"); n != 2 {
+		t.Errorf("%d contracts refunded in batch, expected 2:\n%s", n, out)
+	}
+}
+
+func TestBatchRedeemCommand(t *testing.T) {
+	env := newTestEnv(t, 0)
+
+	var lines []string
+	for _, amount := range []string{"0.1", "0.2"} {
+		out := env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), amount)
+		lines = append(lines, env.contractTx(out)+" "+env.match(out, `Secret:      ([0-9a-f]{64})`))
+	}
+	out := env.mustRun("", "-account", env.bobFile, "batchredeem", env.writeFile("redeems", strings.Join(lines, "\n")))
+	if n := strings.Count(out, "Redeem transaction"); n != 2 {
+		t.Errorf("%d contracts redeemed in batch, expected 2:\n%s", n, out)
+	}
+}
+
+func TestContractProofCommands(t *testing.T) {
+	env := newTestEnv(t, 0)
+
+	out := env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), "1")
+	initiateTx := env.contractTx(out)
+
+	out = env.mustRun("", "contractproof", initiateTx)
+	blockHash := env.match(out, `included in block \d+ \(([0-9a-f]{64})\)`)
+	proof := env.writeFile("proof.json", out[strings.Index(out, "{"):])
+
+	// audited offline, the node is not called
+	env.writeNetworks("http://127.0.0.1:1")
+	out = env.mustRun("", "auditcontract", initiateTx, "-proof", proof, "-blockhash", "0x"+blockHash)
+	if !strings.Contains(out, "Contract proven in block") || !strings.Contains(out, fmt.Sprintf("Recipient address:       %x", env.bob)) {
+		t.Errorf("unexpected auditcontract output:\n%s", out)
+	}
+	if _, err := env.run("", "auditcontract", initiateTx, "-proof", proof, "-blockhash", "0x"+strings.Repeat("00", 32)); err == nil {
+		t.Error("contract proven in another block")
+	}
+
+	// the proof does not cover the code of the contract,
+	// so a contract other than the one of the network has to be passed explicitly
+	env.writeNetworks(testRPCURL)
+	out = env.mustRun("", "-account", env.aliceFile, "-c", env.other.Hex(), "initiate", env.bob.Hex(), "1")
+	otherTx := env.contractTx(out)
+	out = env.mustRun("", "-c", env.other.Hex(), "contractproof", otherTx)
+	blockHash = env.match(out, `included in block \d+ \(([0-9a-f]{64})\)`)
+	proof = env.writeFile("proof.json", out[strings.Index(out, "{"):])
+	env.writeNetworks("http://127.0.0.1:1")
+	if _, err := env.run("", "auditcontract", otherTx, "-proof", proof, "-blockhash", "0x"+blockHash); err == nil || !strings.Contains(err.Error(), "not the trusted contract") {
+		t.Errorf("contract proven for an unknown contract address: %v", err)
+	}
+	out = env.mustRun("", "-c", env.other.Hex(), "auditcontract", otherTx, "-proof", proof, "-blockhash", "0x"+blockHash)
+	if !strings.Contains(out, fmt.Sprintf("Contract address:        %x", env.other)) {
+		t.Errorf("unexpected auditcontract output:\n%s", out)
+	}
+}
+
+func TestContractCommands(t *testing.T) {
+	env := newTestEnv(t, 0)
+
+	out := env.mustRun("", "verifycontract")
+	if !strings.Contains(out, "Contract version: AtomicSwap-v1") {
+		t.Errorf("unexpected verifycontract output:\n%s", out)
+	}
+	if _, err := env.run("", "verifycontract", env.alice.Hex()); err == nil {
+		t.Error("verified an account without code")
+	}
+	if _, err := env.run("", "-testnet", "verifycontract"); err == nil || !strings.Contains(err.Error(), "-testnet") {
+		t.Errorf("-testnet overrode -network: %v", err)
+	}
+
+	deployTx := env.minedTx(env.match(env.mustRun("y\n", "-account", env.aliceFile, "deploycontract"),
+		`Published deploy transaction \(([0-9a-f]{64})\)`))
+	out = env.mustRun("", "validatedeployedcontract", deployTx)
+	if !strings.Contains(out, "Contract is valid: AtomicSwap-v1") {
+		t.Errorf("unexpected validatedeployedcontract output:\n%s", out)
+	}
+
+	// the deploy transaction is not published without confirmation
+	out = env.mustRun("n\n", "-account", env.aliceFile, "deploycontract")
+	if strings.Contains(out, "Published") {
+		t.Errorf("deploy transaction published without confirmation:\n%s", out)
+	}
+}
+
+func TestOfflineSigningCommands(t *testing.T) {
+	env := newTestEnv(t, 0)
+
+	out := env.mustRun("", "-account", env.aliceFile, "-unsigned", "initiate", env.bob.Hex(), "1")
+	secretHash := env.match(out, `Secret hash: ([0-9a-f]{64})`)
+	unsigned := env.writeFile("unsigned.json", out[strings.Index(out, "{"):])
+
+	// signing does not need the node
+	out = env.mustRun("", "-account", env.aliceFile, "sign", unsigned)
+	signed := env.match(out, `Signed initiate transaction \([0-9a-f]{64}\):\n([0-9a-f]+)`)
+	if _, err := env.run("", "-account", env.bobFile, "sign", unsigned); err == nil {
+		t.Error("signed by another account")
+	}
+
+	out = env.mustRun("", "broadcast", signed)
+	contractTx := env.match(out, `Published transaction \([0-9a-f]{64}\) from [0-9a-f]{40}:\n([0-9a-f]+)`)
+	out = env.mustRun("", "auditcontract", contractTx)
+	if env.match(out, `Secret hash: ([0-9a-f]{64})`) != secretHash {
+		t.Errorf("unexpected contract broadcast:\n%s", out)
+	}
+}
+
+func TestAuthorizeRedeemCommand(t *testing.T) {
+	env := newTestEnv(t, 0)
+
+	out := env.mustRun("", "-account", env.aliceFile, "initiate", env.bob.Hex(), "1")
+	initiateTx := env.contractTx(out)
+	relayer := common.HexToAddress("0x7e1a")
+
+	if _, err := env.run("", "-account", env.aliceFile, "authorizeredeem", initiateTx, relayer.Hex(), "0.01"); err == nil {
+		t.Error("redeem authorized by the initiator")
+	}
+	out = env.mustRun("", "-account", env.bobFile, "authorizeredeem", initiateTx, relayer.Hex(), "0.01")
+	var auth eth.RedeemAuthorization
+	if err := json.Unmarshal([]byte(out[strings.Index(out, "{"):]), &auth); err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := auth.Recipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recipient != env.bob || auth.Relayer != relayer || auth.Contract != env.contract || auth.Fee.Cmp(big.NewInt(params.Ether/100)) != 0 {
+		t.Errorf("unexpected redeem authorization: %+v", auth)
+	}
+}
@@ -6,12 +6,12 @@ package main
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -23,42 +23,58 @@ import (
 	"github.com/bgentry/speakeasy"
 	"github.com/pkg/errors"
 
-	ethereum "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
-	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/threefoldtech/atomicswap/chain"
 	"github.com/threefoldtech/atomicswap/eth"
-	"github.com/threefoldtech/atomicswap/eth/contract"
+	"github.com/threefoldtech/atomicswap/timings"
 )
 
 var (
-	chainConfig = params.MainnetChainConfig
-)
-
-const (
-	initiateLockPeriodInSeconds    = 48 * 60 * 60
-	participateLockPeriodInSeconds = 24 * 60 * 60
-
-	maxGasLimit = 210000
+	// network is the profile of the chain swapped on, selected by -network
+	network eth.Network
+	// contractVersions are the known versions of the atomic swap contracts,
+	// the built-in ones and the ones of the contractversions flag
+	contractVersions = eth.DefaultContractVersions()
 )
 
 var (
 	flagset      = flag.NewFlagSet("", flag.ExitOnError)
-	connectFlag  = flagset.String("s", "http://localhost:8545", "endpoint of Ethereum RPC server")
+	connectFlag  = flagset.String("s", "", "endpoint of Ethereum RPC server, defaults to the RPC URL of the network")
 	contractFlag = flagset.String("c", "", "hex-enoded address of the deployed contract")
 	accountFlag  = flagset.String("account", "", "account file, account address or nothing for the daemon's first account")
+	clefFlag     = flagset.String("clef", "", "sign the transactions using Clef at this IPC path or URL, -account optionally selects its account")
+	remoteFlag   = flagset.String("remotesigner", "", "sign the transactions using the remote signing service at this URL for the account address given by -account, authenticating with $"+signerTokenEnv)
 	timeoutFlag  = flagset.Duration("t", 0, "optional timeout of any call made")
-	testnetFlag  = flagset.Bool("testnet", false, "use testnet (Rinkeby) network")
+	networkFlag  = flagset.String("network", "mainnet", "name of the network profile: mainnet, sepolia, holesky or one defined in the -networks file")
+	networksFlag = flagset.String("networks", "", "JSON file with network profiles to add to (or replace) the built-in ones")
+	testnetFlag  = flagset.Bool("testnet", false, "use the Sepolia test network, short for -network sepolia")
+	maxFeeFlag   = flagset.String("maxfee", "", "maximum fee per gas in Gwei, defaults to twice the base fee plus the tip")
+	tipCapFlag   = flagset.String("tipcap", "", "maximum priority fee per gas in Gwei, defaults to the tip suggested by the node")
+	waitFlag     = flagset.Bool("wait", false, "wait for the transaction to be mined, replacing it with bumped fees when stuck")
+	confirmFlag  = flagset.Uint64("confirmations", 0, "wait: amount of blocks, including the one of the transaction, to wait for, defaults to the confirmation depth of the network")
+	bumpFlag     = flagset.Duration("bumpafter", 3*time.Minute, "wait: time after which a transaction that is not mined is replaced with bumped fees, 0 to never replace it")
+	tokenFlag    = flagset.String("token", "", "hex-encoded address of the ERC-20 token to swap instead of Ether, -c then has to be a TokenAtomicSwap contract")
+	unsignedFlag = flagset.Bool("unsigned", false, "initiate, participate, redeem, refund, approve: print the unsigned transaction as JSON, to be signed by the sign command, instead of sending it")
+	proofFlag    = flagset.String("proof", "", "auditcontract: audit offline using the contract proof file built by contractproof, requires -blockhash")
+	blockFlag    = flagset.String("blockhash", "", "auditcontract: hash of the block including the contract transaction, from a source you trust")
+	authFlag     = flagset.String("auth", "", "relayredeem: redeem authorization file built by authorizeredeem, paying its fee to the relayer")
+	versionsFlag = flagset.String("contractversions", "", "JSON file with versions of the atomic swap contracts to add to the built-in ones, such as older or self-compiled deployments")
+	expectFlag   = flagset.String("expect", "", "auditcontract: verify the contract matches the swap terms, e.g. amount=0.5,recipient=<address>,secrethash=<hash>,minlocktime=12h")
+	logRangeFlag = flagset.Uint64("logrange", eth.DefaultLogRange, "extractsecret: number of blocks of which the logs are requested at once, lowered when the node rejects the range")
 )
 
+// lockTimes of the contracts, set using the lock time flags
+var lockTimes = timings.DefaultPolicy
+
+// token swapped, set using the token flag once connected to the node
+var token = eth.TokenInfo{Symbol: "ETH", Decimals: weiPrecision}
+
 // There are two directions that the atomic swap can be performed, as the
 // initiator can be on either chain.  This tool only deals with creating the
 // Bitcoin transactions for these swaps.  A second tool should be used for the
@@ -82,20 +98,34 @@ var (
 //   cp2 redeems eth with S
 
 func init() {
+	lockTimes.AddFlags(flagset)
 	flagset.Usage = func() {
 		fmt.Println("Usage: ethatomicswap [flags] cmd [cmd args]")
 		fmt.Println()
 		fmt.Println("Commands:")
-		fmt.Println("  initiate <participant address> <amount>")
-		fmt.Println("  participate <initiator address> <amount> <secret hash>")
+		fmt.Println("  initiate [-token address] <participant address> <amount>")
+		fmt.Println("  participate [-token address] <initiator address> <amount> <secret hash>")
 		fmt.Println("  redeem <contract transaction> <secret>")
 		fmt.Println("  refund <contract transaction>")
-		fmt.Println("  extractsecret <redemption transaction> <secret hash>")
-		fmt.Println("  auditcontract <contract transaction>")
+		fmt.Println("  batchredeem <file with a contract transaction and secret per line>")
+		fmt.Println("  batchrefund <file with a contract transaction per line>")
+		fmt.Println("  extractsecret [redemption transaction] <secret hash>")
+		fmt.Println("  auditcontract [-proof file -blockhash hash] <contract transaction>")
+		fmt.Println("  contractproof <contract transaction>")
+		fmt.Println()
+		fmt.Println("Relayed Redeem Commands:")
+		fmt.Println("  authorizeredeem <contract transaction> <relayer address> <fee>")
+		fmt.Println("  relayredeem [-auth file] <contract transaction> <secret>")
 		fmt.Println()
 		fmt.Println("Extra Commands:")
 		fmt.Println("  deploycontract")
 		fmt.Println("  validatedeployedcontract <deploy transaction>")
+		fmt.Println("  verifycontract [contract address]")
+		fmt.Println()
+		fmt.Println("Offline Signing Commands:")
+		fmt.Println("  approve -unsigned -token address <amount>")
+		fmt.Println("  sign <unsigned transaction file>")
+		fmt.Println("  broadcast <signed transaction>")
 		fmt.Println()
 		fmt.Println("Flags:")
 		flagset.PrintDefaults()
@@ -112,14 +142,22 @@ type offlineCommand interface {
 	runOfflineCommand() error
 }
 
+// client commands only require the RPC client, not the contract.
+type clientCommand interface {
+	command
+	runClientCommand(*eth.EthClient) error
+}
+
 type initiateCmd struct {
-	cp2Addr common.Address
-	amount  *big.Int // in wei
+	cp2Addr   common.Address
+	amount    *big.Int // in wei, or the smallest unit of the token
+	amountArg string   // parsed once the decimals of the token are known
 }
 
 type participateCmd struct {
 	cp1Addr    common.Address
-	amount     *big.Int // in wei
+	amount     *big.Int // in wei, or the smallest unit of the token
+	amountArg  string   // parsed once the decimals of the token are known
 	secretHash [32]byte
 }
 
@@ -128,25 +166,76 @@ type redeemCmd struct {
 	secret     [32]byte
 }
 
+type authorizeRedeemCmd struct {
+	contractTx *types.Transaction
+	relayer    common.Address
+	feeArg     string
+}
+
+type relayRedeemCmd struct {
+	contractTx *types.Transaction
+	secret     [32]byte
+	authPath   string
+}
+
 type refundCmd struct {
 	contractTx *types.Transaction
 }
 
+type batchRedeemCmd struct {
+	contractTxs []*types.Transaction
+	secrets     [][32]byte
+}
+
+type batchRefundCmd struct {
+	contractTxs []*types.Transaction
+}
+
 type extractSecretCmd struct {
 	redemptionTx *types.Transaction
 	secretHash   [32]byte
 }
 
+type extractSecretByHashCmd struct {
+	secretHash [32]byte
+}
+
 type auditContractCmd struct {
 	contractTx *types.Transaction
 }
 
+type auditContractProofCmd struct {
+	contractTx *types.Transaction
+	proofPath  string
+	blockHash  common.Hash
+}
+
+type contractProofCmd struct {
+	contractTx *types.Transaction
+}
+
 type deployContractCmd struct{}
 
+type approveCmd struct {
+	amountArg string // parsed once the decimals of the token are known
+}
+
+type signCmd struct {
+	path string
+}
+
+type broadcastCmd struct {
+	tx *types.Transaction
+}
+
 type validateDeployedContractCmd struct {
 	deployTx *types.Transaction
 }
 
+type verifyContractCmd struct {
+	contractAddr common.Address // the zero address for the contract of the network
+}
+
 func main() {
 	err, showUsage := run()
 	if err != nil {
@@ -266,14 +355,40 @@ func run() (err error, showUsage bool) {
 		cmdArgs = 2
 	case "refund":
 		cmdArgs = 1
+	case "authorizeredeem":
+		cmdArgs = 3
+	case "relayredeem":
+		cmdArgs = 2
+	case "batchredeem":
+		cmdArgs = 1
+	case "batchrefund":
+		cmdArgs = 1
 	case "extractsecret":
+		// the redemption transaction is optional
 		cmdArgs = 2
+		if checkCmdArgLength(args[1:], 2) < 2 {
+			cmdArgs = 1
+		}
 	case "auditcontract":
 		cmdArgs = 1
+	case "contractproof":
+		cmdArgs = 1
 	case "deploycontract":
 		cmdArgs = 0
 	case "validatedeployedcontract":
 		cmdArgs = 1
+	case "verifycontract":
+		// the contract address defaults to the one of the network
+		cmdArgs = 1
+		if checkCmdArgLength(args[1:], 1) < 1 {
+			cmdArgs = 0
+		}
+	case "approve":
+		cmdArgs = 1
+	case "sign":
+		cmdArgs = 1
+	case "broadcast":
+		cmdArgs = 1
 	default:
 		return fmt.Errorf("unknown command %v", args[0]), true
 	}
@@ -286,28 +401,51 @@ func run() (err error, showUsage bool) {
 		return fmt.Errorf("unexpected argument: %s", flagset.Arg(0)), true
 	}
 
+	networkName := *networkFlag
 	if *testnetFlag {
-		chainConfig = params.RinkebyChainConfig
+		if isFlagSet("network") && networkName != "sepolia" {
+			return fmt.Errorf("-testnet conflicts with -network %s", networkName), true
+		}
+		networkName = "sepolia"
+	}
+	networks := eth.DefaultNetworks()
+	if *networksFlag != "" {
+		if err = networks.Load(*networksFlag); err != nil {
+			return err, false
+		}
+	}
+	network, err = networks.Get(networkName)
+	if err != nil {
+		return err, true
+	}
+	if *versionsFlag != "" {
+		if err = contractVersions.Load(*versionsFlag); err != nil {
+			return err, false
+		}
+	}
+	if *tokenFlag != "" && !common.IsHexAddress(*tokenFlag) {
+		return fmt.Errorf("invalid token address: %s", *tokenFlag), true
 	}
 
 	var cmd command
 	switch args[0] {
 	case "initiate":
 		cp2Addr := common.HexToAddress(args[1])
-		amount, err := parseEthAsWei(args[2])
+		amount, err := parseAmountArg(args[2])
 		if err != nil {
-			return fmt.Errorf("unexpected amount argument (%v): %v", args[2], err), true
+			return err, true
 		}
 		cmd = &initiateCmd{
-			cp2Addr: cp2Addr,
-			amount:  amount,
+			cp2Addr:   cp2Addr,
+			amount:    amount,
+			amountArg: args[2],
 		}
 
 	case "participate":
 		cp1Addr := common.HexToAddress(args[1])
-		amount, err := parseEthAsWei(args[2])
+		amount, err := parseAmountArg(args[2])
 		if err != nil {
-			return fmt.Errorf("unexpected amount argument (%v): %v", args[2], err), true
+			return err, true
 		}
 		secretHash, err := hexDecodeSha256Hash("secret hash", args[3])
 		if err != nil {
@@ -316,6 +454,7 @@ func run() (err error, showUsage bool) {
 		cmd = &participateCmd{
 			cp1Addr:    cp1Addr,
 			amount:     amount,
+			amountArg:  args[2],
 			secretHash: secretHash,
 		}
 
@@ -342,7 +481,81 @@ func run() (err error, showUsage bool) {
 			contractTx: contractTx,
 		}
 
+	case "authorizeredeem":
+		contractTx, err := hexDecodeTransaction(args[1])
+		if err != nil {
+			return err, true
+		}
+		if !common.IsHexAddress(args[2]) {
+			return fmt.Errorf("invalid relayer address: %s", args[2]), true
+		}
+		cmd = &authorizeRedeemCmd{
+			contractTx: contractTx,
+			relayer:    common.HexToAddress(args[2]),
+			feeArg:     args[3],
+		}
+
+	case "relayredeem":
+		contractTx, err := hexDecodeTransaction(args[1])
+		if err != nil {
+			return err, true
+		}
+		secret, err := hexDecodeSha256Hash("secret", args[2])
+		if err != nil {
+			return err, true
+		}
+		cmd = &relayRedeemCmd{
+			contractTx: contractTx,
+			secret:     secret,
+			authPath:   *authFlag,
+		}
+
+	case "batchredeem":
+		lines, err := readBatchFile(args[1], 2)
+		if err != nil {
+			return err, true
+		}
+		batch := new(batchRedeemCmd)
+		for _, line := range lines {
+			contractTx, err := hexDecodeTransaction(line[0])
+			if err != nil {
+				return err, true
+			}
+			secret, err := hexDecodeSha256Hash("secret", line[1])
+			if err != nil {
+				return err, true
+			}
+			batch.contractTxs = append(batch.contractTxs, contractTx)
+			batch.secrets = append(batch.secrets, secret)
+		}
+		cmd = batch
+
+	case "batchrefund":
+		lines, err := readBatchFile(args[1], 1)
+		if err != nil {
+			return err, true
+		}
+		batch := new(batchRefundCmd)
+		for _, line := range lines {
+			contractTx, err := hexDecodeTransaction(line[0])
+			if err != nil {
+				return err, true
+			}
+			batch.contractTxs = append(batch.contractTxs, contractTx)
+		}
+		cmd = batch
+
 	case "extractsecret":
+		if cmdArgs == 1 {
+			secretHash, err := hexDecodeSha256Hash("secret hash", args[1])
+			if err != nil {
+				return err, true
+			}
+			cmd = &extractSecretByHashCmd{
+				secretHash: secretHash,
+			}
+			break
+		}
 		redemptionTx, err := hexDecodeTransaction(args[1])
 		if err != nil {
 			return err, true
@@ -361,10 +574,34 @@ func run() (err error, showUsage bool) {
 		if err != nil {
 			return err, true
 		}
+		if *proofFlag != "" {
+			if *blockFlag == "" {
+				return errors.New("auditcontract: the trusted hash of the block of the proof is required (-blockhash)"), true
+			}
+			blockHash, err := hexutil.Decode(*blockFlag)
+			if err != nil || len(blockHash) != common.HashLength {
+				return fmt.Errorf("invalid block hash: %s", *blockFlag), true
+			}
+			cmd = &auditContractProofCmd{
+				contractTx: contractTx,
+				proofPath:  *proofFlag,
+				blockHash:  common.BytesToHash(blockHash),
+			}
+			break
+		}
 		cmd = &auditContractCmd{
 			contractTx: contractTx,
 		}
 
+	case "contractproof":
+		contractTx, err := hexDecodeTransaction(args[1])
+		if err != nil {
+			return err, true
+		}
+		cmd = &contractProofCmd{
+			contractTx: contractTx,
+		}
+
 	case "deploycontract":
 		cmd = new(deployContractCmd)
 
@@ -377,6 +614,42 @@ func run() (err error, showUsage bool) {
 			deployTx: deployTx,
 		}
 
+	case "verifycontract":
+		cmd = new(verifyContractCmd)
+		if nArgs == 1 {
+			if !common.IsHexAddress(args[1]) {
+				return fmt.Errorf("invalid contract address: %s", args[1]), true
+			}
+			cmd = &verifyContractCmd{
+				contractAddr: common.HexToAddress(args[1]),
+			}
+		}
+
+	case "approve":
+		if *tokenFlag == "" {
+			return errors.New("approve: the token to approve is required (-token)"), true
+		}
+		if !*unsignedFlag {
+			return errors.New("approve: initiate and participate approve the contract themselves, approve is only needed with -unsigned"), true
+		}
+		cmd = &approveCmd{
+			amountArg: args[1],
+		}
+
+	case "sign":
+		cmd = &signCmd{
+			path: args[1],
+		}
+
+	case "broadcast":
+		tx, err := hexDecodeRawTransaction(args[1])
+		if err != nil {
+			return err, true
+		}
+		cmd = &broadcastCmd{
+			tx: tx,
+		}
+
 	default:
 		panic(fmt.Sprintf("unknown command %v", args[0]))
 	}
@@ -386,38 +659,135 @@ func run() (err error, showUsage bool) {
 		return cmd.runOfflineCommand(), false
 	}
 
-	ctx := context.Background()
-	client, err := eth.DialClient(ctx, *connectFlag)
+	ctx, cancel := newContext()
+	defer cancel()
+	client, err := dialClient(ctx, rpcURL())
 	if err != nil {
 		return fmt.Errorf("rpc connect: %v", err), false
 	}
 	defer client.Close()
+	if err = network.CheckChainID(ctx, client); err != nil {
+		return err, false
+	}
+	if cmd, ok := cmd.(clientCommand); ok {
+		return cmd.runClientCommand(client), false
+	}
 
 	// create (swap) contract transactor
-	contractAddr, err := getDeployedContractAddress()
-	if err != nil {
-		return fmt.Errorf("failed to get contract address: %v", err), false
+	var contractAddr common.Address
+	if _, ok := cmd.(*deployContractCmd); !ok {
+		contractAddr, err = getDeployedContractAddress()
+		if err != nil {
+			return fmt.Errorf("failed to get contract address: %v", err), false
+		}
 	}
-	key, err := loadAccount(*accountFlag)
+	signer, err := loadSigner(ctx, client)
 	if err != nil {
-		return errors.Wrap(err, "could not load account key"), false
+		return errors.Wrap(err, "could not load account signer"), false
 	}
-	sct, err := eth.NewSwapContractTransactor(ctx, client, contractAddr, key, chainConfig.ChainID)
+	if clefSigner, ok := signer.(*eth.ClefSigner); ok {
+		defer clefSigner.Close()
+	}
+	sct, err := eth.NewSwapContractTransactorWithSigner(ctx, client, contractAddr, signer, network.ChainID)
 	if err != nil {
 		return err, false
 	}
+	sct.Versions = contractVersions
+	sct.LogRange = *logRangeFlag
+	sct.GasFeeCap, err = parseGwei(*maxFeeFlag)
+	if err != nil {
+		return fmt.Errorf("invalid max fee: %v", err), true
+	}
+	sct.GasTipCap, err = parseGwei(*tipCapFlag)
+	if err != nil {
+		return fmt.Errorf("invalid tip cap: %v", err), true
+	}
+	if *tokenFlag != "" {
+		token, err = eth.GetTokenInfo(ctx, client, common.HexToAddress(*tokenFlag))
+		if err != nil {
+			return err, false
+		}
+	}
 
 	err = cmd.runCommand(sct)
 	return err, false
 }
 
+// isFlagSet reports whether the flag was passed on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flagset.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// newContext returns the context of the calls made to the node,
+// cancelled after the timeout of the t flag if set
+func newContext() (context.Context, context.CancelFunc) {
+	if *timeoutFlag == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), *timeoutFlag)
+}
+
+// parseGwei parses an amount of Gwei to Wei, nil if the amount is empty
+func parseGwei(str string) (*big.Int, error) {
+	if str == "" {
+		return nil, nil
+	}
+	amount, err := chain.ParseAmount(str, 9)
+	if err != nil {
+		return nil, err
+	}
+	return amount.BigInt(), nil
+}
+
+// signerTokenEnv is the environment variable holding the token of the remote signing service
+const signerTokenEnv = "ETH_SIGNER_TOKEN"
+
+// loadSigner returns the signer selected by the flags,
+// nil to sign using the daemon's first account
+func loadSigner(ctx context.Context, client *eth.EthClient) (eth.Signer, error) {
+	var address common.Address
+	if common.IsHexAddress(*accountFlag) {
+		address = common.HexToAddress(*accountFlag)
+	}
+	switch {
+	case *clefFlag != "":
+		return eth.NewClefSigner(ctx, *clefFlag, address)
+	case *remoteFlag != "":
+		if address == (common.Address{}) {
+			return nil, errors.New("the remote signer requires the account address (-account)")
+		}
+		return eth.NewRemoteSigner(*remoteFlag, address, os.Getenv(signerTokenEnv)), nil
+	case *accountFlag == "":
+		return nil, nil
+	case address != (common.Address{}):
+		return eth.NewNodeSigner(ctx, client, address)
+	}
+	key, err := loadAccount(*accountFlag)
+	if err != nil {
+		return nil, err
+	}
+	return eth.NewKeySigner(key), nil
+}
+
+// askPassphrase prompts for the passphrase of an account file, without echoing it
+var askPassphrase = speakeasy.Ask
+
+// dialClient connects to the Ethereum node at the RPC URL
+var dialClient = eth.DialClient
+
 func loadAccount(path string) (*ecdsa.PrivateKey, error) {
 
 	json, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read encrypted account/key file (%s) content: %v", path, err)
 	}
-	passphrase, err := speakeasy.Ask("Account passphrase: ")
+	passphrase, err := askPassphrase("Account passphrase: ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get passphrase from STDIN: %v", err)
 	}
@@ -433,14 +803,24 @@ func getDeployedContractAddress() (common.Address, error) {
 	if contractAddress != "" {
 		return common.HexToAddress(contractAddress), nil
 	}
-	switch chainConfig {
-	case params.MainnetChainConfig:
-		return common.Address{}, errors.New("no default contract exist yet for the main net")
-	case params.RinkebyChainConfig:
-		return common.HexToAddress("2661CBAa149721f7c5FAB3FA88C1EA564A683631"), nil
+	if *tokenFlag != "" {
+		if network.TokenContract == (common.Address{}) {
+			return common.Address{}, fmt.Errorf("no default TokenAtomicSwap contract exists for network %s, pass its address using -c", network.Name)
+		}
+		return network.TokenContract, nil
+	}
+	if network.Contract == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("no default contract exists for network %s, pass its address using -c", network.Name)
 	}
+	return network.Contract, nil
+}
 
-	panic("unknown chain config for chain ID: " + chainConfig.ChainID.String())
+// rpcURL returns the endpoint of the RPC server to connect to
+func rpcURL() string {
+	if *connectFlag != "" {
+		return *connectFlag
+	}
+	return network.RPCURL
 }
 
 func sha256Hash(x []byte) [sha256.Size]byte {
@@ -475,6 +855,44 @@ func hexDecodeTransaction(str string) (*types.Transaction, error) {
 	return &tx, nil
 }
 
+// hexDecodeRawTransaction decodes a binary encoded transaction, as signed by the sign command
+func hexDecodeRawTransaction(str string) (*types.Transaction, error) {
+	slice, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return nil, errors.New("transaction must be hex encoded")
+	}
+	var tx types.Transaction
+	err = tx.UnmarshalBinary(slice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %v", err)
+	}
+	return &tx, nil
+}
+
+// readBatchFile reads the swaps of a batch command,
+// one per line with the given amount of fields, ignoring empty lines
+func readBatchFile(path string, fields int) ([][]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch file (%s): %v", path, err)
+	}
+	var lines [][]string
+	for i, line := range strings.Split(string(b), "\n") {
+		line := strings.Fields(line)
+		if len(line) == 0 {
+			continue
+		}
+		if len(line) != fields {
+			return nil, fmt.Errorf("batch file (%s) line %d: expected %d fields, got %d", path, i+1, fields, len(line))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("batch file (%s) is empty", path)
+	}
+	return lines, nil
+}
+
 func generateSecretHashPair() (secret, secretHash [sha256.Size]byte) {
 	rand.Read(secret[:])
 	secretHash = sha256Hash(secret[:])
@@ -503,124 +921,262 @@ func promptPublishTx(name string) (bool, error) {
 	}
 }
 
-func calcGasCost(limit uint64, c *ethclient.Client) (*big.Int, error) {
-	price, err := c.SuggestGasPrice(context.Background())
+// parseAmountArg parses an amount of Ether as Wei,
+// or returns nil for an amount of tokens, parsed by parseTokenAmount once connected to the node
+func parseAmountArg(str string) (*big.Int, error) {
+	if *tokenFlag != "" {
+		return nil, nil
+	}
+	amount, err := parseEthAsWei(str)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unexpected amount argument (%v): %v", str, err)
 	}
-	return price.Mul(price, big.NewInt(int64(limit))), nil
+	return amount, nil
 }
 
-func unpackContractInputParams(abi abi.ABI, tx *types.Transaction) (params struct {
-	LockDuration *big.Int
-	SecretHash   [sha256.Size]byte
-	ToAddress    common.Address
-}, err error) {
-	txData := tx.Data()
-
-	// first 4 bytes contain the id, so let's get method using that ID
-	method, err := abi.MethodById(txData[:4])
+// parseTokenAmount parses an amount of tokens using the decimals of the token
+func parseTokenAmount(str string) (*big.Int, error) {
+	amount, err := chain.ParseAmount(str, token.Decimals)
 	if err != nil {
-		err = fmt.Errorf("failed to get method using its parsed id: %v", err)
-		return
+		return nil, fmt.Errorf("unexpected amount argument (%v): %v", str, err)
+	}
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("unexpected amount argument (%v): must be positive", str)
 	}
+	return amount.BigInt(), nil
+}
 
-	args, err := method.Inputs.Unpack(txData[4:])
-	if err != nil {
-		err = fmt.Errorf("failed to unpack method's input params: %v", err)
+// formatAmount formats an amount of Wei as Ether, or of the smallest unit of the token
+func formatAmount(amount *big.Int) string {
+	if token.Address == (common.Address{}) {
+		return formatWeiAsEthString(amount) + " ETH"
 	}
-	if len(args) != 3 {
-		err = errors.New("unexpected amount of transaction params")
-		return
+	symbol := token.Symbol
+	if symbol == "" {
+		symbol = token.Address.Hex()
 	}
-
-	params.LockDuration = args[0].(*big.Int)
-	params.SecretHash = args[1].([sha256.Size]byte)
-	params.ToAddress = args[2].(common.Address)
-
-	return
+	return chain.NewAmount(amount).Format(token.Decimals) + " " + symbol
 }
 
 func (cmd *initiateCmd) runCommand(sct eth.SwapContractTransactor) error {
-	output, err := eth.Initiate(context.Background(), sct, cmd.cp2Addr, cmd.amount)
+	ctx, cancel := newContext()
+	defer cancel()
+	if cmd.amount == nil {
+		var err error
+		if cmd.amount, err = parseTokenAmount(cmd.amountArg); err != nil {
+			return err
+		}
+	}
+	if *unsignedFlag {
+		secret, secretHash := generateSecretHashPair()
+		unsignedTx, err := eth.BuildInitiate(ctx, sct, cmd.cp2Addr, cmd.amount, secretHash, lockTimes, token.Address)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Secret:      %x\n", secret)
+		fmt.Printf("Secret hash: %x\n\n", secretHash)
+		return printUnsignedTx(unsignedTx)
+	}
+	output, err := eth.Initiate(ctx, sct, cmd.cp2Addr, cmd.amount, lockTimes, token.Address)
 	if err != nil {
 		return errors.Wrap(err, "failed to create initiate TX")
 	}
 
-	fmt.Printf("Amount: %s Wei (%s ETH)\n\n",
-		cmd.amount.String(), formatWeiAsEthString(cmd.amount))
+	fmt.Printf("Amount: %s (%s)\n\n",
+		cmd.amount.String(), formatAmount(cmd.amount))
 
 	fmt.Printf("Author's refund address: %x\n\n", sct.FromAddr)
 
 	fmt.Printf("Secret:      %x\n", output.Secret)
 	fmt.Printf("Secret hash: %x\n\n", output.SecretHash)
 
-	fmt.Printf("Chain ID:         %s\n", chainConfig.ChainID.String())
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
 	fmt.Printf("Contract Address: %x\n", sct.ContractAddr)
 
 	fmt.Printf("Contract transaction (%x):\n", output.ContractTransaction.Hash())
-	txBytes, err := rlp.EncodeToBytes(output.ContractTransaction)
+	txBytes, err := rlp.EncodeToBytes(&output.ContractTransaction)
 	if err != nil {
 		return fmt.Errorf("failed to encode contract TX: %v", err)
 	}
 	fmt.Printf("%x\n\n", txBytes)
 
-	publish, err := promptPublishTx("contract")
-	if err != nil || !publish {
-		return err
-	}
-
-	return nil
+	return waitMined(sct, output.ContractTransaction.Hash())
 }
 
 func (cmd *participateCmd) runCommand(sct eth.SwapContractTransactor) error {
-	output, err := eth.Participate(context.Background(), sct, cmd.cp1Addr, cmd.amount, cmd.secretHash)
+	ctx, cancel := newContext()
+	defer cancel()
+	if cmd.amount == nil {
+		var err error
+		if cmd.amount, err = parseTokenAmount(cmd.amountArg); err != nil {
+			return err
+		}
+	}
+	if *unsignedFlag {
+		unsignedTx, err := eth.BuildParticipate(ctx, sct, cmd.cp1Addr, cmd.amount, cmd.secretHash, lockTimes, token.Address)
+		if err != nil {
+			return err
+		}
+		return printUnsignedTx(unsignedTx)
+	}
+	output, err := eth.Participate(ctx, sct, cmd.cp1Addr, cmd.amount, cmd.secretHash, lockTimes, token.Address)
 	if err != nil {
 		return errors.Wrap(err, "failed to participate in atomic swap")
 	}
 
-	fmt.Printf("Amount: %s Wei (%s ETH)\n\n",
-		cmd.amount.String(), formatWeiAsEthString(cmd.amount))
+	fmt.Printf("Amount: %s (%s)\n\n",
+		cmd.amount.String(), formatAmount(cmd.amount))
 
 	fmt.Printf("Author's refund address: %x\n\n", sct.FromAddr)
 
-	fmt.Printf("Chain ID:         %s\n", chainConfig.ChainID.String())
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
 	fmt.Printf("Contract Address: %x\n", sct.ContractAddr)
 
 	fmt.Printf("Contract transaction (%x):\n", output.ContractTransactionHash)
+	txBytes, err := rlp.EncodeToBytes(&output.ContractTransaction)
+	if err != nil {
+		return fmt.Errorf("failed to encode contract TX: %v", err)
+	}
+	fmt.Printf("%x\n\n", txBytes)
 
-	return nil
+	return waitMined(sct, output.ContractTransactionHash)
 }
 
 func (cmd *redeemCmd) runCommand(sct eth.SwapContractTransactor) error {
-	params, err := unpackContractInputParams(sct.Abi, cmd.contractTx)
+	ctx, cancel := newContext()
+	defer cancel()
+	params, err := eth.UnpackContractParams(cmd.contractTx)
 	if err != nil {
 		return err
 	}
-	output, err := eth.Redeem(context.Background(), sct, params.SecretHash, cmd.secret)
+	if *unsignedFlag {
+		unsignedTx, err := eth.BuildRedeem(ctx, sct, params.SecretHash, cmd.secret, token.Address)
+		if err != nil {
+			return err
+		}
+		return printUnsignedTx(unsignedTx)
+	}
+	output, err := eth.Redeem(ctx, sct, params.SecretHash, cmd.secret, token.Address)
 	if err != nil {
 		return fmt.Errorf("failed to create redeem TX: %v", err)
 	}
 
-	fmt.Printf("Chain ID:         %s\n", chainConfig.ChainID.String())
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
 	fmt.Printf("Contract Address: %x\n", sct.ContractAddr)
 
 	fmt.Printf("Redeem transaction (%x):\n", output.RedeemTxHash)
 
+	return waitMined(sct, output.RedeemTxHash)
+}
+
+func (cmd *authorizeRedeemCmd) runCommand(eth.SwapContractTransactor) error {
+	return cmd.runOfflineCommand()
+}
+
+func (cmd *authorizeRedeemCmd) runOfflineCommand() error {
+	if cmd.contractTx.To() == nil {
+		return errors.New("contract transaction does not call a contract")
+	}
+	fee := new(big.Int)
+	if *tokenFlag == "" {
+		var err error
+		if fee, err = parseEthAsWei(cmd.feeArg); err != nil {
+			return fmt.Errorf("unexpected fee argument (%v): %v", cmd.feeArg, err)
+		}
+	} else {
+		// the decimals of the token are not known offline,
+		// so the fee is in the smallest unit of the token
+		if _, ok := fee.SetString(cmd.feeArg, 10); !ok {
+			return fmt.Errorf("unexpected fee argument (%v): not an amount of the smallest unit of the token", cmd.feeArg)
+		}
+	}
+	params, err := eth.UnpackContractParams(cmd.contractTx)
+	if err != nil {
+		return err
+	}
+	if *accountFlag == "" || common.IsHexAddress(*accountFlag) {
+		return errors.New("the account file of the key of the recipient is required (-account)")
+	}
+	key, err := loadAccount(*accountFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not load account key")
+	}
+	if recipient := crypto.PubkeyToAddress(key.PublicKey); recipient != params.ToAddress {
+		return fmt.Errorf("account %x is not the recipient %x of the contract", recipient, params.ToAddress)
+	}
+	auth, err := eth.AuthorizeRedeem(key, *cmd.contractTx.To(), network.ChainID, params.SecretHash, cmd.relayer, fee)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(auth, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode redeem authorization: %v", err)
+	}
+
+	fmt.Printf("Redeem authorization for relayer %x:\n", cmd.relayer)
+	fmt.Printf("%s\n", b)
 	return nil
 }
 
+func (cmd *relayRedeemCmd) runCommand(sct eth.SwapContractTransactor) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	if cmd.contractTx.To() == nil {
+		return errors.New("contract transaction does not call a contract")
+	}
+	// the swap is redeemed at the RelayAtomicSwap or TokenAtomicSwapV2 contract that holds it
+	sct.ContractAddr = *cmd.contractTx.To()
+	params, err := eth.UnpackContractParams(cmd.contractTx)
+	if err != nil {
+		return err
+	}
+	var auth *eth.RedeemAuthorization
+	if cmd.authPath != "" {
+		b, err := ioutil.ReadFile(cmd.authPath)
+		if err != nil {
+			return fmt.Errorf("failed to read redeem authorization file (%s): %v", cmd.authPath, err)
+		}
+		auth = new(eth.RedeemAuthorization)
+		if err = json.Unmarshal(b, auth); err != nil {
+			return fmt.Errorf("failed to decode redeem authorization file (%s): %v", cmd.authPath, err)
+		}
+	}
+	output, err := eth.RelayRedeem(ctx, sct, params.SecretHash, cmd.secret, auth, token.Address)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
+	fmt.Printf("Contract Address: %x\n", sct.ContractAddr)
+	if auth != nil {
+		fmt.Printf("Relayer fee:      %s\n", formatAmount(auth.Fee))
+	}
+
+	fmt.Printf("Redeem transaction (%x):\n", output.RedeemTxHash)
+
+	return waitMined(sct, output.RedeemTxHash)
+}
+
 func (cmd *refundCmd) runCommand(sct eth.SwapContractTransactor) error {
-	output, err := eth.Refund(context.Background(), sct, cmd.contractTx)
+	ctx, cancel := newContext()
+	defer cancel()
+	if *unsignedFlag {
+		unsignedTx, err := eth.BuildRefund(ctx, sct, cmd.contractTx, token.Address)
+		if err != nil {
+			return err
+		}
+		return printUnsignedTx(unsignedTx)
+	}
+	output, err := eth.Refund(ctx, sct, cmd.contractTx, token.Address)
 	if err != nil {
 		return fmt.Errorf("failed to create refund TX: %v", err)
 	}
 
-	fmt.Printf("Chain ID:         %s\n", chainConfig.ChainID.String())
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
 	fmt.Printf("Contract Address: %x\n", sct.ContractAddr)
 
 	fmt.Printf("Refund transaction (%x):\n", output)
-	return nil
+	return waitMined(sct, output)
 }
 
 func (cmd *extractSecretCmd) runCommand(eth.SwapContractTransactor) error {
@@ -628,78 +1184,86 @@ func (cmd *extractSecretCmd) runCommand(eth.SwapContractTransactor) error {
 }
 
 func (cmd *extractSecretCmd) runOfflineCommand() error {
-	abi, err := abi.JSON(strings.NewReader(contract.ContractABI))
+	// the transaction is decoded offline, no contract transactor is needed
+	secret, err := eth.ExtractSecret(context.Background(), eth.SwapContractTransactor{}, cmd.redemptionTx, cmd.secretHash)
 	if err != nil {
-		return fmt.Errorf("failed to read (smart) contract ABI: %v", err)
+		return err
 	}
 
-	txData := cmd.redemptionTx.Data()
+	// print secret
+	fmt.Printf("Secret: %x\n", secret)
+	return nil
+}
 
-	// first 4 bytes contain the id, so let's get method using that ID
-	method, err := abi.MethodById(txData[:4])
+func (cmd *extractSecretByHashCmd) runCommand(sct eth.SwapContractTransactor) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	secret, err := eth.ExtractSecretByHash(ctx, sct, cmd.secretHash)
 	if err != nil {
-		return fmt.Errorf("failed to get method using its parsed id: %v", err)
-	}
-	if method.Name != "redeem" {
-		return fmt.Errorf("unexpected name for unpacked method ID: %s", method.Name)
+		return fmt.Errorf("failed to extract secret: %v", err)
 	}
 
-	// prepare the params
-	params := struct {
-		Secret     [sha256.Size]byte
-		SecretHash [sha256.Size]byte
-	}{}
+	// print secret
+	fmt.Printf("Secret: %x\n", secret)
+	return nil
+}
 
-	// unpack the params
-	args, err := method.Inputs.Unpack(txData[4:])
+func (cmd *auditContractCmd) runCommand(sct eth.SwapContractTransactor) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	output, err := eth.AuditContract(ctx, sct, cmd.contractTx, token.Address)
 	if err != nil {
-		return fmt.Errorf("failed to unpack method's input params: %v", err)
-	}
-
-	if len(args) > 2 {
-		return errors.New("unexpected arguments count")
+		return errors.Wrap(err, "could not audit conract")
 	}
+	return printAudit(output)
+}
 
-	params.Secret = args[0].([sha256.Size]byte)
-	params.SecretHash = args[1].([sha256.Size]byte)
+func (cmd *auditContractProofCmd) runCommand(eth.SwapContractTransactor) error {
+	return cmd.runOfflineCommand()
+}
 
-	// ensure secret hash is the same as the given one
-	if cmd.secretHash != params.SecretHash {
-		return fmt.Errorf("unexpected secret hash found: %x", params.SecretHash)
+func (cmd *auditContractProofCmd) runOfflineCommand() error {
+	b, err := ioutil.ReadFile(cmd.proofPath)
+	if err != nil {
+		return fmt.Errorf("failed to read contract proof file (%s): %v", cmd.proofPath, err)
 	}
-	secretHash := sha256Hash(params.Secret[:])
-	if params.SecretHash != secretHash {
-		return fmt.Errorf("unexpected secret found: %x", params.Secret)
+	var proof eth.ContractProof
+	if err = json.Unmarshal(b, &proof); err != nil {
+		return fmt.Errorf("failed to decode contract proof file (%s): %v", cmd.proofPath, err)
 	}
-
-	// print secret
-	fmt.Printf("Secret: %x\n", params.Secret)
-	return nil
-}
-
-func (cmd *auditContractCmd) runCommand(sct eth.SwapContractTransactor) error {
-	// unpack input params from contract tx
-	params, err := unpackContractInputParams(sct.Abi, cmd.contractTx)
+	if *tokenFlag != "" {
+		// the decimals of the token are not known offline,
+		// so its amounts are in the smallest unit of the token
+		token = eth.TokenInfo{Address: common.HexToAddress(*tokenFlag)}
+	}
+	// the proof does not cover the code of the contract,
+	// so only the contract of the network or the one given by -c is trusted
+	contractAddr, err := getDeployedContractAddress()
 	if err != nil {
 		return err
 	}
-
-	output, err := eth.AuditContract(context.Background(), sct, cmd.contractTx)
+	output, err := eth.AuditContractProof(cmd.contractTx, proof, cmd.blockHash, contractAddr, token.Address)
 	if err != nil {
 		return errors.Wrap(err, "could not audit conract")
 	}
+	fmt.Printf("Contract proven in block %s (%x)\n\n", proof.Header.Number, cmd.blockHash)
+	return printAudit(output)
+}
 
+// printAudit prints the audited contract,
+// verifying it against the swap terms if any
+func printAudit(output eth.AuditContractOutput) error {
 	// print contract info
-	fmt.Printf("Contract address:        %x\n", cmd.contractTx.To())
-	fmt.Printf("Contract value:          %s ETH\n", formatWeiAsEthString(cmd.contractTx.Value()))
-	fmt.Printf("Recipient address:       %x\n", params.ToAddress)
-	fmt.Printf("Author's refund address: %x\n\n", output.RefundAddress)
-
-	fmt.Printf("Secret hash: %x\n\n", params.SecretHash)
+	fmt.Printf("Contract address:        %x\n", output.ContractAddress)
+	fmt.Printf("Contract value:          %s\n", formatAmount(output.ContractValue))
+	fmt.Printf("Recipient address:       %x\n", output.RecipientAddress)
+	fmt.Printf("Author's refund address: %x\n", output.RefundAddress)
+	if output.ContractVersion != "" {
+		fmt.Printf("Contract version:        %s\n", output.ContractVersion)
+	}
+	fmt.Println()
 
-	// NOTE:
-	// the reason we require th node for this method,
-	// is because we need to be able to know the transaction's timestamp
+	fmt.Printf("Secret hash: %x\n\n", output.SecretHash)
 
 	lockTime := time.Unix(output.Locktime, 0)
 	fmt.Printf("Locktime: %v\n", lockTime.UTC())
@@ -709,21 +1273,48 @@ func (cmd *auditContractCmd) runCommand(sct eth.SwapContractTransactor) error {
 	} else {
 		fmt.Printf("Contract refund time lock has expired\n")
 	}
+	return verifyTerms(output.AuditResult())
+}
+
+// verifyTerms verifies an audited contract matches the terms of the expect flag, if any
+func verifyTerms(audit chain.AuditResult) error {
+	if *expectFlag == "" {
+		return nil
+	}
+	terms, err := chain.ParseTerms(*expectFlag, token.Decimals)
+	if err != nil {
+		return err
+	}
+	if terms.Recipient != "" {
+		if !common.IsHexAddress(terms.Recipient) {
+			return fmt.Errorf("invalid recipient term: %q is not an Ethereum address", terms.Recipient)
+		}
+		// compare checksummed addresses
+		terms.Recipient = common.HexToAddress(terms.Recipient).Hex()
+	}
+	if err = chain.VerifySwapTerms(audit, terms); err != nil {
+		return err
+	}
+	fmt.Println("\nContract matches the swap terms")
 	return nil
 }
 
 func (cmd *deployContractCmd) runCommand(sct eth.SwapContractTransactor) error {
-	ctx := context.Background()
+	if *tokenFlag != "" {
+		return errors.New("the TokenAtomicSwap contract has to be deployed from its Solidity sources")
+	}
+	ctx, cancel := newContext()
+	defer cancel()
 	tx, err := sct.DeployTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create deploy TX: %v", err)
 	}
 
 	deployTxCost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
-	fmt.Printf("Deploy fee: %s ETH\n\n", formatWeiAsEthString(deployTxCost))
+	fmt.Printf("Max deploy fee: %s ETH\n\n", formatWeiAsEthString(deployTxCost))
 
-	fmt.Printf("Chain ID:         %s\n", chainConfig.ChainID.String())
-	fmt.Printf("Contract Address: %x\n", sct.ContractAddr)
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
+	fmt.Printf("Contract Address: %x\n", crypto.CreateAddress(sct.FromAddr, tx.Nonce()))
 
 	fmt.Printf("Deploy transaction (%x):\n", tx.Hash())
 	txBytes, err := rlp.EncodeToBytes(tx)
@@ -742,7 +1333,43 @@ func (cmd *deployContractCmd) runCommand(sct eth.SwapContractTransactor) error {
 		return err
 	}
 	fmt.Printf("Published deploy transaction (%x)\n", tx.Hash())
-	return nil
+	return waitMined(sct, tx.Hash())
+}
+
+// waitMined waits for a published transaction to be mined if the wait flag is set.
+// If the transaction got replaced with bumped fees, the mined replacement is printed.
+func waitMined(sct eth.SwapContractTransactor, txHash common.Hash) error {
+	return waitMinedBumpingAfter(sct, txHash, *bumpFlag)
+}
+
+// waitMinedBumpingAfter waits like waitMined,
+// replacing a stuck transaction after bumpAfter, never if zero
+func waitMinedBumpingAfter(sct eth.SwapContractTransactor, txHash common.Hash, bumpAfter time.Duration) error {
+	if !*waitFlag {
+		return nil
+	}
+	confirmations := *confirmFlag
+	if confirmations == 0 {
+		confirmations = network.Confirmations
+	}
+	fmt.Printf("Waiting for transaction (%x) to be mined...\n", txHash)
+	tx, receipt, err := sct.WaitMined(context.Background(), txHash, eth.WaitOptions{
+		Confirmations: confirmations,
+		BumpAfter:     bumpAfter,
+		MaxGasFeeCap:  sct.GasFeeCap,
+	})
+	if receipt == nil {
+		return err
+	}
+	if tx.Hash() != txHash {
+		txBytes, encodeErr := rlp.EncodeToBytes(tx)
+		if encodeErr != nil {
+			return fmt.Errorf("failed to encode replacement TX: %v", encodeErr)
+		}
+		fmt.Printf("Transaction replaced with bumped fees by transaction (%x):\n%x\n", tx.Hash(), txBytes)
+	}
+	fmt.Printf("Transaction (%x) mined in block %d\n", tx.Hash(), receipt.BlockNumber)
+	return err
 }
 
 func (cmd *validateDeployedContractCmd) runCommand(eth.SwapContractTransactor) error {
@@ -750,457 +1377,214 @@ func (cmd *validateDeployedContractCmd) runCommand(eth.SwapContractTransactor) e
 }
 
 func (cmd *validateDeployedContractCmd) runOfflineCommand() error {
-	if !bytes.Equal(cmd.deployTx.Data(), contractBin) {
-		return errors.New("deployed contract is invalid (make sure to use the same Solidity contract source code and Compiler version (0.4.24))")
+	v, ok := contractVersions.ByDeployCode(cmd.deployTx.Data())
+	if !ok {
+		return errors.New("deployed contract is invalid (make sure to use the Solidity contract source code and compiler version of a known contract version)")
 	}
-	fmt.Println("Contract is valid")
+	fmt.Printf("Contract is valid: %s (solc %s)\n", v.Name, v.Compiler)
 	return nil
 }
 
-type (
-	// swapContractTransactor allows the creation of transactions for the different
-	// atomic swap actions
-	swapContractTransactor struct {
-		abi          abi.ABI
-		signer       bind.SignerFn
-		client       *ethClient
-		fromAddr     common.Address
-		contractAddr common.Address
-		autoAccount  bool // defines if an account is automatically selected
-
-		_contract *contract.Contract // created only once
-	}
-
-	// swapTransaction adds send functionality to the transaction,
-	// such that it can be send in an easy way
-	swapTransaction struct {
-		*types.Transaction
-		client *ethClient
-	}
-)
+func (cmd *verifyContractCmd) runCommand(eth.SwapContractTransactor) error {
+	return errors.New("verifycontract requires the RPC client")
+}
 
-func (sct *swapContractTransactor) initiateTx(amount *big.Int, secretHash [sha256.Size]byte, participant common.Address) (*swapTransaction, error) {
-	// validate tx does not exist yet,
-	// as to provide more meaningful error messages
-	switch _, err := sct.getSwapContract(secretHash); err {
-	case errNotExists:
-		// this is what we want
-	case nil:
-		return nil, errors.New("secret hash is already used for another atomic swap contract")
-	default:
-		return nil, fmt.Errorf("unexpected error while checking for an existing contract: %v", err)
-	}
-	// create initiate tx
-	return sct.newTransaction(
-		amount, "initiate",
-		// lock duration
-		big.NewInt(initiateLockPeriodInSeconds),
-		// secret hash
-		secretHash,
-		// participant
-		participant,
-	)
-}
-
-func (sct *swapContractTransactor) participateTx(amount *big.Int, secretHash [sha256.Size]byte, initiator common.Address) (*swapTransaction, error) {
-	// validate tx does not exist yet,
-	// as to provide more meaningful error messages
-	switch _, err := sct.getSwapContract(secretHash); err {
-	case errNotExists:
-		// this is what we want
-	case nil:
-		return nil, errors.New("secret hash is already used for another atomic swap contract")
-	default:
-		return nil, fmt.Errorf("unexpected error while checking for an existing contract: %v", err)
-	}
-	return sct.newTransaction(
-		amount, "participate",
-		// lock duration
-		big.NewInt(participateLockPeriodInSeconds),
-		// secret hash
-		secretHash,
-		// participant
-		initiator,
-	)
-}
-
-func (sct *swapContractTransactor) redeemTx(secretHash, secret [sha256.Size]byte) (*swapTransaction, error) {
-	// validate swap contract,
-	// as to provide more meaningful errors
-	sc, err := sct.getSwapContract(secretHash)
-	if err != nil {
-		return nil, err
-	}
-	if sc.SecretHash != secretHash {
-		return nil, errors.New("invalid secret hash registered")
-	}
-	if userSecretHash := sha256Hash(secret[:]); sc.SecretHash != userSecretHash {
-		return nil, errors.New("secret does not match secret hash")
-	}
-	switch sc.Kind {
-	case swapKindInitiator:
-		if sc.Participant != sct.fromAddr {
-			return nil, fmt.Errorf("only the participant can redeem: unexpected address: %x", sct.fromAddr)
-		}
-	case swapKindParticipant:
-		if sc.Initiator != sct.fromAddr {
-			return nil, fmt.Errorf("only the initiator can redeem: unexpected address: %x", sct.fromAddr)
+func (cmd *verifyContractCmd) runClientCommand(client *eth.EthClient) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	contractAddr := cmd.contractAddr
+	if contractAddr == (common.Address{}) {
+		var err error
+		if contractAddr, err = getDeployedContractAddress(); err != nil {
+			return fmt.Errorf("failed to get contract address: %v", err)
 		}
-	default:
-		return nil, fmt.Errorf("invalid atomic swap contract kind: %d", sc.Kind)
 	}
-	if sc.State != swapStateFilled {
-		return nil, errors.New("inactive atomic swap contract")
+	v, err := eth.VerifyContractCode(ctx, client, contractAddr, contractVersions)
+	if err != nil {
+		return err
 	}
-	// create redeem tx
-	return sct.newTransaction(
-		nil, "redeem",
-		// secret,
-		secret,
-		// secret hash
-		secretHash,
-	)
+
+	fmt.Printf("Contract Address: %x\n", contractAddr)
+	fmt.Printf("Contract version: %s (%s, solc %s)\n", v.Name, v.Kind, v.Compiler)
+	fmt.Printf("Code hash:        %x\n", v.CodeHash)
+	return nil
 }
 
-func (sct *swapContractTransactor) refundTx(secretHash [sha256.Size]byte) (*swapTransaction, error) {
-	// validate swap contract,
-	// as to provide more meaningful errors
-	sc, err := sct.getSwapContract(secretHash)
+func (cmd *approveCmd) runCommand(sct eth.SwapContractTransactor) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	amount, err := parseTokenAmount(cmd.amountArg)
 	if err != nil {
-		return nil, err
-	}
-	if sc.SecretHash != secretHash {
-		return nil, errors.New("invalid secret hash registered")
-	}
-	switch sc.Kind {
-	case swapKindInitiator:
-		if sc.Initiator != sct.fromAddr {
-			return nil, fmt.Errorf("only the participant can refund: unexpected address: %x", sct.fromAddr)
-		}
-	case swapKindParticipant:
-		if sc.Participant != sct.fromAddr {
-			return nil, fmt.Errorf("only the initiator can refund: unexpected address: %x", sct.fromAddr)
-		}
-	default:
-		return nil, fmt.Errorf("invalid atomic swap contract kind: %d", sc.Kind)
-	}
-	if sc.State != swapStateFilled {
-		return nil, errors.New("inactive atomic swap contract")
+		return err
 	}
-	lockTime := time.Unix(bigIntPtrToUint64(sc.InitTimestamp)+bigIntPtrToUint64(sc.RefundTime), 0)
-	if dur := time.Until(lockTime).Truncate(time.Second); dur >= 0 {
-		return nil, fmt.Errorf("contract is still locked for %v", dur+time.Second)
+	unsignedTx, err := eth.BuildApprove(ctx, sct, amount, token.Address)
+	if err != nil {
+		return err
 	}
-	// create refund tx
-	return sct.newTransaction(
-		nil, "refund",
-		// secret hash
-		secretHash,
-	)
+	return printUnsignedTx(unsignedTx)
 }
 
-func bigIntPtrToUint64(i *big.Int) int64 {
-	if i == nil {
-		return 0
+// printUnsignedTx prints the unsigned transaction as JSON,
+// to be stored in a file and signed by the sign command
+func printUnsignedTx(unsignedTx eth.UnsignedTransaction) error {
+	b, err := json.MarshalIndent(unsignedTx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode unsigned %s TX: %v", unsignedTx.Action, err)
 	}
-	return i.Int64()
+	fmt.Printf("Unsigned %s transaction, to be signed by %x:\n", unsignedTx.Action, unsignedTx.From)
+	fmt.Printf("%s\n", b)
+	return nil
 }
 
-func (sct *swapContractTransactor) deployTx() (*swapTransaction, error) {
-	return sct.newTransactionWithInput(nil, false, common.FromHex(contract.ContractBin))
+func (cmd *signCmd) runCommand(eth.SwapContractTransactor) error {
+	return cmd.runOfflineCommand()
 }
 
-func (sct *swapContractTransactor) maxGasCost() (*big.Int, error) {
-	ctx := newContext()
-	gasPrice, err := sct.client.SuggestGasPrice(ctx)
-	ctx.Cancel()
+func (cmd *signCmd) runOfflineCommand() error {
+	b, err := ioutil.ReadFile(cmd.path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to suggest gas price: %v", err)
+		return fmt.Errorf("failed to read unsigned transaction file (%s): %v", cmd.path, err)
 	}
-	return gasPrice.Mul(gasPrice, big.NewInt(maxGasLimit)), nil
-}
-
-// states have to be mapped 1-to-1 with Enum AtomicSwap.State,
-// as found in ./contract/src/contracts/AtomicSwap.sol
-//
-// This isn't part of the Ethereum-generated Go code found in the child "contract" pkg,
-// given that the ABI does not export Enums.
-const (
-	swapStateEmpty uint8 = iota
-	swapStateFilled
-	swapStateRedeemed
-	swapStateRefunded
-)
-
-// kinds have to be mapped 1-to-1 with Enum AtomicSwap.Kind,
-// as found in ./contract/src/contracts/AtomicSwap.sol
-//
-// This isn't part of the Ethereum-generated Go code found in the child "contract" pkg,
-// given that the ABI does not export Enums.
-const (
-	swapKindInitiator uint8 = iota
-	swapKindParticipant
-)
-
-var (
-	// error reported when an atomic swap contract (identified by a secret hash),
-	// has the state Empty, indicating it doesn't exist yet.
-	errNotExists = errors.New("atomic swap contract does not exist")
-)
-
-// getSwapContract is a free contract call,
-// which allows us to retrieve an atomic swap contract from a deployed AtomicSwap smart contract,
-// using the secret hash used in that atomic swap contract as this contract's identifier.
-func (sct *swapContractTransactor) getSwapContract(secretHash [32]byte) (*struct {
-	InitTimestamp *big.Int
-	RefundTime    *big.Int
-	SecretHash    [32]byte
-	Secret        [32]byte
-	Initiator     common.Address
-	Participant   common.Address
-	Value         *big.Int
-	Kind          uint8
-	State         uint8
-}, error) {
-	if sct._contract == nil {
-		var err error
-		sct._contract, err = contract.NewContract(sct.contractAddr, sct.client.Client)
-		if err != nil {
-			return nil, fmt.Errorf("failed to bind smart contract (at %x): %v", sct.contractAddr, err)
-		}
+	var unsignedTx eth.UnsignedTransaction
+	if err = json.Unmarshal(b, &unsignedTx); err != nil {
+		return fmt.Errorf("failed to decode unsigned transaction file (%s): %v", cmd.path, err)
 	}
-	ctx := newContext()
-	sc, err := sct._contract.Swaps(&bind.CallOpts{
-		Pending: false,
-		From:    sct.fromAddr,
-		Context: ctx,
-	}, secretHash)
-	ctx.Cancel()
+	if unsignedTx.ChainID == nil || unsignedTx.ChainID.Cmp(network.ChainID) != 0 {
+		return fmt.Errorf("transaction is for chain ID %s, not for network %s (%s)", unsignedTx.ChainID, network.Name, network.ChainID)
+	}
+	if *accountFlag == "" || common.IsHexAddress(*accountFlag) {
+		return errors.New("the account file of the key signing the transaction is required (-account)")
+	}
+	key, err := loadAccount(*accountFlag)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get swap contract from smart contract (at %x): %v", sct.contractAddr, err)
+		return errors.Wrap(err, "could not load account key")
 	}
-	if sc.State == swapStateEmpty {
-		return nil, errNotExists
+	tx, err := unsignedTx.Sign(context.Background(), eth.NewKeySigner(key))
+	if err != nil {
+		return err
 	}
-	return &sc, nil
-}
-
-func (sct *swapContractTransactor) newTransaction(amount *big.Int, name string, params ...interface{}) (*swapTransaction, error) {
-	// pack up the parameters and contract name
-	input, err := sct.abi.Pack(name, params...)
+	txBytes, err := tx.MarshalBinary()
 	if err != nil {
-		return nil, fmt.Errorf("failed to pack input")
+		return fmt.Errorf("failed to encode signed TX: %v", err)
 	}
-	return sct.newTransactionWithInput(amount, true, input)
+
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
+	fmt.Printf("Signed %s transaction (%x):\n", unsignedTx.Action, tx.Hash())
+	fmt.Printf("%x\n", txBytes)
+	return nil
 }
 
-func (sct *swapContractTransactor) newTransactionWithInput(amount *big.Int, contractCall bool, input []byte) (*swapTransaction, error) {
-	// define the TransactOpts for binding
-	opts, err := sct.calcBaseOpts(amount)
+func (cmd *broadcastCmd) runCommand(eth.SwapContractTransactor) error {
+	return errors.New("broadcast requires the RPC client")
+}
+
+func (cmd *broadcastCmd) runClientCommand(client *eth.EthClient) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	from, err := types.Sender(types.LatestSignerForChainID(network.ChainID), cmd.tx)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid signed transaction for network %s: %v", network.Name, err)
 	}
-	opts.GasLimit, err = sct.calcGasLimit(opts.Value, opts.GasPrice, contractCall, input)
-	if err != nil {
-		return nil, err
+	if err = client.SendTransaction(ctx, cmd.tx); err != nil {
+		return fmt.Errorf("failed to send transaction: %v", err)
 	}
 
-	// sign using daemon or do it client-side if desired
-	var signedTx *types.Transaction
-	if opts.Signer == nil {
-		var toAddr *common.Address
-		if contractCall {
-			toAddr = &sct.contractAddr
-		}
-		// sign transaction using the daemon
-		var result struct {
-			Raw string            `json:"raw"`
-			Tx  types.Transaction `json:"tx"`
-		}
-		ctx := newContext()
-		err = sct.client.rpcClient.CallContext(ctx, &result, "eth_signTransaction", struct {
-			From     common.Address  `json:"from"`
-			To       *common.Address `json:"to"`
-			Gas      hexutil.Uint64  `json:"gas"`
-			GasPrice hexutil.Big     `json:"gasPrice"`
-			Value    hexutil.Big     `json:"value"`
-			Nonce    hexutil.Uint64  `json:"nonce"`
-			Data     hexutil.Bytes   `json:"data"`
-		}{
-			From:     opts.From,
-			To:       toAddr,
-			Gas:      hexutil.Uint64(opts.GasLimit),
-			GasPrice: hexutil.Big(*opts.GasPrice),
-			Value: func() hexutil.Big {
-				if amount == nil {
-					return hexutil.Big{}
-				}
-				return hexutil.Big(*amount)
-			}(),
-			Nonce: hexutil.Uint64(opts.Nonce.Uint64()),
-			Data:  hexutil.Bytes(input),
-		})
-		ctx.Cancel()
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign transaction from daemon: %v", err)
-		}
-		signedTx = &result.Tx
-	} else {
-		var rawTx *types.Transaction
-		if contractCall {
-			rawTx = types.NewTransaction(
-				opts.Nonce.Uint64(),
-				sct.contractAddr,
-				opts.Value,
-				opts.GasLimit,
-				opts.GasPrice,
-				input,
-			)
-		} else {
-			rawTx = types.NewContractCreation(
-				opts.Nonce.Uint64(),
-				opts.Value,
-				opts.GasLimit,
-				opts.GasPrice,
-				input,
-			)
-		}
-		// sign ourselves
-		signedTx, err = opts.Signer(opts.From, rawTx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign transaction from client: %v", err)
-		}
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
+	fmt.Printf("Published transaction (%x) from %x:\n", cmd.tx.Hash(), from)
+	txBytes, err := rlp.EncodeToBytes(cmd.tx)
+	if err != nil {
+		return fmt.Errorf("failed to encode TX: %v", err)
 	}
-	return &swapTransaction{
-		Transaction: signedTx,
-		client:      sct.client,
-	}, nil
+	// printed in the encoding the other commands expect a contract transaction in
+	fmt.Printf("%x\n", txBytes)
+
+	// the transaction can not be replaced with bumped fees without its key
+	sct := eth.SwapContractTransactor{Client: client, FromAddr: from}
+	return waitMinedBumpingAfter(sct, cmd.tx.Hash(), 0)
 }
 
-func (sct *swapContractTransactor) calcBaseOpts(amount *big.Int) (*bind.TransactOpts, error) {
-	ctx := newContext()
-	nonce, err := sct.client.PendingNonceAt(ctx, sct.fromAddr)
-	ctx.Cancel()
+func (cmd *contractProofCmd) runCommand(eth.SwapContractTransactor) error {
+	return errors.New("contractproof requires the RPC client")
+}
+
+func (cmd *contractProofCmd) runClientCommand(client *eth.EthClient) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	proof, err := eth.BuildContractProof(ctx, client, cmd.contractTx.Hash())
 	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to retrieve account (%x) nonce: %v",
-			sct.fromAddr, err)
+		return err
 	}
-	ctx = newContext()
-	gasPrice, err := sct.client.SuggestGasPrice(ctx)
-	ctx.Cancel()
+	b, err := json.MarshalIndent(proof, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("failed to suggest gas price: %v", err)
-	}
-	if amount == nil {
-		amount = new(big.Int)
+		return fmt.Errorf("failed to encode contract proof: %v", err)
 	}
-	return &bind.TransactOpts{
-		From:     sct.fromAddr,
-		Nonce:    new(big.Int).SetUint64(nonce),
-		Signer:   sct.signer,
-		Value:    amount,
-		GasPrice: gasPrice,
-	}, nil
+	fmt.Printf("Contract transaction (%x) included in block %s (%x), proven by:\n", cmd.contractTx.Hash(), proof.Header.Number, proof.Header.Hash())
+	fmt.Printf("%s\n", b)
+	return nil
 }
 
-func (sct *swapContractTransactor) calcGasLimit(amount, gasPrice *big.Int, contractCall bool, input []byte) (uint64, error) {
-	if contractCall {
-		ctx := newContext()
-		code, err := sct.client.PendingCodeAt(ctx, sct.contractAddr)
-		ctx.Cancel()
+func (cmd *batchRedeemCmd) runCommand(sct eth.SwapContractTransactor) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	requests := make([]eth.RedeemRequest, len(cmd.contractTxs))
+	for i, contractTx := range cmd.contractTxs {
+		params, err := eth.UnpackContractParams(contractTx)
 		if err != nil {
-			return 0, fmt.Errorf("failed to estimate gas needed: %v", err)
-		} else if len(code) == 0 {
-			return 0, fmt.Errorf("failed to estimate gas needed: %v", bind.ErrNoCode)
+			return fmt.Errorf("contract transaction (%x): %v", contractTx.Hash(), err)
 		}
+		requests[i] = eth.RedeemRequest{SecretHash: params.SecretHash, Secret: cmd.secrets[i]}
 	}
-	// If the contract surely has code (or code is not needed), estimate the transaction
-	msg := ethereum.CallMsg{
-		From:  sct.fromAddr,
-		Value: amount,
-		Data:  input,
-	}
-	if contractCall {
-		msg.To = &sct.contractAddr
-	}
-	ctx := newContext()
-	gasLimit, err := sct.client.EstimateGas(ctx, msg)
-	ctx.Cancel()
+	results, err := eth.BatchRedeem(ctx, sct, requests, token.Address)
 	if err != nil {
-		return 0, fmt.Errorf("failed to estimate gas needed: %v", err)
-	}
-	if contractCall && gasLimit > maxGasLimit {
-		return 0, fmt.Errorf("%d exceeds the hardcoded code-call gas limit of %d", gasLimit, maxGasLimit)
+		return err
 	}
-	return gasLimit, nil
+	return printBatchResults(sct, "Redeem", results)
 }
 
-func (st *swapTransaction) Send() error {
-	ctx := newContext()
-	err := st.client.SendTransaction(ctx, st.Transaction)
-	ctx.Cancel()
-	if err != nil {
-		return fmt.Errorf("failed to send transaction: %v", err)
+func (cmd *batchRefundCmd) runCommand(sct eth.SwapContractTransactor) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	secretHashes := make([][32]byte, len(cmd.contractTxs))
+	for i, contractTx := range cmd.contractTxs {
+		params, err := eth.UnpackContractParams(contractTx)
+		if err != nil {
+			return fmt.Errorf("contract transaction (%x): %v", contractTx.Hash(), err)
+		}
+		secretHashes[i] = params.SecretHash
 	}
-	return nil
-}
-
-func dialClient() (*ethClient, error) {
-	c, err := rpc.DialContext(context.Background(), *connectFlag)
+	results, err := eth.BatchRefund(ctx, sct, secretHashes, token.Address)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &ethClient{
-		Client:    ethclient.NewClient(c),
-		rpcClient: c,
-	}, nil
-}
-
-type ethClient struct {
-	*ethclient.Client
-	rpcClient *rpc.Client
+	return printBatchResults(sct, "Refund", results)
 }
 
-// newContext creates a context which HAS
-// to be manually cancelled, as to not leak any resources
-func newContext() *cancelableContext {
-	if *timeoutFlag == 0 {
-		ctx, cancelFn := context.WithCancel(context.Background())
-		return &cancelableContext{
-			Context:  ctx,
-			cancelFn: cancelFn,
+// printBatchResults prints the result of each swap of a batch,
+// waiting for the transactions sent if the wait flag is set
+func printBatchResults(sct eth.SwapContractTransactor, action string, results []eth.BatchResult) error {
+	fmt.Printf("Chain ID:         %s\n", network.ChainID.String())
+	fmt.Printf("Contract Address: %x\n\n", sct.ContractAddr)
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("Secret hash %x: failed: %v\n", result.SecretHash, result.Err)
+			continue
+		}
+		fmt.Printf("Secret hash %x: %s transaction (%x)\n", result.SecretHash, action, result.TxHash)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		if err := waitMined(sct, result.TxHash); err != nil {
+			failed++
+			fmt.Printf("Secret hash %x: %v\n", result.SecretHash, err)
 		}
 	}
-	ctx, cancelFn := context.WithTimeout(context.Background(), *timeoutFlag)
-	return &cancelableContext{
-		Context:  ctx,
-		cancelFn: cancelFn,
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d swaps failed", failed, len(results))
 	}
+	return nil
 }
-
-type cancelableContext struct {
-	context.Context
-	cancelFn context.CancelFunc
-}
-
-func (cc *cancelableContext) Cancel() {
-	cc.cancelFn()
-}
-
-var (
-	// decode the byte code of the smart contract used
-	// during the initialisation phase of this CLI tool,
-	// as to ensure the hex-encoded string is valid at all times.
-	//
-	// This prevents of having a hidden error,
-	// due to the fact that it is only ever used in
-	// our extra smart-contract-related commands.
-	contractBin = func() []byte {
-		b, err := hex.DecodeString(contract.ContractBin)
-		if err != nil {
-			panic("invalid binary contract: " + err.Error())
-		}
-		return b
-	}()
-)
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -15,7 +17,9 @@ import (
 
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/txnbuild"
+	"github.com/threefoldtech/atomicswap/chain"
 	"github.com/threefoldtech/atomicswap/stellar"
+	"github.com/threefoldtech/atomicswap/timings"
 
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
@@ -33,8 +37,12 @@ var (
 	testnetFlag   = flagset.Bool("testnet", false, "use testnet network")
 	automatedFlag = flagset.Bool("automated", false, "Use automated/unattended version with json output")
 	assetParam    = flagset.String("asset", "", "The asset to transfer in case of non native XLM, format: `code:issuer`")
+	expectFlag    = flagset.String("expect", "", "auditcontract: verify the contract matches the swap terms, e.g. amount=10,asset=XLM,recipient=<address>,secrethash=<hash>,minlocktime=12h")
 )
 
+// lockTimes of the contracts, set using the lock time flags
+var lockTimes = timings.DefaultPolicy
+
 // There are two directions that the atomic swap can be performed, as the
 // initiator can be on either chain.  This tool only deals with creating the
 // Stellar transactions for these swaps.  A second tool should be used for the
@@ -58,6 +66,7 @@ var (
 //   cp2 redeems xlm with S
 
 func init() {
+	lockTimes.AddFlags(flagset)
 	flagset.Usage = func() {
 		fmt.Println("Usage: stellaratomicswap [flags] cmd [cmd args]")
 		fmt.Println()
@@ -315,10 +324,21 @@ func run() (showUsage bool, err error) {
 }
 
 func (cmd *initiateCmd) runCommand(client horizonclient.ClientInterface) error {
-	output, err := stellar.Initiate(targetNetwork, cmd.InitiatorKeyPair, cmd.cp2Addr, cmd.amount, cmd.asset, client)
+	var secret [secretSize]byte
+	_, err := rand.Read(secret[:])
 	if err != nil {
 		return err
 	}
+	holdingAccountKeyPair, err := stellar.GenerateKeyPair()
+	if err != nil {
+		return errors.Wrap(err, "failed to create holding account keypair")
+	}
+	recorder := &refundRecorder{}
+	ctx := chain.WithRecorder(context.Background(), recorder)
+	output, err := stellar.InitiateWithHoldingAccount(ctx, targetNetwork, cmd.InitiatorKeyPair, holdingAccountKeyPair, cmd.cp2Addr, cmd.amount, secret, lockTimes, cmd.asset, client)
+	if err != nil {
+		return recorder.recoveryError(err, holdingAccountKeyPair)
+	}
 
 	if !*automatedFlag {
 		fmt.Printf("Secret:      %x\n", output.Secret)
@@ -334,9 +354,15 @@ func (cmd *initiateCmd) runCommand(client horizonclient.ClientInterface) error {
 }
 
 func (cmd *participateCmd) runCommand(client horizonclient.ClientInterface) error {
-	output, err := stellar.Participate(targetNetwork, cmd.participatorKeyPair, cmd.cp1Addr, cmd.amount, cmd.secretHash, cmd.asset, client)
+	holdingAccountKeyPair, err := stellar.GenerateKeyPair()
 	if err != nil {
-		return err
+		return errors.Wrap(err, "failed to create holding account keypair")
+	}
+	recorder := &refundRecorder{}
+	ctx := chain.WithRecorder(context.Background(), recorder)
+	output, err := stellar.ParticipateWithHoldingAccount(ctx, targetNetwork, cmd.participatorKeyPair, holdingAccountKeyPair, cmd.cp1Addr, cmd.amount, cmd.secretHash, lockTimes, cmd.asset, client)
+	if err != nil {
+		return recorder.recoveryError(err, holdingAccountKeyPair)
 	}
 	if !*automatedFlag {
 		fmt.Printf("participant address: %s\n", output.InitiatorAddress)
@@ -349,6 +375,31 @@ func (cmd *participateCmd) runCommand(client horizonclient.ClientInterface) erro
 	return nil
 }
 
+// refundRecorder keeps the refund transaction of a holding account,
+// recorded before the holding account is locked
+type refundRecorder struct {
+	refundTransaction string
+}
+
+func (r *refundRecorder) RecordKey(name, key string) error {
+	return nil
+}
+
+func (r *refundRecorder) RecordContract(contract chain.Contract) error {
+	r.refundTransaction = contract.RefundTransaction
+	return nil
+}
+
+// recoveryError adds what is needed to recover the funds to the error:
+// the holding account seed, and the refund transaction,
+// as the seed can no longer sign once the holding account is locked.
+func (r *refundRecorder) recoveryError(err error, holdingAccountKeyPair *keypair.Full) error {
+	if r.refundTransaction == "" {
+		return errors.Wrapf(err, "holding account seed: %s", holdingAccountKeyPair.Seed())
+	}
+	return errors.Wrapf(err, "holding account seed: %s, refund transaction: %s", holdingAccountKeyPair.Seed(), r.refundTransaction)
+}
+
 func (cmd *auditContractCmd) runCommand(client horizonclient.ClientInterface) error {
 	output, err := stellar.AuditContract(targetNetwork, cmd.refundTx, cmd.holdingAccountAdress, cmd.asset, client)
 	if err != nil {
@@ -375,7 +426,36 @@ func (cmd *auditContractCmd) runCommand(client horizonclient.ClientInterface) er
 		jsonoutput, _ := json.Marshal(output)
 		fmt.Println(string(jsonoutput))
 	}
-	return nil
+	audit, err := output.AuditResult(cmd.asset)
+	if err != nil {
+		return err
+	}
+	return verifyTerms(audit)
+}
+
+// verifyTerms verifies an audited contract matches the terms of the expect flag, if any
+func verifyTerms(audit chain.AuditResult) error {
+	if *expectFlag == "" {
+		return nil
+	}
+	terms, err := chain.ParseTerms(*expectFlag, 7)
+	if err != nil {
+		return err
+	}
+	err = chain.VerifySwapTerms(audit, terms)
+	if *automatedFlag {
+		violations, _ := err.(chain.Violations)
+		if violations == nil {
+			violations = chain.Violations{}
+		}
+		jsonoutput, _ := json.Marshal(struct {
+			Violations chain.Violations `json:"violations"`
+		}{violations})
+		fmt.Println(string(jsonoutput))
+	} else if err == nil {
+		fmt.Println("Contract matches the swap terms")
+	}
+	return err
 }
 
 func (cmd *refundCmd) runCommand(client horizonclient.ClientInterface) error {
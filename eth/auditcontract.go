@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/threefoldtech/atomicswap/chain"
 )
 
 type (
@@ -20,6 +21,11 @@ type (
 		RefundAddress    common.Address    `json:"refundAddress"`
 		SecretHash       [sha256.Size]byte `json:"secretHash"`
 		Locktime         int64             `json:"locktime"`
+		// Token locked by the contract, the zero address for Ether
+		Token common.Address `json:"token"`
+		// ContractVersion is the name of the version of the deployed contract,
+		// empty if its code could not be verified
+		ContractVersion string `json:"contractVersion,omitempty"`
 	}
 )
 
@@ -28,37 +34,58 @@ var (
 	ErrTxPending = errors.New("transaction is pending")
 )
 
-func AuditContract(ctx context.Context, sct SwapContractTransactor, contractTx *types.Transaction) (AuditContractOutput, error) {
+// AuditContract audits the contract transaction of an atomic swap locking the token,
+// the zero address for Ether.
+// The code deployed at the contract address has to match a known version
+// of the atomic swap contracts, see SwapContractTransactor.Versions.
+func AuditContract(ctx context.Context, sct SwapContractTransactor, contractTx *types.Transaction, token common.Address) (AuditContractOutput, error) {
+	sct = sct.forToken(token)
 	// unpack input params from contract tx
 	params, err := unpackContractInputParams(sct.Abi, contractTx)
 	if err != nil {
 		return AuditContractOutput{}, err
 	}
+	version, err := VerifyContractCode(ctx, sct.Client, *contractTx.To(), sct.versions())
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	if version.Token() != (token != common.Address{}) {
+		return AuditContractOutput{}, fmt.Errorf("contract version %s does not lock %s", version.Name, assetName(token))
+	}
+	value := contractTx.Value()
+	if params.Value != nil {
+		value = params.Value
+	}
+	if params.Token != token {
+		return AuditContractOutput{}, fmt.Errorf("contract locks %s, not %s", assetName(params.Token), assetName(token))
+	}
 
-	rpcTransaction := struct {
-		tx          *types.Transaction
-		BlockNumber *string
-		BlockHash   *common.Hash
-		From        *common.Address
-	}{}
-
-	// get transaction by hash
+	// get the block of the transaction in order to know its timestamp
 	contractHash := contractTx.Hash()
-	err = sct.Client.rpcClient.CallContext(ctx,
-		&rpcTransaction, "eth_getTransactionByHash", contractHash)
+	_, pending, err := sct.Client.TransactionByHash(ctx, contractHash)
 	if err != nil {
 		return AuditContractOutput{}, fmt.Errorf(
 			"failed to find transaction (%x): %v", contractHash, err)
 	}
-	if rpcTransaction.BlockNumber == nil || *rpcTransaction.BlockNumber == "" || *rpcTransaction.BlockNumber == "0" {
+	if pending {
 		return AuditContractOutput{}, ErrTxPending
 	}
-
-	// get block in order to know the timestamp of the txn
-	block, err := sct.Client.BlockByHash(ctx, *rpcTransaction.BlockHash)
+	receipt, err := sct.Client.TransactionReceipt(ctx, contractHash)
 	if err != nil {
 		return AuditContractOutput{}, fmt.Errorf(
-			"failed to find block (%x): %v", rpcTransaction.BlockHash, err)
+			"failed to get receipt of transaction (%x): %v", contractHash, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return AuditContractOutput{}, errors.New("contract transaction failed")
+	}
+	block, err := sct.Client.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return AuditContractOutput{}, fmt.Errorf(
+			"failed to find block (%x): %v", receipt.BlockHash, err)
+	}
+	refundAddr, err := types.Sender(types.LatestSignerForChainID(contractTx.ChainId()), contractTx)
+	if err != nil {
+		return AuditContractOutput{}, fmt.Errorf("invalid contract transaction signature: %v", err)
 	}
 
 	// compute the locktime
@@ -66,15 +93,31 @@ func AuditContract(ctx context.Context, sct SwapContractTransactor, contractTx *
 
 	// NOTE:
 	// the reason we require th node for this method,
-	// is because we need to be able to know the transaction's timestamp
+	// is because we need to be able to know the transaction's timestamp,
+	// see AuditContractProof to audit a contract without a node
 
 	return AuditContractOutput{
 			ContractAddress:  *contractTx.To(),
-			ContractValue:    contractTx.Value(),
+			ContractValue:    value,
 			RecipientAddress: params.ToAddress,
-			RefundAddress:    *rpcTransaction.From,
+			RefundAddress:    refundAddr,
 			SecretHash:       params.SecretHash,
 			Locktime:         lockTime.Unix(),
+			Token:            token,
+			ContractVersion:  version.Name,
 		},
 		nil
 }
+
+// AuditResult converts the output to the chain agnostic chain.AuditResult
+func (o AuditContractOutput) AuditResult() chain.AuditResult {
+	return chain.AuditResult{
+		ContractAddress:  o.ContractAddress.Hex(),
+		ContractValue:    chain.NewAmount(o.ContractValue),
+		RecipientAddress: o.RecipientAddress.Hex(),
+		RefundAddress:    o.RefundAddress.Hex(),
+		SecretHash:       o.SecretHash,
+		LockTime:         chain.LockTime(o.Locktime),
+		Asset:            assetName(o.Token),
+	}
+}
@@ -0,0 +1,81 @@
+package eth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type (
+	// RedeemRequest is an atomic swap to redeem in a batch
+	RedeemRequest struct {
+		SecretHash [sha256.Size]byte
+		Secret     [sha256.Size]byte
+	}
+
+	// BatchResult is the result of redeeming or refunding one atomic swap of a batch
+	BatchResult struct {
+		SecretHash [sha256.Size]byte `json:"secretHash"`
+		// TxHash of the transaction sent, the zero hash if it failed
+		TxHash common.Hash `json:"transactionHash"`
+		// Err is the reason the swap could not be redeemed or refunded, nil if the transaction was sent
+		Err error `json:"-"`
+	}
+)
+
+// BatchRedeem redeems many atomic swaps locking the token, the zero address for Ether.
+// The transactions are sent one by one, using consecutive nonces of our account,
+// so they do not race for the same nonce. A swap that can not be redeemed
+// does not stop the batch, its result holds the error instead.
+// The account should not send other transactions while the batch is sent.
+func BatchRedeem(ctx context.Context, sct SwapContractTransactor, requests []RedeemRequest, token common.Address) ([]BatchResult, error) {
+	secretHashes := make([][sha256.Size]byte, len(requests))
+	for i, req := range requests {
+		secretHashes[i] = req.SecretHash
+	}
+	return sct.forToken(token).batch(ctx, "redeem", secretHashes, func(sct *SwapContractTransactor, i int) (*swapTransaction, error) {
+		return sct.redeemTx(ctx, requests[i].SecretHash, requests[i].Secret)
+	})
+}
+
+// BatchRefund refunds many atomic swaps locking the token, the zero address for Ether,
+// identified by their secret hashes. The transactions are sent like BatchRedeem does.
+func BatchRefund(ctx context.Context, sct SwapContractTransactor, secretHashes [][sha256.Size]byte, token common.Address) ([]BatchResult, error) {
+	return sct.forToken(token).batch(ctx, "refund", secretHashes, func(sct *SwapContractTransactor, i int) (*swapTransaction, error) {
+		return sct.refundTx(ctx, secretHashes[i])
+	})
+}
+
+// batch creates and sends the transactions of a batch,
+// managing the nonce of our account itself
+func (sct SwapContractTransactor) batch(ctx context.Context, action string, secretHashes [][sha256.Size]byte, newTx func(*SwapContractTransactor, int) (*swapTransaction, error)) ([]BatchResult, error) {
+	nonce, err := sct.Client.PendingNonceAt(ctx, sct.FromAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve account (%x) nonce: %v", sct.FromAddr, err)
+	}
+	sct.nonce = &nonce
+
+	results := make([]BatchResult, len(secretHashes))
+	for i, secretHash := range secretHashes {
+		results[i].SecretHash = secretHash
+		if err = ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		tx, err := newTx(&sct, i)
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to create %s TX: %v", action, err)
+			continue
+		}
+		if err = tx.Send(ctx); err != nil {
+			results[i].Err = err
+			continue
+		}
+		// only a sent transaction uses the nonce
+		nonce++
+		results[i].TxHash = tx.Hash()
+	}
+	return results, nil
+}
@@ -0,0 +1,91 @@
+package eth
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+func TestBatch(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	bobAddr := crypto.PubkeyToAddress(c.bob.PublicKey)
+	initiate := func() InitiateOutput {
+		initiation, err := Initiate(ctx, c.transactor(c.alice, c.initiatorContract), bobAddr, ether(1), timings.DefaultPolicy, common.Address{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return initiation
+	}
+	// checkResults verifies that the swap at index bad failed,
+	// and that the transactions of all others are mined, with consecutive nonces
+	checkResults := func(results []BatchResult, secretHashes [][sha256.Size]byte, bad int) {
+		if len(results) != len(secretHashes) {
+			t.Fatalf("%d results, expected %d", len(results), len(secretHashes))
+		}
+		var nonce uint64
+		for i, result := range results {
+			if result.SecretHash != secretHashes[i] {
+				t.Errorf("result #%d has secret hash %x, expected %x", i, result.SecretHash, secretHashes[i])
+			}
+			if i == bad {
+				if result.Err == nil || result.TxHash != (common.Hash{}) {
+					t.Errorf("result #%d has no error, transaction %x sent", i, result.TxHash)
+				}
+				continue
+			}
+			if result.Err != nil {
+				t.Errorf("result #%d failed: %v", i, result.Err)
+				continue
+			}
+			receipt, err := c.client.TransactionReceipt(ctx, result.TxHash)
+			if err != nil {
+				t.Errorf("transaction of result #%d not mined: %v", i, err)
+				continue
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				t.Errorf("transaction of result #%d failed", i)
+			}
+			tx, _, err := c.client.TransactionByHash(ctx, result.TxHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i > 0 && tx.Nonce() != nonce+1 {
+				t.Errorf("transaction of result #%d has nonce %d, expected %d", i, tx.Nonce(), nonce+1)
+			}
+			nonce = tx.Nonce()
+		}
+	}
+
+	// redeem a batch of which a swap does not exist
+	var requests []RedeemRequest
+	var secretHashes [][sha256.Size]byte
+	for i := 0; i < 3; i++ {
+		initiation := initiate()
+		requests = append(requests, RedeemRequest{SecretHash: initiation.SecretHash, Secret: initiation.Secret})
+		secretHashes = append(secretHashes, initiation.SecretHash)
+	}
+	unknown := RedeemRequest{SecretHash: sha256Hash([]byte("unknown")), Secret: sha256Hash([]byte("secret"))}
+	requests = append(requests[:1], append([]RedeemRequest{unknown}, requests[1:]...)...)
+	secretHashes = append(secretHashes[:1], append([][sha256.Size]byte{unknown.SecretHash}, secretHashes[1:]...)...)
+	results, err := BatchRedeem(ctx, c.transactor(c.bob, c.initiatorContract), requests, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(results, secretHashes, 1)
+
+	// refund a batch of which a swap is already redeemed
+	refunded := [][sha256.Size]byte{initiate().SecretHash, requests[0].SecretHash, initiate().SecretHash}
+	c.adjustTime(timings.DefaultPolicy.Initiator)
+	results, err = BatchRefund(ctx, c.transactor(c.alice, c.initiatorContract), refunded, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(results, refunded, 1)
+}
@@ -2,13 +2,29 @@ package eth
 
 import (
 	"context"
+	"errors"
+	"math/big"
 
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// Backend is the Ethereum node used by the client,
+// implemented by go-ethereum's ethclient.Client and simulated backend
+type Backend interface {
+	bind.ContractBackend
+	ethereum.ChainReader
+	ethereum.ChainStateReader
+	ethereum.TransactionReader
+}
+
+// EthClient calls an Ethereum node, see DialClient and NewClient
 type EthClient struct {
-	*ethclient.Client
+	Backend
+	// rpcClient calls the JSON-RPC API of the node,
+	// nil if the backend is not a JSON-RPC node
 	rpcClient *rpc.Client
 }
 
@@ -18,8 +34,57 @@ func DialClient(ctx context.Context, url string) (*EthClient, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewRPCClient(c), nil
+}
+
+// NewRPCClient creates a client calling the JSON-RPC API of a node
+func NewRPCClient(c *rpc.Client) *EthClient {
 	return &EthClient{
-		Client:    ethclient.NewClient(c),
+		Backend:   ethclient.NewClient(c),
 		rpcClient: c,
-	}, nil
+	}
+}
+
+// NewClient creates a client using the backend, such as go-ethereum's simulated backend.
+// The accounts of a backend created this way are not available, see NewNodeSigner.
+func NewClient(backend Backend) *EthClient {
+	return &EthClient{Backend: backend}
+}
+
+// ErrNoChainID is returned by ChainID when the backend does not report its chain ID
+var ErrNoChainID = errors.New("backend does not report its chain ID")
+
+// ChainID returns the chain ID of the node, see ErrNoChainID
+func (c *EthClient) ChainID(ctx context.Context) (*big.Int, error) {
+	b, ok := c.Backend.(interface {
+		ChainID(context.Context) (*big.Int, error)
+	})
+	if !ok {
+		return nil, ErrNoChainID
+	}
+	return b.ChainID(ctx)
+}
+
+// BlockNumber returns the number of the latest block
+func (c *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	if b, ok := c.Backend.(interface {
+		BlockNumber(context.Context) (uint64, error)
+	}); ok {
+		return b.BlockNumber(ctx)
+	}
+	head, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return head.Number.Uint64(), nil
+}
+
+// Close the connection to the node, or the backend
+func (c *EthClient) Close() {
+	switch b := c.Backend.(type) {
+	case interface{ Close() }:
+		b.Close()
+	case interface{ Close() error }:
+		b.Close()
+	}
 }
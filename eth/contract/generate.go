@@ -10,3 +10,20 @@ package contract
 //go:generate sh -c "solc --abi src/contracts/AtomicSwap.sol | awk '/JSON ABI/{x=1;next}x' > AtomicSwap.abi"
 //go:generate sh -c "solc --bin src/contracts/AtomicSwap.sol | awk '/Binary:/{x=1;next}x' > AtomicSwap.bin"
 //go:generate abigen --bin=AtomicSwap.bin --abi=AtomicSwap.abi --pkg=contract --out=atomicswap.go
+
+// the ERC-20 variant of the contract and the token interface it uses,
+// solc writes the ABI and binary of every contract to the build directory.
+// It is compiled for the Paris fork, without the PUSH0 opcode of Shanghai,
+// such that it can be deployed on the chains not supporting Shanghai yet and tested against the simulated backend.
+//go:generate solc --abi --bin --overwrite --evm-version paris -o build src/contracts/TokenAtomicSwap.sol
+//go:generate abigen --bin=build/TokenAtomicSwap.bin --abi=build/TokenAtomicSwap.abi --pkg=contract --type=TokenContract --out=tokenatomicswap.go
+//go:generate abigen --abi=build/IERC20.abi --pkg=contract --type=ERC20 --out=erc20.go
+
+// the variant of the AtomicSwap contract of which the swaps can be redeemed by a relayer
+//go:generate solc --abi --bin --overwrite --evm-version paris -o build src/contracts/RelayAtomicSwap.sol
+//go:generate abigen --bin=build/RelayAtomicSwap.bin --abi=build/RelayAtomicSwap.abi --pkg=contract --type=RelayContract --out=relayatomicswap.go
+
+// the second version of the TokenAtomicSwap contract, of which the swaps can be redeemed by a relayer,
+// the first version being kept as deployed
+//go:generate solc --abi --bin --overwrite --evm-version paris -o build src/contracts/TokenAtomicSwapV2.sol
+//go:generate abigen --bin=build/TokenAtomicSwapV2.bin --abi=build/TokenAtomicSwapV2.abi --pkg=contract --type=TokenContractV2 --out=tokenatomicswapv2.go
@@ -0,0 +1,972 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// RelayContractMetaData contains all meta data concerning the RelayContract contract.
+var RelayContractMetaData = &bind.MetaData{
+	ABI: "[{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Initiated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Participated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"redeemTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"redeemer\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Redeemed\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"refunder\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Refunded\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"}],\"name\":\"initiate\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"}],\"name\":\"participate\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"}],\"name\":\"redeem\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}],\"name\":\"redeemFor\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"}],\"name\":\"refund\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"relayer\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"relayHash\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"swaps\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"enumAtomicSwap.Kind\",\"name\":\"kind\",\"type\":\"uint8\"},{\"internalType\":\"enumAtomicSwap.State\",\"name\":\"state\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561001057600080fd5b50611b3e806100206000396000f3fe6080604052600436106100705760003560e01c8063b31597ad1161004e578063b31597ad146100d6578063d91ad122146100ff578063dbcbb0c714610128578063eb84e7f21461016557610070565b80631aa02853146100755780637249fbb614610091578063ae052147146100ba575b600080fd5b61008f600480360381019061008a919061136c565b6101aa565b005b34801561009d57600080fd5b506100b860048036038101906100b391906113bf565b6103ed565b005b6100d460048036038101906100cf919061136c565b6106bf565b005b3480156100e257600080fd5b506100fd60048036038101906100f891906113ec565b610902565b005b34801561010b57600080fd5b5061012660048036038101906101219190611465565b610c38565b005b34801561013457600080fd5b5061014f600480360381019061014a91906114f2565b6110b5565b60405161015c9190611554565b60405180910390f35b34801561017157600080fd5b5061018c600480360381019061018791906113bf565b6110ef565b6040516101a19998979695949392919061164c565b60405180910390f35b82600034116101b857600080fd5b600081116101c557600080fd5b82600060038111156101da576101d961158d565b5b60008083815260200190815260200160002060070160019054906101000a900460ff16600381111561020f5761020e61158d565b5b1461021957600080fd5b4260008086815260200190815260200160002060000181905550846000808681526020019081526020016000206001018190555083600080868152602001908152602001600020600201819055508260008086815260200190815260200160002060040160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055503360008086815260200190815260200160002060050160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055503460008086815260200190815260200160002060060181905550600160008086815260200190815260200160002060070160006101000a81548160ff021916908360018111156103625761036161158d565b5b0217905550600160008086815260200190815260200160002060070160016101000a81548160ff021916908360038111156103a05761039f61158d565b5b02179055507fe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f44286868633346040516103de969594939291906116d9565b60405180910390a15050505050565b8033600160038111156104035761040261158d565b5b60008084815260200190815260200160002060070160019054906101000a900460ff1660038111156104385761043761158d565b5b1461044257600080fd5b6001808111156104555761045461158d565b5b60008084815260200190815260200160002060070160009054906101000a900460ff16600181111561048a5761048961158d565b5b03610501578073ffffffffffffffffffffffffffffffffffffffff1660008084815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16146104fc57600080fd5b61056f565b8073ffffffffffffffffffffffffffffffffffffffff1660008084815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff161461056e57600080fd5b5b600080600084815260200190815260200160002060000154905060008084815260200190815260200160002060010154816105aa9190611769565b90508042116105b857600080fd5b3373ffffffffffffffffffffffffffffffffffffffff166108fc600080878152602001908152602001600020600601549081150290604051600060405180830381858888f19350505050158015610613573d6000803e3d6000fd5b50600360008086815260200190815260200160002060070160016101000a81548160ff0219169083600381111561064d5761064c61158d565b5b02179055507fadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8426000808781526020019081526020016000206002015433600080898152602001908152602001600020600601546040516106b1949392919061179d565b60405180910390a150505050565b82600034116106cd57600080fd5b600081116106da57600080fd5b82600060038111156106ef576106ee61158d565b5b60008083815260200190815260200160002060070160019054906101000a900460ff1660038111156107245761072361158d565b5b1461072e57600080fd5b4260008086815260200190815260200160002060000181905550846000808681526020019081526020016000206001018190555083600080868152602001908152602001600020600201819055503360008086815260200190815260200160002060040160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055508260008086815260200190815260200160002060050160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055503460008086815260200190815260200160002060060181905550600080600086815260200190815260200160002060070160006101000a81548160ff021916908360018111156108775761087661158d565b5b0217905550600160008086815260200190815260200160002060070160016101000a81548160ff021916908360038111156108b5576108b461158d565b5b02179055507f75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf5764286863387346040516108f3969594939291906116d9565b60405180910390a15050505050565b808233600160038111156109195761091861158d565b5b60008085815260200190815260200160002060070160019054906101000a900460ff16600381111561094e5761094d61158d565b5b1461095857600080fd5b60018081111561096b5761096a61158d565b5b60008085815260200190815260200160002060070160009054906101000a900460ff1660018111156109a05761099f61158d565b5b03610a17578073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610a1257600080fd5b610a85565b8073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610a8457600080fd5b5b82600283604051602001610a999190611803565b604051602081830303815290604052604051610ab5919061188f565b602060405180830381855afa158015610ad2573d6000803e3d6000fd5b5050506040513d601f19601f82011682018060405250810190610af591906118bb565b14610aff57600080fd5b3373ffffffffffffffffffffffffffffffffffffffff166108fc600080878152602001908152602001600020600601549081150290604051600060405180830381858888f19350505050158015610b5a573d6000803e3d6000fd5b50600260008086815260200190815260200160002060070160016101000a81548160ff02191690836003811115610b9457610b9361158d565b5b021790555084600080868152602001908152602001600020600301819055507fe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591426000808781526020019081526020016000206002015460008088815260200190815260200160002060030154336000808a815260200190815260200160002060060154604051610c299594939291906118e8565b60405180910390a15050505050565b8486610c4387611197565b60016003811115610c5757610c5661158d565b5b60008085815260200190815260200160002060070160019054906101000a900460ff166003811115610c8c57610c8b61158d565b5b14610c9657600080fd5b600180811115610ca957610ca861158d565b5b60008085815260200190815260200160002060070160009054906101000a900460ff166001811115610cde57610cdd61158d565b5b03610d55578073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610d5057600080fd5b610dc3565b8073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610dc257600080fd5b5b82600283604051602001610dd79190611803565b604051602081830303815290604052604051610df3919061188f565b602060405180830381855afa158015610e10573d6000803e3d6000fd5b5050506040513d601f19601f82011682018060405250810190610e3391906118bb565b14610e3d57600080fd5b6000610e4889611197565b905060008060008b81526020019081526020016000206006015490506000891115610f445780891115610e7a57600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff1603610eb357600080fd5b8173ffffffffffffffffffffffffffffffffffffffff166001610ed78c338d611261565b8a8a8a60405160008152602001604052604051610ef7949392919061194a565b6020604051602081039080840390855afa158015610f19573d6000803e3d6000fd5b5050506020604051035173ffffffffffffffffffffffffffffffffffffffff1614610f4357600080fd5b5b60026000808c815260200190815260200160002060070160016101000a81548160ff02191690836003811115610f7d57610f7c61158d565b5b02179055508a6000808c8152602001908152602001600020600301819055508173ffffffffffffffffffffffffffffffffffffffff166108fc8a83610fc2919061198f565b9081150290604051600060405180830381858888f19350505050158015610fed573d6000803e3d6000fd5b50600089111561103f573373ffffffffffffffffffffffffffffffffffffffff166108fc8a9081150290604051600060405180830381858888f1935050505015801561103d573d6000803e3d6000fd5b505b7fe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591426000808d8152602001908152602001600020600201546000808e81526020019081526020016000206003015485856040516110a09594939291906118e8565b60405180910390a15050505050505050505050565b600030468585856040516020016110d0959493929190611a2c565b6040516020818303038152906040528051906020012090509392505050565b60006020528060005260406000206000915090508060000154908060010154908060020154908060030154908060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060060154908060070160009054906101000a900460ff16908060070160019054906101000a900460ff16905089565b60006001808111156111ac576111ab61158d565b5b60008084815260200190815260200160002060070160009054906101000a900460ff1660018111156111e1576111e061158d565b5b036112235760008083815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16905061125c565b60008083815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690505b919050565b600061126e8484846110b5565b60405160200161127e9190611ae2565b6040516020818303038152906040528051906020012090509392505050565b600080fd5b6000819050919050565b6112b5816112a2565b81146112c057600080fd5b50565b6000813590506112d2816112ac565b92915050565b6000819050919050565b6112eb816112d8565b81146112f657600080fd5b50565b600081359050611308816112e2565b92915050565b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b60006113398261130e565b9050919050565b6113498161132e565b811461135457600080fd5b50565b60008135905061136681611340565b92915050565b6000806000606084860312156113855761138461129d565b5b6000611393868287016112c3565b93505060206113a4868287016112f9565b92505060406113b586828701611357565b9150509250925092565b6000602082840312156113d5576113d461129d565b5b60006113e3848285016112f9565b91505092915050565b600080604083850312156114035761140261129d565b5b6000611411858286016112f9565b9250506020611422858286016112f9565b9150509250929050565b600060ff82169050919050565b6114428161142c565b811461144d57600080fd5b50565b60008135905061145f81611439565b92915050565b60008060008060008060c087890312156114825761148161129d565b5b600061149089828a016112f9565b96505060206114a189828a016112f9565b95505060406114b289828a016112c3565b94505060606114c389828a01611450565b93505060806114d489828a016112f9565b92505060a06114e589828a016112f9565b9150509295509295509295565b60008060006060848603121561150b5761150a61129d565b5b6000611519868287016112f9565b935050602061152a86828701611357565b925050604061153b868287016112c3565b9150509250925092565b61154e816112d8565b82525050565b60006020820190506115696000830184611545565b92915050565b611578816112a2565b82525050565b6115878161132e565b82525050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052602160045260246000fd5b600281106115cd576115cc61158d565b5b50565b60008190506115de826115bc565b919050565b60006115ee826115d0565b9050919050565b6115fe816115e3565b82525050565b600481106116155761161461158d565b5b50565b600081905061162682611604565b919050565b600061163682611618565b9050919050565b6116468161162b565b82525050565b600061012082019050611662600083018c61156f565b61166f602083018b61156f565b61167c604083018a611545565b6116896060830189611545565b611696608083018861157e565b6116a360a083018761157e565b6116b060c083018661156f565b6116bd60e08301856115f5565b6116cb61010083018461163d565b9a9950505050505050505050565b600060c0820190506116ee600083018961156f565b6116fb602083018861156f565b6117086040830187611545565b611715606083018661157e565b611722608083018561157e565b61172f60a083018461156f565b979650505050505050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b6000611774826112a2565b915061177f836112a2565b92508282019050808211156117975761179661173a565b5b92915050565b60006080820190506117b2600083018761156f565b6117bf6020830186611545565b6117cc604083018561157e565b6117d9606083018461156f565b95945050505050565b6000819050919050565b6117fd6117f8826112d8565b6117e2565b82525050565b600061180f82846117ec565b60208201915081905092915050565b600081519050919050565b600081905092915050565b60005b83811015611852578082015181840152602081019050611837565b60008484015250505050565b60006118698261181e565b6118738185611829565b9350611883818560208601611834565b80840191505092915050565b600061189b828461185e565b915081905092915050565b6000815190506118b5816112e2565b92915050565b6000602082840312156118d1576118d061129d565b5b60006118df848285016118a6565b91505092915050565b600060a0820190506118fd600083018861156f565b61190a6020830187611545565b6119176040830186611545565b611924606083018561157e565b611931608083018461156f565b9695505050505050565b6119448161142c565b82525050565b600060808201905061195f6000830187611545565b61196c602083018661193b565b6119796040830185611545565b6119866060830184611545565b95945050505050565b600061199a826112a2565b91506119a5836112a2565b92508282039050818111156119bd576119bc61173a565b5b92915050565b60008160601b9050919050565b60006119db826119c3565b9050919050565b60006119ed826119d0565b9050919050565b611a05611a008261132e565b6119e2565b82525050565b6000819050919050565b611a26611a21826112a2565b611a0b565b82525050565b6000611a3882886119f4565b601482019150611a488287611a15565b602082019150611a5882866117ec565b602082019150611a6882856119f4565b601482019150611a788284611a15565b6020820191508190509695505050505050565b600081905092915050565b7f19457468657265756d205369676e6564204d6573736167653a0a333200000000600082015250565b6000611acc601c83611a8b565b9150611ad782611a96565b601c82019050919050565b6000611aed82611abf565b9150611af982846117ec565b6020820191508190509291505056fea2646970667358221220caf470494dff4b7692b4be7f09bee4853c87a846d7f610c774d14af885dd2fdc64736f6c63430008150033",
+}
+
+// RelayContractABI is the input ABI used to generate the binding from.
+// Deprecated: Use RelayContractMetaData.ABI instead.
+var RelayContractABI = RelayContractMetaData.ABI
+
+// RelayContractBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use RelayContractMetaData.Bin instead.
+var RelayContractBin = RelayContractMetaData.Bin
+
+// DeployRelayContract deploys a new Ethereum contract, binding an instance of RelayContract to it.
+func DeployRelayContract(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *RelayContract, error) {
+	parsed, err := RelayContractMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(RelayContractBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &RelayContract{RelayContractCaller: RelayContractCaller{contract: contract}, RelayContractTransactor: RelayContractTransactor{contract: contract}, RelayContractFilterer: RelayContractFilterer{contract: contract}}, nil
+}
+
+// RelayContract is an auto generated Go binding around an Ethereum contract.
+type RelayContract struct {
+	RelayContractCaller     // Read-only binding to the contract
+	RelayContractTransactor // Write-only binding to the contract
+	RelayContractFilterer   // Log filterer for contract events
+}
+
+// RelayContractCaller is an auto generated read-only Go binding around an Ethereum contract.
+type RelayContractCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RelayContractTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type RelayContractTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RelayContractFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type RelayContractFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RelayContractSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type RelayContractSession struct {
+	Contract     *RelayContract    // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// RelayContractCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type RelayContractCallerSession struct {
+	Contract *RelayContractCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts        // Call options to use throughout this session
+}
+
+// RelayContractTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type RelayContractTransactorSession struct {
+	Contract     *RelayContractTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts        // Transaction auth options to use throughout this session
+}
+
+// RelayContractRaw is an auto generated low-level Go binding around an Ethereum contract.
+type RelayContractRaw struct {
+	Contract *RelayContract // Generic contract binding to access the raw methods on
+}
+
+// RelayContractCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type RelayContractCallerRaw struct {
+	Contract *RelayContractCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// RelayContractTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type RelayContractTransactorRaw struct {
+	Contract *RelayContractTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewRelayContract creates a new instance of RelayContract, bound to a specific deployed contract.
+func NewRelayContract(address common.Address, backend bind.ContractBackend) (*RelayContract, error) {
+	contract, err := bindRelayContract(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContract{RelayContractCaller: RelayContractCaller{contract: contract}, RelayContractTransactor: RelayContractTransactor{contract: contract}, RelayContractFilterer: RelayContractFilterer{contract: contract}}, nil
+}
+
+// NewRelayContractCaller creates a new read-only instance of RelayContract, bound to a specific deployed contract.
+func NewRelayContractCaller(address common.Address, caller bind.ContractCaller) (*RelayContractCaller, error) {
+	contract, err := bindRelayContract(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractCaller{contract: contract}, nil
+}
+
+// NewRelayContractTransactor creates a new write-only instance of RelayContract, bound to a specific deployed contract.
+func NewRelayContractTransactor(address common.Address, transactor bind.ContractTransactor) (*RelayContractTransactor, error) {
+	contract, err := bindRelayContract(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractTransactor{contract: contract}, nil
+}
+
+// NewRelayContractFilterer creates a new log filterer instance of RelayContract, bound to a specific deployed contract.
+func NewRelayContractFilterer(address common.Address, filterer bind.ContractFilterer) (*RelayContractFilterer, error) {
+	contract, err := bindRelayContract(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractFilterer{contract: contract}, nil
+}
+
+// bindRelayContract binds a generic wrapper to an already deployed contract.
+func bindRelayContract(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := RelayContractMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_RelayContract *RelayContractRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _RelayContract.Contract.RelayContractCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_RelayContract *RelayContractRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RelayContract.Contract.RelayContractTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_RelayContract *RelayContractRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _RelayContract.Contract.RelayContractTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_RelayContract *RelayContractCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _RelayContract.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_RelayContract *RelayContractTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _RelayContract.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_RelayContract *RelayContractTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _RelayContract.Contract.contract.Transact(opts, method, params...)
+}
+
+// RelayHash is a free data retrieval call binding the contract method 0xdbcbb0c7.
+//
+// Solidity: function relayHash(bytes32 secretHash, address relayer, uint256 fee) view returns(bytes32)
+func (_RelayContract *RelayContractCaller) RelayHash(opts *bind.CallOpts, secretHash [32]byte, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _RelayContract.contract.Call(opts, &out, "relayHash", secretHash, relayer, fee)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// RelayHash is a free data retrieval call binding the contract method 0xdbcbb0c7.
+//
+// Solidity: function relayHash(bytes32 secretHash, address relayer, uint256 fee) view returns(bytes32)
+func (_RelayContract *RelayContractSession) RelayHash(secretHash [32]byte, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	return _RelayContract.Contract.RelayHash(&_RelayContract.CallOpts, secretHash, relayer, fee)
+}
+
+// RelayHash is a free data retrieval call binding the contract method 0xdbcbb0c7.
+//
+// Solidity: function relayHash(bytes32 secretHash, address relayer, uint256 fee) view returns(bytes32)
+func (_RelayContract *RelayContractCallerSession) RelayHash(secretHash [32]byte, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	return _RelayContract.Contract.RelayHash(&_RelayContract.CallOpts, secretHash, relayer, fee)
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state)
+func (_RelayContract *RelayContractCaller) Swaps(opts *bind.CallOpts, arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+}, error) {
+	var out []interface{}
+	err := _RelayContract.contract.Call(opts, &out, "swaps", arg0)
+
+	outstruct := new(struct {
+		InitTimestamp *big.Int
+		RefundTime    *big.Int
+		SecretHash    [32]byte
+		Secret        [32]byte
+		Initiator     common.Address
+		Participant   common.Address
+		Value         *big.Int
+		Kind          uint8
+		State         uint8
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.InitTimestamp = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.RefundTime = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.SecretHash = *abi.ConvertType(out[2], new([32]byte)).(*[32]byte)
+	outstruct.Secret = *abi.ConvertType(out[3], new([32]byte)).(*[32]byte)
+	outstruct.Initiator = *abi.ConvertType(out[4], new(common.Address)).(*common.Address)
+	outstruct.Participant = *abi.ConvertType(out[5], new(common.Address)).(*common.Address)
+	outstruct.Value = *abi.ConvertType(out[6], new(*big.Int)).(**big.Int)
+	outstruct.Kind = *abi.ConvertType(out[7], new(uint8)).(*uint8)
+	outstruct.State = *abi.ConvertType(out[8], new(uint8)).(*uint8)
+
+	return *outstruct, err
+
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state)
+func (_RelayContract *RelayContractSession) Swaps(arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+}, error) {
+	return _RelayContract.Contract.Swaps(&_RelayContract.CallOpts, arg0)
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state)
+func (_RelayContract *RelayContractCallerSession) Swaps(arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+}, error) {
+	return _RelayContract.Contract.Swaps(&_RelayContract.CallOpts, arg0)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0xae052147.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant) payable returns()
+func (_RelayContract *RelayContractTransactor) Initiate(opts *bind.TransactOpts, refundTime *big.Int, secretHash [32]byte, participant common.Address) (*types.Transaction, error) {
+	return _RelayContract.contract.Transact(opts, "initiate", refundTime, secretHash, participant)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0xae052147.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant) payable returns()
+func (_RelayContract *RelayContractSession) Initiate(refundTime *big.Int, secretHash [32]byte, participant common.Address) (*types.Transaction, error) {
+	return _RelayContract.Contract.Initiate(&_RelayContract.TransactOpts, refundTime, secretHash, participant)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0xae052147.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant) payable returns()
+func (_RelayContract *RelayContractTransactorSession) Initiate(refundTime *big.Int, secretHash [32]byte, participant common.Address) (*types.Transaction, error) {
+	return _RelayContract.Contract.Initiate(&_RelayContract.TransactOpts, refundTime, secretHash, participant)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x1aa02853.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator) payable returns()
+func (_RelayContract *RelayContractTransactor) Participate(opts *bind.TransactOpts, refundTime *big.Int, secretHash [32]byte, initiator common.Address) (*types.Transaction, error) {
+	return _RelayContract.contract.Transact(opts, "participate", refundTime, secretHash, initiator)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x1aa02853.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator) payable returns()
+func (_RelayContract *RelayContractSession) Participate(refundTime *big.Int, secretHash [32]byte, initiator common.Address) (*types.Transaction, error) {
+	return _RelayContract.Contract.Participate(&_RelayContract.TransactOpts, refundTime, secretHash, initiator)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x1aa02853.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator) payable returns()
+func (_RelayContract *RelayContractTransactorSession) Participate(refundTime *big.Int, secretHash [32]byte, initiator common.Address) (*types.Transaction, error) {
+	return _RelayContract.Contract.Participate(&_RelayContract.TransactOpts, refundTime, secretHash, initiator)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_RelayContract *RelayContractTransactor) Redeem(opts *bind.TransactOpts, secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _RelayContract.contract.Transact(opts, "redeem", secret, secretHash)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_RelayContract *RelayContractSession) Redeem(secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _RelayContract.Contract.Redeem(&_RelayContract.TransactOpts, secret, secretHash)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_RelayContract *RelayContractTransactorSession) Redeem(secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _RelayContract.Contract.Redeem(&_RelayContract.TransactOpts, secret, secretHash)
+}
+
+// RedeemFor is a paid mutator transaction binding the contract method 0xd91ad122.
+//
+// Solidity: function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s) returns()
+func (_RelayContract *RelayContractTransactor) RedeemFor(opts *bind.TransactOpts, secret [32]byte, secretHash [32]byte, fee *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _RelayContract.contract.Transact(opts, "redeemFor", secret, secretHash, fee, v, r, s)
+}
+
+// RedeemFor is a paid mutator transaction binding the contract method 0xd91ad122.
+//
+// Solidity: function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s) returns()
+func (_RelayContract *RelayContractSession) RedeemFor(secret [32]byte, secretHash [32]byte, fee *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _RelayContract.Contract.RedeemFor(&_RelayContract.TransactOpts, secret, secretHash, fee, v, r, s)
+}
+
+// RedeemFor is a paid mutator transaction binding the contract method 0xd91ad122.
+//
+// Solidity: function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s) returns()
+func (_RelayContract *RelayContractTransactorSession) RedeemFor(secret [32]byte, secretHash [32]byte, fee *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _RelayContract.Contract.RedeemFor(&_RelayContract.TransactOpts, secret, secretHash, fee, v, r, s)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_RelayContract *RelayContractTransactor) Refund(opts *bind.TransactOpts, secretHash [32]byte) (*types.Transaction, error) {
+	return _RelayContract.contract.Transact(opts, "refund", secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_RelayContract *RelayContractSession) Refund(secretHash [32]byte) (*types.Transaction, error) {
+	return _RelayContract.Contract.Refund(&_RelayContract.TransactOpts, secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_RelayContract *RelayContractTransactorSession) Refund(secretHash [32]byte) (*types.Transaction, error) {
+	return _RelayContract.Contract.Refund(&_RelayContract.TransactOpts, secretHash)
+}
+
+// RelayContractInitiatedIterator is returned from FilterInitiated and is used to iterate over the raw logs and unpacked data for Initiated events raised by the RelayContract contract.
+type RelayContractInitiatedIterator struct {
+	Event *RelayContractInitiated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RelayContractInitiatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RelayContractInitiated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RelayContractInitiated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RelayContractInitiatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RelayContractInitiatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RelayContractInitiated represents a Initiated event raised by the RelayContract contract.
+type RelayContractInitiated struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterInitiated is a free log retrieval operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_RelayContract *RelayContractFilterer) FilterInitiated(opts *bind.FilterOpts) (*RelayContractInitiatedIterator, error) {
+
+	logs, sub, err := _RelayContract.contract.FilterLogs(opts, "Initiated")
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractInitiatedIterator{contract: _RelayContract.contract, event: "Initiated", logs: logs, sub: sub}, nil
+}
+
+// WatchInitiated is a free log subscription operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_RelayContract *RelayContractFilterer) WatchInitiated(opts *bind.WatchOpts, sink chan<- *RelayContractInitiated) (event.Subscription, error) {
+
+	logs, sub, err := _RelayContract.contract.WatchLogs(opts, "Initiated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RelayContractInitiated)
+				if err := _RelayContract.contract.UnpackLog(event, "Initiated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseInitiated is a log parse operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_RelayContract *RelayContractFilterer) ParseInitiated(log types.Log) (*RelayContractInitiated, error) {
+	event := new(RelayContractInitiated)
+	if err := _RelayContract.contract.UnpackLog(event, "Initiated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RelayContractParticipatedIterator is returned from FilterParticipated and is used to iterate over the raw logs and unpacked data for Participated events raised by the RelayContract contract.
+type RelayContractParticipatedIterator struct {
+	Event *RelayContractParticipated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RelayContractParticipatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RelayContractParticipated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RelayContractParticipated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RelayContractParticipatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RelayContractParticipatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RelayContractParticipated represents a Participated event raised by the RelayContract contract.
+type RelayContractParticipated struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterParticipated is a free log retrieval operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_RelayContract *RelayContractFilterer) FilterParticipated(opts *bind.FilterOpts) (*RelayContractParticipatedIterator, error) {
+
+	logs, sub, err := _RelayContract.contract.FilterLogs(opts, "Participated")
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractParticipatedIterator{contract: _RelayContract.contract, event: "Participated", logs: logs, sub: sub}, nil
+}
+
+// WatchParticipated is a free log subscription operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_RelayContract *RelayContractFilterer) WatchParticipated(opts *bind.WatchOpts, sink chan<- *RelayContractParticipated) (event.Subscription, error) {
+
+	logs, sub, err := _RelayContract.contract.WatchLogs(opts, "Participated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RelayContractParticipated)
+				if err := _RelayContract.contract.UnpackLog(event, "Participated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseParticipated is a log parse operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_RelayContract *RelayContractFilterer) ParseParticipated(log types.Log) (*RelayContractParticipated, error) {
+	event := new(RelayContractParticipated)
+	if err := _RelayContract.contract.UnpackLog(event, "Participated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RelayContractRedeemedIterator is returned from FilterRedeemed and is used to iterate over the raw logs and unpacked data for Redeemed events raised by the RelayContract contract.
+type RelayContractRedeemedIterator struct {
+	Event *RelayContractRedeemed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RelayContractRedeemedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RelayContractRedeemed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RelayContractRedeemed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RelayContractRedeemedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RelayContractRedeemedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RelayContractRedeemed represents a Redeemed event raised by the RelayContract contract.
+type RelayContractRedeemed struct {
+	RedeemTime *big.Int
+	SecretHash [32]byte
+	Secret     [32]byte
+	Redeemer   common.Address
+	Value      *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterRedeemed is a free log retrieval operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_RelayContract *RelayContractFilterer) FilterRedeemed(opts *bind.FilterOpts) (*RelayContractRedeemedIterator, error) {
+
+	logs, sub, err := _RelayContract.contract.FilterLogs(opts, "Redeemed")
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractRedeemedIterator{contract: _RelayContract.contract, event: "Redeemed", logs: logs, sub: sub}, nil
+}
+
+// WatchRedeemed is a free log subscription operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_RelayContract *RelayContractFilterer) WatchRedeemed(opts *bind.WatchOpts, sink chan<- *RelayContractRedeemed) (event.Subscription, error) {
+
+	logs, sub, err := _RelayContract.contract.WatchLogs(opts, "Redeemed")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RelayContractRedeemed)
+				if err := _RelayContract.contract.UnpackLog(event, "Redeemed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRedeemed is a log parse operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_RelayContract *RelayContractFilterer) ParseRedeemed(log types.Log) (*RelayContractRedeemed, error) {
+	event := new(RelayContractRedeemed)
+	if err := _RelayContract.contract.UnpackLog(event, "Redeemed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RelayContractRefundedIterator is returned from FilterRefunded and is used to iterate over the raw logs and unpacked data for Refunded events raised by the RelayContract contract.
+type RelayContractRefundedIterator struct {
+	Event *RelayContractRefunded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RelayContractRefundedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RelayContractRefunded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RelayContractRefunded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RelayContractRefundedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RelayContractRefundedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RelayContractRefunded represents a Refunded event raised by the RelayContract contract.
+type RelayContractRefunded struct {
+	RefundTime *big.Int
+	SecretHash [32]byte
+	Refunder   common.Address
+	Value      *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterRefunded is a free log retrieval operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_RelayContract *RelayContractFilterer) FilterRefunded(opts *bind.FilterOpts) (*RelayContractRefundedIterator, error) {
+
+	logs, sub, err := _RelayContract.contract.FilterLogs(opts, "Refunded")
+	if err != nil {
+		return nil, err
+	}
+	return &RelayContractRefundedIterator{contract: _RelayContract.contract, event: "Refunded", logs: logs, sub: sub}, nil
+}
+
+// WatchRefunded is a free log subscription operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_RelayContract *RelayContractFilterer) WatchRefunded(opts *bind.WatchOpts, sink chan<- *RelayContractRefunded) (event.Subscription, error) {
+
+	logs, sub, err := _RelayContract.contract.WatchLogs(opts, "Refunded")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RelayContractRefunded)
+				if err := _RelayContract.contract.UnpackLog(event, "Refunded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRefunded is a log parse operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_RelayContract *RelayContractFilterer) ParseRefunded(log types.Log) (*RelayContractRefunded, error) {
+	event := new(RelayContractRefunded)
+	if err := _RelayContract.contract.UnpackLog(event, "Refunded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
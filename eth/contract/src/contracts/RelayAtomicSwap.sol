@@ -0,0 +1,65 @@
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+pragma solidity ^0.8.19;
+
+// Notes on security warnings:
+//  + the state of a swap is updated before any Ether is transferred,
+//    so neither the recipient nor the relayer can redeem twice.
+
+import "./AtomicSwap.sol";
+
+// RelayAtomicSwap is the AtomicSwap contract of which the swaps can also be
+// redeemed by a relayer, such that the recipient needs no Ether to pay for the gas.
+// Its storage, events and other calls are the same as the ones of the AtomicSwap contract.
+contract RelayAtomicSwap is AtomicSwap {
+    // redeemFor redeems a swap for its recipient, whoever submits it.
+    // The relayer submitting it is paid the fee out of the value of the swap,
+    // which the recipient authorises by signing the relay hash as an Ethereum signed message.
+    // No signature is needed without a fee.
+    function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s)
+        public
+        isRedeemable(secretHash, secret, recipientOf(secretHash))
+    {
+        address recipient = recipientOf(secretHash);
+        uint256 value = swaps[secretHash].value;
+        if (fee > 0) {
+            require(fee <= value);
+            require(recipient != address(0));
+            require(ecrecover(relayMessage(secretHash, msg.sender, fee), v, r, s) == recipient);
+        }
+
+        swaps[secretHash].state = State.Redeemed;
+        swaps[secretHash].secret = secret;
+
+        payable(recipient).transfer(value - fee);
+        if (fee > 0) {
+            payable(msg.sender).transfer(fee);
+        }
+
+        emit Redeemed(
+            block.timestamp,
+            swaps[secretHash].secretHash,
+            swaps[secretHash].secret,
+            recipient,
+            value
+        );
+    }
+
+    // relayHash is the hash the recipient of a swap signs to authorise
+    // the relayer to redeem it for the fee
+    function relayHash(bytes32 secretHash, address relayer, uint256 fee) public view returns (bytes32) {
+        return keccak256(abi.encodePacked(address(this), block.chainid, secretHash, relayer, fee));
+    }
+
+    function relayMessage(bytes32 secretHash, address relayer, uint256 fee) private view returns (bytes32) {
+        return keccak256(abi.encodePacked("\x19Ethereum Signed Message:\n32", relayHash(secretHash, relayer, fee)));
+    }
+
+    function recipientOf(bytes32 secretHash) private view returns (address) {
+        if (swaps[secretHash].kind == Kind.Participant) {
+            return swaps[secretHash].initiator;
+        }
+        return swaps[secretHash].participant;
+    }
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2017 Altcoin Exchange, Inc
+// Copyright (c) 2018 The Decred developers and Contributors
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+pragma solidity ^0.8.19;
+
+// Notes on security warnings:
+//  + block.timestamp is safe to use,
+//    given that our timestamp can tolerate a 30-second drift in time;
+//  + the state of a swap is updated before any token is transferred,
+//    so a malicious token can not reenter the contract to redeem or refund twice.
+
+import "./IERC20.sol";
+
+// TokenAtomicSwap is the AtomicSwap contract for ERC-20 tokens.
+// The tokens are transferred from the sender once it approved the contract to spend them.
+// Its events are the same as the ones of the AtomicSwap contract.
+contract TokenAtomicSwap {
+    enum Kind { Initiator, Participant }
+    enum State { Empty, Filled, Redeemed, Refunded }
+
+    struct Swap {
+        uint initTimestamp;
+        uint refundTime;
+        bytes32 secretHash;
+        bytes32 secret;
+        address initiator;
+        address participant;
+        uint256 value;
+        Kind kind;
+        State state;
+        address token;
+    }
+
+    mapping(bytes32 => Swap) public swaps;
+
+    event Refunded(
+        uint refundTime,
+        bytes32 secretHash,
+        address refunder,
+        uint256 value
+    );
+
+    event Redeemed(
+        uint redeemTime,
+        bytes32 secretHash,
+        bytes32 secret,
+        address redeemer,
+        uint256 value
+    );
+
+    event Participated(
+        uint initTimestamp,
+        uint refundTime,
+        bytes32 secretHash,
+        address initiator,
+        address participant,
+        uint256 value
+    );
+
+    event Initiated(
+        uint initTimestamp,
+        uint refundTime,
+        bytes32 secretHash,
+        address initiator,
+        address participant,
+        uint256 value
+    );
+
+    constructor() {}
+
+    modifier isRefundable(bytes32 secretHash, address refunder) {
+        require(swaps[secretHash].state == State.Filled);
+        if (swaps[secretHash].kind == Kind.Participant) {
+            require(swaps[secretHash].participant == refunder);
+        } else {
+            require(swaps[secretHash].initiator == refunder);
+        }
+        uint preRefundTimestamp = swaps[secretHash].initTimestamp;
+        preRefundTimestamp += swaps[secretHash].refundTime;
+        require(block.timestamp > preRefundTimestamp);
+        _;
+    }
+
+    modifier isRedeemable(bytes32 secretHash, bytes32 secret, address redeemer) {
+        require(swaps[secretHash].state == State.Filled);
+        if (swaps[secretHash].kind == Kind.Participant) {
+            require(swaps[secretHash].initiator == redeemer);
+        } else {
+            require(swaps[secretHash].participant == redeemer);
+        }
+        require(sha256(abi.encodePacked(secret)) == secretHash);
+        _;
+    }
+
+    modifier isNotInitiated(bytes32 secretHash) {
+        require(swaps[secretHash].state == State.Empty);
+        _;
+    }
+
+    // the functions locking tokens are not payable, refusing any Ether sent along
+    modifier hasNoNilValues(uint refundTime, address token, uint256 value) {
+        require(value > 0);
+        require(refundTime > 0);
+        require(token != address(0));
+        _;
+    }
+
+    function initiate(uint refundTime, bytes32 secretHash, address participant, address token, uint256 value)
+        public
+        hasNoNilValues(refundTime, token, value)
+        isNotInitiated(secretHash)
+    {
+        swaps[secretHash].initTimestamp = block.timestamp;
+        swaps[secretHash].refundTime = refundTime;
+        swaps[secretHash].secretHash = secretHash;
+        swaps[secretHash].initiator = msg.sender;
+        swaps[secretHash].participant = participant;
+        swaps[secretHash].value = value;
+        swaps[secretHash].kind = Kind.Initiator;
+        swaps[secretHash].state = State.Filled;
+        swaps[secretHash].token = token;
+        lockTokens(token, value);
+        emit Initiated(
+            block.timestamp,
+            refundTime,
+            secretHash,
+            msg.sender,
+            participant,
+            value
+        );
+    }
+
+    function participate(uint refundTime, bytes32 secretHash, address initiator, address token, uint256 value)
+        public
+        hasNoNilValues(refundTime, token, value)
+        isNotInitiated(secretHash)
+    {
+        swaps[secretHash].initTimestamp = block.timestamp;
+        swaps[secretHash].refundTime = refundTime;
+        swaps[secretHash].secretHash = secretHash;
+        swaps[secretHash].initiator = initiator;
+        swaps[secretHash].participant = msg.sender;
+        swaps[secretHash].value = value;
+        swaps[secretHash].kind = Kind.Participant;
+        swaps[secretHash].state = State.Filled;
+        swaps[secretHash].token = token;
+        lockTokens(token, value);
+        emit Participated(
+            block.timestamp,
+            refundTime,
+            secretHash,
+            initiator,
+            msg.sender,
+            value
+        );
+    }
+
+    function redeem(bytes32 secret, bytes32 secretHash)
+        public
+        isRedeemable(secretHash, secret, msg.sender)
+    {
+        swaps[secretHash].state = State.Redeemed;
+        swaps[secretHash].secret = secret;
+
+        sendTokens(swaps[secretHash].token, msg.sender, swaps[secretHash].value);
+
+        emit Redeemed(
+            block.timestamp,
+            swaps[secretHash].secretHash,
+            swaps[secretHash].secret,
+            msg.sender,
+            swaps[secretHash].value
+        );
+    }
+
+    function refund(bytes32 secretHash)
+        public
+        isRefundable(secretHash, msg.sender)
+    {
+        swaps[secretHash].state = State.Refunded;
+
+        sendTokens(swaps[secretHash].token, msg.sender, swaps[secretHash].value);
+
+        emit Refunded(
+            block.timestamp,
+            swaps[secretHash].secretHash,
+            msg.sender,
+            swaps[secretHash].value
+        );
+    }
+
+    // lockTokens transfers the approved tokens from the sender to this contract,
+    // tokens taking a fee on transfers are not supported
+    function lockTokens(address token, uint256 value) private {
+        uint256 balance = IERC20(token).balanceOf(address(this));
+        (bool success, bytes memory data) = token.call(
+            abi.encodeWithSelector(IERC20.transferFrom.selector, msg.sender, address(this), value)
+        );
+        // tokens such as USDT do not return a value
+        require(success && (data.length == 0 || abi.decode(data, (bool))));
+        require(IERC20(token).balanceOf(address(this)) - balance == value);
+    }
+
+    function sendTokens(address token, address to, uint256 value) private {
+        (bool success, bytes memory data) = token.call(
+            abi.encodeWithSelector(IERC20.transfer.selector, to, value)
+        );
+        require(success && (data.length == 0 || abi.decode(data, (bool))));
+    }
+}
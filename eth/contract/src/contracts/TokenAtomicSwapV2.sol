@@ -0,0 +1,263 @@
+// Copyright (c) 2017 Altcoin Exchange, Inc
+// Copyright (c) 2018 The Decred developers and Contributors
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+pragma solidity ^0.8.19;
+
+// Notes on security warnings:
+//  + block.timestamp is safe to use,
+//    given that our timestamp can tolerate a 30-second drift in time;
+//  + the state of a swap is updated before any token is transferred,
+//    so a malicious token can not reenter the contract to redeem or refund twice.
+
+import "./IERC20.sol";
+
+// TokenAtomicSwapV2 is the second version of the TokenAtomicSwap contract,
+// of which the swaps can also be redeemed by a relayer like the ones of the RelayAtomicSwap contract.
+// Its storage, events and other calls are the same as the ones of the TokenAtomicSwap contract.
+contract TokenAtomicSwapV2 {
+    enum Kind { Initiator, Participant }
+    enum State { Empty, Filled, Redeemed, Refunded }
+
+    struct Swap {
+        uint initTimestamp;
+        uint refundTime;
+        bytes32 secretHash;
+        bytes32 secret;
+        address initiator;
+        address participant;
+        uint256 value;
+        Kind kind;
+        State state;
+        address token;
+    }
+
+    mapping(bytes32 => Swap) public swaps;
+
+    event Refunded(
+        uint refundTime,
+        bytes32 secretHash,
+        address refunder,
+        uint256 value
+    );
+
+    event Redeemed(
+        uint redeemTime,
+        bytes32 secretHash,
+        bytes32 secret,
+        address redeemer,
+        uint256 value
+    );
+
+    event Participated(
+        uint initTimestamp,
+        uint refundTime,
+        bytes32 secretHash,
+        address initiator,
+        address participant,
+        uint256 value
+    );
+
+    event Initiated(
+        uint initTimestamp,
+        uint refundTime,
+        bytes32 secretHash,
+        address initiator,
+        address participant,
+        uint256 value
+    );
+
+    constructor() {}
+
+    modifier isRefundable(bytes32 secretHash, address refunder) {
+        require(swaps[secretHash].state == State.Filled);
+        if (swaps[secretHash].kind == Kind.Participant) {
+            require(swaps[secretHash].participant == refunder);
+        } else {
+            require(swaps[secretHash].initiator == refunder);
+        }
+        uint preRefundTimestamp = swaps[secretHash].initTimestamp;
+        preRefundTimestamp += swaps[secretHash].refundTime;
+        require(block.timestamp > preRefundTimestamp);
+        _;
+    }
+
+    modifier isRedeemable(bytes32 secretHash, bytes32 secret, address redeemer) {
+        require(swaps[secretHash].state == State.Filled);
+        if (swaps[secretHash].kind == Kind.Participant) {
+            require(swaps[secretHash].initiator == redeemer);
+        } else {
+            require(swaps[secretHash].participant == redeemer);
+        }
+        require(sha256(abi.encodePacked(secret)) == secretHash);
+        _;
+    }
+
+    modifier isNotInitiated(bytes32 secretHash) {
+        require(swaps[secretHash].state == State.Empty);
+        _;
+    }
+
+    // the functions locking tokens are not payable, refusing any Ether sent along
+    modifier hasNoNilValues(uint refundTime, address token, uint256 value) {
+        require(value > 0);
+        require(refundTime > 0);
+        require(token != address(0));
+        _;
+    }
+
+    function initiate(uint refundTime, bytes32 secretHash, address participant, address token, uint256 value)
+        public
+        hasNoNilValues(refundTime, token, value)
+        isNotInitiated(secretHash)
+    {
+        swaps[secretHash].initTimestamp = block.timestamp;
+        swaps[secretHash].refundTime = refundTime;
+        swaps[secretHash].secretHash = secretHash;
+        swaps[secretHash].initiator = msg.sender;
+        swaps[secretHash].participant = participant;
+        swaps[secretHash].value = value;
+        swaps[secretHash].kind = Kind.Initiator;
+        swaps[secretHash].state = State.Filled;
+        swaps[secretHash].token = token;
+        lockTokens(token, value);
+        emit Initiated(
+            block.timestamp,
+            refundTime,
+            secretHash,
+            msg.sender,
+            participant,
+            value
+        );
+    }
+
+    function participate(uint refundTime, bytes32 secretHash, address initiator, address token, uint256 value)
+        public
+        hasNoNilValues(refundTime, token, value)
+        isNotInitiated(secretHash)
+    {
+        swaps[secretHash].initTimestamp = block.timestamp;
+        swaps[secretHash].refundTime = refundTime;
+        swaps[secretHash].secretHash = secretHash;
+        swaps[secretHash].initiator = initiator;
+        swaps[secretHash].participant = msg.sender;
+        swaps[secretHash].value = value;
+        swaps[secretHash].kind = Kind.Participant;
+        swaps[secretHash].state = State.Filled;
+        swaps[secretHash].token = token;
+        lockTokens(token, value);
+        emit Participated(
+            block.timestamp,
+            refundTime,
+            secretHash,
+            initiator,
+            msg.sender,
+            value
+        );
+    }
+
+    function redeem(bytes32 secret, bytes32 secretHash)
+        public
+        isRedeemable(secretHash, secret, msg.sender)
+    {
+        swaps[secretHash].state = State.Redeemed;
+        swaps[secretHash].secret = secret;
+
+        sendTokens(swaps[secretHash].token, msg.sender, swaps[secretHash].value);
+
+        emit Redeemed(
+            block.timestamp,
+            swaps[secretHash].secretHash,
+            swaps[secretHash].secret,
+            msg.sender,
+            swaps[secretHash].value
+        );
+    }
+
+    // redeemFor redeems a swap for its recipient, whoever submits it,
+    // such that the recipient needs no Ether to pay for the gas.
+    // The relayer submitting it is paid the fee out of the tokens of the swap,
+    // which the recipient authorises by signing the relay hash as an Ethereum signed message.
+    // No signature is needed without a fee.
+    function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s)
+        public
+        isRedeemable(secretHash, secret, recipientOf(secretHash))
+    {
+        address recipient = recipientOf(secretHash);
+        uint256 value = swaps[secretHash].value;
+        if (fee > 0) {
+            require(fee <= value);
+            require(recipient != address(0));
+            require(ecrecover(relayMessage(secretHash, msg.sender, fee), v, r, s) == recipient);
+        }
+
+        swaps[secretHash].state = State.Redeemed;
+        swaps[secretHash].secret = secret;
+
+        sendTokens(swaps[secretHash].token, recipient, value - fee);
+        if (fee > 0) {
+            sendTokens(swaps[secretHash].token, msg.sender, fee);
+        }
+
+        emit Redeemed(
+            block.timestamp,
+            swaps[secretHash].secretHash,
+            swaps[secretHash].secret,
+            recipient,
+            value
+        );
+    }
+
+    function refund(bytes32 secretHash)
+        public
+        isRefundable(secretHash, msg.sender)
+    {
+        swaps[secretHash].state = State.Refunded;
+
+        sendTokens(swaps[secretHash].token, msg.sender, swaps[secretHash].value);
+
+        emit Refunded(
+            block.timestamp,
+            swaps[secretHash].secretHash,
+            msg.sender,
+            swaps[secretHash].value
+        );
+    }
+
+    // relayHash is the hash the recipient of a swap signs to authorise
+    // the relayer to redeem it for the fee
+    function relayHash(bytes32 secretHash, address relayer, uint256 fee) public view returns (bytes32) {
+        return keccak256(abi.encodePacked(address(this), block.chainid, secretHash, relayer, fee));
+    }
+
+    function relayMessage(bytes32 secretHash, address relayer, uint256 fee) private view returns (bytes32) {
+        return keccak256(abi.encodePacked("\x19Ethereum Signed Message:\n32", relayHash(secretHash, relayer, fee)));
+    }
+
+    function recipientOf(bytes32 secretHash) private view returns (address) {
+        if (swaps[secretHash].kind == Kind.Participant) {
+            return swaps[secretHash].initiator;
+        }
+        return swaps[secretHash].participant;
+    }
+
+    // lockTokens transfers the approved tokens from the sender to this contract,
+    // tokens taking a fee on transfers are not supported
+    function lockTokens(address token, uint256 value) private {
+        uint256 balance = IERC20(token).balanceOf(address(this));
+        (bool success, bytes memory data) = token.call(
+            abi.encodeWithSelector(IERC20.transferFrom.selector, msg.sender, address(this), value)
+        );
+        // tokens such as USDT do not return a value
+        require(success && (data.length == 0 || abi.decode(data, (bool))));
+        require(IERC20(token).balanceOf(address(this)) - balance == value);
+    }
+
+    function sendTokens(address token, address to, uint256 value) private {
+        (bool success, bytes memory data) = token.call(
+            abi.encodeWithSelector(IERC20.transfer.selector, to, value)
+        );
+        require(success && (data.length == 0 || abi.decode(data, (bool))));
+    }
+}
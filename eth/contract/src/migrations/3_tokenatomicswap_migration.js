@@ -0,0 +1,6 @@
+var TokenAtomicSwap = artifacts.require("TokenAtomicSwap");
+
+module.exports = function(deployer) {
+  // deployment steps
+  deployer.deploy(TokenAtomicSwap);
+};
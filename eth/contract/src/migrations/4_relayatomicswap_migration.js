@@ -0,0 +1,6 @@
+var RelayAtomicSwap = artifacts.require("RelayAtomicSwap");
+
+module.exports = function(deployer) {
+  // deployment steps
+  deployer.deploy(RelayAtomicSwap);
+};
@@ -0,0 +1,6 @@
+var TokenAtomicSwapV2 = artifacts.require("TokenAtomicSwapV2");
+
+module.exports = function(deployer) {
+  // deployment steps
+  deployer.deploy(TokenAtomicSwapV2);
+};
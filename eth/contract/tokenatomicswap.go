@@ -0,0 +1,925 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// TokenContractMetaData contains all meta data concerning the TokenContract contract.
+var TokenContractMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Initiated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Participated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"redeemTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"redeemer\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Redeemed\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"refunder\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Refunded\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"initiate\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"participate\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"}],\"name\":\"redeem\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"}],\"name\":\"refund\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"swaps\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"enumTokenAtomicSwap.Kind\",\"name\":\"kind\",\"type\":\"uint8\"},{\"internalType\":\"enumTokenAtomicSwap.State\",\"name\":\"state\",\"type\":\"uint8\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561001057600080fd5b50611808806100206000396000f3fe608060405234801561001057600080fd5b50600436106100575760003560e01c80630103b16b1461005c57806315601f4f146100785780637249fbb614610094578063b31597ad146100b0578063eb84e7f2146100cc575b600080fd5b610076600480360381019061007191906111aa565b610105565b005b610092600480360381019061008d91906111aa565b6103e5565b005b6100ae60048036038101906100a99190611225565b6106c5565b005b6100ca60048036038101906100c59190611252565b610990565b005b6100e660048036038101906100e19190611225565b610cbf565b6040516100fc9a9998979695949392919061137e565b60405180910390f35b8482826000811161011557600080fd5b6000831161012257600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff160361015b57600080fd5b86600060038111156101705761016f6112bf565b5b60008083815260200190815260200160002060070160019054906101000a900460ff1660038111156101a5576101a46112bf565b5b146101af57600080fd5b426000808a815260200190815260200160002060000181905550886000808a815260200190815260200160002060010181905550876000808a815260200190815260200160002060020181905550866000808a815260200190815260200160002060040160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550336000808a815260200190815260200160002060050160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550846000808a81526020019081526020016000206006018190555060016000808a815260200190815260200160002060070160006101000a81548160ff021916908360018111156102f8576102f76112bf565b5b021790555060016000808a815260200190815260200160002060070160016101000a81548160ff02191690836003811115610336576103356112bf565b5b0217905550856000808a815260200190815260200160002060070160026101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055506103998686610d8d565b7fe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4428a8a8a338a6040516103d29695949392919061141a565b60405180910390a1505050505050505050565b848282600081116103f557600080fd5b6000831161040257600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff160361043b57600080fd5b86600060038111156104505761044f6112bf565b5b60008083815260200190815260200160002060070160019054906101000a900460ff166003811115610485576104846112bf565b5b1461048f57600080fd5b426000808a815260200190815260200160002060000181905550886000808a815260200190815260200160002060010181905550876000808a815260200190815260200160002060020181905550336000808a815260200190815260200160002060040160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550866000808a815260200190815260200160002060050160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550846000808a81526020019081526020016000206006018190555060008060008a815260200190815260200160002060070160006101000a81548160ff021916908360018111156105d8576105d76112bf565b5b021790555060016000808a815260200190815260200160002060070160016101000a81548160ff02191690836003811115610616576106156112bf565b5b0217905550856000808a815260200190815260200160002060070160026101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055506106798686610d8d565b7f75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576428a8a338b8a6040516106b29695949392919061141a565b60405180910390a1505050505050505050565b8033600160038111156106db576106da6112bf565b5b60008084815260200190815260200160002060070160019054906101000a900460ff1660038111156107105761070f6112bf565b5b1461071a57600080fd5b60018081111561072d5761072c6112bf565b5b60008084815260200190815260200160002060070160009054906101000a900460ff166001811115610762576107616112bf565b5b036107d9578073ffffffffffffffffffffffffffffffffffffffff1660008084815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16146107d457600080fd5b610847565b8073ffffffffffffffffffffffffffffffffffffffff1660008084815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff161461084657600080fd5b5b6000806000848152602001908152602001600020600001549050600080848152602001908152602001600020600101548161088291906114aa565b905080421161089057600080fd5b600360008086815260200190815260200160002060070160016101000a81548160ff021916908360038111156108c9576108c86112bf565b5b021790555061092360008086815260200190815260200160002060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff163360008088815260200190815260200160002060060154610fbc565b7fadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c84260008087815260200190815260200160002060020154336000808981526020019081526020016000206006015460405161098294939291906114de565b60405180910390a150505050565b808233600160038111156109a7576109a66112bf565b5b60008085815260200190815260200160002060070160019054906101000a900460ff1660038111156109dc576109db6112bf565b5b146109e657600080fd5b6001808111156109f9576109f86112bf565b5b60008085815260200190815260200160002060070160009054906101000a900460ff166001811115610a2e57610a2d6112bf565b5b03610aa5578073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610aa057600080fd5b610b13565b8073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610b1257600080fd5b5b82600283604051602001610b279190611544565b604051602081830303815290604052604051610b4391906115d0565b602060405180830381855afa158015610b60573d6000803e3d6000fd5b5050506040513d601f19601f82011682018060405250810190610b8391906115fc565b14610b8d57600080fd5b600260008086815260200190815260200160002060070160016101000a81548160ff02191690836003811115610bc657610bc56112bf565b5b02179055508460008086815260200190815260200160002060030181905550610c3a60008086815260200190815260200160002060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff163360008088815260200190815260200160002060060154610fbc565b7fe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591426000808781526020019081526020016000206002015460008088815260200190815260200160002060030154336000808a815260200190815260200160002060060154604051610cb0959493929190611629565b60405180910390a15050505050565b60006020528060005260406000206000915090508060000154908060010154908060020154908060030154908060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060060154908060070160009054906101000a900460ff16908060070160019054906101000a900460ff16908060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690508a565b60008273ffffffffffffffffffffffffffffffffffffffff166370a08231306040518263ffffffff1660e01b8152600401610dc8919061167c565b602060405180830381865afa158015610de5573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610e0991906116ac565b90506000808473ffffffffffffffffffffffffffffffffffffffff166323b872dd60e01b333087604051602401610e42939291906116d9565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff8381831617835250505050604051610eac91906115d0565b6000604051808303816000865af19150503d8060008114610ee9576040519150601f19603f3d011682016040523d82523d6000602084013e610eee565b606091505b5091509150818015610f1c5750600081511480610f1b575080806020019051810190610f1a9190611748565b5b5b610f2557600080fd5b83838673ffffffffffffffffffffffffffffffffffffffff166370a08231306040518263ffffffff1660e01b8152600401610f60919061167c565b602060405180830381865afa158015610f7d573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610fa191906116ac565b610fab9190611775565b14610fb557600080fd5b5050505050565b6000808473ffffffffffffffffffffffffffffffffffffffff1663a9059cbb60e01b8585604051602401610ff19291906117a9565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff838183161783525050505060405161105b91906115d0565b6000604051808303816000865af19150503d8060008114611098576040519150601f19603f3d011682016040523d82523d6000602084013e61109d565b606091505b50915091508180156110cb57506000815114806110ca5750808060200190518101906110c99190611748565b5b5b6110d457600080fd5b5050505050565b600080fd5b6000819050919050565b6110f3816110e0565b81146110fe57600080fd5b50565b600081359050611110816110ea565b92915050565b6000819050919050565b61112981611116565b811461113457600080fd5b50565b60008135905061114681611120565b92915050565b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b60006111778261114c565b9050919050565b6111878161116c565b811461119257600080fd5b50565b6000813590506111a48161117e565b92915050565b600080600080600060a086880312156111c6576111c56110db565b5b60006111d488828901611101565b95505060206111e588828901611137565b94505060406111f688828901611195565b935050606061120788828901611195565b925050608061121888828901611101565b9150509295509295909350565b60006020828403121561123b5761123a6110db565b5b600061124984828501611137565b91505092915050565b60008060408385031215611269576112686110db565b5b600061127785828601611137565b925050602061128885828601611137565b9150509250929050565b61129b816110e0565b82525050565b6112aa81611116565b82525050565b6112b98161116c565b82525050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052602160045260246000fd5b600281106112ff576112fe6112bf565b5b50565b6000819050611310826112ee565b919050565b600061132082611302565b9050919050565b61133081611315565b82525050565b60048110611347576113466112bf565b5b50565b600081905061135882611336565b919050565b60006113688261134a565b9050919050565b6113788161135d565b82525050565b600061014082019050611394600083018d611292565b6113a1602083018c611292565b6113ae604083018b6112a1565b6113bb606083018a6112a1565b6113c860808301896112b0565b6113d560a08301886112b0565b6113e260c0830187611292565b6113ef60e0830186611327565b6113fd61010083018561136f565b61140b6101208301846112b0565b9b9a5050505050505050505050565b600060c08201905061142f6000830189611292565b61143c6020830188611292565b61144960408301876112a1565b61145660608301866112b0565b61146360808301856112b0565b61147060a0830184611292565b979650505050505050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b60006114b5826110e0565b91506114c0836110e0565b92508282019050808211156114d8576114d761147b565b5b92915050565b60006080820190506114f36000830187611292565b61150060208301866112a1565b61150d60408301856112b0565b61151a6060830184611292565b95945050505050565b6000819050919050565b61153e61153982611116565b611523565b82525050565b6000611550828461152d565b60208201915081905092915050565b600081519050919050565b600081905092915050565b60005b83811015611593578082015181840152602081019050611578565b60008484015250505050565b60006115aa8261155f565b6115b4818561156a565b93506115c4818560208601611575565b80840191505092915050565b60006115dc828461159f565b915081905092915050565b6000815190506115f681611120565b92915050565b600060208284031215611612576116116110db565b5b6000611620848285016115e7565b91505092915050565b600060a08201905061163e6000830188611292565b61164b60208301876112a1565b61165860408301866112a1565b61166560608301856112b0565b6116726080830184611292565b9695505050505050565b600060208201905061169160008301846112b0565b92915050565b6000815190506116a6816110ea565b92915050565b6000602082840312156116c2576116c16110db565b5b60006116d084828501611697565b91505092915050565b60006060820190506116ee60008301866112b0565b6116fb60208301856112b0565b6117086040830184611292565b949350505050565b60008115159050919050565b61172581611710565b811461173057600080fd5b50565b6000815190506117428161171c565b92915050565b60006020828403121561175e5761175d6110db565b5b600061176c84828501611733565b91505092915050565b6000611780826110e0565b915061178b836110e0565b92508282039050818111156117a3576117a261147b565b5b92915050565b60006040820190506117be60008301856112b0565b6117cb6020830184611292565b939250505056fea2646970667358221220541ccfffe7074306bdb3547c72831b9b69b2a90d3ca873b387a0f501461aaa2864736f6c63430008150033",
+}
+
+// TokenContractABI is the input ABI used to generate the binding from.
+// Deprecated: Use TokenContractMetaData.ABI instead.
+var TokenContractABI = TokenContractMetaData.ABI
+
+// TokenContractBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use TokenContractMetaData.Bin instead.
+var TokenContractBin = TokenContractMetaData.Bin
+
+// DeployTokenContract deploys a new Ethereum contract, binding an instance of TokenContract to it.
+func DeployTokenContract(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *TokenContract, error) {
+	parsed, err := TokenContractMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(TokenContractBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &TokenContract{TokenContractCaller: TokenContractCaller{contract: contract}, TokenContractTransactor: TokenContractTransactor{contract: contract}, TokenContractFilterer: TokenContractFilterer{contract: contract}}, nil
+}
+
+// TokenContract is an auto generated Go binding around an Ethereum contract.
+type TokenContract struct {
+	TokenContractCaller     // Read-only binding to the contract
+	TokenContractTransactor // Write-only binding to the contract
+	TokenContractFilterer   // Log filterer for contract events
+}
+
+// TokenContractCaller is an auto generated read-only Go binding around an Ethereum contract.
+type TokenContractCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TokenContractTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type TokenContractTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TokenContractFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type TokenContractFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TokenContractSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type TokenContractSession struct {
+	Contract     *TokenContract    // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// TokenContractCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type TokenContractCallerSession struct {
+	Contract *TokenContractCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts        // Call options to use throughout this session
+}
+
+// TokenContractTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type TokenContractTransactorSession struct {
+	Contract     *TokenContractTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts        // Transaction auth options to use throughout this session
+}
+
+// TokenContractRaw is an auto generated low-level Go binding around an Ethereum contract.
+type TokenContractRaw struct {
+	Contract *TokenContract // Generic contract binding to access the raw methods on
+}
+
+// TokenContractCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type TokenContractCallerRaw struct {
+	Contract *TokenContractCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// TokenContractTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type TokenContractTransactorRaw struct {
+	Contract *TokenContractTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewTokenContract creates a new instance of TokenContract, bound to a specific deployed contract.
+func NewTokenContract(address common.Address, backend bind.ContractBackend) (*TokenContract, error) {
+	contract, err := bindTokenContract(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContract{TokenContractCaller: TokenContractCaller{contract: contract}, TokenContractTransactor: TokenContractTransactor{contract: contract}, TokenContractFilterer: TokenContractFilterer{contract: contract}}, nil
+}
+
+// NewTokenContractCaller creates a new read-only instance of TokenContract, bound to a specific deployed contract.
+func NewTokenContractCaller(address common.Address, caller bind.ContractCaller) (*TokenContractCaller, error) {
+	contract, err := bindTokenContract(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractCaller{contract: contract}, nil
+}
+
+// NewTokenContractTransactor creates a new write-only instance of TokenContract, bound to a specific deployed contract.
+func NewTokenContractTransactor(address common.Address, transactor bind.ContractTransactor) (*TokenContractTransactor, error) {
+	contract, err := bindTokenContract(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractTransactor{contract: contract}, nil
+}
+
+// NewTokenContractFilterer creates a new log filterer instance of TokenContract, bound to a specific deployed contract.
+func NewTokenContractFilterer(address common.Address, filterer bind.ContractFilterer) (*TokenContractFilterer, error) {
+	contract, err := bindTokenContract(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractFilterer{contract: contract}, nil
+}
+
+// bindTokenContract binds a generic wrapper to an already deployed contract.
+func bindTokenContract(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := TokenContractMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_TokenContract *TokenContractRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _TokenContract.Contract.TokenContractCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_TokenContract *TokenContractRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _TokenContract.Contract.TokenContractTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_TokenContract *TokenContractRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _TokenContract.Contract.TokenContractTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_TokenContract *TokenContractCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _TokenContract.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_TokenContract *TokenContractTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _TokenContract.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_TokenContract *TokenContractTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _TokenContract.Contract.contract.Transact(opts, method, params...)
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state, address token)
+func (_TokenContract *TokenContractCaller) Swaps(opts *bind.CallOpts, arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+	Token         common.Address
+}, error) {
+	var out []interface{}
+	err := _TokenContract.contract.Call(opts, &out, "swaps", arg0)
+
+	outstruct := new(struct {
+		InitTimestamp *big.Int
+		RefundTime    *big.Int
+		SecretHash    [32]byte
+		Secret        [32]byte
+		Initiator     common.Address
+		Participant   common.Address
+		Value         *big.Int
+		Kind          uint8
+		State         uint8
+		Token         common.Address
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.InitTimestamp = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.RefundTime = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.SecretHash = *abi.ConvertType(out[2], new([32]byte)).(*[32]byte)
+	outstruct.Secret = *abi.ConvertType(out[3], new([32]byte)).(*[32]byte)
+	outstruct.Initiator = *abi.ConvertType(out[4], new(common.Address)).(*common.Address)
+	outstruct.Participant = *abi.ConvertType(out[5], new(common.Address)).(*common.Address)
+	outstruct.Value = *abi.ConvertType(out[6], new(*big.Int)).(**big.Int)
+	outstruct.Kind = *abi.ConvertType(out[7], new(uint8)).(*uint8)
+	outstruct.State = *abi.ConvertType(out[8], new(uint8)).(*uint8)
+	outstruct.Token = *abi.ConvertType(out[9], new(common.Address)).(*common.Address)
+
+	return *outstruct, err
+
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state, address token)
+func (_TokenContract *TokenContractSession) Swaps(arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+	Token         common.Address
+}, error) {
+	return _TokenContract.Contract.Swaps(&_TokenContract.CallOpts, arg0)
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state, address token)
+func (_TokenContract *TokenContractCallerSession) Swaps(arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+	Token         common.Address
+}, error) {
+	return _TokenContract.Contract.Swaps(&_TokenContract.CallOpts, arg0)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0x15601f4f.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant, address token, uint256 value) returns()
+func (_TokenContract *TokenContractTransactor) Initiate(opts *bind.TransactOpts, refundTime *big.Int, secretHash [32]byte, participant common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContract.contract.Transact(opts, "initiate", refundTime, secretHash, participant, token, value)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0x15601f4f.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant, address token, uint256 value) returns()
+func (_TokenContract *TokenContractSession) Initiate(refundTime *big.Int, secretHash [32]byte, participant common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContract.Contract.Initiate(&_TokenContract.TransactOpts, refundTime, secretHash, participant, token, value)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0x15601f4f.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant, address token, uint256 value) returns()
+func (_TokenContract *TokenContractTransactorSession) Initiate(refundTime *big.Int, secretHash [32]byte, participant common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContract.Contract.Initiate(&_TokenContract.TransactOpts, refundTime, secretHash, participant, token, value)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x0103b16b.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator, address token, uint256 value) returns()
+func (_TokenContract *TokenContractTransactor) Participate(opts *bind.TransactOpts, refundTime *big.Int, secretHash [32]byte, initiator common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContract.contract.Transact(opts, "participate", refundTime, secretHash, initiator, token, value)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x0103b16b.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator, address token, uint256 value) returns()
+func (_TokenContract *TokenContractSession) Participate(refundTime *big.Int, secretHash [32]byte, initiator common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContract.Contract.Participate(&_TokenContract.TransactOpts, refundTime, secretHash, initiator, token, value)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x0103b16b.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator, address token, uint256 value) returns()
+func (_TokenContract *TokenContractTransactorSession) Participate(refundTime *big.Int, secretHash [32]byte, initiator common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContract.Contract.Participate(&_TokenContract.TransactOpts, refundTime, secretHash, initiator, token, value)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_TokenContract *TokenContractTransactor) Redeem(opts *bind.TransactOpts, secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContract.contract.Transact(opts, "redeem", secret, secretHash)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_TokenContract *TokenContractSession) Redeem(secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContract.Contract.Redeem(&_TokenContract.TransactOpts, secret, secretHash)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_TokenContract *TokenContractTransactorSession) Redeem(secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContract.Contract.Redeem(&_TokenContract.TransactOpts, secret, secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_TokenContract *TokenContractTransactor) Refund(opts *bind.TransactOpts, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContract.contract.Transact(opts, "refund", secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_TokenContract *TokenContractSession) Refund(secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContract.Contract.Refund(&_TokenContract.TransactOpts, secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_TokenContract *TokenContractTransactorSession) Refund(secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContract.Contract.Refund(&_TokenContract.TransactOpts, secretHash)
+}
+
+// TokenContractInitiatedIterator is returned from FilterInitiated and is used to iterate over the raw logs and unpacked data for Initiated events raised by the TokenContract contract.
+type TokenContractInitiatedIterator struct {
+	Event *TokenContractInitiated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractInitiatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractInitiated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractInitiated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractInitiatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractInitiatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractInitiated represents a Initiated event raised by the TokenContract contract.
+type TokenContractInitiated struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterInitiated is a free log retrieval operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContract *TokenContractFilterer) FilterInitiated(opts *bind.FilterOpts) (*TokenContractInitiatedIterator, error) {
+
+	logs, sub, err := _TokenContract.contract.FilterLogs(opts, "Initiated")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractInitiatedIterator{contract: _TokenContract.contract, event: "Initiated", logs: logs, sub: sub}, nil
+}
+
+// WatchInitiated is a free log subscription operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContract *TokenContractFilterer) WatchInitiated(opts *bind.WatchOpts, sink chan<- *TokenContractInitiated) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContract.contract.WatchLogs(opts, "Initiated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractInitiated)
+				if err := _TokenContract.contract.UnpackLog(event, "Initiated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseInitiated is a log parse operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContract *TokenContractFilterer) ParseInitiated(log types.Log) (*TokenContractInitiated, error) {
+	event := new(TokenContractInitiated)
+	if err := _TokenContract.contract.UnpackLog(event, "Initiated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// TokenContractParticipatedIterator is returned from FilterParticipated and is used to iterate over the raw logs and unpacked data for Participated events raised by the TokenContract contract.
+type TokenContractParticipatedIterator struct {
+	Event *TokenContractParticipated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractParticipatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractParticipated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractParticipated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractParticipatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractParticipatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractParticipated represents a Participated event raised by the TokenContract contract.
+type TokenContractParticipated struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterParticipated is a free log retrieval operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContract *TokenContractFilterer) FilterParticipated(opts *bind.FilterOpts) (*TokenContractParticipatedIterator, error) {
+
+	logs, sub, err := _TokenContract.contract.FilterLogs(opts, "Participated")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractParticipatedIterator{contract: _TokenContract.contract, event: "Participated", logs: logs, sub: sub}, nil
+}
+
+// WatchParticipated is a free log subscription operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContract *TokenContractFilterer) WatchParticipated(opts *bind.WatchOpts, sink chan<- *TokenContractParticipated) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContract.contract.WatchLogs(opts, "Participated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractParticipated)
+				if err := _TokenContract.contract.UnpackLog(event, "Participated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseParticipated is a log parse operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContract *TokenContractFilterer) ParseParticipated(log types.Log) (*TokenContractParticipated, error) {
+	event := new(TokenContractParticipated)
+	if err := _TokenContract.contract.UnpackLog(event, "Participated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// TokenContractRedeemedIterator is returned from FilterRedeemed and is used to iterate over the raw logs and unpacked data for Redeemed events raised by the TokenContract contract.
+type TokenContractRedeemedIterator struct {
+	Event *TokenContractRedeemed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractRedeemedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractRedeemed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractRedeemed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractRedeemedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractRedeemedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractRedeemed represents a Redeemed event raised by the TokenContract contract.
+type TokenContractRedeemed struct {
+	RedeemTime *big.Int
+	SecretHash [32]byte
+	Secret     [32]byte
+	Redeemer   common.Address
+	Value      *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterRedeemed is a free log retrieval operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_TokenContract *TokenContractFilterer) FilterRedeemed(opts *bind.FilterOpts) (*TokenContractRedeemedIterator, error) {
+
+	logs, sub, err := _TokenContract.contract.FilterLogs(opts, "Redeemed")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractRedeemedIterator{contract: _TokenContract.contract, event: "Redeemed", logs: logs, sub: sub}, nil
+}
+
+// WatchRedeemed is a free log subscription operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_TokenContract *TokenContractFilterer) WatchRedeemed(opts *bind.WatchOpts, sink chan<- *TokenContractRedeemed) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContract.contract.WatchLogs(opts, "Redeemed")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractRedeemed)
+				if err := _TokenContract.contract.UnpackLog(event, "Redeemed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRedeemed is a log parse operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_TokenContract *TokenContractFilterer) ParseRedeemed(log types.Log) (*TokenContractRedeemed, error) {
+	event := new(TokenContractRedeemed)
+	if err := _TokenContract.contract.UnpackLog(event, "Redeemed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// TokenContractRefundedIterator is returned from FilterRefunded and is used to iterate over the raw logs and unpacked data for Refunded events raised by the TokenContract contract.
+type TokenContractRefundedIterator struct {
+	Event *TokenContractRefunded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractRefundedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractRefunded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractRefunded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractRefundedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractRefundedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractRefunded represents a Refunded event raised by the TokenContract contract.
+type TokenContractRefunded struct {
+	RefundTime *big.Int
+	SecretHash [32]byte
+	Refunder   common.Address
+	Value      *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterRefunded is a free log retrieval operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_TokenContract *TokenContractFilterer) FilterRefunded(opts *bind.FilterOpts) (*TokenContractRefundedIterator, error) {
+
+	logs, sub, err := _TokenContract.contract.FilterLogs(opts, "Refunded")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractRefundedIterator{contract: _TokenContract.contract, event: "Refunded", logs: logs, sub: sub}, nil
+}
+
+// WatchRefunded is a free log subscription operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_TokenContract *TokenContractFilterer) WatchRefunded(opts *bind.WatchOpts, sink chan<- *TokenContractRefunded) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContract.contract.WatchLogs(opts, "Refunded")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractRefunded)
+				if err := _TokenContract.contract.UnpackLog(event, "Refunded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRefunded is a log parse operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_TokenContract *TokenContractFilterer) ParseRefunded(log types.Log) (*TokenContractRefunded, error) {
+	event := new(TokenContractRefunded)
+	if err := _TokenContract.contract.UnpackLog(event, "Refunded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
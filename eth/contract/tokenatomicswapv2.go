@@ -0,0 +1,977 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// TokenContractV2MetaData contains all meta data concerning the TokenContractV2 contract.
+var TokenContractV2MetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Initiated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Participated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"redeemTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"redeemer\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Redeemed\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"refunder\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Refunded\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"initiate\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"participate\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"}],\"name\":\"redeem\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}],\"name\":\"redeemFor\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"}],\"name\":\"refund\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"relayer\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"relayHash\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"swaps\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"initTimestamp\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"refundTime\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"secretHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"secret\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"initiator\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"participant\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"enumTokenAtomicSwapV2.Kind\",\"name\":\"kind\",\"type\":\"uint8\"},{\"internalType\":\"enumTokenAtomicSwapV2.State\",\"name\":\"state\",\"type\":\"uint8\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561001057600080fd5b506120f6806100206000396000f3fe608060405234801561001057600080fd5b506004361061007d5760003560e01c8063b31597ad1161005b578063b31597ad146100d6578063d91ad122146100f2578063dbcbb0c71461010e578063eb84e7f21461013e5761007d565b80630103b16b1461008257806315601f4f1461009e5780637249fbb6146100ba575b600080fd5b61009c600480360381019061009791906117cb565b610177565b005b6100b860048036038101906100b391906117cb565b610457565b005b6100d460048036038101906100cf9190611846565b610737565b005b6100f060048036038101906100eb9190611873565b610a02565b005b61010c600480360381019061010791906118ec565b610d31565b005b61012860048036038101906101239190611979565b6111a0565b60405161013591906119db565b60405180910390f35b61015860048036038101906101539190611846565b6111da565b60405161016e9a99989796959493929190611ad3565b60405180910390f35b8482826000811161018757600080fd5b6000831161019457600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff16036101cd57600080fd5b86600060038111156101e2576101e1611a14565b5b60008083815260200190815260200160002060070160019054906101000a900460ff16600381111561021757610216611a14565b5b1461022157600080fd5b426000808a815260200190815260200160002060000181905550886000808a815260200190815260200160002060010181905550876000808a815260200190815260200160002060020181905550866000808a815260200190815260200160002060040160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550336000808a815260200190815260200160002060050160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550846000808a81526020019081526020016000206006018190555060016000808a815260200190815260200160002060070160006101000a81548160ff0219169083600181111561036a57610369611a14565b5b021790555060016000808a815260200190815260200160002060070160016101000a81548160ff021916908360038111156103a8576103a7611a14565b5b0217905550856000808a815260200190815260200160002060070160026101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff16021790555061040b86866112a8565b7fe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4428a8a8a338a60405161044496959493929190611b6f565b60405180910390a1505050505050505050565b8482826000811161046757600080fd5b6000831161047457600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff16036104ad57600080fd5b86600060038111156104c2576104c1611a14565b5b60008083815260200190815260200160002060070160019054906101000a900460ff1660038111156104f7576104f6611a14565b5b1461050157600080fd5b426000808a815260200190815260200160002060000181905550886000808a815260200190815260200160002060010181905550876000808a815260200190815260200160002060020181905550336000808a815260200190815260200160002060040160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550866000808a815260200190815260200160002060050160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550846000808a81526020019081526020016000206006018190555060008060008a815260200190815260200160002060070160006101000a81548160ff0219169083600181111561064a57610649611a14565b5b021790555060016000808a815260200190815260200160002060070160016101000a81548160ff0219169083600381111561068857610687611a14565b5b0217905550856000808a815260200190815260200160002060070160026101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055506106eb86866112a8565b7f75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576428a8a338b8a60405161072496959493929190611b6f565b60405180910390a1505050505050505050565b80336001600381111561074d5761074c611a14565b5b60008084815260200190815260200160002060070160019054906101000a900460ff16600381111561078257610781611a14565b5b1461078c57600080fd5b60018081111561079f5761079e611a14565b5b60008084815260200190815260200160002060070160009054906101000a900460ff1660018111156107d4576107d3611a14565b5b0361084b578073ffffffffffffffffffffffffffffffffffffffff1660008084815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff161461084657600080fd5b6108b9565b8073ffffffffffffffffffffffffffffffffffffffff1660008084815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16146108b857600080fd5b5b600080600084815260200190815260200160002060000154905060008084815260200190815260200160002060010154816108f49190611bff565b905080421161090257600080fd5b600360008086815260200190815260200160002060070160016101000a81548160ff0219169083600381111561093b5761093a611a14565b5b021790555061099560008086815260200190815260200160002060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff1633600080888152602001908152602001600020600601546114d7565b7fadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8426000808781526020019081526020016000206002015433600080898152602001908152602001600020600601546040516109f49493929190611c33565b60405180910390a150505050565b80823360016003811115610a1957610a18611a14565b5b60008085815260200190815260200160002060070160019054906101000a900460ff166003811115610a4e57610a4d611a14565b5b14610a5857600080fd5b600180811115610a6b57610a6a611a14565b5b60008085815260200190815260200160002060070160009054906101000a900460ff166001811115610aa057610a9f611a14565b5b03610b17578073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610b1257600080fd5b610b85565b8073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610b8457600080fd5b5b82600283604051602001610b999190611c99565b604051602081830303815290604052604051610bb59190611d25565b602060405180830381855afa158015610bd2573d6000803e3d6000fd5b5050506040513d601f19601f82011682018060405250810190610bf59190611d51565b14610bff57600080fd5b600260008086815260200190815260200160002060070160016101000a81548160ff02191690836003811115610c3857610c37611a14565b5b02179055508460008086815260200190815260200160002060030181905550610cac60008086815260200190815260200160002060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff1633600080888152602001908152602001600020600601546114d7565b7fe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591426000808781526020019081526020016000206002015460008088815260200190815260200160002060030154336000808a815260200190815260200160002060060154604051610d22959493929190611d7e565b60405180910390a15050505050565b8486610d3c876115f6565b60016003811115610d5057610d4f611a14565b5b60008085815260200190815260200160002060070160019054906101000a900460ff166003811115610d8557610d84611a14565b5b14610d8f57600080fd5b600180811115610da257610da1611a14565b5b60008085815260200190815260200160002060070160009054906101000a900460ff166001811115610dd757610dd6611a14565b5b03610e4e578073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610e4957600080fd5b610ebc565b8073ffffffffffffffffffffffffffffffffffffffff1660008085815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1614610ebb57600080fd5b5b82600283604051602001610ed09190611c99565b604051602081830303815290604052604051610eec9190611d25565b602060405180830381855afa158015610f09573d6000803e3d6000fd5b5050506040513d601f19601f82011682018060405250810190610f2c9190611d51565b14610f3657600080fd5b6000610f41896115f6565b905060008060008b8152602001908152602001600020600601549050600089111561103d5780891115610f7357600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff1603610fac57600080fd5b8173ffffffffffffffffffffffffffffffffffffffff166001610fd08c338d6116c0565b8a8a8a60405160008152602001604052604051610ff09493929190611de0565b6020604051602081039080840390855afa158015611012573d6000803e3d6000fd5b5050506020604051035173ffffffffffffffffffffffffffffffffffffffff161461103c57600080fd5b5b60026000808c815260200190815260200160002060070160016101000a81548160ff0219169083600381111561107657611075611a14565b5b02179055508a6000808c8152602001908152602001600020600301819055506110e06000808c815260200190815260200160002060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff16838b846110db9190611e25565b6114d7565b600089111561112a576111296000808c815260200190815260200160002060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff16338b6114d7565b5b7fe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591426000808d8152602001908152602001600020600201546000808e815260200190815260200160002060030154858560405161118b959493929190611d7e565b60405180910390a15050505050505050505050565b600030468585856040516020016111bb959493929190611ec2565b6040516020818303038152906040528051906020012090509392505050565b60006020528060005260406000206000915090508060000154908060010154908060020154908060030154908060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060060154908060070160009054906101000a900460ff16908060070160019054906101000a900460ff16908060070160029054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690508a565b60008273ffffffffffffffffffffffffffffffffffffffff166370a08231306040518263ffffffff1660e01b81526004016112e39190611f21565b602060405180830381865afa158015611300573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906113249190611f51565b90506000808473ffffffffffffffffffffffffffffffffffffffff166323b872dd60e01b33308760405160240161135d93929190611f7e565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506040516113c79190611d25565b6000604051808303816000865af19150503d8060008114611404576040519150601f19603f3d011682016040523d82523d6000602084013e611409565b606091505b509150915081801561143757506000815114806114365750808060200190518101906114359190611fed565b5b5b61144057600080fd5b83838673ffffffffffffffffffffffffffffffffffffffff166370a08231306040518263ffffffff1660e01b815260040161147b9190611f21565b602060405180830381865afa158015611498573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906114bc9190611f51565b6114c69190611e25565b146114d057600080fd5b5050505050565b6000808473ffffffffffffffffffffffffffffffffffffffff1663a9059cbb60e01b858560405160240161150c92919061201a565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506040516115769190611d25565b6000604051808303816000865af19150503d80600081146115b3576040519150601f19603f3d011682016040523d82523d6000602084013e6115b8565b606091505b50915091508180156115e657506000815114806115e55750808060200190518101906115e49190611fed565b5b5b6115ef57600080fd5b5050505050565b600060018081111561160b5761160a611a14565b5b60008084815260200190815260200160002060070160009054906101000a900460ff1660018111156116405761163f611a14565b5b036116825760008083815260200190815260200160002060040160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690506116bb565b60008083815260200190815260200160002060050160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690505b919050565b60006116cd8484846111a0565b6040516020016116dd919061209a565b6040516020818303038152906040528051906020012090509392505050565b600080fd5b6000819050919050565b61171481611701565b811461171f57600080fd5b50565b6000813590506117318161170b565b92915050565b6000819050919050565b61174a81611737565b811461175557600080fd5b50565b60008135905061176781611741565b92915050565b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b60006117988261176d565b9050919050565b6117a88161178d565b81146117b357600080fd5b50565b6000813590506117c58161179f565b92915050565b600080600080600060a086880312156117e7576117e66116fc565b5b60006117f588828901611722565b955050602061180688828901611758565b9450506040611817888289016117b6565b9350506060611828888289016117b6565b925050608061183988828901611722565b9150509295509295909350565b60006020828403121561185c5761185b6116fc565b5b600061186a84828501611758565b91505092915050565b6000806040838503121561188a576118896116fc565b5b600061189885828601611758565b92505060206118a985828601611758565b9150509250929050565b600060ff82169050919050565b6118c9816118b3565b81146118d457600080fd5b50565b6000813590506118e6816118c0565b92915050565b60008060008060008060c08789031215611909576119086116fc565b5b600061191789828a01611758565b965050602061192889828a01611758565b955050604061193989828a01611722565b945050606061194a89828a016118d7565b935050608061195b89828a01611758565b92505060a061196c89828a01611758565b9150509295509295509295565b600080600060608486031215611992576119916116fc565b5b60006119a086828701611758565b93505060206119b1868287016117b6565b92505060406119c286828701611722565b9150509250925092565b6119d581611737565b82525050565b60006020820190506119f060008301846119cc565b92915050565b6119ff81611701565b82525050565b611a0e8161178d565b82525050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052602160045260246000fd5b60028110611a5457611a53611a14565b5b50565b6000819050611a6582611a43565b919050565b6000611a7582611a57565b9050919050565b611a8581611a6a565b82525050565b60048110611a9c57611a9b611a14565b5b50565b6000819050611aad82611a8b565b919050565b6000611abd82611a9f565b9050919050565b611acd81611ab2565b82525050565b600061014082019050611ae9600083018d6119f6565b611af6602083018c6119f6565b611b03604083018b6119cc565b611b10606083018a6119cc565b611b1d6080830189611a05565b611b2a60a0830188611a05565b611b3760c08301876119f6565b611b4460e0830186611a7c565b611b52610100830185611ac4565b611b60610120830184611a05565b9b9a5050505050505050505050565b600060c082019050611b8460008301896119f6565b611b9160208301886119f6565b611b9e60408301876119cc565b611bab6060830186611a05565b611bb86080830185611a05565b611bc560a08301846119f6565b979650505050505050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b6000611c0a82611701565b9150611c1583611701565b9250828201905080821115611c2d57611c2c611bd0565b5b92915050565b6000608082019050611c4860008301876119f6565b611c5560208301866119cc565b611c626040830185611a05565b611c6f60608301846119f6565b95945050505050565b6000819050919050565b611c93611c8e82611737565b611c78565b82525050565b6000611ca58284611c82565b60208201915081905092915050565b600081519050919050565b600081905092915050565b60005b83811015611ce8578082015181840152602081019050611ccd565b60008484015250505050565b6000611cff82611cb4565b611d098185611cbf565b9350611d19818560208601611cca565b80840191505092915050565b6000611d318284611cf4565b915081905092915050565b600081519050611d4b81611741565b92915050565b600060208284031215611d6757611d666116fc565b5b6000611d7584828501611d3c565b91505092915050565b600060a082019050611d9360008301886119f6565b611da060208301876119cc565b611dad60408301866119cc565b611dba6060830185611a05565b611dc760808301846119f6565b9695505050505050565b611dda816118b3565b82525050565b6000608082019050611df560008301876119cc565b611e026020830186611dd1565b611e0f60408301856119cc565b611e1c60608301846119cc565b95945050505050565b6000611e3082611701565b9150611e3b83611701565b9250828203905081811115611e5357611e52611bd0565b5b92915050565b60008160601b9050919050565b6000611e7182611e59565b9050919050565b6000611e8382611e66565b9050919050565b611e9b611e968261178d565b611e78565b82525050565b6000819050919050565b611ebc611eb782611701565b611ea1565b82525050565b6000611ece8288611e8a565b601482019150611ede8287611eab565b602082019150611eee8286611c82565b602082019150611efe8285611e8a565b601482019150611f0e8284611eab565b6020820191508190509695505050505050565b6000602082019050611f366000830184611a05565b92915050565b600081519050611f4b8161170b565b92915050565b600060208284031215611f6757611f666116fc565b5b6000611f7584828501611f3c565b91505092915050565b6000606082019050611f936000830186611a05565b611fa06020830185611a05565b611fad60408301846119f6565b949350505050565b60008115159050919050565b611fca81611fb5565b8114611fd557600080fd5b50565b600081519050611fe781611fc1565b92915050565b600060208284031215612003576120026116fc565b5b600061201184828501611fd8565b91505092915050565b600060408201905061202f6000830185611a05565b61203c60208301846119f6565b9392505050565b600081905092915050565b7f19457468657265756d205369676e6564204d6573736167653a0a333200000000600082015250565b6000612084601c83612043565b915061208f8261204e565b601c82019050919050565b60006120a582612077565b91506120b18284611c82565b6020820191508190509291505056fea26469706673582212208cb0593f2117ef7fbd8923a3c5ff9d5a9336fcd6ebeae004bfacc06815c012a464736f6c63430008150033",
+}
+
+// TokenContractV2ABI is the input ABI used to generate the binding from.
+// Deprecated: Use TokenContractV2MetaData.ABI instead.
+var TokenContractV2ABI = TokenContractV2MetaData.ABI
+
+// TokenContractV2Bin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use TokenContractV2MetaData.Bin instead.
+var TokenContractV2Bin = TokenContractV2MetaData.Bin
+
+// DeployTokenContractV2 deploys a new Ethereum contract, binding an instance of TokenContractV2 to it.
+func DeployTokenContractV2(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *TokenContractV2, error) {
+	parsed, err := TokenContractV2MetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(TokenContractV2Bin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &TokenContractV2{TokenContractV2Caller: TokenContractV2Caller{contract: contract}, TokenContractV2Transactor: TokenContractV2Transactor{contract: contract}, TokenContractV2Filterer: TokenContractV2Filterer{contract: contract}}, nil
+}
+
+// TokenContractV2 is an auto generated Go binding around an Ethereum contract.
+type TokenContractV2 struct {
+	TokenContractV2Caller     // Read-only binding to the contract
+	TokenContractV2Transactor // Write-only binding to the contract
+	TokenContractV2Filterer   // Log filterer for contract events
+}
+
+// TokenContractV2Caller is an auto generated read-only Go binding around an Ethereum contract.
+type TokenContractV2Caller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TokenContractV2Transactor is an auto generated write-only Go binding around an Ethereum contract.
+type TokenContractV2Transactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TokenContractV2Filterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type TokenContractV2Filterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// TokenContractV2Session is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type TokenContractV2Session struct {
+	Contract     *TokenContractV2  // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// TokenContractV2CallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type TokenContractV2CallerSession struct {
+	Contract *TokenContractV2Caller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts          // Call options to use throughout this session
+}
+
+// TokenContractV2TransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type TokenContractV2TransactorSession struct {
+	Contract     *TokenContractV2Transactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts          // Transaction auth options to use throughout this session
+}
+
+// TokenContractV2Raw is an auto generated low-level Go binding around an Ethereum contract.
+type TokenContractV2Raw struct {
+	Contract *TokenContractV2 // Generic contract binding to access the raw methods on
+}
+
+// TokenContractV2CallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type TokenContractV2CallerRaw struct {
+	Contract *TokenContractV2Caller // Generic read-only contract binding to access the raw methods on
+}
+
+// TokenContractV2TransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type TokenContractV2TransactorRaw struct {
+	Contract *TokenContractV2Transactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewTokenContractV2 creates a new instance of TokenContractV2, bound to a specific deployed contract.
+func NewTokenContractV2(address common.Address, backend bind.ContractBackend) (*TokenContractV2, error) {
+	contract, err := bindTokenContractV2(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2{TokenContractV2Caller: TokenContractV2Caller{contract: contract}, TokenContractV2Transactor: TokenContractV2Transactor{contract: contract}, TokenContractV2Filterer: TokenContractV2Filterer{contract: contract}}, nil
+}
+
+// NewTokenContractV2Caller creates a new read-only instance of TokenContractV2, bound to a specific deployed contract.
+func NewTokenContractV2Caller(address common.Address, caller bind.ContractCaller) (*TokenContractV2Caller, error) {
+	contract, err := bindTokenContractV2(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2Caller{contract: contract}, nil
+}
+
+// NewTokenContractV2Transactor creates a new write-only instance of TokenContractV2, bound to a specific deployed contract.
+func NewTokenContractV2Transactor(address common.Address, transactor bind.ContractTransactor) (*TokenContractV2Transactor, error) {
+	contract, err := bindTokenContractV2(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2Transactor{contract: contract}, nil
+}
+
+// NewTokenContractV2Filterer creates a new log filterer instance of TokenContractV2, bound to a specific deployed contract.
+func NewTokenContractV2Filterer(address common.Address, filterer bind.ContractFilterer) (*TokenContractV2Filterer, error) {
+	contract, err := bindTokenContractV2(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2Filterer{contract: contract}, nil
+}
+
+// bindTokenContractV2 binds a generic wrapper to an already deployed contract.
+func bindTokenContractV2(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := TokenContractV2MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_TokenContractV2 *TokenContractV2Raw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _TokenContractV2.Contract.TokenContractV2Caller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_TokenContractV2 *TokenContractV2Raw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.TokenContractV2Transactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_TokenContractV2 *TokenContractV2Raw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.TokenContractV2Transactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_TokenContractV2 *TokenContractV2CallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _TokenContractV2.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_TokenContractV2 *TokenContractV2TransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_TokenContractV2 *TokenContractV2TransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.contract.Transact(opts, method, params...)
+}
+
+// RelayHash is a free data retrieval call binding the contract method 0xdbcbb0c7.
+//
+// Solidity: function relayHash(bytes32 secretHash, address relayer, uint256 fee) view returns(bytes32)
+func (_TokenContractV2 *TokenContractV2Caller) RelayHash(opts *bind.CallOpts, secretHash [32]byte, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _TokenContractV2.contract.Call(opts, &out, "relayHash", secretHash, relayer, fee)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// RelayHash is a free data retrieval call binding the contract method 0xdbcbb0c7.
+//
+// Solidity: function relayHash(bytes32 secretHash, address relayer, uint256 fee) view returns(bytes32)
+func (_TokenContractV2 *TokenContractV2Session) RelayHash(secretHash [32]byte, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	return _TokenContractV2.Contract.RelayHash(&_TokenContractV2.CallOpts, secretHash, relayer, fee)
+}
+
+// RelayHash is a free data retrieval call binding the contract method 0xdbcbb0c7.
+//
+// Solidity: function relayHash(bytes32 secretHash, address relayer, uint256 fee) view returns(bytes32)
+func (_TokenContractV2 *TokenContractV2CallerSession) RelayHash(secretHash [32]byte, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	return _TokenContractV2.Contract.RelayHash(&_TokenContractV2.CallOpts, secretHash, relayer, fee)
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state, address token)
+func (_TokenContractV2 *TokenContractV2Caller) Swaps(opts *bind.CallOpts, arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+	Token         common.Address
+}, error) {
+	var out []interface{}
+	err := _TokenContractV2.contract.Call(opts, &out, "swaps", arg0)
+
+	outstruct := new(struct {
+		InitTimestamp *big.Int
+		RefundTime    *big.Int
+		SecretHash    [32]byte
+		Secret        [32]byte
+		Initiator     common.Address
+		Participant   common.Address
+		Value         *big.Int
+		Kind          uint8
+		State         uint8
+		Token         common.Address
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.InitTimestamp = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.RefundTime = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.SecretHash = *abi.ConvertType(out[2], new([32]byte)).(*[32]byte)
+	outstruct.Secret = *abi.ConvertType(out[3], new([32]byte)).(*[32]byte)
+	outstruct.Initiator = *abi.ConvertType(out[4], new(common.Address)).(*common.Address)
+	outstruct.Participant = *abi.ConvertType(out[5], new(common.Address)).(*common.Address)
+	outstruct.Value = *abi.ConvertType(out[6], new(*big.Int)).(**big.Int)
+	outstruct.Kind = *abi.ConvertType(out[7], new(uint8)).(*uint8)
+	outstruct.State = *abi.ConvertType(out[8], new(uint8)).(*uint8)
+	outstruct.Token = *abi.ConvertType(out[9], new(common.Address)).(*common.Address)
+
+	return *outstruct, err
+
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state, address token)
+func (_TokenContractV2 *TokenContractV2Session) Swaps(arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+	Token         common.Address
+}, error) {
+	return _TokenContractV2.Contract.Swaps(&_TokenContractV2.CallOpts, arg0)
+}
+
+// Swaps is a free data retrieval call binding the contract method 0xeb84e7f2.
+//
+// Solidity: function swaps(bytes32 ) view returns(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, bytes32 secret, address initiator, address participant, uint256 value, uint8 kind, uint8 state, address token)
+func (_TokenContractV2 *TokenContractV2CallerSession) Swaps(arg0 [32]byte) (struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Secret        [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Kind          uint8
+	State         uint8
+	Token         common.Address
+}, error) {
+	return _TokenContractV2.Contract.Swaps(&_TokenContractV2.CallOpts, arg0)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0x15601f4f.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant, address token, uint256 value) returns()
+func (_TokenContractV2 *TokenContractV2Transactor) Initiate(opts *bind.TransactOpts, refundTime *big.Int, secretHash [32]byte, participant common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContractV2.contract.Transact(opts, "initiate", refundTime, secretHash, participant, token, value)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0x15601f4f.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant, address token, uint256 value) returns()
+func (_TokenContractV2 *TokenContractV2Session) Initiate(refundTime *big.Int, secretHash [32]byte, participant common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Initiate(&_TokenContractV2.TransactOpts, refundTime, secretHash, participant, token, value)
+}
+
+// Initiate is a paid mutator transaction binding the contract method 0x15601f4f.
+//
+// Solidity: function initiate(uint256 refundTime, bytes32 secretHash, address participant, address token, uint256 value) returns()
+func (_TokenContractV2 *TokenContractV2TransactorSession) Initiate(refundTime *big.Int, secretHash [32]byte, participant common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Initiate(&_TokenContractV2.TransactOpts, refundTime, secretHash, participant, token, value)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x0103b16b.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator, address token, uint256 value) returns()
+func (_TokenContractV2 *TokenContractV2Transactor) Participate(opts *bind.TransactOpts, refundTime *big.Int, secretHash [32]byte, initiator common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContractV2.contract.Transact(opts, "participate", refundTime, secretHash, initiator, token, value)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x0103b16b.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator, address token, uint256 value) returns()
+func (_TokenContractV2 *TokenContractV2Session) Participate(refundTime *big.Int, secretHash [32]byte, initiator common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Participate(&_TokenContractV2.TransactOpts, refundTime, secretHash, initiator, token, value)
+}
+
+// Participate is a paid mutator transaction binding the contract method 0x0103b16b.
+//
+// Solidity: function participate(uint256 refundTime, bytes32 secretHash, address initiator, address token, uint256 value) returns()
+func (_TokenContractV2 *TokenContractV2TransactorSession) Participate(refundTime *big.Int, secretHash [32]byte, initiator common.Address, token common.Address, value *big.Int) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Participate(&_TokenContractV2.TransactOpts, refundTime, secretHash, initiator, token, value)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_TokenContractV2 *TokenContractV2Transactor) Redeem(opts *bind.TransactOpts, secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.contract.Transact(opts, "redeem", secret, secretHash)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_TokenContractV2 *TokenContractV2Session) Redeem(secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Redeem(&_TokenContractV2.TransactOpts, secret, secretHash)
+}
+
+// Redeem is a paid mutator transaction binding the contract method 0xb31597ad.
+//
+// Solidity: function redeem(bytes32 secret, bytes32 secretHash) returns()
+func (_TokenContractV2 *TokenContractV2TransactorSession) Redeem(secret [32]byte, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Redeem(&_TokenContractV2.TransactOpts, secret, secretHash)
+}
+
+// RedeemFor is a paid mutator transaction binding the contract method 0xd91ad122.
+//
+// Solidity: function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s) returns()
+func (_TokenContractV2 *TokenContractV2Transactor) RedeemFor(opts *bind.TransactOpts, secret [32]byte, secretHash [32]byte, fee *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.contract.Transact(opts, "redeemFor", secret, secretHash, fee, v, r, s)
+}
+
+// RedeemFor is a paid mutator transaction binding the contract method 0xd91ad122.
+//
+// Solidity: function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s) returns()
+func (_TokenContractV2 *TokenContractV2Session) RedeemFor(secret [32]byte, secretHash [32]byte, fee *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.RedeemFor(&_TokenContractV2.TransactOpts, secret, secretHash, fee, v, r, s)
+}
+
+// RedeemFor is a paid mutator transaction binding the contract method 0xd91ad122.
+//
+// Solidity: function redeemFor(bytes32 secret, bytes32 secretHash, uint256 fee, uint8 v, bytes32 r, bytes32 s) returns()
+func (_TokenContractV2 *TokenContractV2TransactorSession) RedeemFor(secret [32]byte, secretHash [32]byte, fee *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.RedeemFor(&_TokenContractV2.TransactOpts, secret, secretHash, fee, v, r, s)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_TokenContractV2 *TokenContractV2Transactor) Refund(opts *bind.TransactOpts, secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.contract.Transact(opts, "refund", secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_TokenContractV2 *TokenContractV2Session) Refund(secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Refund(&_TokenContractV2.TransactOpts, secretHash)
+}
+
+// Refund is a paid mutator transaction binding the contract method 0x7249fbb6.
+//
+// Solidity: function refund(bytes32 secretHash) returns()
+func (_TokenContractV2 *TokenContractV2TransactorSession) Refund(secretHash [32]byte) (*types.Transaction, error) {
+	return _TokenContractV2.Contract.Refund(&_TokenContractV2.TransactOpts, secretHash)
+}
+
+// TokenContractV2InitiatedIterator is returned from FilterInitiated and is used to iterate over the raw logs and unpacked data for Initiated events raised by the TokenContractV2 contract.
+type TokenContractV2InitiatedIterator struct {
+	Event *TokenContractV2Initiated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractV2InitiatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractV2Initiated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractV2Initiated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractV2InitiatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractV2InitiatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractV2Initiated represents a Initiated event raised by the TokenContractV2 contract.
+type TokenContractV2Initiated struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterInitiated is a free log retrieval operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) FilterInitiated(opts *bind.FilterOpts) (*TokenContractV2InitiatedIterator, error) {
+
+	logs, sub, err := _TokenContractV2.contract.FilterLogs(opts, "Initiated")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2InitiatedIterator{contract: _TokenContractV2.contract, event: "Initiated", logs: logs, sub: sub}, nil
+}
+
+// WatchInitiated is a free log subscription operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) WatchInitiated(opts *bind.WatchOpts, sink chan<- *TokenContractV2Initiated) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContractV2.contract.WatchLogs(opts, "Initiated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractV2Initiated)
+				if err := _TokenContractV2.contract.UnpackLog(event, "Initiated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseInitiated is a log parse operation binding the contract event 0x75501a491c11746724d18ea6e5ac6a53864d886d653da6b846fdecda837cf576.
+//
+// Solidity: event Initiated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) ParseInitiated(log types.Log) (*TokenContractV2Initiated, error) {
+	event := new(TokenContractV2Initiated)
+	if err := _TokenContractV2.contract.UnpackLog(event, "Initiated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// TokenContractV2ParticipatedIterator is returned from FilterParticipated and is used to iterate over the raw logs and unpacked data for Participated events raised by the TokenContractV2 contract.
+type TokenContractV2ParticipatedIterator struct {
+	Event *TokenContractV2Participated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractV2ParticipatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractV2Participated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractV2Participated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractV2ParticipatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractV2ParticipatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractV2Participated represents a Participated event raised by the TokenContractV2 contract.
+type TokenContractV2Participated struct {
+	InitTimestamp *big.Int
+	RefundTime    *big.Int
+	SecretHash    [32]byte
+	Initiator     common.Address
+	Participant   common.Address
+	Value         *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterParticipated is a free log retrieval operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) FilterParticipated(opts *bind.FilterOpts) (*TokenContractV2ParticipatedIterator, error) {
+
+	logs, sub, err := _TokenContractV2.contract.FilterLogs(opts, "Participated")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2ParticipatedIterator{contract: _TokenContractV2.contract, event: "Participated", logs: logs, sub: sub}, nil
+}
+
+// WatchParticipated is a free log subscription operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) WatchParticipated(opts *bind.WatchOpts, sink chan<- *TokenContractV2Participated) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContractV2.contract.WatchLogs(opts, "Participated")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractV2Participated)
+				if err := _TokenContractV2.contract.UnpackLog(event, "Participated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseParticipated is a log parse operation binding the contract event 0xe5571d467a528d7481c0e3bdd55ad528d0df6b457b07bab736c3e245c3aa16f4.
+//
+// Solidity: event Participated(uint256 initTimestamp, uint256 refundTime, bytes32 secretHash, address initiator, address participant, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) ParseParticipated(log types.Log) (*TokenContractV2Participated, error) {
+	event := new(TokenContractV2Participated)
+	if err := _TokenContractV2.contract.UnpackLog(event, "Participated", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// TokenContractV2RedeemedIterator is returned from FilterRedeemed and is used to iterate over the raw logs and unpacked data for Redeemed events raised by the TokenContractV2 contract.
+type TokenContractV2RedeemedIterator struct {
+	Event *TokenContractV2Redeemed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractV2RedeemedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractV2Redeemed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractV2Redeemed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractV2RedeemedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractV2RedeemedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractV2Redeemed represents a Redeemed event raised by the TokenContractV2 contract.
+type TokenContractV2Redeemed struct {
+	RedeemTime *big.Int
+	SecretHash [32]byte
+	Secret     [32]byte
+	Redeemer   common.Address
+	Value      *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterRedeemed is a free log retrieval operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) FilterRedeemed(opts *bind.FilterOpts) (*TokenContractV2RedeemedIterator, error) {
+
+	logs, sub, err := _TokenContractV2.contract.FilterLogs(opts, "Redeemed")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2RedeemedIterator{contract: _TokenContractV2.contract, event: "Redeemed", logs: logs, sub: sub}, nil
+}
+
+// WatchRedeemed is a free log subscription operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) WatchRedeemed(opts *bind.WatchOpts, sink chan<- *TokenContractV2Redeemed) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContractV2.contract.WatchLogs(opts, "Redeemed")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractV2Redeemed)
+				if err := _TokenContractV2.contract.UnpackLog(event, "Redeemed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRedeemed is a log parse operation binding the contract event 0xe4da013d8c42cdfa76ab1d5c08edcdc1503d2da88d7accc854f0e57ebe45c591.
+//
+// Solidity: event Redeemed(uint256 redeemTime, bytes32 secretHash, bytes32 secret, address redeemer, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) ParseRedeemed(log types.Log) (*TokenContractV2Redeemed, error) {
+	event := new(TokenContractV2Redeemed)
+	if err := _TokenContractV2.contract.UnpackLog(event, "Redeemed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// TokenContractV2RefundedIterator is returned from FilterRefunded and is used to iterate over the raw logs and unpacked data for Refunded events raised by the TokenContractV2 contract.
+type TokenContractV2RefundedIterator struct {
+	Event *TokenContractV2Refunded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *TokenContractV2RefundedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(TokenContractV2Refunded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(TokenContractV2Refunded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *TokenContractV2RefundedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *TokenContractV2RefundedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// TokenContractV2Refunded represents a Refunded event raised by the TokenContractV2 contract.
+type TokenContractV2Refunded struct {
+	RefundTime *big.Int
+	SecretHash [32]byte
+	Refunder   common.Address
+	Value      *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterRefunded is a free log retrieval operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) FilterRefunded(opts *bind.FilterOpts) (*TokenContractV2RefundedIterator, error) {
+
+	logs, sub, err := _TokenContractV2.contract.FilterLogs(opts, "Refunded")
+	if err != nil {
+		return nil, err
+	}
+	return &TokenContractV2RefundedIterator{contract: _TokenContractV2.contract, event: "Refunded", logs: logs, sub: sub}, nil
+}
+
+// WatchRefunded is a free log subscription operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) WatchRefunded(opts *bind.WatchOpts, sink chan<- *TokenContractV2Refunded) (event.Subscription, error) {
+
+	logs, sub, err := _TokenContractV2.contract.WatchLogs(opts, "Refunded")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(TokenContractV2Refunded)
+				if err := _TokenContractV2.contract.UnpackLog(event, "Refunded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRefunded is a log parse operation binding the contract event 0xadb1dca52dfad065e50a1e25c2ee47ae54013a1f2d6f8ea5abace52eb4b7a4c8.
+//
+// Solidity: event Refunded(uint256 refundTime, bytes32 secretHash, address refunder, uint256 value)
+func (_TokenContractV2 *TokenContractV2Filterer) ParseRefunded(log types.Log) (*TokenContractV2Refunded, error) {
+	event := new(TokenContractV2Refunded)
+	if err := _TokenContractV2.contract.UnpackLog(event, "Refunded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
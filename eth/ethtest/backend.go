@@ -0,0 +1,80 @@
+// Package ethtest provides the Ethereum chain the atomic swaps are tested against:
+// go-ethereum's simulated backend, mining every transaction as soon as it is sent.
+//
+// The simulated backend only mines the pending transactions when committed,
+// while the eth package waits for the transactions it sends to be mined,
+// so Backend commits them. Its clock starts at the Unix epoch, the time of the genesis block,
+// and can be moved forward, to pass the lock time of the contracts.
+// The time of the blocks can not be ahead of the current time.
+//
+// The simulated backend does not serve a JSON-RPC API, it is used through eth.NewClient.
+// The calls only available through the API of a node, such as the ones of eth.NodeSigner,
+// are tested against stubs of the API.
+//
+// TestToken is an ERC-20 token to test the swaps of tokens with, see TestToken.sol.
+package ethtest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// GasLimit of the blocks
+const GasLimit = 30000000
+
+// Backend is go-ethereum's simulated backend mining every transaction in a block of its own
+type Backend struct {
+	*backends.SimulatedBackend
+}
+
+// NewBackend creates a backend of which the genesis block allocates the balances, in Wei.
+// The chain ID of its chain is 1337.
+func NewBackend(alloc core.GenesisAlloc) *Backend {
+	return &Backend{backends.NewSimulatedBackend(alloc, GasLimit)}
+}
+
+// ChainID returns the ID of the chain, which the simulated backend does not report
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.Blockchain().Config().ChainID), nil
+}
+
+// SendTransaction sends the transaction and mines it in a new block.
+// The simulated backend panics on transactions that can not be included in a block,
+// such as without enough funds to pay for the gas, their error is returned instead.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid transaction: %v", r)
+		}
+	}()
+	if err = b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+// AdjustTime moves the clock forward and mines an empty block at the adjusted time
+func (b *Backend) AdjustTime(d time.Duration) error {
+	if err := b.SimulatedBackend.AdjustTime(d); err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+// Balance returns the balance of the account in the latest block, in Wei
+func (b *Backend) Balance(addr common.Address) *big.Int {
+	balance, err := b.BalanceAt(context.Background(), addr, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to get the balance of %x: %v", addr, err))
+	}
+	return balance
+}
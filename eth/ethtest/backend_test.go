@@ -0,0 +1,100 @@
+package ethtest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+)
+
+func TestDeployContract(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	b := NewBackend(core.GenesisAlloc{from: {Balance: big.NewInt(params.Ether)}})
+	defer b.Close()
+	ctx := context.Background()
+	chainID, err := b.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gas, err := b.EstimateGas(ctx, ethereum.CallMsg{From: from, Data: common.FromHex(contract.ContractBin)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTx := func(gas uint64) *types.Transaction {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Gas:       gas,
+			GasFeeCap: new(big.Int).Mul(head.BaseFee, big.NewInt(2)),
+			GasTipCap: big.NewInt(params.GWei),
+			Data:      common.FromHex(contract.ContractBin),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	// the simulated backend panics on a transaction that can not be included in a block
+	if err = b.SendTransaction(ctx, newTx(GasLimit+1)); err == nil {
+		t.Error("transaction exceeding the gas limit of the blocks sent")
+	}
+	tx := newTx(gas)
+	if err = b.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.SendTransaction(ctx, tx); err == nil {
+		t.Error("transaction sent twice")
+	}
+
+	// the transaction is mined once sent
+	receipt, err := b.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deployment failed, used %d gas of %d", receipt.GasUsed, gas)
+	}
+	code, err := b.CodeAt(ctx, receipt.ContractAddress, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the code hash of the AtomicSwap-v1 contract version
+	if codeHash := crypto.Keccak256Hash(code); codeHash != common.HexToHash("44dcf70b2586e0719cd8a9dbde448a985840edfdfe79529ccfbae3702acfacee") {
+		t.Errorf("unexpected code hash %x", codeHash)
+	}
+	block, err := b.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions()) != 1 || block.Transactions()[0].Hash() != tx.Hash() {
+		t.Error("transaction not included in a block of its own")
+	}
+
+	if err = b.AdjustTime(48 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if head, err = b.HeaderByNumber(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if head.Time < block.Time()+uint64(48*time.Hour/time.Second) {
+		t.Errorf("time of the head %d not adjusted from %d", head.Time, block.Time())
+	}
+}
@@ -0,0 +1,6 @@
+package ethtest
+
+// the ERC-20 token the swaps of tokens are tested with,
+// compiled for the London and Paris forks of the simulated backend
+//go:generate solc --abi --bin --overwrite --evm-version paris -o build TestToken.sol
+//go:generate abigen --bin=build/TestToken.bin --abi=build/TestToken.abi --pkg=ethtest --type=TestToken --out=testtoken.go
@@ -0,0 +1,305 @@
+package eth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+)
+
+type (
+	// EventKind is the name of an event emitted by the AtomicSwap contract
+	EventKind string
+
+	// Event is an event emitted by the AtomicSwap contract.
+	// The fields which are not part of the event are left empty.
+	Event struct {
+		Kind        EventKind
+		SecretHash  [sha256.Size]byte
+		Secret      [sha256.Size]byte // Redeemed only
+		Initiator   common.Address    // Initiated and Participated only
+		Participant common.Address    // Initiated and Participated only
+		// Sender is the redeemer or refunder of Redeemed and Refunded events
+		Sender        common.Address
+		Value         *big.Int
+		RefundTime    *big.Int // Initiated and Participated only
+		Confirmations uint64
+		Log           types.Log
+	}
+
+	// EventFilter selects the events to backfill or stream.
+	// The AtomicSwap events are not indexed,
+	// so the secret hash and address are matched client side.
+	EventFilter struct {
+		FromBlock uint64
+		// ToBlock is the last block, the latest block is used if nil
+		ToBlock *uint64
+		// Kinds of the events, all kinds if empty
+		Kinds []EventKind
+		// SecretHash of the events, any secret hash if nil
+		SecretHash *[sha256.Size]byte
+		// Address which initiated, participated, redeemed or refunded,
+		// any address if nil
+		Address *common.Address
+	}
+)
+
+// The events emitted by the AtomicSwap contract
+const (
+	EventInitiated    EventKind = "Initiated"
+	EventParticipated EventKind = "Participated"
+	EventRedeemed     EventKind = "Redeemed"
+	EventRefunded     EventKind = "Refunded"
+)
+
+// DefaultEventPollInterval is the interval at which WatchEvents polls for new blocks
+const DefaultEventPollInterval = 15 * time.Second
+
+// DefaultLogRange is the number of blocks of which the logs are requested at once.
+// Nodes and providers limit the blocks or results of a log request,
+// typically to 2000 to 10000 blocks or 10000 results.
+const DefaultLogRange = 5000
+
+// FilterEvents returns the events of the AtomicSwap contract matching the filter,
+// in the order in which they were emitted.
+// The logs are requested in ranges of sct.LogRange blocks.
+func (sct *SwapContractTransactor) FilterEvents(ctx context.Context, filter EventFilter) ([]Event, error) {
+	head, err := sct.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest block number: %v", err)
+	}
+	to := head
+	if filter.ToBlock != nil && *filter.ToBlock < head {
+		to = *filter.ToBlock
+	}
+	if filter.FromBlock > to {
+		return nil, nil
+	}
+	return sct.filterEvents(ctx, filter, filter.FromBlock, to, head)
+}
+
+// WatchEvents streams the events of the AtomicSwap contract matching the filter to sink,
+// starting at filter.FromBlock, until ctx is done or an error occurs.
+//
+// An event is only sent once its block has the given amount of confirmations,
+// and is still part of the canonical chain, so events of blocks removed
+// by a reorganisation of the chain are never sent. filter.ToBlock is ignored.
+func (sct *SwapContractTransactor) WatchEvents(ctx context.Context, filter EventFilter, confirmations uint64, pollInterval time.Duration, sink chan<- Event) error {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultEventPollInterval
+	}
+	next := filter.FromBlock
+	for {
+		head, err := sct.Client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get the latest block number: %v", err)
+		}
+		if head+1 >= next+confirmations {
+			to := head + 1 - confirmations
+			events, err := sct.filterEvents(ctx, filter, next, to, head)
+			if err != nil {
+				return err
+			}
+			canonical, err := sct.canonicalEvents(ctx, events)
+			if err != nil {
+				return err
+			}
+			if canonical {
+				for _, e := range events {
+					select {
+					case sink <- e:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				next = to + 1
+			}
+			// otherwise the chain was reorganised while filtering,
+			// the same range is filtered again on the next poll
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// canonicalEvents returns false if the block of any of the events
+// is no longer part of the canonical chain
+func (sct *SwapContractTransactor) canonicalEvents(ctx context.Context, events []Event) (bool, error) {
+	checked := make(map[uint64]bool)
+	for _, e := range events {
+		if e.Log.Removed {
+			return false, nil
+		}
+		if checked[e.Log.BlockNumber] {
+			continue
+		}
+		header, err := sct.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(e.Log.BlockNumber))
+		if err != nil {
+			return false, fmt.Errorf("failed to get block %d: %v", e.Log.BlockNumber, err)
+		}
+		if header.Hash() != e.Log.BlockHash {
+			return false, nil
+		}
+		checked[e.Log.BlockNumber] = true
+	}
+	return true, nil
+}
+
+func (sct *SwapContractTransactor) filterEvents(ctx context.Context, filter EventFilter, from, to, head uint64) ([]Event, error) {
+	kinds := filter.Kinds
+	if len(kinds) == 0 {
+		kinds = []EventKind{EventInitiated, EventParticipated, EventRedeemed, EventRefunded}
+	}
+	topics := make([]common.Hash, 0, len(kinds))
+	for _, kind := range kinds {
+		event, ok := sct.Abi.Events[string(kind)]
+		if !ok {
+			return nil, fmt.Errorf("unknown event %q", kind)
+		}
+		topics = append(topics, event.ID)
+	}
+	filterer, err := contract.NewContractFilterer(sct.ContractAddr, sct.Client.Backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind smart contract (at %x): %v", sct.ContractAddr, err)
+	}
+	logRange := sct.LogRange
+	if logRange == 0 {
+		logRange = DefaultLogRange
+	}
+	var events []Event
+	for start := from; start <= to; {
+		end := to
+		if to-start >= logRange {
+			end = start + logRange - 1
+		}
+		logs, err := sct.Client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{sct.ContractAddr},
+			Topics:    [][]common.Hash{topics},
+		})
+		if err != nil {
+			if end > start && isLogRangeError(err) {
+				// the remaining blocks are requested in smaller ranges
+				logRange = (end - start + 1) / 2
+				continue
+			}
+			return nil, fmt.Errorf("failed to filter logs of smart contract (at %x) in blocks %d to %d: %v", sct.ContractAddr, start, end, err)
+		}
+		for _, log := range logs {
+			e, err := decodeEvent(sct.Abi, filterer, log)
+			if err != nil {
+				return nil, err
+			}
+			if !filter.matches(e) {
+				continue
+			}
+			if log.BlockNumber <= head {
+				e.Confirmations = head - log.BlockNumber + 1
+			}
+			events = append(events, e)
+		}
+		start = end + 1
+	}
+	return events, nil
+}
+
+// limitExceededCode is the JSON-RPC error code of a request exceeding a limit of the node
+const limitExceededCode = -32005
+
+// isLogRangeError returns true if the node rejected a log request
+// because of the number of blocks or results, with the JSON-RPC code for exceeded limits
+// or one of the messages of the providers: "query returned more than 10000 results",
+// "block range too large", "exceed maximum block range: 5000",
+// "block range exceeds the range allowed" or "Log response size exceeded".
+func isLogRangeError(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"query returned more than", "block range", "exceeds the range", "response size exceeded"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeEvent decodes a log emitted by the AtomicSwap contract
+func decodeEvent(contractABI abi.ABI, filterer *contract.ContractFilterer, log types.Log) (Event, error) {
+	if len(log.Topics) == 0 {
+		return Event{}, fmt.Errorf("log %s:%d has no topics", log.TxHash.Hex(), log.Index)
+	}
+	event, err := contractABI.EventByID(log.Topics[0])
+	if err != nil {
+		return Event{}, fmt.Errorf("log %s:%d is not an AtomicSwap event", log.TxHash.Hex(), log.Index)
+	}
+	var e Event
+	switch EventKind(event.Name) {
+	case EventInitiated:
+		var ev *contract.ContractInitiated
+		if ev, err = filterer.ParseInitiated(log); err == nil {
+			e = Event{Kind: EventInitiated, SecretHash: ev.SecretHash, Initiator: ev.Initiator,
+				Participant: ev.Participant, Value: ev.Value, RefundTime: ev.RefundTime}
+		}
+	case EventParticipated:
+		var ev *contract.ContractParticipated
+		if ev, err = filterer.ParseParticipated(log); err == nil {
+			e = Event{Kind: EventParticipated, SecretHash: ev.SecretHash, Initiator: ev.Initiator,
+				Participant: ev.Participant, Value: ev.Value, RefundTime: ev.RefundTime}
+		}
+	case EventRedeemed:
+		var ev *contract.ContractRedeemed
+		if ev, err = filterer.ParseRedeemed(log); err == nil {
+			e = Event{Kind: EventRedeemed, SecretHash: ev.SecretHash, Secret: ev.Secret,
+				Sender: ev.Redeemer, Value: ev.Value}
+		}
+	case EventRefunded:
+		var ev *contract.ContractRefunded
+		if ev, err = filterer.ParseRefunded(log); err == nil {
+			e = Event{Kind: EventRefunded, SecretHash: ev.SecretHash, Sender: ev.Refunder, Value: ev.Value}
+		}
+	default:
+		return Event{}, fmt.Errorf("log %s:%d is an unknown %s event", log.TxHash.Hex(), log.Index, event.Name)
+	}
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to decode log %s:%d: %v", log.TxHash.Hex(), log.Index, err)
+	}
+	e.Log = log
+	return e, nil
+}
+
+// matches returns true if the event matches the secret hash and address of the filter
+func (filter EventFilter) matches(e Event) bool {
+	if filter.SecretHash != nil && *filter.SecretHash != e.SecretHash {
+		return false
+	}
+	if filter.Address == nil {
+		return true
+	}
+	addr := *filter.Address
+	switch e.Kind {
+	case EventInitiated, EventParticipated:
+		return e.Initiator == addr || e.Participant == addr
+	default:
+		return e.Sender == addr
+	}
+}
@@ -0,0 +1,214 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+	"github.com/threefoldtech/atomicswap/eth/ethtest"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(contract.ContractABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filterer, err := contract.NewContractFilterer(common.Address{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newLog := func(name string, args ...interface{}) types.Log {
+		event := contractABI.Events[name]
+		data, err := event.Inputs.Pack(args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.Log{Topics: []common.Hash{event.ID}, Data: data, BlockNumber: 7}
+	}
+
+	secret := [32]byte{1, 2, 3}
+	secretHash := sha256Hash(secret[:])
+	alice, bob := common.HexToAddress("0xa11ce"), common.HexToAddress("0xb0b")
+	value := big.NewInt(1000)
+
+	initiated, err := decodeEvent(contractABI, filterer, newLog("Initiated",
+		big.NewInt(100), big.NewInt(200), secretHash, alice, bob, value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initiated.Kind != EventInitiated || initiated.SecretHash != secretHash || initiated.Initiator != alice ||
+		initiated.Participant != bob || initiated.Value.Cmp(value) != 0 || initiated.RefundTime.Int64() != 200 {
+		t.Errorf("unexpected Initiated event: %+v", initiated)
+	}
+
+	redeemed, err := decodeEvent(contractABI, filterer, newLog("Redeemed",
+		big.NewInt(150), secretHash, secret, bob, value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redeemed.Kind != EventRedeemed || redeemed.Secret != secret || redeemed.Sender != bob {
+		t.Errorf("unexpected Redeemed event: %+v", redeemed)
+	}
+	if redeemed.Log.BlockNumber != 7 {
+		t.Errorf("log not kept: %+v", redeemed.Log)
+	}
+
+	if _, err = decodeEvent(contractABI, filterer, types.Log{Topics: []common.Hash{{1}}}); err == nil {
+		t.Error("decoded an unknown event")
+	}
+
+	otherHash := [32]byte{9}
+	testCases := []struct {
+		Filter   EventFilter
+		Event    Event
+		Expected bool
+	}{
+		{EventFilter{}, redeemed, true},
+		{EventFilter{SecretHash: &secretHash}, redeemed, true},
+		{EventFilter{SecretHash: &otherHash}, redeemed, false},
+		{EventFilter{Address: &alice}, initiated, true},
+		{EventFilter{Address: &bob}, initiated, true},
+		{EventFilter{Address: &bob}, redeemed, true},
+		{EventFilter{Address: &alice}, redeemed, false},
+	}
+	for idx, testCase := range testCases {
+		if matches := testCase.Filter.matches(testCase.Event); matches != testCase.Expected {
+			t.Errorf("testCase #%d: matches = %v, expected %v", idx, matches, testCase.Expected)
+		}
+	}
+}
+
+// logRangeLimiter rejects the log requests of more than limit blocks,
+// as providers do, and records the ranges requested
+type logRangeLimiter struct {
+	*ethtest.Backend
+	limit    uint64
+	requests [][2]uint64
+	// err is returned for the rejected requests, a block range error if nil
+	err error
+}
+
+func (b *logRangeLimiter) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	b.requests = append(b.requests, [2]uint64{from, to})
+	if to-from+1 > b.limit {
+		if b.err != nil {
+			return nil, b.err
+		}
+		return nil, errors.New("exceed maximum block range: 3")
+	}
+	return b.Backend.FilterLogs(ctx, query)
+}
+
+func TestFilterEventsInRanges(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	bobAddr := crypto.PubkeyToAddress(c.bob.PublicKey)
+	var secretHashes [][32]byte
+	for i := 0; i < 3; i++ {
+		initiation, err := Initiate(ctx, c.transactor(c.alice, c.initiatorContract), bobAddr, ether(1), timings.DefaultPolicy, common.Address{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		secretHashes = append(secretHashes, initiation.SecretHash)
+		c.adjustTime(time.Minute)
+	}
+
+	backend := &logRangeLimiter{Backend: c.backend, limit: 3}
+	sct := c.transactor(c.alice, c.initiatorContract)
+	sct.Client = NewClient(backend)
+	sct.LogRange = 8
+	head, err := sct.Client.BlockNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := sct.FilterEvents(ctx, EventFilter{Kinds: []EventKind{EventInitiated}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != len(secretHashes) {
+		t.Fatalf("%d events filtered, expected %d", len(events), len(secretHashes))
+	}
+	for i, e := range events {
+		if e.SecretHash != secretHashes[i] {
+			t.Errorf("event #%d has secret hash %x, expected %x", i, e.SecretHash, secretHashes[i])
+		}
+	}
+
+	// the rejected ranges are halved, the accepted ones cover all blocks in order
+	next := uint64(0)
+	for _, r := range backend.requests {
+		if r[1]-r[0]+1 > backend.limit {
+			continue
+		}
+		if r[0] != next {
+			t.Fatalf("blocks %d to %d requested after block %d", r[0], r[1], next)
+		}
+		next = r[1] + 1
+	}
+	if next != head+1 {
+		t.Errorf("logs requested up to block %d, expected %d", next-1, head)
+	}
+	if first := backend.requests[0]; first != [2]uint64{0, 7} {
+		t.Errorf("first request of blocks %d to %d, expected the log range", first[0], first[1])
+	}
+
+	// a single block is not split any further
+	backend.limit = 0
+	if _, err = sct.FilterEvents(ctx, EventFilter{}); err == nil {
+		t.Error("events filtered while the node rejects every request")
+	}
+
+	// other errors are returned without splitting the range
+	backend.limit = 3
+	backend.requests = nil
+	backend.err = errors.New("runtime error: index out of range [3] with length 3")
+	if _, err = sct.FilterEvents(ctx, EventFilter{}); err == nil || !strings.Contains(err.Error(), backend.err.Error()) {
+		t.Errorf("expected the error of the node, got %v", err)
+	}
+	if len(backend.requests) != 1 {
+		t.Errorf("logs requested %d times, expected once", len(backend.requests))
+	}
+}
+
+// rpcError is an error of the JSON-RPC API of a node
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e rpcError) Error() string  { return e.msg }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestIsLogRangeError(t *testing.T) {
+	testCases := []struct {
+		Err      error
+		Expected bool
+	}{
+		{errors.New("query returned more than 10000 results"), true},
+		{errors.New("block range too large"), true},
+		{errors.New("exceed maximum block range: 5000"), true},
+		{errors.New("eth_getLogs block range exceeds the range allowed for your plan"), true},
+		{errors.New("Log response size exceeded."), true},
+		{rpcError{limitExceededCode, "limit exceeded"}, true},
+		{errors.New("connection refused"), false},
+		{errors.New("runtime error: index out of range [3] with length 3"), false},
+		{rpcError{-32000, "header not found"}, false},
+	}
+	for idx, testCase := range testCases {
+		if isRangeErr := isLogRangeError(testCase.Err); isRangeErr != testCase.Expected {
+			t.Errorf("testCase #%d (%v): isLogRangeError = %v, expected %v", idx, testCase.Err, isRangeErr, testCase.Expected)
+		}
+	}
+}
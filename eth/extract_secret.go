@@ -12,21 +12,26 @@ import (
 	"github.com/threefoldtech/atomicswap/eth/contract"
 )
 
-// ExtractSecret from a redeem call to the contract
+// ExtractSecret from a redeem call to the contract,
+// or a redeemFor call submitted by a relayer
 func ExtractSecret(ctx context.Context, sct SwapContractTransactor, redemptionTx *types.Transaction, secretHash [sha256.Size]byte) ([]byte, error) {
-	abi, err := abi.JSON(strings.NewReader(contract.ContractABI))
+	// the ABI of the RelayAtomicSwap contract holds the calls of the AtomicSwap contract
+	abi, err := abi.JSON(strings.NewReader(contract.RelayContractABI))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read (smart) contract ABI: %v", err)
 	}
 
 	txData := redemptionTx.Data()
+	if len(txData) < 4 {
+		return nil, errors.New("transaction does not call a contract method")
+	}
 
 	// first 4 bytes contain the id, so let's get method using that ID
 	method, err := abi.MethodById(txData[:4])
 	if err != nil {
 		return nil, fmt.Errorf("failed to get method using its parsed id: %v", err)
 	}
-	if method.Name != "redeem" {
+	if method.Name != "redeem" && method.Name != "redeemFor" {
 		return nil, fmt.Errorf("unexpected name for unpacked method ID: %s", method.Name)
 	}
 
@@ -36,7 +41,8 @@ func ExtractSecret(ctx context.Context, sct SwapContractTransactor, redemptionTx
 		return nil, fmt.Errorf("failed to unpack method's input params: %v", err)
 	}
 
-	if len(rawParams) != 2 {
+	// both calls take the secret and secret hash as first arguments
+	if len(rawParams) != len(method.Inputs) || len(rawParams) < 2 {
 		return nil, errors.New("unexpected redeem call argument count")
 	}
 
@@ -44,7 +50,7 @@ func ExtractSecret(ctx context.Context, sct SwapContractTransactor, redemptionTx
 	if !ok {
 		return nil, errors.New("could not decode secret in redeem call")
 	}
-	contractSecretHash, ok := rawParams[0].([sha256.Size]byte)
+	contractSecretHash, ok := rawParams[1].([sha256.Size]byte)
 	if !ok {
 		return nil, errors.New("could not decode secret hash in redeem call")
 	}
@@ -61,3 +67,47 @@ func ExtractSecret(ctx context.Context, sct SwapContractTransactor, redemptionTx
 
 	return secret[:], nil
 }
+
+// ErrNotRedeemed is returned by ExtractSecretByHash
+// when the atomic swap contract has not been redeemed yet
+var ErrNotRedeemed = errors.New("atomic swap contract has not been redeemed yet")
+
+// ExtractSecretByHash extracts the secret of a redeemed atomic swap contract,
+// identified by its secret hash, so the redemption transaction is not needed.
+// The secret is read from the swap stored in the contract,
+// or from its Redeemed event if the swap can not be read.
+func ExtractSecretByHash(ctx context.Context, sct SwapContractTransactor, secretHash [sha256.Size]byte) ([]byte, error) {
+	sc, err := sct.getSwapContract(ctx, secretHash)
+	if err == nil {
+		switch sc.State {
+		case swapStateRedeemed:
+			return verifySecret(sc.Secret, secretHash)
+		case swapStateRefunded:
+			return nil, errors.New("atomic swap contract has been refunded")
+		default:
+			return nil, ErrNotRedeemed
+		}
+	}
+	if err == errNotExists {
+		return nil, err
+	}
+
+	events, logErr := sct.FilterEvents(ctx, EventFilter{
+		Kinds:      []EventKind{EventRedeemed},
+		SecretHash: &secretHash,
+	})
+	if logErr != nil {
+		return nil, fmt.Errorf("%v, failed to get Redeemed event: %v", err, logErr)
+	}
+	if len(events) == 0 {
+		return nil, err
+	}
+	return verifySecret(events[0].Secret, secretHash)
+}
+
+func verifySecret(secret, secretHash [sha256.Size]byte) ([]byte, error) {
+	if sha256Hash(secret[:]) != secretHash {
+		return nil, fmt.Errorf("unexpected secret found: %x", secret)
+	}
+	return secret[:], nil
+}
@@ -0,0 +1,42 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestExtractSecret(t *testing.T) {
+	secret := sha256Hash([]byte("secret"))
+	secretHash := sha256Hash(secret[:])
+	input, err := atomicSwapABI.Pack("redeem", secret, secretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAddr := common.HexToAddress("0xc0de")
+	tx := types.NewTx(&types.DynamicFeeTx{To: &contractAddr, Data: input})
+	extracted, err := ExtractSecret(context.Background(), SwapContractTransactor{}, tx, secretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the secret and the secret hash are distinct arguments of the redeem call
+	if !bytes.Equal(extracted, secret[:]) {
+		t.Errorf("extracted secret %x, expected %x", extracted, secret)
+	}
+	if _, err = ExtractSecret(context.Background(), SwapContractTransactor{}, tx, secret); err == nil {
+		t.Error("secret extracted for another secret hash")
+	}
+
+	// the contract transaction does not reveal the secret
+	input, err = atomicSwapABI.Pack("initiate", common.Big1, secretHash, contractAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx = types.NewTx(&types.DynamicFeeTx{To: &contractAddr, Data: input})
+	if _, err = ExtractSecret(context.Background(), SwapContractTransactor{}, tx, secretHash); err == nil {
+		t.Error("secret extracted from a contract transaction")
+	}
+}
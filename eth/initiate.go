@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/threefoldtech/atomicswap/timings"
 )
 
 type InitiateOutput struct {
@@ -18,10 +19,16 @@ type InitiateOutput struct {
 	ContractTransaction types.Transaction `json:"contractTransaction"`
 }
 
-// Initiate an atomic swap
-func Initiate(ctx context.Context, sct SwapContractTransactor, cp2Addr common.Address, amount *big.Int) (InitiateOutput, error) {
+// Initiate an atomic swap, locked for the initiator lock time of the policy.
+// The amount is in the smallest unit of the token, the zero address for Ether.
+// Locking a token first approves the contract to transfer it, if not done yet.
+func Initiate(ctx context.Context, sct SwapContractTransactor, cp2Addr common.Address, amount *big.Int, policy timings.Policy, token common.Address) (InitiateOutput, error) {
+	if err := policy.Validate(); err != nil {
+		return InitiateOutput{}, err
+	}
+	sct = sct.forToken(token)
 	secret, secretHash := generateSecretHashPair()
-	tx, err := sct.initiateTx(ctx, amount, secretHash, cp2Addr)
+	tx, err := sct.initiateTx(ctx, amount, secretHash, cp2Addr, policy.Initiator)
 	if err != nil {
 		return InitiateOutput{}, fmt.Errorf("failed to create initiate TX: %v", err)
 	}
@@ -0,0 +1,245 @@
+// Package mpt proves and verifies the values of Merkle Patricia tries,
+// such as the ones committing to the transactions and receipts of a block.
+package mpt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// The transactions and receipts of a block are committed to by the roots
+// of Merkle Patricia tries in its header. The trie package of go-ethereum
+// pulls in its database backends, so the small part needed to compute the root,
+// and prove and verify a single value of such a trie is implemented here.
+
+// trieItem is a key, as nibbles, and value of a trie
+type trieItem struct {
+	key   []byte
+	value []byte
+}
+
+// Prove returns the root of the trie with the keys and values,
+// and the nodes proving the value of the key, root first
+func Prove(keys, values [][]byte, key []byte) (common.Hash, [][]byte) {
+	if len(keys) == 0 {
+		return types.EmptyRootHash, nil
+	}
+	items := make([]trieItem, len(keys))
+	for i := range keys {
+		items[i] = trieItem{key: keyNibbles(keys[i]), value: values[i]}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return bytes.Compare(items[i].key, items[j].key) < 0
+	})
+	var proof [][]byte
+	root := trieNode(items, 0, keyNibbles(key), &proof)
+	// the nodes are added to the proof leaf first
+	for i, j := 0, len(proof)-1; i < j; i, j = i+1, j-1 {
+		proof[i], proof[j] = proof[j], proof[i]
+	}
+	return crypto.Keccak256Hash(root), proof
+}
+
+// Hasher computes the root of a trie, see types.DeriveSha
+type Hasher struct {
+	keys, values [][]byte
+}
+
+// Reset the hasher to an empty trie
+func (h *Hasher) Reset() {
+	h.keys, h.values = nil, nil
+}
+
+// Update adds the value of the key to the trie
+func (h *Hasher) Update(key, value []byte) error {
+	h.keys = append(h.keys, append([]byte(nil), key...))
+	h.values = append(h.values, append([]byte(nil), value...))
+	return nil
+}
+
+// Hash returns the root of the trie
+func (h *Hasher) Hash() common.Hash {
+	root, _ := Prove(h.keys, h.values, nil)
+	return root
+}
+
+// trieNode returns the encoded node of the items, sorted by their keys,
+// which share the first depth nibbles. The target is the key to prove,
+// nil if this node is not on its path.
+func trieNode(items []trieItem, depth int, target []byte, proof *[][]byte) []byte {
+	var node []interface{}
+	if len(items) == 1 {
+		node = []interface{}{compactPath(items[0].key[depth:], true), items[0].value}
+	} else if prefix := commonPrefix(items[0].key[depth:], items[len(items)-1].key[depth:]); prefix > 0 {
+		childTarget := target
+		if !bytes.HasPrefix(target, items[0].key[:depth+prefix]) {
+			childTarget = nil
+		}
+		child := trieNode(items, depth+prefix, childTarget, proof)
+		node = []interface{}{compactPath(items[0].key[depth:depth+prefix], false), trieRef(child)}
+	} else {
+		node = make([]interface{}, 17)
+		for i := range node {
+			node[i] = []byte{}
+		}
+		if len(items[0].key) == depth {
+			node[16] = items[0].value
+			items = items[1:]
+		}
+		for len(items) > 0 {
+			nibble := items[0].key[depth]
+			n := sort.Search(len(items), func(i int) bool { return items[i].key[depth] != nibble })
+			var childTarget []byte
+			if len(target) > depth && target[depth] == nibble {
+				childTarget = target
+			}
+			node[nibble] = trieRef(trieNode(items[:n], depth+1, childTarget, proof))
+			items = items[n:]
+		}
+	}
+	enc, err := rlp.EncodeToBytes(node)
+	if err != nil {
+		panic("failed to encode trie node: " + err.Error())
+	}
+	// nodes shorter than a hash are embedded in their parent
+	if target != nil && (len(enc) >= common.HashLength || depth == 0) {
+		*proof = append(*proof, enc)
+	}
+	return enc
+}
+
+// trieRef returns the reference to a node by its parent
+func trieRef(enc []byte) interface{} {
+	if len(enc) < common.HashLength {
+		return rlp.RawValue(enc)
+	}
+	return crypto.Keccak256(enc)
+}
+
+// Verify returns the value of the key proven to be in the trie with the root,
+// nil if the proof shows the trie has no such key
+func Verify(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	nodes := make(map[common.Hash][]byte, len(proof))
+	for _, node := range proof {
+		nodes[crypto.Keccak256Hash(node)] = node
+	}
+	node, ok := nodes[root]
+	if !ok {
+		return nil, errors.New("proof is missing the root node")
+	}
+	path := keyNibbles(key)
+	for {
+		var elems []rlp.RawValue
+		if err := rlp.DecodeBytes(node, &elems); err != nil {
+			return nil, fmt.Errorf("invalid trie node: %v", err)
+		}
+		var child rlp.RawValue
+		switch len(elems) {
+		case 17:
+			if len(path) == 0 {
+				return decodeTrieValue(elems[16])
+			}
+			child, path = elems[path[0]], path[1:]
+		case 2:
+			var compact []byte
+			if err := rlp.DecodeBytes(elems[0], &compact); err != nil || len(compact) == 0 {
+				return nil, errors.New("invalid trie node path")
+			}
+			nibbles, leaf := decodeCompactPath(compact)
+			if !bytes.HasPrefix(path, nibbles) {
+				return nil, nil
+			}
+			path = path[len(nibbles):]
+			if leaf {
+				if len(path) != 0 {
+					return nil, nil
+				}
+				return decodeTrieValue(elems[1])
+			}
+			child = elems[1]
+		default:
+			return nil, fmt.Errorf("invalid trie node with %d elements", len(elems))
+		}
+
+		kind, ref, _, err := rlp.Split(child)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trie node reference: %v", err)
+		}
+		switch {
+		case kind == rlp.List:
+			node = child
+		case len(ref) == 0:
+			return nil, nil
+		case len(ref) == common.HashLength:
+			if node, ok = nodes[common.BytesToHash(ref)]; !ok {
+				return nil, fmt.Errorf("proof is missing node %x", ref)
+			}
+		default:
+			return nil, errors.New("invalid trie node reference")
+		}
+	}
+}
+
+func decodeTrieValue(raw rlp.RawValue) ([]byte, error) {
+	var value []byte
+	if err := rlp.DecodeBytes(raw, &value); err != nil {
+		return nil, fmt.Errorf("invalid trie value: %v", err)
+	}
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func keyNibbles(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[i*2] = b >> 4
+		nibbles[i*2+1] = b & 0x0f
+	}
+	return nibbles
+}
+
+// compactPath encodes nibbles using the hex prefix encoding,
+// which flags leaves and paths with an odd amount of nibbles
+func compactPath(nibbles []byte, leaf bool) []byte {
+	var flag byte
+	if leaf {
+		flag = 2
+	}
+	path := make([]byte, 0, len(nibbles)/2+1)
+	if len(nibbles)%2 == 1 {
+		path = append(path, (flag|1)<<4|nibbles[0])
+		nibbles = nibbles[1:]
+	} else {
+		path = append(path, flag<<4)
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		path = append(path, nibbles[i]<<4|nibbles[i+1])
+	}
+	return path
+}
+
+func decodeCompactPath(path []byte) (nibbles []byte, leaf bool) {
+	flag := path[0] >> 4
+	nibbles = keyNibbles(path)
+	if flag&1 == 1 {
+		return nibbles[1:], flag&2 == 2
+	}
+	return nibbles[2:], flag&2 == 2
+}
+
+func commonPrefix(a, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
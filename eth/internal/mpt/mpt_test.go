@@ -0,0 +1,72 @@
+package mpt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestProve(t *testing.T) {
+	// roots of the trie tests of go-ethereum
+	keys := [][]byte{[]byte("doe"), []byte("dog"), []byte("dogglesworth")}
+	values := [][]byte{[]byte("reindeer"), []byte("puppy"), []byte("cat")}
+	root, proof := Prove(keys, values, []byte("dog"))
+	if root != common.HexToHash("8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3") {
+		t.Errorf("unexpected root %x", root)
+	}
+	if value, err := Verify(root, []byte("dog"), proof); err != nil || string(value) != "puppy" {
+		t.Errorf("proven value %q (%v), expected puppy", value, err)
+	}
+	if value, err := Verify(root, []byte("cat"), proof); err != nil || value != nil {
+		t.Errorf("proven value %q (%v) of a missing key", value, err)
+	}
+	root, _ = Prove([][]byte{[]byte("A")}, [][]byte{[]byte("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")}, []byte("A"))
+	if root != common.HexToHash("d23786fb4a010da3ce639d66d5e904a11dbc02746d1ce25029e53290cabf28ab") {
+		t.Errorf("unexpected root %x", root)
+	}
+	if root, _ = Prove(nil, nil, nil); root != types.EmptyRootHash {
+		t.Errorf("unexpected empty root %x", root)
+	}
+}
+
+func TestHasher(t *testing.T) {
+	if root := types.DeriveSha(types.Transactions{}, new(Hasher)); root != types.EmptyRootHash {
+		t.Errorf("unexpected empty root %x", root)
+	}
+	to := common.HexToAddress("0xb0b")
+	txs := make(types.Transactions, 130)
+	for i := range txs {
+		txs[i] = types.NewTx(&types.LegacyTx{Nonce: uint64(i), To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)})
+	}
+	// the hasher is reused for the transactions and receipts of a block
+	hasher := new(Hasher)
+	types.DeriveSha(txs[:2], hasher)
+	root := types.DeriveSha(txs, hasher)
+	for _, i := range []int{0, 127, 129} {
+		b, err := txs[i].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := []byte{byte(i)}
+		switch {
+		case i == 0:
+			key = []byte{0x80}
+		case i > 127:
+			key = []byte{0x81, byte(i)}
+		}
+		value, err := Verify(root, key, proofOf(txs, key))
+		if err != nil || string(value) != string(b) {
+			t.Errorf("transaction %d: proven value %x (%v), expected %x", i, value, err, b)
+		}
+	}
+}
+
+// proofOf proves the transaction with the RLP encoded index as key
+func proofOf(txs types.Transactions, key []byte) [][]byte {
+	h := new(Hasher)
+	types.DeriveSha(txs, h)
+	_, proof := Prove(h.keys, h.values, key)
+	return proof
+}
@@ -0,0 +1,137 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Network is the profile of an Ethereum (compatible) chain
+type Network struct {
+	// Name of the network, used to select it
+	Name string `json:"name"`
+	// ChainID is the EIP-155 chain ID of the network
+	ChainID *big.Int `json:"chainId"`
+	// RPCURL is the default endpoint of the RPC server to connect to
+	RPCURL string `json:"rpc"`
+	// Contract is the address of the deployed AtomicSwap contract, the zero address if none
+	Contract common.Address `json:"contract"`
+	// TokenContract is the address of the deployed TokenAtomicSwap contract, the zero address if none
+	TokenContract common.Address `json:"tokenContract"`
+	// Confirmations is the amount of blocks, including the one of the transaction,
+	// a transaction has to be buried under to be considered final
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// Validate the network profile
+func (n Network) Validate() error {
+	if n.Name == "" {
+		return errors.New("network has no name")
+	}
+	if n.ChainID == nil || n.ChainID.Sign() <= 0 {
+		return fmt.Errorf("network %s has an invalid chain ID", n.Name)
+	}
+	if n.RPCURL == "" {
+		return fmt.Errorf("network %s has no RPC URL", n.Name)
+	}
+	if n.Confirmations == 0 {
+		return fmt.Errorf("network %s requires at least one confirmation", n.Name)
+	}
+	return nil
+}
+
+// CheckChainID verifies the node the client is connected to is on the network
+func (n Network) CheckChainID(ctx context.Context, client *EthClient) error {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %v", err)
+	}
+	if chainID.Cmp(n.ChainID) != 0 {
+		return fmt.Errorf("node is on the chain with ID %s, while network %s has chain ID %s", chainID, n.Name, n.ChainID)
+	}
+	return nil
+}
+
+// Networks is a registry of network profiles, by name
+type Networks map[string]Network
+
+// DefaultNetworks returns a registry with the built-in network profiles.
+// Mainnet expects a local node, the test networks default to a public endpoint.
+func DefaultNetworks() Networks {
+	return Networks{
+		"mainnet": {
+			Name:          "mainnet",
+			ChainID:       big.NewInt(1),
+			RPCURL:        "http://localhost:8545",
+			Confirmations: 12,
+		},
+		"sepolia": {
+			Name:          "sepolia",
+			ChainID:       big.NewInt(11155111),
+			RPCURL:        "https://rpc.sepolia.org",
+			Confirmations: 3,
+		},
+		"holesky": {
+			Name:          "holesky",
+			ChainID:       big.NewInt(17000),
+			RPCURL:        "https://ethereum-holesky.publicnode.com",
+			Confirmations: 3,
+		},
+	}
+}
+
+// Add a network profile to the registry, replacing the one with the same name
+func (ns Networks) Add(n Network) error {
+	if err := n.Validate(); err != nil {
+		return err
+	}
+	ns[n.Name] = n
+	return nil
+}
+
+// Load the network profiles of a JSON file, a list of profiles such as
+//
+//	[{"name": "bsc", "chainId": 56, "rpc": "https://bsc-dataseed.binance.org", "contract": "0x...", "confirmations": 15}]
+//
+// into the registry, replacing the built-in profiles with the same name.
+func (ns Networks) Load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read networks file (%s): %v", path, err)
+	}
+	var networks []Network
+	if err = json.Unmarshal(b, &networks); err != nil {
+		return fmt.Errorf("failed to decode networks file (%s): %v", path, err)
+	}
+	for _, n := range networks {
+		if err = ns.Add(n); err != nil {
+			return fmt.Errorf("invalid network in networks file (%s): %v", path, err)
+		}
+	}
+	return nil
+}
+
+// Get the network profile with the given name
+func (ns Networks) Get(name string) (Network, error) {
+	n, ok := ns[name]
+	if !ok {
+		return Network{}, fmt.Errorf("unknown network %s, known networks are %v", name, ns.Names())
+	}
+	return n, nil
+}
+
+// Names of the networks in the registry, sorted
+func (ns Networks) Names() []string {
+	names := make([]string, 0, len(ns))
+	for name := range ns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
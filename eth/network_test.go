@@ -0,0 +1,86 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestNetworks(t *testing.T) {
+	networks := DefaultNetworks()
+	for _, name := range []string{"mainnet", "sepolia", "holesky"} {
+		n, err := networks.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = n.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := networks.Get("rinkeby"); err == nil {
+		t.Error("got an unknown network")
+	}
+
+	path := filepath.Join(t.TempDir(), "networks.json")
+	config := `[
+		{"name": "bsc", "chainId": 56, "rpc": "https://bsc-dataseed.binance.org", "contract": "0x2661CBAa149721f7c5FAB3FA88C1EA564A683631", "confirmations": 15},
+		{"name": "sepolia", "chainId": 11155111, "rpc": "http://localhost:8545", "confirmations": 1}
+	]`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := networks.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	bsc, err := networks.Get("bsc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bsc.ChainID.Int64() != 56 || bsc.Confirmations != 15 ||
+		bsc.Contract != common.HexToAddress("0x2661CBAa149721f7c5FAB3FA88C1EA564A683631") {
+		t.Errorf("unexpected network: %+v", bsc)
+	}
+	if sepolia, _ := networks.Get("sepolia"); sepolia.RPCURL != "http://localhost:8545" {
+		t.Error("built-in network not replaced")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "private", "rpc": "http://localhost:8545", "confirmations": 1}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := networks.Load(path); err == nil {
+		t.Error("loaded a network without chain ID")
+	}
+}
+
+type chainIDStub struct {
+	chainID *big.Int
+}
+
+func (s *chainIDStub) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(s.chainID)
+}
+
+func TestNetworkCheckChainID(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", &chainIDStub{chainID: big.NewInt(17000)}); err != nil {
+		t.Fatal(err)
+	}
+	c := rpc.DialInProc(server)
+	client := NewRPCClient(c)
+	defer client.Close()
+
+	networks := DefaultNetworks()
+	if err := networks["holesky"].CheckChainID(context.Background(), client); err != nil {
+		t.Error(err)
+	}
+	if err := networks["sepolia"].CheckChainID(context.Background(), client); err == nil {
+		t.Error("chain ID of another network accepted")
+	}
+}
@@ -0,0 +1,156 @@
+package eth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// UnsignedTransaction is a transaction built by a host connected to a node,
+// to be signed by a host holding the key of the account, which can be offline,
+// after which any host can broadcast it.
+type UnsignedTransaction struct {
+	// Action of the transaction: initiate, participate, redeem, refund or approve
+	Action string `json:"action"`
+	// From is the account that has to sign the transaction
+	From common.Address `json:"from"`
+	// ChainID of the chain the transaction has to be signed for
+	ChainID *big.Int `json:"chainId"`
+	// Transaction is the binary encoded unsigned transaction,
+	// holding the nonce, gas, fees and the call data of the contract
+	Transaction hexutil.Bytes `json:"transaction"`
+}
+
+// Tx decodes the unsigned transaction
+func (ut UnsignedTransaction) Tx() (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(ut.Transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode unsigned transaction: %v", err)
+	}
+	if v, r, s := tx.RawSignatureValues(); v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0 {
+		return nil, errors.New("transaction is already signed")
+	}
+	return tx, nil
+}
+
+// Sign the transaction with the signer, which has to sign for the From account.
+// This does not require a connection to a node when using a KeySigner.
+func (ut UnsignedTransaction) Sign(ctx context.Context, signer Signer) (*types.Transaction, error) {
+	if signer.Address() != ut.From {
+		return nil, fmt.Errorf("transaction has to be signed by %x, not %x", ut.From, signer.Address())
+	}
+	if ut.ChainID == nil || ut.ChainID.Sign() <= 0 {
+		return nil, errors.New("transaction has no valid chain ID")
+	}
+	tx, err := ut.Tx()
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type() != types.LegacyTxType && tx.ChainId().Cmp(ut.ChainID) != 0 {
+		return nil, fmt.Errorf("transaction is for chain ID %s, not %s", tx.ChainId(), ut.ChainID)
+	}
+	signedTx, err := signer.SignTx(ctx, tx, ut.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifySignedTx(tx, signedTx, ut.From, ut.ChainID); err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return signedTx, nil
+}
+
+// forExport returns a copy of the transactor building unsigned transactions
+func (sct SwapContractTransactor) forExport() SwapContractTransactor {
+	sct.unsigned = true
+	return sct
+}
+
+// unsignedTransaction exports the transaction built for the action
+func (sct *SwapContractTransactor) unsignedTransaction(action string, tx *swapTransaction) (UnsignedTransaction, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to encode unsigned %s TX: %v", action, err)
+	}
+	return UnsignedTransaction{
+		Action:      action,
+		From:        sct.FromAddr,
+		ChainID:     sct.chainID,
+		Transaction: b,
+	}, nil
+}
+
+// BuildInitiate builds the unsigned transaction initiating an atomic swap
+// for the secret hash, see Initiate. The contract has to be approved already
+// to transfer the amount of a token, see BuildApprove.
+func BuildInitiate(ctx context.Context, sct SwapContractTransactor, cp2Addr common.Address, amount *big.Int, secretHash [sha256.Size]byte, policy timings.Policy, token common.Address) (UnsignedTransaction, error) {
+	if err := policy.Validate(); err != nil {
+		return UnsignedTransaction{}, err
+	}
+	sct = sct.forToken(token).forExport()
+	tx, err := sct.initiateTx(ctx, amount, secretHash, cp2Addr, policy.Initiator)
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to create initiate TX: %v", err)
+	}
+	return sct.unsignedTransaction("initiate", tx)
+}
+
+// BuildParticipate builds the unsigned transaction participating in an atomic swap,
+// see Participate. The contract has to be approved already
+// to transfer the amount of a token, see BuildApprove.
+func BuildParticipate(ctx context.Context, sct SwapContractTransactor, cp1Addr common.Address, amount *big.Int, secretHash [sha256.Size]byte, policy timings.Policy, token common.Address) (UnsignedTransaction, error) {
+	if err := policy.Validate(); err != nil {
+		return UnsignedTransaction{}, err
+	}
+	sct = sct.forToken(token).forExport()
+	tx, err := sct.participateTx(ctx, amount, secretHash, cp1Addr, policy.Participant)
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to create participate TX: %v", err)
+	}
+	return sct.unsignedTransaction("participate", tx)
+}
+
+// BuildRedeem builds the unsigned transaction redeeming an atomic swap, see Redeem
+func BuildRedeem(ctx context.Context, sct SwapContractTransactor, secretHash [sha256.Size]byte, secret [sha256.Size]byte, token common.Address) (UnsignedTransaction, error) {
+	sct = sct.forToken(token).forExport()
+	tx, err := sct.redeemTx(ctx, secretHash, secret)
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to create redeem TX: %v", err)
+	}
+	return sct.unsignedTransaction("redeem", tx)
+}
+
+// BuildRefund builds the unsigned transaction refunding an atomic swap, see Refund
+func BuildRefund(ctx context.Context, sct SwapContractTransactor, contractTx *types.Transaction, token common.Address) (UnsignedTransaction, error) {
+	sct = sct.forToken(token).forExport()
+	params, err := unpackContractInputParams(sct.Abi, contractTx)
+	if err != nil {
+		return UnsignedTransaction{}, err
+	}
+	tx, err := sct.refundTx(ctx, params.SecretHash)
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to create refund TX: %v", err)
+	}
+	return sct.unsignedTransaction("refund", tx)
+}
+
+// BuildApprove builds the unsigned transaction allowing the TokenAtomicSwap contract
+// to transfer the amount of the token, which has to be mined
+// before the transactions locking the tokens can be built.
+func BuildApprove(ctx context.Context, sct SwapContractTransactor, amount *big.Int, token common.Address) (UnsignedTransaction, error) {
+	if token == (common.Address{}) {
+		return UnsignedTransaction{}, errors.New("only tokens have to be approved")
+	}
+	sct = sct.forToken(token).forExport()
+	tx, err := sct.approveTx(ctx, amount)
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to create approve TX: %v", err)
+	}
+	return sct.unsignedTransaction("approve", tx)
+}
@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestUnsignedTransactionSign(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewKeySigner(key)
+	chainID := big.NewInt(11155111)
+	to := common.HexToAddress("0xb0b")
+	for _, rawTx := range []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 3, GasPrice: big.NewInt(10), Gas: 50000, To: &to, Value: big.NewInt(1), Data: []byte{1, 2}}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(20), Gas: 50000, To: &to, Data: []byte{1, 2}}),
+	} {
+		b, err := rawTx.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sct := SwapContractTransactor{FromAddr: signer.Address(), chainID: chainID}
+		exported, err := sct.unsignedTransaction("redeem", &swapTransaction{Transaction: rawTx})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the unsigned transaction is passed between hosts as JSON
+		encoded, err := json.Marshal(exported)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ut UnsignedTransaction
+		if err = json.Unmarshal(encoded, &ut); err != nil {
+			t.Fatal(err)
+		}
+		if ut.Action != "redeem" || ut.From != signer.Address() || ut.ChainID.Cmp(chainID) != 0 || string(ut.Transaction) != string(b) {
+			t.Fatalf("unexpected unsigned transaction: %+v", ut)
+		}
+
+		signedTx, err := ut.Sign(ctx, signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signedTx.Nonce() != 3 || signedTx.Gas() != 50000 || *signedTx.To() != to {
+			t.Error("signed transaction differs from the unsigned one")
+		}
+		if sender, err := types.Sender(types.NewLondonSigner(chainID), signedTx); err != nil || sender != signer.Address() {
+			t.Errorf("transaction signed by %x (%v), expected %x", sender, err, signer.Address())
+		}
+
+		otherKey, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ut.Sign(ctx, NewKeySigner(otherKey)); err == nil {
+			t.Error("signed by another account")
+		}
+		if ut.Transaction, err = signedTx.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ut.Sign(ctx, signer); err == nil {
+			t.Error("signed a signed transaction")
+		}
+	}
+}
@@ -6,18 +6,27 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/threefoldtech/atomicswap/timings"
 )
 
 type (
 	ParticipateOutput struct {
-		InitiatorAddress        common.Address `json:"initiatorAddress"`
-		ContractTransactionHash common.Hash    `json:"contractTransactionHash"`
+		InitiatorAddress        common.Address    `json:"initiatorAddress"`
+		ContractTransactionHash common.Hash       `json:"contractTransactionHash"`
+		ContractTransaction     types.Transaction `json:"contractTransaction"`
 	}
 )
 
-// Participate in an atomic swap
-func Participate(ctx context.Context, sct SwapContractTransactor, cp1Addr common.Address, amount *big.Int, secretHash [32]byte) (ParticipateOutput, error) {
-	tx, err := sct.participateTx(ctx, amount, secretHash, cp1Addr)
+// Participate in an atomic swap, locked for the participant lock time of the policy.
+// The amount is in the smallest unit of the token, the zero address for Ether.
+// Locking a token first approves the contract to transfer it, if not done yet.
+func Participate(ctx context.Context, sct SwapContractTransactor, cp1Addr common.Address, amount *big.Int, secretHash [32]byte, policy timings.Policy, token common.Address) (ParticipateOutput, error) {
+	if err := policy.Validate(); err != nil {
+		return ParticipateOutput{}, err
+	}
+	sct = sct.forToken(token)
+	tx, err := sct.participateTx(ctx, amount, secretHash, cp1Addr, policy.Participant)
 	if err != nil {
 		return ParticipateOutput{}, fmt.Errorf("failed to create participate TX: %v", err)
 	}
@@ -30,5 +39,6 @@ func Participate(ctx context.Context, sct SwapContractTransactor, cp1Addr common
 	return ParticipateOutput{
 		InitiatorAddress:        sct.FromAddr,
 		ContractTransactionHash: tx.Hash(),
+		ContractTransaction:     *tx.Transaction,
 	}, nil
 }
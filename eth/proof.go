@@ -0,0 +1,219 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+	"github.com/threefoldtech/atomicswap/eth/internal/mpt"
+)
+
+// ContractProof proves the contract transaction of an atomic swap
+// was included and executed in a block, such that the contract
+// can be audited without a node, see AuditContractProof.
+// It is built by the party that created the contract, see BuildContractProof.
+type ContractProof struct {
+	// Header of the block including the contract transaction
+	Header *types.Header `json:"header"`
+	// Index of the contract transaction in the block
+	Index uint64 `json:"index"`
+	// TransactionProof are the nodes of the transactions trie of the block
+	// proving the transaction at the index
+	TransactionProof []hexutil.Bytes `json:"transactionProof"`
+	// ReceiptProof are the nodes of the receipts trie of the block
+	// proving the receipt, holding the logs, of the transaction at the index
+	ReceiptProof []hexutil.Bytes `json:"receiptProof"`
+}
+
+// BuildContractProof builds the proof of a mined contract transaction
+func BuildContractProof(ctx context.Context, client *EthClient, txHash common.Hash) (ContractProof, error) {
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return ContractProof{}, fmt.Errorf("failed to get receipt of transaction (%x): %v", txHash, err)
+	}
+	block, err := client.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return ContractProof{}, fmt.Errorf("failed to find block (%x): %v", receipt.BlockHash, err)
+	}
+	header := block.Header()
+	if header.Hash() != receipt.BlockHash {
+		return ContractProof{}, fmt.Errorf("block (%x) has header fields unknown to this version", receipt.BlockHash)
+	}
+
+	txs := make([][]byte, 0, len(block.Transactions()))
+	receipts := make([][]byte, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		b, err := tx.MarshalBinary()
+		if err != nil {
+			return ContractProof{}, fmt.Errorf("failed to encode transaction (%x): %v", tx.Hash(), err)
+		}
+		txs = append(txs, b)
+		r, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return ContractProof{}, fmt.Errorf("failed to get receipt of transaction (%x): %v", tx.Hash(), err)
+		}
+		if b, err = r.MarshalBinary(); err != nil {
+			return ContractProof{}, fmt.Errorf("failed to encode receipt of transaction (%x): %v", tx.Hash(), err)
+		}
+		receipts = append(receipts, b)
+	}
+
+	index := uint64(receipt.TransactionIndex)
+	txProof, err := proveIndex(txs, index, header.TxHash)
+	if err != nil {
+		return ContractProof{}, fmt.Errorf("failed to prove transaction: %v", err)
+	}
+	receiptProof, err := proveIndex(receipts, index, header.ReceiptHash)
+	if err != nil {
+		return ContractProof{}, fmt.Errorf("failed to prove receipt: %v", err)
+	}
+	return ContractProof{
+		Header:           header,
+		Index:            index,
+		TransactionProof: txProof,
+		ReceiptProof:     receiptProof,
+	}, nil
+}
+
+// AuditContractProof audits the contract transaction of an atomic swap locking the token,
+// the zero address for Ether, like AuditContract but without a node.
+// The block of the proof has to be the one with the trusted block hash,
+// which the auditor has to get from a source it trusts.
+// The audit output is taken from the Initiated or Participated event of the proven receipt,
+// which has to match the contract transaction.
+// The proof does not cover the code of the contract, so unlike AuditContract
+// the contract transaction has to call the trusted contract, a deployment known to the auditor.
+func AuditContractProof(contractTx *types.Transaction, proof ContractProof, trustedBlockHash common.Hash, trustedContract common.Address, token common.Address) (AuditContractOutput, error) {
+	if proof.Header == nil {
+		return AuditContractOutput{}, errors.New("proof has no block header")
+	}
+	if blockHash := proof.Header.Hash(); blockHash != trustedBlockHash {
+		return AuditContractOutput{}, fmt.Errorf("proof is for block (%x), not for the trusted block (%x)", blockHash, trustedBlockHash)
+	}
+	if contractTx.To() == nil {
+		return AuditContractOutput{}, errors.New("contract transaction does not call a contract")
+	}
+	if *contractTx.To() != trustedContract {
+		return AuditContractOutput{}, fmt.Errorf("contract transaction calls contract (at %x), not the trusted contract (at %x)", *contractTx.To(), trustedContract)
+	}
+
+	txBytes, err := verifyIndexProof(proof.Header.TxHash, proof.Index, proof.TransactionProof)
+	if err != nil {
+		return AuditContractOutput{}, fmt.Errorf("invalid transaction proof: %v", err)
+	}
+	contractTxBytes, err := contractTx.MarshalBinary()
+	if err != nil {
+		return AuditContractOutput{}, fmt.Errorf("failed to encode contract transaction: %v", err)
+	}
+	if !bytes.Equal(txBytes, contractTxBytes) {
+		return AuditContractOutput{}, errors.New("proof is not for the contract transaction")
+	}
+	receiptBytes, err := verifyIndexProof(proof.Header.ReceiptHash, proof.Index, proof.ReceiptProof)
+	if err != nil {
+		return AuditContractOutput{}, fmt.Errorf("invalid receipt proof: %v", err)
+	}
+	receipt := new(types.Receipt)
+	if err = receipt.UnmarshalBinary(receiptBytes); err != nil {
+		return AuditContractOutput{}, fmt.Errorf("failed to decode receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return AuditContractOutput{}, errors.New("contract transaction failed")
+	}
+
+	contractABI := atomicSwapABI
+	if token != (common.Address{}) {
+		contractABI = tokenContractABI
+	}
+	params, err := unpackContractInputParams(contractABI, contractTx)
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	if params.Token != token {
+		return AuditContractOutput{}, fmt.Errorf("contract locks %s, not %s", assetName(params.Token), assetName(token))
+	}
+	value := contractTx.Value()
+	if params.Value != nil {
+		value = params.Value
+	}
+
+	// the TokenAtomicSwap contract emits the same events as the AtomicSwap contract
+	filterer, err := contract.NewContractFilterer(*contractTx.To(), nil)
+	if err != nil {
+		return AuditContractOutput{}, err
+	}
+	for _, log := range receipt.Logs {
+		if log.Address != *contractTx.To() {
+			continue
+		}
+		e, err := decodeEvent(atomicSwapABI, filterer, *log)
+		if err != nil || e.SecretHash != params.SecretHash {
+			continue
+		}
+		// the participant of a Participated event is the one that locked the funds
+		recipient, refunder := e.Participant, e.Initiator
+		switch e.Kind {
+		case EventInitiated:
+		case EventParticipated:
+			recipient, refunder = e.Initiator, e.Participant
+		default:
+			continue
+		}
+		if recipient != params.ToAddress || e.Value.Cmp(value) != 0 || e.RefundTime.Cmp(params.LockDuration) != 0 {
+			return AuditContractOutput{}, fmt.Errorf("%s event does not match the contract transaction", e.Kind)
+		}
+		return AuditContractOutput{
+			ContractAddress:  *contractTx.To(),
+			ContractValue:    value,
+			RecipientAddress: recipient,
+			RefundAddress:    refunder,
+			SecretHash:       params.SecretHash,
+			Locktime:         int64(proof.Header.Time) + params.LockDuration.Int64(),
+			Token:            token,
+		}, nil
+	}
+	return AuditContractOutput{}, errors.New("receipt has no Initiated or Participated event for the contract")
+}
+
+// proveIndex returns the proof of the value at the index,
+// in the trie of the values of a block with the given root
+func proveIndex(values [][]byte, index uint64, root common.Hash) ([]hexutil.Bytes, error) {
+	if index >= uint64(len(values)) {
+		return nil, fmt.Errorf("index %d out of range", index)
+	}
+	keys := make([][]byte, len(values))
+	for i := range values {
+		keys[i] = rlp.AppendUint64(nil, uint64(i))
+	}
+	trieRoot, nodes := mpt.Prove(keys, values, keys[index])
+	if trieRoot != root {
+		return nil, errors.New("trie root does not match the block header")
+	}
+	proof := make([]hexutil.Bytes, len(nodes))
+	for i, node := range nodes {
+		proof[i] = node
+	}
+	return proof, nil
+}
+
+// verifyIndexProof returns the value at the index proven to be in the trie with the given root
+func verifyIndexProof(root common.Hash, index uint64, proof []hexutil.Bytes) ([]byte, error) {
+	nodes := make([][]byte, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	value, err := mpt.Verify(root, rlp.AppendUint64(nil, index), nodes)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("no value at index %d", index)
+	}
+	return value, nil
+}
@@ -0,0 +1,157 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/threefoldtech/atomicswap/eth/internal/mpt"
+)
+
+func TestIndexProof(t *testing.T) {
+	// tries of blocks, keyed by the RLP encoded index, pass 0x7f and 0x80
+	values := make([][]byte, 300)
+	for i := range values {
+		values[i] = crypto.Keccak256(big.NewInt(int64(i)).Bytes())
+	}
+	for _, index := range []uint64{0, 1, 127, 128, 129, 299} {
+		root, proof, err := proveBlockTrie(values, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := verifyIndexProof(root, index, proof)
+		if err != nil {
+			t.Fatalf("index %d: %v", index, err)
+		}
+		if string(value) != string(values[index]) {
+			t.Errorf("index %d: proven value %x, expected %x", index, value, values[index])
+		}
+		if _, err = verifyIndexProof(root, index+1, proof); err == nil {
+			t.Errorf("index %d: proof proves another index", index)
+		}
+	}
+	if _, err := proveIndex(values, 3, common.Hash{1}); err == nil {
+		t.Error("proved a value for another root")
+	}
+}
+
+// proveBlockTrie proves the value at the index of a trie keyed by index
+func proveBlockTrie(values [][]byte, index uint64) (common.Hash, []hexutil.Bytes, error) {
+	keys := make([][]byte, len(values))
+	for i := range values {
+		keys[i] = rlp.AppendUint64(nil, uint64(i))
+	}
+	root, _ := mpt.Prove(keys, values, nil)
+	proof, err := proveIndex(values, index, root)
+	return root, proof, err
+}
+
+func TestAuditContractProof(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(5)
+	initiator := crypto.PubkeyToAddress(key.PublicKey)
+	participant, contractAddr := common.HexToAddress("0xb0b"), common.HexToAddress("0xc0de")
+	secretHash := sha256Hash([]byte("secret"))
+	value := big.NewInt(1000)
+	lockDuration := big.NewInt(48 * 3600)
+
+	input, err := atomicSwapABI.Pack("initiate", lockDuration, secretHash, participant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractTx, err := NewKeySigner(key).SignTx(context.Background(), types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10),
+		Gas: 200000, To: &contractAddr, Value: value, Data: input,
+	}), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherTx, err := NewKeySigner(key).SignTx(context.Background(), types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 0, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10),
+		Gas: 21000, To: &participant, Value: big.NewInt(1),
+	}), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockTime := uint64(1700000000)
+	event := atomicSwapABI.Events["Initiated"]
+	data, err := event.Inputs.Pack(new(big.Int).SetUint64(blockTime), lockDuration, secretHash, initiator, participant, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipts := []*types.Receipt{
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000},
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 150000,
+			Logs: []*types.Log{{Address: contractAddr, Topics: []common.Hash{event.ID}, Data: data}}},
+	}
+	var txBytes, receiptBytes [][]byte
+	for i, tx := range []*types.Transaction{otherTx, contractTx} {
+		b, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		txBytes = append(txBytes, b)
+		receipts[i].Bloom = types.CreateBloom(types.Receipts{receipts[i]})
+		if b, err = receipts[i].MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+		receiptBytes = append(receiptBytes, b)
+	}
+	txRoot, txProof, err := proveBlockTrie(txBytes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiptRoot, receiptProof, err := proveBlockTrie(receiptBytes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.Header{Number: big.NewInt(100), Time: blockTime, TxHash: txRoot, ReceiptHash: receiptRoot, Difficulty: new(big.Int)}
+	proof := ContractProof{Header: header, Index: 1, TransactionProof: txProof, ReceiptProof: receiptProof}
+
+	// the proof is sent to the auditor as JSON
+	encoded, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof = ContractProof{}
+	if err = json.Unmarshal(encoded, &proof); err != nil {
+		t.Fatal(err)
+	}
+	output, err := AuditContractProof(contractTx, proof, header.Hash(), contractAddr, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.ContractAddress != contractAddr || output.ContractValue.Cmp(value) != 0 || output.RecipientAddress != participant ||
+		output.RefundAddress != initiator || output.SecretHash != secretHash || output.Locktime != int64(blockTime)+lockDuration.Int64() {
+		t.Errorf("unexpected audit output: %+v", output)
+	}
+
+	if _, err = AuditContractProof(contractTx, proof, common.Hash{1}, contractAddr, common.Address{}); err == nil {
+		t.Error("audited a block that is not trusted")
+	}
+	if _, err = AuditContractProof(otherTx, proof, header.Hash(), participant, common.Address{}); err == nil {
+		t.Error("audited a proof of another transaction")
+	}
+	wrongIndex := proof
+	wrongIndex.Index = 0
+	if _, err = AuditContractProof(contractTx, wrongIndex, header.Hash(), contractAddr, common.Address{}); err == nil {
+		t.Error("audited a proof of another index")
+	}
+	if _, err = AuditContractProof(contractTx, proof, header.Hash(), contractAddr, common.HexToAddress("0x7043")); err == nil {
+		t.Error("audited Ether as a token")
+	}
+	if _, err = AuditContractProof(contractTx, proof, header.Hash(), participant, common.Address{}); err == nil {
+		t.Error("audited a contract that is not trusted")
+	}
+}
@@ -17,15 +17,22 @@ type (
 		RedeemTxHash common.Hash `json:"redeemTransactionHash"`
 	}
 
-	params struct {
+	// ContractParams are the arguments of the initiate or participate call
+	// creating an atomic swap contract
+	ContractParams struct {
 		LockDuration *big.Int
 		SecretHash   [sha256.Size]byte
 		ToAddress    common.Address
+		// Token and Value of the tokens locked by a TokenAtomicSwap contract,
+		// nil and the zero address for Ether
+		Token common.Address
+		Value *big.Int
 	}
 )
 
-// Redeem an atomic swap
-func Redeem(ctx context.Context, sct SwapContractTransactor, secretHash [sha256.Size]byte, secret [32]byte) (RedeemOutput, error) {
+// Redeem an atomic swap locking the token, the zero address for Ether
+func Redeem(ctx context.Context, sct SwapContractTransactor, secretHash [sha256.Size]byte, secret [32]byte, token common.Address) (RedeemOutput, error) {
+	sct = sct.forToken(token)
 	tx, err := sct.redeemTx(ctx, secretHash, secret)
 	if err != nil {
 		return RedeemOutput{}, fmt.Errorf("failed to create redeem TX: %v", err)
@@ -40,8 +47,25 @@ func Redeem(ctx context.Context, sct SwapContractTransactor, secretHash [sha256.
 	}, nil
 }
 
-func unpackContractInputParams(abi abi.ABI, tx *types.Transaction) (params params, err error) {
+// UnpackContractParams unpacks the arguments of the contract transaction
+// of an atomic swap, calling an AtomicSwap, RelayAtomicSwap or TokenAtomicSwap contract.
+// This does not require a connection to a node.
+func UnpackContractParams(contractTx *types.Transaction) (ContractParams, error) {
+	data := contractTx.Data()
+	if len(data) >= 4 {
+		if _, err := tokenContractABI.MethodById(data[:4]); err == nil {
+			return unpackContractInputParams(tokenContractABI, contractTx)
+		}
+	}
+	return unpackContractInputParams(atomicSwapABI, contractTx)
+}
+
+func unpackContractInputParams(abi abi.ABI, tx *types.Transaction) (params ContractParams, err error) {
 	txData := tx.Data()
+	if len(txData) < 4 {
+		err = errors.New("transaction does not call a contract method")
+		return
+	}
 
 	// first 4 bytes contain the id, so let's get method using that ID
 	method, err := abi.MethodById(txData[:4])
@@ -53,9 +77,11 @@ func unpackContractInputParams(abi abi.ABI, tx *types.Transaction) (params param
 	rawParams, err := method.Inputs.Unpack(txData[4:])
 	if err != nil {
 		err = fmt.Errorf("failed to unpack method's input params: %v", err)
+		return
 	}
 
-	if len(rawParams) != 3 {
+	// the TokenAtomicSwap contract takes the token and value as extra arguments
+	if len(rawParams) != 3 && len(rawParams) != 5 {
 		err = errors.New("unexpected argument count")
 		return
 	}
@@ -77,5 +103,15 @@ func unpackContractInputParams(abi abi.ABI, tx *types.Transaction) (params param
 	params.LockDuration = lockDuration
 	params.SecretHash = secretHash
 	params.ToAddress = toAddress
+	if len(rawParams) == 5 {
+		if params.Token, ok = rawParams[3].(common.Address); !ok {
+			err = errors.New("could not parse token address")
+			return
+		}
+		if params.Value, ok = rawParams[4].(*big.Int); !ok {
+			err = errors.New("could not parse token value")
+			return
+		}
+	}
 	return
 }
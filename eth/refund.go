@@ -8,7 +8,9 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-func Refund(ctx context.Context, sct SwapContractTransactor, contractTx *types.Transaction) (common.Hash, error) {
+// Refund an atomic swap locking the token, the zero address for Ether
+func Refund(ctx context.Context, sct SwapContractTransactor, contractTx *types.Transaction, token common.Address) (common.Hash, error) {
+	sct = sct.forToken(token)
 	params, err := unpackContractInputParams(sct.Abi, contractTx)
 	if err != nil {
 		return common.Hash{}, err
@@ -0,0 +1,215 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RedeemAuthorization authorises a relayer to redeem an atomic swap for its recipient,
+// in exchange for a fee out of the value of the swap, see AuthorizeRedeem and RelayRedeem.
+// Redeeming without a fee needs no authorisation.
+type RedeemAuthorization struct {
+	// Contract is the RelayAtomicSwap or TokenAtomicSwapV2 contract holding the swap
+	Contract common.Address `json:"contract"`
+	// ChainID of the chain of the contract
+	ChainID    *big.Int          `json:"chainId"`
+	SecretHash [sha256.Size]byte `json:"secretHash"`
+	// Relayer is the only account allowed to submit the redemption for the fee
+	Relayer common.Address `json:"relayer"`
+	// Fee paid to the relayer, in Wei or in the smallest unit of the token
+	Fee *big.Int `json:"fee"`
+	// Signature of the recipient of the swap over the relay hash,
+	// as an Ethereum signed message (personal_sign), with V 27 or 28
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// AuthorizeRedeem authorises the relayer to redeem the atomic swap with the secret hash
+// for the fee, signing the authorisation with the key of the recipient of the swap.
+// This does not require a connection to a node.
+func AuthorizeRedeem(key *ecdsa.PrivateKey, contractAddr common.Address, chainID *big.Int, secretHash [sha256.Size]byte, relayer common.Address, fee *big.Int) (RedeemAuthorization, error) {
+	auth := RedeemAuthorization{
+		Contract:   contractAddr,
+		ChainID:    chainID,
+		SecretHash: secretHash,
+		Relayer:    relayer,
+		Fee:        fee,
+	}
+	if err := auth.validate(); err != nil {
+		return RedeemAuthorization{}, err
+	}
+	sig, err := crypto.Sign(accounts.TextHash(auth.relayHash().Bytes()), key)
+	if err != nil {
+		return RedeemAuthorization{}, fmt.Errorf("failed to sign redeem authorization: %v", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	auth.Signature = sig
+	return auth, nil
+}
+
+// Recipient returns the account that signed the authorisation,
+// which has to be the recipient of the swap
+func (auth RedeemAuthorization) Recipient() (common.Address, error) {
+	if err := auth.validate(); err != nil {
+		return common.Address{}, err
+	}
+	if len(auth.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid redeem authorization signature length: %d", len(auth.Signature))
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, auth.Signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(auth.relayHash().Bytes()), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid redeem authorization signature: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func (auth RedeemAuthorization) validate() error {
+	if auth.ChainID == nil || auth.ChainID.Sign() <= 0 {
+		return errors.New("redeem authorization has no valid chain ID")
+	}
+	if auth.Fee == nil || auth.Fee.Sign() <= 0 {
+		return errors.New("redeem authorization has no positive fee")
+	}
+	return nil
+}
+
+// relayHash is the hash signed by the recipient,
+// as computed by the relayHash call of the contracts
+func (auth RedeemAuthorization) relayHash() common.Hash {
+	return crypto.Keccak256Hash(
+		auth.Contract.Bytes(),
+		common.BigToHash(auth.ChainID).Bytes(),
+		auth.SecretHash[:],
+		auth.Relayer.Bytes(),
+		common.BigToHash(auth.Fee).Bytes(),
+	)
+}
+
+// RelayRedeem redeems an atomic swap locking the token, the zero address for Ether,
+// for its recipient, using the account of the transactor to pay for the gas.
+// The contract address of the transactor has to be a deployed RelayAtomicSwap contract for Ether,
+// or a TokenAtomicSwapV2 contract for tokens, the first version of the TokenAtomicSwap contract not supporting relayers.
+// Without authorisation the full value of the swap is sent to the recipient,
+// otherwise the relayer is paid the authorised fee out of it.
+func RelayRedeem(ctx context.Context, sct SwapContractTransactor, secretHash [sha256.Size]byte, secret [sha256.Size]byte, auth *RedeemAuthorization, token common.Address) (RedeemOutput, error) {
+	sct = sct.forRelay(token)
+	tx, err := sct.relayRedeemTx(ctx, secretHash, secret, auth)
+	if err != nil {
+		return RedeemOutput{}, fmt.Errorf("failed to create relayed redeem TX: %v", err)
+	}
+
+	err = tx.Send(ctx)
+	if err != nil {
+		return RedeemOutput{}, err
+	}
+	return RedeemOutput{
+		RedeemTxHash: tx.Hash(),
+	}, nil
+}
+
+// forRelay returns a copy of the transactor for the contracts
+// that can be redeemed by a relayer, locking the token, the zero address for Ether
+func (sct SwapContractTransactor) forRelay(token common.Address) SwapContractTransactor {
+	if token != (common.Address{}) {
+		// the TokenAtomicSwap contract supports relayers from its second version
+		return sct.forToken(token)
+	}
+	sct.Abi = relayContractABI
+	return sct
+}
+
+func (sct *SwapContractTransactor) relayRedeemTx(ctx context.Context, secretHash, secret [sha256.Size]byte, auth *RedeemAuthorization) (*swapTransaction, error) {
+	// validate swap contract,
+	// as to provide more meaningful errors
+	contractABI, err := sct.swapsABI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := contractABI.Methods["redeemFor"]; !ok {
+		return nil, fmt.Errorf("contract (at %x) does not support relayers", sct.ContractAddr)
+	}
+	sc, err := sct.getSwapContract(ctx, secretHash)
+	if err != nil {
+		return nil, err
+	}
+	if sc.SecretHash != secretHash {
+		return nil, errors.New("invalid secret hash registered")
+	}
+	if userSecretHash := sha256Hash(secret[:]); sc.SecretHash != userSecretHash {
+		return nil, errors.New("secret does not match secret hash")
+	}
+	var recipient common.Address
+	switch sc.Kind {
+	case swapKindInitiator:
+		recipient = sc.Participant
+	case swapKindParticipant:
+		recipient = sc.Initiator
+	default:
+		return nil, fmt.Errorf("invalid atomic swap contract kind: %d", sc.Kind)
+	}
+	if sc.State != swapStateFilled {
+		return nil, errors.New("inactive atomic swap contract")
+	}
+	if sc.Token != sct.token {
+		return nil, fmt.Errorf("atomic swap contract locks %s, not %s", assetName(sc.Token), assetName(sct.token))
+	}
+
+	fee := new(big.Int)
+	var v uint8
+	var r, s [32]byte
+	if auth != nil {
+		if auth.Contract != sct.ContractAddr {
+			return nil, fmt.Errorf("redeem authorization is for contract %x, not %x", auth.Contract, sct.ContractAddr)
+		}
+		if sct.chainID != nil && auth.ChainID != nil && auth.ChainID.Cmp(sct.chainID) != 0 {
+			return nil, fmt.Errorf("redeem authorization is for chain ID %s, not %s", auth.ChainID, sct.chainID)
+		}
+		if auth.SecretHash != secretHash {
+			return nil, fmt.Errorf("redeem authorization is for secret hash %x", auth.SecretHash)
+		}
+		if auth.Relayer != sct.FromAddr {
+			return nil, fmt.Errorf("redeem authorization is for relayer %x, not %x", auth.Relayer, sct.FromAddr)
+		}
+		signer, err := auth.Recipient()
+		if err != nil {
+			return nil, err
+		}
+		if signer != recipient {
+			return nil, fmt.Errorf("redeem authorization is signed by %x, not by the recipient %x", signer, recipient)
+		}
+		if auth.Fee.Cmp(sc.Value) > 0 {
+			return nil, fmt.Errorf("fee %s exceeds the value %s of the atomic swap contract", auth.Fee, sc.Value)
+		}
+		fee = auth.Fee
+		copy(r[:], auth.Signature[:32])
+		copy(s[:], auth.Signature[32:64])
+		v = auth.Signature[crypto.RecoveryIDOffset]
+		if v < 27 {
+			v += 27
+		}
+	}
+	// create redeemFor tx
+	return sct.newTransaction(
+		ctx,
+		nil, "redeemFor",
+		// secret,
+		secret,
+		// secret hash
+		secretHash,
+		// fee of the relayer, and the signature of the recipient authorising it
+		fee, v, r, s,
+	)
+}
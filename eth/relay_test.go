@@ -0,0 +1,200 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+func TestAuthorizeRedeem(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := crypto.PubkeyToAddress(key.PublicKey)
+	contractAddr, relayer := common.HexToAddress("0xc0de"), common.HexToAddress("0x7e1a")
+	secretHash := sha256Hash([]byte("secret"))
+
+	auth, err := AuthorizeRedeem(key, contractAddr, big.NewInt(5), secretHash, relayer, big.NewInt(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := auth.Signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+		t.Errorf("unexpected signature V %d", v)
+	}
+	signer, err := auth.Recipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != recipient {
+		t.Errorf("authorization signed by %x, expected %x", signer, recipient)
+	}
+
+	// the signature does not authorise another fee
+	tampered := auth
+	tampered.Fee = big.NewInt(2000)
+	if signer, err = tampered.Recipient(); err == nil && signer == recipient {
+		t.Error("authorization valid for another fee")
+	}
+
+	if _, err = AuthorizeRedeem(key, contractAddr, big.NewInt(5), secretHash, relayer, new(big.Int)); err == nil {
+		t.Error("authorized a redemption without fee")
+	}
+}
+
+func TestExtractSecretFromRelayedRedeem(t *testing.T) {
+	secret := sha256Hash([]byte("secret"))
+	secretHash := sha256Hash(secret[:])
+	input, err := relayContractABI.Pack("redeemFor", secret, secretHash, big.NewInt(1000), uint8(27), [32]byte{1}, [32]byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAddr := common.HexToAddress("0xc0de")
+	tx := types.NewTx(&types.DynamicFeeTx{To: &contractAddr, Data: input})
+	extracted, err := ExtractSecret(context.Background(), SwapContractTransactor{}, tx, secretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(extracted, secret[:]) {
+		t.Errorf("extracted secret %x, expected %x", extracted, secret)
+	}
+}
+
+func TestRelayRedeem(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	bobAddr := crypto.PubkeyToAddress(c.bob.PublicKey)
+	policy := timings.DefaultPolicy
+	chainID, err := c.client.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relayContract, _, _, err := contract.DeployRelayContract(c.deployer(), c.backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// carol has no Ether to pay for the gas, bob relays her redemptions
+	carol := newTestKey(t)
+	carolAddr := crypto.PubkeyToAddress(carol.PublicKey)
+	withoutFee, err := Initiate(ctx, c.transactor(c.alice, relayContract), carolAddr, ether(2), policy, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withFee, err := Initiate(ctx, c.transactor(c.alice, relayContract), carolAddr, ether(1), policy, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = RelayRedeem(ctx, c.transactor(c.bob, relayContract), withoutFee.SecretHash, withFee.Secret, nil, common.Address{}); err == nil {
+		t.Error("relayed a redemption with the wrong secret")
+	}
+	if _, err = RelayRedeem(ctx, c.transactor(c.bob, relayContract), withoutFee.SecretHash, withoutFee.Secret, nil, common.Address{}); err != nil {
+		t.Fatal(err)
+	}
+	if balance := c.backend.Balance(carolAddr); balance.Cmp(ether(2)) != 0 {
+		t.Errorf("carol has %s Wei after the relayed redemption of 2 ETH", balance)
+	}
+
+	// the authorisation of the fee only pays bob
+	fee := big.NewInt(params.GWei * 1000000)
+	auth, err := AuthorizeRedeem(carol, relayContract, chainID, withFee.SecretHash, bobAddr, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RelayRedeem(ctx, c.transactor(c.alice, relayContract), withFee.SecretHash, withFee.Secret, &auth, common.Address{}); err == nil {
+		t.Error("relayed by another relayer than the authorized one")
+	}
+	balance := c.backend.Balance(bobAddr)
+	output, err := RelayRedeem(ctx, c.transactor(c.bob, relayContract), withFee.SecretHash, withFee.Secret, &auth, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received := new(big.Int).Sub(c.backend.Balance(carolAddr), ether(2)); received.Cmp(new(big.Int).Sub(ether(1), fee)) != 0 {
+		t.Errorf("carol received %s Wei of a relayed redemption of 1 ETH for a fee of %s Wei", received, fee)
+	}
+	receipt, err := c.client.TransactionReceipt(ctx, output.RedeemTxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gasCost := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	if received := new(big.Int).Sub(c.backend.Balance(bobAddr), balance); received.Cmp(new(big.Int).Sub(fee, gasCost)) != 0 {
+		t.Errorf("bob received %s Wei relaying for a fee of %s Wei and %s Wei of gas", received, fee, gasCost)
+	}
+
+	// the secret is extracted from the relayed redemption
+	tx, _, err := c.client.TransactionByHash(ctx, output.RedeemTxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := ExtractSecret(ctx, c.transactor(c.alice, relayContract), tx, withFee.SecretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret, withFee.Secret[:]) {
+		t.Errorf("extracted secret %x, expected %x", secret, withFee.Secret)
+	}
+}
+
+func TestRelayRedeemTokens(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	aliceAddr, bobAddr := crypto.PubkeyToAddress(c.alice.PublicKey), crypto.PubkeyToAddress(c.bob.PublicKey)
+	policy := timings.DefaultPolicy
+	chainID, err := c.client.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, erc20 := c.deployToken(6, aliceAddr)
+	tokenContract, _, _, err := contract.DeployTokenContractV2(c.deployer(), c.backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	carol := newTestKey(t)
+	carolAddr := crypto.PubkeyToAddress(carol.PublicKey)
+	withoutFee, err := Initiate(ctx, c.transactor(c.alice, tokenContract), carolAddr, big.NewInt(2000000), policy, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withFee, err := Initiate(ctx, c.transactor(c.alice, tokenContract), carolAddr, big.NewInt(1000000), policy, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = RelayRedeem(ctx, c.transactor(c.bob, tokenContract), withoutFee.SecretHash, withoutFee.Secret, nil, common.Address{}); err == nil {
+		t.Error("relayed a redemption of tokens as one of Ether")
+	}
+	if _, err = RelayRedeem(ctx, c.transactor(c.bob, tokenContract), withoutFee.SecretHash, withoutFee.Secret, nil, token); err != nil {
+		t.Fatal(err)
+	}
+	if balance := tokenBalance(t, erc20, carolAddr); balance.Int64() != 2000000 {
+		t.Errorf("carol has %s tokens after the relayed redemption of 2", balance)
+	}
+
+	auth, err := AuthorizeRedeem(carol, tokenContract, chainID, withFee.SecretHash, bobAddr, big.NewInt(100000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RelayRedeem(ctx, c.transactor(c.bob, tokenContract), withFee.SecretHash, withFee.Secret, &auth, token); err != nil {
+		t.Fatal(err)
+	}
+	if balance := tokenBalance(t, erc20, carolAddr); balance.Int64() != 2900000 {
+		t.Errorf("carol has %s tokens after the relayed redemption of 1 for a fee of 0.1", balance)
+	}
+	if balance := tokenBalance(t, erc20, bobAddr); balance.Int64() != 100000 {
+		t.Errorf("bob has %s tokens after relaying for a fee of 0.1", balance)
+	}
+	if balance := c.backend.Balance(carolAddr); balance.Sign() != 0 {
+		t.Errorf("carol has %s Wei", balance)
+	}
+}
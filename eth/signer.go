@@ -0,0 +1,344 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs the transactions of an account,
+// so the key of the account does not have to be known by the process creating them.
+type Signer interface {
+	// Address of the account the transactions are signed for
+	Address() common.Address
+	// SignTx signs a transaction for the chain with the given ID
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+var (
+	_ Signer = (*KeySigner)(nil)
+	_ Signer = (*NodeSigner)(nil)
+	_ Signer = (*ClefSigner)(nil)
+	_ Signer = (*RemoteSigner)(nil)
+)
+
+// KeySigner signs transactions using a private key kept in memory
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner creates a Signer for a private key
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+// Address implements Signer.Address
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.SignTx
+func (s *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	// the London signer signs both legacy (EIP-155) and dynamic fee transactions
+	return types.SignTx(tx, types.NewLondonSigner(chainID), s.key)
+}
+
+// NodeSigner signs transactions using eth_signTransaction of the Ethereum node,
+// for an account unlocked in the node
+type NodeSigner struct {
+	client  *EthClient
+	address common.Address
+}
+
+// NewNodeSigner creates a Signer for an account of the node,
+// the first account of the node if the address is the zero address
+func NewNodeSigner(ctx context.Context, client *EthClient, address common.Address) (*NodeSigner, error) {
+	if client.rpcClient == nil {
+		return nil, errors.New("the accounts of the node are not available through its backend")
+	}
+	if address == (common.Address{}) {
+		var accounts []common.Address
+		if err := client.rpcClient.CallContext(ctx, &accounts, "eth_accounts"); err != nil {
+			return nil, fmt.Errorf("failed to list the accounts of the node: %v", err)
+		}
+		if len(accounts) == 0 {
+			return nil, errors.New("the node has no accounts")
+		}
+		address = accounts[0]
+	}
+	return &NodeSigner{client: client, address: address}, nil
+}
+
+// Address implements Signer.Address
+func (s *NodeSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.SignTx
+func (s *NodeSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var result signTransactionResult
+	err := s.client.rpcClient.CallContext(ctx, &result, "eth_signTransaction", newTransactionArgs(s.address, tx, chainID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction from daemon: %v", err)
+	}
+	return result.Tx, nil
+}
+
+// ClefSigner signs transactions using the account_signTransaction call of Clef,
+// or any external signer implementing its JSON-RPC API
+type ClefSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// NewClefSigner connects to Clef at the given endpoint, an IPC path or a HTTP(S) URL.
+// The first account listed by Clef is used if the address is the zero address.
+func NewClefSigner(ctx context.Context, endpoint string, address common.Address) (*ClefSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the external signer: %v", err)
+	}
+	if address == (common.Address{}) {
+		var accounts []common.Address
+		if err = client.CallContext(ctx, &accounts, "account_list"); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to list the accounts of the external signer: %v", err)
+		}
+		if len(accounts) == 0 {
+			client.Close()
+			return nil, errors.New("the external signer has no accounts")
+		}
+		address = accounts[0]
+	}
+	return &ClefSigner{client: client, address: address}, nil
+}
+
+// Address implements Signer.Address
+func (s *ClefSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.SignTx
+func (s *ClefSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var result signTransactionResult
+	err := s.client.CallContext(ctx, &result, "account_signTransaction", newTransactionArgs(s.address, tx, chainID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction using the external signer: %v", err)
+	}
+	return result.Tx, nil
+}
+
+// Close the connection to the external signer
+func (s *ClefSigner) Close() {
+	s.client.Close()
+}
+
+// RemoteSigner signs transactions using a remote signing service,
+// see NewSignerHandler for the protocol.
+type RemoteSigner struct {
+	// URL of the signing service
+	URL string
+	// Token, if not empty, is sent as bearer token to authenticate with the signing service
+	Token string
+	// Client used for the requests, http.DefaultClient if nil
+	Client *http.Client
+
+	address common.Address
+}
+
+// NewRemoteSigner creates a Signer for an account of a remote signing service
+func NewRemoteSigner(url string, address common.Address, token string) *RemoteSigner {
+	return &RemoteSigner{URL: url, Token: token, address: address}
+}
+
+// Address implements Signer.Address
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.SignTx
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	unsigned, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	body, err := json.Marshal(signRequest{
+		Address:     s.address,
+		ChainID:     (*hexutil.Big)(chainID),
+		Transaction: unsigned,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the signing service: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("signing service refused to sign: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var result signResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of the signing service: %v", err)
+	}
+	signed := new(types.Transaction)
+	if err = signed.UnmarshalBinary(result.Transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode the signed transaction: %v", err)
+	}
+	return signed, nil
+}
+
+type (
+	// signRequest is the body of the requests of RemoteSigner
+	signRequest struct {
+		Address     common.Address `json:"address"`
+		ChainID     *hexutil.Big   `json:"chainId"`
+		Transaction hexutil.Bytes  `json:"transaction"`
+	}
+	// signResponse is the body of the responses to RemoteSigner
+	signResponse struct {
+		Transaction hexutil.Bytes `json:"transaction"`
+	}
+)
+
+// NewSignerHandler serves the protocol of RemoteSigner, signing with the given signer.
+// Requests are JSON objects POSTed with the address, chainId and the binary encoded
+// unsigned transaction, to which the binary encoded signed transaction is returned:
+//
+//	{"address": "0x...", "chainId": "0x1", "transaction": "0x..."} -> {"transaction": "0x..."}
+//
+// If the token is not empty, requests have to send it as bearer token.
+// It is meant as a local stub of a signing service, a production service
+// would also apply its policies to the transactions it signs.
+func NewSignerHandler(signer Signer, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req signRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Address != signer.Address() {
+			http.Error(w, fmt.Sprintf("unknown account %x", req.Address), http.StatusForbidden)
+			return
+		}
+		if req.ChainID == nil {
+			http.Error(w, "invalid request: no chain ID", http.StatusBadRequest)
+			return
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(req.Transaction); err != nil {
+			http.Error(w, "invalid transaction: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		signed, err := signer.SignTx(r.Context(), tx, req.ChainID.ToInt())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b, err := signed.MarshalBinary()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(signResponse{Transaction: b})
+	})
+}
+
+// signTransactionResult is the result of eth_signTransaction and account_signTransaction
+type signTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// transactionArgs are the arguments of eth_signTransaction and account_signTransaction
+type transactionArgs struct {
+	From      common.Address  `json:"from"`
+	To        *common.Address `json:"to"`
+	Gas       hexutil.Uint64  `json:"gas"`
+	GasPrice  *hexutil.Big    `json:"gasPrice,omitempty"`
+	GasFeeCap *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	GasTipCap *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value     hexutil.Big     `json:"value"`
+	Nonce     hexutil.Uint64  `json:"nonce"`
+	Data      hexutil.Bytes   `json:"data"`
+	ChainID   *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+func newTransactionArgs(from common.Address, tx *types.Transaction, chainID *big.Int) transactionArgs {
+	args := transactionArgs{
+		From:    from,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    hexutil.Bytes(tx.Data()),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		args.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+	return args
+}
+
+// verifySignedTx verifies an external signer signed the transaction we asked for,
+// for the expected account
+func verifySignedTx(unsigned, signed *types.Transaction, from common.Address, chainID *big.Int) error {
+	if signed == nil {
+		return errors.New("no signed transaction returned")
+	}
+	signer := types.NewLondonSigner(chainID)
+	if signer.Hash(signed) != signer.Hash(unsigned) {
+		return errors.New("signed transaction differs from the transaction to sign")
+	}
+	sender, err := types.Sender(signer, signed)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	if sender != from {
+		return fmt.Errorf("transaction signed by %x instead of %x", sender, from)
+	}
+	return nil
+}
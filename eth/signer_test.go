@@ -0,0 +1,159 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// clefStub implements the account namespace of Clef
+type clefStub struct {
+	key *ecdsa.PrivateKey
+	// tamper modifies the transactions before they are signed
+	tamper bool
+}
+
+func (c *clefStub) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(c.key.PublicKey)}
+}
+
+func (c *clefStub) SignTransaction(ctx context.Context, args transactionArgs, methodSelector *string) (*signTransactionResult, error) {
+	value := args.Value.ToInt()
+	if c.tamper {
+		value = new(big.Int).Add(value, big.NewInt(1))
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     uint64(args.Nonce),
+		GasTipCap: args.GasTipCap.ToInt(),
+		GasFeeCap: args.GasFeeCap.ToInt(),
+		Gas:       uint64(args.Gas),
+		To:        args.To,
+		Value:     value,
+		Data:      args.Data,
+	})
+	signed, err := NewKeySigner(c.key).SignTx(ctx, tx, args.ChainID.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTransactionResult{Raw: raw, Tx: signed}, nil
+}
+
+// nodeStub implements the calls of the eth namespace used by NodeSigner,
+// the node signing as Clef does
+type nodeStub struct {
+	clef *clefStub
+}
+
+func (n *nodeStub) Accounts() []common.Address {
+	return n.clef.List()
+}
+
+func (n *nodeStub) SignTransaction(ctx context.Context, args transactionArgs) (*signTransactionResult, error) {
+	return n.clef.SignTransaction(ctx, args, nil)
+}
+
+func TestSigners(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(5)
+	keySigner := NewKeySigner(key)
+	address := keySigner.Address()
+	to := common.HexToAddress("0xb0b")
+	legacyTx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(1)})
+	dynamicFeeTx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(20), Gas: 21000, To: &to, Value: big.NewInt(1)})
+
+	// remote signing service, using a local stub
+	server := httptest.NewServer(NewSignerHandler(keySigner, "secret"))
+	defer server.Close()
+	remoteSigner := NewRemoteSigner(server.URL, address, "secret")
+
+	// Clef, using a stub of its JSON-RPC API
+	rpcServer := rpc.NewServer()
+	if err = rpcServer.RegisterName("account", &clefStub{key: key}); err != nil {
+		t.Fatal(err)
+	}
+	clefServer := httptest.NewServer(rpcServer)
+	defer clefServer.Close()
+	clefSigner, err := NewClefSigner(ctx, clefServer.URL, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clefSigner.Close()
+	if clefSigner.Address() != address {
+		t.Errorf("Clef signer uses account %x, expected %x", clefSigner.Address(), address)
+	}
+
+	// account of a node, using a stub of its JSON-RPC API
+	if err = rpcServer.RegisterName("eth", &nodeStub{clef: &clefStub{key: key}}); err != nil {
+		t.Fatal(err)
+	}
+	client := NewRPCClient(rpc.DialInProc(rpcServer))
+	defer client.Close()
+	nodeSigner, err := NewNodeSigner(ctx, client, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodeSigner.Address() != address {
+		t.Errorf("node signer uses account %x, expected %x", nodeSigner.Address(), address)
+	}
+
+	signers := map[string]Signer{"key": keySigner, "remote": remoteSigner, "clef": clefSigner, "node": nodeSigner}
+	for name, signer := range signers {
+		txs := []*types.Transaction{legacyTx, dynamicFeeTx}
+		if name == "clef" || name == "node" {
+			// the stub only signs dynamic fee transactions
+			txs = txs[1:]
+		}
+		for _, tx := range txs {
+			signed, err := signer.SignTx(ctx, tx, chainID)
+			if err != nil {
+				t.Errorf("%s signer: %v", name, err)
+				continue
+			}
+			if err = verifySignedTx(tx, signed, address, chainID); err != nil {
+				t.Errorf("%s signer: transaction of type %d: %v", name, tx.Type(), err)
+			}
+		}
+	}
+
+	if _, err = NewRemoteSigner(server.URL, address, "wrong").SignTx(ctx, legacyTx, chainID); err == nil {
+		t.Error("remote signer signed with a wrong token")
+	}
+	if _, err = NewRemoteSigner(server.URL, to, "secret").SignTx(ctx, legacyTx, chainID); err == nil {
+		t.Error("remote signer signed for an unknown account")
+	}
+
+	// a signer signing another transaction or for another account is detected
+	if err = rpcServer.RegisterName("account", &clefStub{key: key, tamper: true}); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := clefSigner.SignTx(ctx, dynamicFeeTx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifySignedTx(dynamicFeeTx, signed, address, chainID); err == nil {
+		t.Error("tampered transaction not detected")
+	}
+	signed, err = keySigner.SignTx(ctx, legacyTx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifySignedTx(legacyTx, signed, to, chainID); err == nil {
+		t.Error("transaction signed for another account not detected")
+	}
+}
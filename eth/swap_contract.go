@@ -4,20 +4,18 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/threefoldtech/atomicswap/eth/contract"
 )
@@ -27,13 +25,47 @@ type (
 	// atomic swap actions
 	SwapContractTransactor struct {
 		Abi          abi.ABI
-		signer       bind.SignerFn
+		Signer       Signer // signs the transactions, the node signs them for FromAddr if nil
 		Client       *EthClient
 		FromAddr     common.Address
 		ContractAddr common.Address
 		autoAccount  bool // defines if an account is automatically selected
 
-		_contract *contract.Contract // created only once
+		// GasFeeCap is the maximum fee per gas of the (EIP-1559) transactions,
+		// nil to use twice the base fee of the latest block plus the tip cap
+		GasFeeCap *big.Int
+		// GasTipCap is the maximum priority fee per gas of the (EIP-1559) transactions,
+		// nil to use the tip suggested by the node
+		GasTipCap *big.Int
+		// Versions are the known versions of the atomic swap contracts,
+		// nil for DefaultContractVersions.
+		// They have to be set before reading the swaps, the version of a contract is looked up only once.
+		Versions ContractVersions
+		// LogRange is the number of blocks of which the logs are requested at once,
+		// DefaultLogRange if zero. It is halved when the node rejects a request as too large.
+		LogRange uint64
+
+		chainID *big.Int
+		// unsigned transactions are built but not signed,
+		// to be exported and signed by another host, see forExport
+		unsigned bool
+		// nonce of the next transaction, managed by batches,
+		// nil to use the pending nonce of the node
+		nonce *uint64
+		// token locked by the contracts, the zero address for Ether,
+		// see forToken
+		token common.Address
+
+		// swapsABIs caches the ABI of the version of the contracts by address,
+		// shared by the copies of the transactor, nil to look it up on every call
+		swapsABIs *swapsABICache
+	}
+
+	// swapsABICache is the ABI of the version of the contract at each address,
+	// nil for unknown code, of which the swaps are read using the ABI of the transactor
+	swapsABICache struct {
+		mu   sync.Mutex
+		abis map[common.Address]*abi.ABI
 	}
 
 	// swapTransaction adds send functionality to the transaction,
@@ -45,13 +77,13 @@ type (
 )
 
 const (
-	initiateLockPeriodInSeconds    = 48 * 60 * 60
-	participateLockPeriodInSeconds = 24 * 60 * 60
-
 	maxGasLimit = 210000
+	// maxTokenGasLimit is the gas limit of the calls of the TokenAtomicSwap contracts,
+	// which also call the token contract to transfer the tokens
+	maxTokenGasLimit = 300000
 )
 
-func (sct *SwapContractTransactor) initiateTx(ctx context.Context, amount *big.Int, secretHash [sha256.Size]byte, participant common.Address) (*swapTransaction, error) {
+func (sct *SwapContractTransactor) initiateTx(ctx context.Context, amount *big.Int, secretHash [sha256.Size]byte, participant common.Address, lockTime time.Duration) (*swapTransaction, error) {
 	// validate tx does not exist yet,
 	// as to provide more meaningful error messages
 	switch _, err := sct.getSwapContract(ctx, secretHash); err {
@@ -62,12 +94,27 @@ func (sct *SwapContractTransactor) initiateTx(ctx context.Context, amount *big.I
 	default:
 		return nil, fmt.Errorf("unexpected error while checking for an existing contract: %v", err)
 	}
+	if sct.token != (common.Address{}) {
+		if err := sct.approveTokens(ctx, amount); err != nil {
+			return nil, err
+		}
+		return sct.newTransaction(
+			ctx,
+			nil, "initiate",
+			big.NewInt(int64(lockTime/time.Second)),
+			secretHash,
+			participant,
+			// token and amount of tokens to lock
+			sct.token,
+			amount,
+		)
+	}
 	// create initiate tx
 	return sct.newTransaction(
 		ctx,
 		amount, "initiate",
 		// lock duration
-		big.NewInt(initiateLockPeriodInSeconds),
+		big.NewInt(int64(lockTime/time.Second)),
 		// secret hash
 		secretHash,
 		// participant
@@ -75,7 +122,7 @@ func (sct *SwapContractTransactor) initiateTx(ctx context.Context, amount *big.I
 	)
 }
 
-func (sct *SwapContractTransactor) participateTx(ctx context.Context, amount *big.Int, secretHash [sha256.Size]byte, initiator common.Address) (*swapTransaction, error) {
+func (sct *SwapContractTransactor) participateTx(ctx context.Context, amount *big.Int, secretHash [sha256.Size]byte, initiator common.Address, lockTime time.Duration) (*swapTransaction, error) {
 	// validate tx does not exist yet,
 	// as to provide more meaningful error messages
 	switch _, err := sct.getSwapContract(ctx, secretHash); err {
@@ -86,11 +133,26 @@ func (sct *SwapContractTransactor) participateTx(ctx context.Context, amount *bi
 	default:
 		return nil, fmt.Errorf("unexpected error while checking for an existing contract: %v", err)
 	}
+	if sct.token != (common.Address{}) {
+		if err := sct.approveTokens(ctx, amount); err != nil {
+			return nil, err
+		}
+		return sct.newTransaction(
+			ctx,
+			nil, "participate",
+			big.NewInt(int64(lockTime/time.Second)),
+			secretHash,
+			initiator,
+			// token and amount of tokens to lock
+			sct.token,
+			amount,
+		)
+	}
 	return sct.newTransaction(
 		ctx,
 		amount, "participate",
 		// lock duration
-		big.NewInt(participateLockPeriodInSeconds),
+		big.NewInt(int64(lockTime/time.Second)),
 		// secret hash
 		secretHash,
 		// participant
@@ -126,6 +188,9 @@ func (sct *SwapContractTransactor) redeemTx(ctx context.Context, secretHash, sec
 	if sc.State != swapStateFilled {
 		return nil, errors.New("inactive atomic swap contract")
 	}
+	if sc.Token != sct.token {
+		return nil, fmt.Errorf("atomic swap contract locks %s, not %s", assetName(sc.Token), assetName(sct.token))
+	}
 	// create redeem tx
 	return sct.newTransaction(
 		ctx,
@@ -162,9 +227,18 @@ func (sct *SwapContractTransactor) refundTx(ctx context.Context, secretHash [sha
 	if sc.State != swapStateFilled {
 		return nil, errors.New("inactive atomic swap contract")
 	}
+	if sc.Token != sct.token {
+		return nil, fmt.Errorf("atomic swap contract locks %s, not %s", assetName(sc.Token), assetName(sct.token))
+	}
+	// the contract can be refunded in a block after the lock time,
+	// compared with the time of the chain rather than the local clock
+	head, err := sct.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest block: %v", err)
+	}
 	lockTime := time.Unix(bigIntPtrToUint64(sc.InitTimestamp)+bigIntPtrToUint64(sc.RefundTime), 0)
-	if dur := time.Until(lockTime).Truncate(time.Second); dur >= 0 {
-		return nil, fmt.Errorf("contract is still locked for %v", dur+time.Second)
+	if dur := lockTime.Sub(time.Unix(int64(head.Time), 0)); dur > 0 {
+		return nil, fmt.Errorf("contract is still locked for %v", dur)
 	}
 	// create refund tx
 	return sct.newTransaction(
@@ -183,15 +257,68 @@ func bigIntPtrToUint64(i *big.Int) int64 {
 }
 
 func (sct *SwapContractTransactor) DeployTx(ctx context.Context) (*swapTransaction, error) {
-	return sct.newTransactionWithInput(ctx, nil, false, common.FromHex(contract.ContractBin))
+	v, ok := sct.versions().Latest(KindAtomicSwap)
+	if !ok {
+		return nil, errors.New("no version of the AtomicSwap contract to deploy")
+	}
+	return sct.newTransactionWithInput(ctx, nil, nil, v.DeployCode)
 }
 
 func (sct *SwapContractTransactor) maxGasCost(ctx context.Context) (*big.Int, error) {
-	gasPrice, err := sct.Client.SuggestGasPrice(ctx)
+	gasPrice, gasFeeCap, _, err := sct.gasFees(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to suggest gas price: %v", err)
+		return nil, err
 	}
-	return gasPrice.Mul(gasPrice, big.NewInt(maxGasLimit)), nil
+	if gasFeeCap != nil {
+		gasPrice = gasFeeCap
+	}
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(sct.maxGasLimit())), nil
+}
+
+// maxGasLimit returns the gas limit of the calls of the contract
+func (sct *SwapContractTransactor) maxGasLimit() uint64 {
+	if sct.token != (common.Address{}) {
+		return maxTokenGasLimit
+	}
+	return maxGasLimit
+}
+
+// gasFees returns the fee caps of a dynamic fee transaction,
+// or the gas price of a legacy transaction if the chain does not support EIP-1559 yet.
+func (sct *SwapContractTransactor) gasFees(ctx context.Context) (gasPrice, gasFeeCap, gasTipCap *big.Int, err error) {
+	head, err := sct.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the latest block header: %v", err)
+	}
+	if head.BaseFee == nil {
+		// pre-London chain, only legacy transactions are supported
+		gasPrice, err = sct.Client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to suggest gas price: %v", err)
+		}
+		return gasPrice, nil, nil, nil
+	}
+	gasTipCap = sct.GasTipCap
+	if gasTipCap == nil {
+		gasTipCap, err = sct.Client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to suggest gas tip cap: %v", err)
+		}
+	}
+	gasFeeCap = sct.GasFeeCap
+	if gasFeeCap == nil {
+		gasFeeCap = defaultGasFeeCap(head.BaseFee, gasTipCap)
+	}
+	if gasFeeCap.Cmp(gasTipCap) < 0 {
+		return nil, nil, nil, fmt.Errorf("max fee per gas %s is lower than the tip cap %s", gasFeeCap, gasTipCap)
+	}
+	return nil, gasFeeCap, gasTipCap, nil
+}
+
+// defaultGasFeeCap allows the base fee to double before a transaction
+// is no longer includable, the same margin as the bind package uses
+func defaultGasFeeCap(baseFee, gasTipCap *big.Int) *big.Int {
+	return new(big.Int).Add(gasTipCap, new(big.Int).Mul(baseFee, big.NewInt(2)))
 }
 
 // states have to be mapped 1-to-1 with Enum AtomicSwap.State,
@@ -222,10 +349,9 @@ var (
 	errNotExists = errors.New("atomic swap contract does not exist")
 )
 
-// getSwapContract is a free contract call,
-// which allows us to retrieve an atomic swap contract from a deployed AtomicSwap smart contract,
-// using the secret hash used in that atomic swap contract as this contract's identifier.
-func (sct *SwapContractTransactor) getSwapContract(ctx context.Context, secretHash [32]byte) (*struct {
+// swapContract is an atomic swap contract
+// as stored by the AtomicSwap and TokenAtomicSwap smart contracts
+type swapContract struct {
 	InitTimestamp *big.Int
 	RefundTime    *big.Int
 	SecretHash    [32]byte
@@ -235,111 +361,139 @@ func (sct *SwapContractTransactor) getSwapContract(ctx context.Context, secretHa
 	Value         *big.Int
 	Kind          uint8
 	State         uint8
-}, error) {
-	if sct._contract == nil {
-		var err error
-		sct._contract, err = contract.NewContract(sct.ContractAddr, sct.Client.Client)
-		if err != nil {
-			return nil, fmt.Errorf("failed to bind smart contract (at %x): %v", sct.ContractAddr, err)
-		}
+	// Token locked by the contract, the zero address for Ether
+	Token common.Address
+}
+
+// getSwapContract is a free contract call,
+// which allows us to retrieve an atomic swap contract from a deployed AtomicSwap smart contract,
+// using the secret hash used in that atomic swap contract as this contract's identifier.
+// The swap is read using the ABI of the version of the deployed contract.
+func (sct *SwapContractTransactor) getSwapContract(ctx context.Context, secretHash [32]byte) (*swapContract, error) {
+	contractABI, err := sct.swapsABI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	input, err := contractABI.Pack("swaps", secretHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack swaps call: %v", err)
 	}
-	sc, err := sct._contract.Swaps(&bind.CallOpts{
-		Pending: false,
-		From:    sct.FromAddr,
-		Context: ctx,
-	}, secretHash)
+	output, err := sct.Client.CallContract(ctx, ethereum.CallMsg{
+		From: sct.FromAddr,
+		To:   &sct.ContractAddr,
+		Data: input,
+	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get swap contract from smart contract (at %x): %v", sct.ContractAddr, err)
 	}
+	// the token is only stored by the TokenAtomicSwap contracts
+	var sc swapContract
+	if err = contractABI.UnpackIntoInterface(&sc, "swaps", output); err != nil {
+		return nil, fmt.Errorf("failed to get swap contract from smart contract (at %x): %v", sct.ContractAddr, err)
+	}
 	if sc.State == swapStateEmpty {
 		return nil, errNotExists
 	}
 	return &sc, nil
 }
 
+// swapsABI returns the ABI of the version of the contract at the contract address,
+// used to read its swaps. The ABI of the transactor is used for contracts of unknown versions,
+// which are refused by the audits but can still be redeemed and refunded.
+// The version of each contract is looked up only once, see swapsABICache.
+func (sct *SwapContractTransactor) swapsABI(ctx context.Context) (abi.ABI, error) {
+	if sct.swapsABIs != nil {
+		sct.swapsABIs.mu.Lock()
+		defer sct.swapsABIs.mu.Unlock()
+		if versionABI, ok := sct.swapsABIs.abis[sct.ContractAddr]; ok {
+			if versionABI == nil {
+				return sct.Abi, nil
+			}
+			return *versionABI, nil
+		}
+	}
+	var versionABI *abi.ABI
+	v, err := VerifyContractCode(ctx, sct.Client, sct.ContractAddr, sct.versions())
+	switch {
+	case err == nil:
+		parsed, err := v.contractABI()
+		if err != nil {
+			return abi.ABI{}, fmt.Errorf("invalid ABI of contract version %s: %v", v.Name, err)
+		}
+		versionABI = &parsed
+	case !errors.Is(err, ErrUnknownContractCode):
+		return abi.ABI{}, err
+	}
+	if sct.swapsABIs != nil {
+		sct.swapsABIs.abis[sct.ContractAddr] = versionABI
+	}
+	if versionABI == nil {
+		return sct.Abi, nil
+	}
+	return *versionABI, nil
+}
+
+// versions returns the known versions of the contracts
+func (sct *SwapContractTransactor) versions() ContractVersions {
+	if sct.Versions == nil {
+		return DefaultContractVersions()
+	}
+	return sct.Versions
+}
+
 func (sct *SwapContractTransactor) newTransaction(ctx context.Context, amount *big.Int, name string, params ...interface{}) (*swapTransaction, error) {
 	// pack up the parameters and contract name
 	input, err := sct.Abi.Pack(name, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to pack input")
 	}
-	return sct.newTransactionWithInput(ctx, amount, true, input)
+	return sct.newTransactionWithInput(ctx, amount, &sct.ContractAddr, input)
 }
 
-func (sct *SwapContractTransactor) newTransactionWithInput(ctx context.Context, amount *big.Int, contractCall bool, input []byte) (*swapTransaction, error) {
+// newTransactionWithInput creates a signed transaction calling the contract at toAddr,
+// or creating a contract if toAddr is nil
+func (sct *SwapContractTransactor) newTransactionWithInput(ctx context.Context, amount *big.Int, toAddr *common.Address, input []byte) (*swapTransaction, error) {
 	// define the TransactOpts for binding
 	opts, err := sct.calcBaseOpts(ctx, amount)
 	if err != nil {
 		return nil, err
 	}
-	opts.GasLimit, err = sct.calcGasLimit(ctx, opts.Value, opts.GasPrice, contractCall, input)
+	opts.GasLimit, err = sct.calcGasLimit(ctx, opts.Value, opts.GasPrice, toAddr, input)
 	if err != nil {
 		return nil, err
 	}
 
-	// sign using daemon or do it client-side if desired
-	var signedTx *types.Transaction
-	if opts.Signer == nil {
-		var toAddr *common.Address
-		if contractCall {
-			toAddr = &sct.ContractAddr
-		}
-		// sign transaction using the daemon
-		var result struct {
-			Raw string            `json:"raw"`
-			Tx  types.Transaction `json:"tx"`
-		}
-		err = sct.Client.rpcClient.CallContext(ctx, &result, "eth_signTransaction", struct {
-			From     common.Address  `json:"from"`
-			To       *common.Address `json:"to"`
-			Gas      hexutil.Uint64  `json:"gas"`
-			GasPrice hexutil.Big     `json:"gasPrice"`
-			Value    hexutil.Big     `json:"value"`
-			Nonce    hexutil.Uint64  `json:"nonce"`
-			Data     hexutil.Bytes   `json:"data"`
-		}{
-			From:     opts.From,
-			To:       toAddr,
-			Gas:      hexutil.Uint64(opts.GasLimit),
-			GasPrice: hexutil.Big(*opts.GasPrice),
-			Value: func() hexutil.Big {
-				if amount == nil {
-					return hexutil.Big{}
-				}
-				return hexutil.Big(*amount)
-			}(),
-			Nonce: hexutil.Uint64(opts.Nonce.Uint64()),
-			Data:  hexutil.Bytes(input),
+	var rawTx *types.Transaction
+	if opts.GasFeeCap != nil {
+		rawTx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   sct.chainID,
+			Nonce:     opts.Nonce.Uint64(),
+			GasTipCap: opts.GasTipCap,
+			GasFeeCap: opts.GasFeeCap,
+			Gas:       opts.GasLimit,
+			To:        toAddr,
+			Value:     opts.Value,
+			Data:      input,
 		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign transaction from daemon: %v", err)
-		}
-		signedTx = &result.Tx
 	} else {
-		var rawTx *types.Transaction
-		if contractCall {
-			rawTx = types.NewTransaction(
-				opts.Nonce.Uint64(),
-				sct.ContractAddr,
-				opts.Value,
-				opts.GasLimit,
-				opts.GasPrice,
-				input,
-			)
-		} else {
-			rawTx = types.NewContractCreation(
-				opts.Nonce.Uint64(),
-				opts.Value,
-				opts.GasLimit,
-				opts.GasPrice,
-				input,
-			)
-		}
-		// sign ourselves
-		signedTx, err = opts.Signer(opts.From, rawTx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign transaction from client: %v", err)
-		}
+		rawTx = types.NewTx(&types.LegacyTx{
+			Nonce:    opts.Nonce.Uint64(),
+			GasPrice: opts.GasPrice,
+			Gas:      opts.GasLimit,
+			To:       toAddr,
+			Value:    opts.Value,
+			Data:     input,
+		})
+	}
+	if sct.unsigned {
+		return &swapTransaction{
+			Transaction: rawTx,
+			client:      sct.Client,
+		}, nil
+	}
+	signedTx, err := sct.sign(ctx, rawTx)
+	if err != nil {
+		return nil, err
 	}
 	return &swapTransaction{
 		Transaction: signedTx,
@@ -347,32 +501,57 @@ func (sct *SwapContractTransactor) newTransactionWithInput(ctx context.Context,
 	}, nil
 }
 
-func (sct *SwapContractTransactor) calcBaseOpts(ctx context.Context, amount *big.Int) (*bind.TransactOpts, error) {
-	nonce, err := sct.Client.PendingNonceAt(ctx, sct.FromAddr)
+// sign a transaction using the signer,
+// verifying it signed the transaction as is for our account
+func (sct *SwapContractTransactor) sign(ctx context.Context, rawTx *types.Transaction) (*types.Transaction, error) {
+	signer := sct.Signer
+	if signer == nil {
+		// sign transaction using the daemon
+		signer = &NodeSigner{client: sct.Client, address: sct.FromAddr}
+	}
+	signedTx, err := signer.SignTx(ctx, rawTx, sct.chainID)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to retrieve account (%x) nonce: %v",
-			sct.FromAddr, err)
+		return nil, err
 	}
-	gasPrice, err := sct.Client.SuggestGasPrice(ctx)
+	if err = verifySignedTx(rawTx, signedTx, sct.FromAddr, sct.chainID); err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return signedTx, nil
+}
+
+func (sct *SwapContractTransactor) calcBaseOpts(ctx context.Context, amount *big.Int) (*bind.TransactOpts, error) {
+	var nonce uint64
+	if sct.nonce != nil {
+		nonce = *sct.nonce
+	} else {
+		var err error
+		nonce, err = sct.Client.PendingNonceAt(ctx, sct.FromAddr)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to retrieve account (%x) nonce: %v",
+				sct.FromAddr, err)
+		}
+	}
+	gasPrice, gasFeeCap, gasTipCap, err := sct.gasFees(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to suggest gas price: %v", err)
+		return nil, err
 	}
 	if amount == nil {
 		amount = new(big.Int)
 	}
 	return &bind.TransactOpts{
-		From:     sct.FromAddr,
-		Nonce:    new(big.Int).SetUint64(nonce),
-		Signer:   sct.signer,
-		Value:    amount,
-		GasPrice: gasPrice,
+		From:      sct.FromAddr,
+		Nonce:     new(big.Int).SetUint64(nonce),
+		Value:     amount,
+		GasPrice:  gasPrice,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
 	}, nil
 }
 
-func (sct *SwapContractTransactor) calcGasLimit(ctx context.Context, amount, gasPrice *big.Int, contractCall bool, input []byte) (uint64, error) {
-	if contractCall {
-		code, err := sct.Client.PendingCodeAt(ctx, sct.ContractAddr)
+func (sct *SwapContractTransactor) calcGasLimit(ctx context.Context, amount, gasPrice *big.Int, toAddr *common.Address, input []byte) (uint64, error) {
+	if toAddr != nil {
+		code, err := sct.Client.PendingCodeAt(ctx, *toAddr)
 		if err != nil {
 			return 0, fmt.Errorf("failed to estimate gas needed: %v", err)
 		} else if len(code) == 0 {
@@ -384,16 +563,14 @@ func (sct *SwapContractTransactor) calcGasLimit(ctx context.Context, amount, gas
 		From:  sct.FromAddr,
 		Value: amount,
 		Data:  input,
-	}
-	if contractCall {
-		msg.To = &sct.ContractAddr
+		To:    toAddr,
 	}
 	gasLimit, err := sct.Client.EstimateGas(ctx, msg)
 	if err != nil {
 		return 0, fmt.Errorf("failed to estimate gas needed: %v", err)
 	}
-	if contractCall && gasLimit > maxGasLimit {
-		return 0, fmt.Errorf("%d exceeds the hardcoded code-call gas limit of %d", gasLimit, maxGasLimit)
+	if toAddr != nil && gasLimit > sct.maxGasLimit() {
+		return 0, fmt.Errorf("%d exceeds the hardcoded code-call gas limit of %d", gasLimit, sct.maxGasLimit())
 	}
 	return gasLimit, nil
 }
@@ -408,78 +585,45 @@ func (st *swapTransaction) Send(ctx context.Context) error {
 	return nil
 }
 
-var (
-	// decode the byte code of the smart contract used
-	// during the initialisation phase of this CLI tool,
-	// as to ensure the hex-encoded string is valid at all times.
-	//
-	// This prevents of having a hidden error,
-	// due to the fact that it is only ever used in
-	// our extra smart-contract-related commands.
-	contractBin = func() []byte {
-		b, err := hex.DecodeString(contract.ContractBin)
-		if err != nil {
-			panic("invalid binary contract: " + err.Error())
-		}
-		return b
-	}()
-)
-
 func sha256Hash(x []byte) [sha256.Size]byte {
 	h := sha256.Sum256(x)
 	return h
 }
 
 // newSwapContractTransactor creates a new swapContract instance,
-// see swapContractTransactor for more information
+// see swapContractTransactor for more information.
+// Transactions are signed using the given key, or by the node if the key is nil.
 func NewSwapContractTransactor(ctx context.Context, c *EthClient, contractAddr common.Address, key *ecdsa.PrivateKey, chainID *big.Int) (SwapContractTransactor, error) {
+	var signer Signer
+	if key != nil {
+		signer = NewKeySigner(key)
+	}
+	return NewSwapContractTransactorWithSigner(ctx, c, contractAddr, signer, chainID)
+}
+
+// NewSwapContractTransactorWithSigner creates a new swapContract instance
+// signing its transactions using the given signer,
+// or using the first account of the node if the signer is nil.
+func NewSwapContractTransactorWithSigner(ctx context.Context, c *EthClient, contractAddr common.Address, signer Signer, chainID *big.Int) (SwapContractTransactor, error) {
 	parsed, err := abi.JSON(strings.NewReader(contract.ContractABI))
 	if err != nil {
 		return SwapContractTransactor{}, fmt.Errorf("failed to read (smart) contract ABI: %v", err)
 	}
-	// sign using given key
-	var signer bind.SignerFn
-	var fromAddr common.Address
-	if key != nil {
-		signer, fromAddr, err = newSigner(key, chainID)
-		if err != nil {
-			return SwapContractTransactor{}, fmt.Errorf("failed to create tx signer: %v", err)
-		}
-	}
-	return SwapContractTransactor{
+	sct := SwapContractTransactor{
 		Abi:          parsed,
-		signer:       signer,
+		Signer:       signer,
 		Client:       c,
-		FromAddr:     fromAddr,
 		ContractAddr: contractAddr,
-	}, nil
-}
-
-// newSigner creates a signer func using the flag-passed
-// private credentials of the sender
-func newSigner(privKey *ecdsa.PrivateKey, chainID *big.Int) (bind.SignerFn, common.Address, error) {
-	// json, err := ioutil.ReadFile(path)
-	// if err != nil {
-	// 	return nil, common.Address{}, fmt.Errorf("failed to read encrypted account/key file (%s) content: %v", path, err)
-	// }
-	// passphrase, err := speakeasy.Ask("Account passphrase: ")
-	// if err != nil {
-	// 	return nil, common.Address{}, fmt.Errorf("failed to get passphrase from STDIN: %v", err)
-	// }
-	// key, err := keystore.DecryptKey(json, passphrase)
-	// if err != nil {
-	// 	return nil, common.Address{}, fmt.Errorf("failed to decrypt (JSON) account/key file (%s): %v", path, err)
-	// }
-	keyAddr := crypto.PubkeyToAddress(privKey.PublicKey)
-	return func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
-		if address != keyAddr {
-			return nil, errors.New("not authorized to sign this account")
-		}
-		s := types.NewEIP155Signer(chainID)
-		signature, err := crypto.Sign(s.Hash(tx).Bytes(), privKey)
-		if err != nil {
-			return nil, err
-		}
-		return tx.WithSignature(s, signature)
-	}, keyAddr, nil
+		chainID:      chainID,
+		swapsABIs:    &swapsABICache{abis: make(map[common.Address]*abi.ABI)},
+	}
+	if signer != nil {
+		sct.FromAddr = signer.Address()
+	} else if nodeSigner, err := NewNodeSigner(ctx, c, common.Address{}); err == nil {
+		// a node without accounts can still be used for the calls not requiring one
+		sct.Signer = nodeSigner
+		sct.FromAddr = nodeSigner.Address()
+		sct.autoAccount = true
+	}
+	return sct, nil
 }
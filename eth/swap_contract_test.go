@@ -0,0 +1,60 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/threefoldtech/atomicswap/eth/ethtest"
+)
+
+func TestDefaultGasFeeCap(t *testing.T) {
+	if feeCap := defaultGasFeeCap(big.NewInt(100), big.NewInt(3)); feeCap.Int64() != 203 {
+		t.Errorf("fee cap is %s, expected 203", feeCap)
+	}
+}
+
+// codeCounter counts the code requests to the backend
+type codeCounter struct {
+	*ethtest.Backend
+	requests int
+}
+
+func (b *codeCounter) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	b.requests++
+	return b.Backend.CodeAt(ctx, account, blockNumber)
+}
+
+func TestSwapsABICache(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	backend := &codeCounter{Backend: c.backend}
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct, err := NewSwapContractTransactor(ctx, NewClient(backend), c.initiatorContract, c.alice, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the version of a contract is looked up once for all copies of the transactor
+	secretHash := sha256Hash([]byte("secret"))
+	for i := 0; i < 3; i++ {
+		for _, contractAddr := range []common.Address{c.initiatorContract, c.participantContract} {
+			cp := sct
+			cp.ContractAddr = contractAddr
+			if _, err = cp.getSwapContract(ctx, secretHash); err != errNotExists {
+				t.Fatalf("unexpected swap lookup error: %v", err)
+			}
+		}
+	}
+	if forToken := sct.forToken(common.HexToAddress("0x7043")); forToken.swapsABIs != sct.swapsABIs {
+		t.Error("ABI cache not shared by the transactor for a token")
+	}
+	if backend.requests != 2 {
+		t.Errorf("code of the contracts requested %d times, expected 2", backend.requests)
+	}
+}
@@ -0,0 +1,229 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/threefoldtech/atomicswap/eth/ethtest"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// testChain is a simulated chain with two AtomicSwap contracts,
+// one for the contracts of each party of a swap, as if they were on different chains
+type testChain struct {
+	t       *testing.T
+	backend *ethtest.Backend
+	client  *EthClient
+	// contracts of the initiator and of the participant
+	initiatorContract, participantContract common.Address
+	alice, bob                             *ecdsa.PrivateKey
+}
+
+func newTestChain(t *testing.T) *testChain {
+	c := &testChain{
+		t:     t,
+		alice: newTestKey(t),
+		bob:   newTestKey(t),
+	}
+	c.backend = ethtest.NewBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(c.alice.PublicKey): {Balance: ether(10)},
+		crypto.PubkeyToAddress(c.bob.PublicKey):   {Balance: ether(10)},
+	})
+	c.client = NewClient(c.backend)
+	t.Cleanup(c.client.Close)
+	c.initiatorContract = c.deploy()
+	c.participantContract = c.deploy()
+	return c
+}
+
+// deploy the AtomicSwap contract of contract.ContractBin
+func (c *testChain) deploy() common.Address {
+	ctx := context.Background()
+	sct := c.transactor(c.alice, common.Address{})
+	tx, err := sct.DeployTx(ctx)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err = tx.Send(ctx); err != nil {
+		c.t.Fatal(err)
+	}
+	receipt, err := c.client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return receipt.ContractAddress
+}
+
+func (c *testChain) transactor(key *ecdsa.PrivateKey, contractAddr common.Address) SwapContractTransactor {
+	chainID, err := c.client.ChainID(context.Background())
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	sct, err := NewSwapContractTransactor(context.Background(), c.client, contractAddr, key, chainID)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return sct
+}
+
+// adjustTime moves the clock of the chain forward
+func (c *testChain) adjustTime(d time.Duration) {
+	if err := c.backend.AdjustTime(d); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+func TestSwap(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	aliceAddr, bobAddr := crypto.PubkeyToAddress(c.alice.PublicKey), crypto.PubkeyToAddress(c.bob.PublicKey)
+	policy := timings.DefaultPolicy
+
+	// alice initiates, bob audits the contract
+	initiation, err := Initiate(ctx, c.transactor(c.alice, c.initiatorContract), bobAddr, ether(2), policy, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err := AuditContract(ctx, c.transactor(c.bob, c.initiatorContract), &initiation.ContractTransaction, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.ContractAddress != c.initiatorContract || audit.ContractValue.Cmp(ether(2)) != 0 ||
+		audit.RecipientAddress != bobAddr || audit.RefundAddress != aliceAddr || audit.SecretHash != initiation.SecretHash {
+		t.Errorf("unexpected audit of the initiation: %+v", audit)
+	}
+	if audit.ContractVersion != "AtomicSwap-v1" {
+		t.Errorf("contract version %q audited, expected AtomicSwap-v1", audit.ContractVersion)
+	}
+	receipt, err := c.client.TransactionReceipt(ctx, initiation.ContractTransaction.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := c.client.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockTime := int64(block.Time()) + int64(policy.Initiator/time.Second); audit.Locktime != lockTime {
+		t.Errorf("lock time %d audited, expected %d", audit.Locktime, lockTime)
+	}
+
+	// bob participates, alice audits the contract
+	participation, err := Participate(ctx, c.transactor(c.bob, c.participantContract), aliceAddr, ether(1), initiation.SecretHash, policy, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err = AuditContract(ctx, c.transactor(c.alice, c.participantContract), &participation.ContractTransaction, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.RecipientAddress != aliceAddr || audit.RefundAddress != bobAddr || audit.ContractValue.Cmp(ether(1)) != 0 {
+		t.Errorf("unexpected audit of the participation: %+v", audit)
+	}
+
+	// alice redeems, revealing the secret to bob
+	if _, err = Redeem(ctx, c.transactor(c.bob, c.participantContract), initiation.SecretHash, initiation.Secret, common.Address{}); err == nil {
+		t.Error("participation redeemed by the participant")
+	}
+	redemption, err := Redeem(ctx, c.transactor(c.alice, c.participantContract), initiation.SecretHash, initiation.Secret, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redemptionTx, _, err := c.client.TransactionByHash(ctx, redemption.RedeemTxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := ExtractSecret(ctx, c.transactor(c.bob, c.participantContract), redemptionTx, initiation.SecretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret, initiation.Secret[:]) {
+		t.Errorf("extracted secret %x, expected %x", secret, initiation.Secret)
+	}
+	secret, err = ExtractSecretByHash(ctx, c.transactor(c.bob, c.participantContract), initiation.SecretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret, initiation.Secret[:]) {
+		t.Errorf("extracted secret %x by hash, expected %x", secret, initiation.Secret)
+	}
+
+	// bob redeems using the secret
+	balance := c.backend.Balance(bobAddr)
+	var extracted [32]byte
+	copy(extracted[:], secret)
+	if _, err = Redeem(ctx, c.transactor(c.bob, c.initiatorContract), initiation.SecretHash, extracted, common.Address{}); err != nil {
+		t.Fatal(err)
+	}
+	if received := new(big.Int).Sub(c.backend.Balance(bobAddr), balance); received.Cmp(ether(2)) >= 0 || received.Cmp(ether(1)) <= 0 {
+		t.Errorf("bob received %s Wei redeeming 2 ETH", received)
+	}
+	if _, err = Refund(ctx, c.transactor(c.alice, c.initiatorContract), &initiation.ContractTransaction, common.Address{}); err == nil {
+		t.Error("redeemed contract refunded")
+	}
+}
+
+func TestRefund(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	aliceAddr, bobAddr := crypto.PubkeyToAddress(c.alice.PublicKey), crypto.PubkeyToAddress(c.bob.PublicKey)
+	policy := timings.DefaultPolicy
+
+	initiation, err := Initiate(ctx, c.transactor(c.alice, c.initiatorContract), bobAddr, ether(2), policy, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	participation, err := Participate(ctx, c.transactor(c.bob, c.participantContract), aliceAddr, ether(1), initiation.SecretHash, policy, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the participation is locked for half the time of the initiation
+	c.adjustTime(policy.Participant - time.Minute)
+	if _, err = Refund(ctx, c.transactor(c.bob, c.participantContract), &participation.ContractTransaction, common.Address{}); err == nil || !strings.Contains(err.Error(), "still locked") {
+		t.Errorf("participation refunded before its lock time: %v", err)
+	}
+	c.adjustTime(2 * time.Minute)
+	if _, err = Refund(ctx, c.transactor(c.bob, c.participantContract), &participation.ContractTransaction, common.Address{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Refund(ctx, c.transactor(c.alice, c.initiatorContract), &initiation.ContractTransaction, common.Address{}); err == nil {
+		t.Error("initiation refunded before its lock time")
+	}
+
+	c.adjustTime(policy.Initiator - policy.Participant)
+	if _, err = Refund(ctx, c.transactor(c.bob, c.initiatorContract), &initiation.ContractTransaction, common.Address{}); err == nil {
+		t.Error("initiation refunded by the participant")
+	}
+	balance := c.backend.Balance(aliceAddr)
+	if _, err = Refund(ctx, c.transactor(c.alice, c.initiatorContract), &initiation.ContractTransaction, common.Address{}); err != nil {
+		t.Fatal(err)
+	}
+	if refunded := new(big.Int).Sub(c.backend.Balance(aliceAddr), balance); refunded.Cmp(ether(2)) >= 0 || refunded.Cmp(ether(1)) <= 0 {
+		t.Errorf("alice got %s Wei refunding 2 ETH", refunded)
+	}
+	if _, err = ExtractSecretByHash(ctx, c.transactor(c.bob, c.initiatorContract), initiation.SecretHash); err == nil {
+		t.Error("secret extracted from a refunded contract")
+	}
+}
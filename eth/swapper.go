@@ -0,0 +1,204 @@
+package eth
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// weiPrecision is the amount of decimals of Ether
+const weiPrecision = 18
+
+// Swapper implements chain.Swapper using the AtomicSwap contract,
+// or the TokenAtomicSwap contract for ERC-20 tokens
+type Swapper struct {
+	sct   SwapContractTransactor
+	token TokenInfo
+}
+
+var _ chain.Swapper = (*Swapper)(nil)
+
+// NewSwapper creates a chain.Swapper for Ethereum
+func NewSwapper(sct SwapContractTransactor) *Swapper {
+	return &Swapper{sct: sct, token: TokenInfo{Symbol: "ETH", Decimals: weiPrecision}}
+}
+
+// NewTokenSwapper creates a chain.Swapper for an ERC-20 token,
+// the contract address of the transactor has to be a deployed TokenAtomicSwap contract
+func NewTokenSwapper(ctx context.Context, sct SwapContractTransactor, token common.Address) (*Swapper, error) {
+	info, err := GetTokenInfo(ctx, sct.Client, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Swapper{sct: sct.forToken(token), token: info}, nil
+}
+
+// Chain implements chain.Swapper.Chain
+func (s *Swapper) Chain() string {
+	return "eth"
+}
+
+// Decimals implements chain.Swapper.Decimals
+func (s *Swapper) Decimals() int {
+	return s.token.Decimals
+}
+
+// Address implements chain.Swapper.Address
+func (s *Swapper) Address(ctx context.Context) (string, error) {
+	return s.sct.FromAddr.Hex(), nil
+}
+
+// Initiate implements chain.Swapper.Initiate
+func (s *Swapper) Initiate(ctx context.Context, participant string, amount chain.Amount, secret chain.Secret, policy timings.Policy) (chain.Contract, error) {
+	if err := policy.Validate(); err != nil {
+		return chain.Contract{}, err
+	}
+	participantAddr, err := decodeAddress("participant", participant)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	secretHash := secret.Hash()
+	tx, err := s.sct.initiateTx(ctx, amount.BigInt(), secretHash, participantAddr, policy.Initiator)
+	if err != nil {
+		return chain.Contract{}, fmt.Errorf("failed to create initiate TX: %v", err)
+	}
+	return s.publishContract(ctx, secretHash, tx)
+}
+
+// Participate implements chain.Swapper.Participate
+func (s *Swapper) Participate(ctx context.Context, initiator string, amount chain.Amount, secretHash chain.SecretHash, policy timings.Policy) (chain.Contract, error) {
+	if err := policy.Validate(); err != nil {
+		return chain.Contract{}, err
+	}
+	initiatorAddr, err := decodeAddress("initiator", initiator)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	tx, err := s.sct.participateTx(ctx, amount.BigInt(), secretHash, initiatorAddr, policy.Participant)
+	if err != nil {
+		return chain.Contract{}, fmt.Errorf("failed to create participate TX: %v", err)
+	}
+	return s.publishContract(ctx, secretHash, tx)
+}
+
+func (s *Swapper) publishContract(ctx context.Context, secretHash chain.SecretHash, tx *swapTransaction) (chain.Contract, error) {
+	contractTx, err := encodeTransaction(tx.Transaction)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	contract := chain.Contract{
+		SecretHash:  secretHash,
+		Address:     s.sct.ContractAddr.Hex(),
+		Transaction: contractTx,
+	}
+	err = chain.RecordContract(ctx, contract)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	err = tx.Send(ctx)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	return contract, nil
+}
+
+// Redeem implements chain.Swapper.Redeem
+func (s *Swapper) Redeem(ctx context.Context, contract chain.Contract, secret chain.Secret) (string, error) {
+	output, err := Redeem(ctx, s.sct, contract.SecretHash, secret, s.token.Address)
+	if err != nil {
+		return "", err
+	}
+	return output.RedeemTxHash.Hex(), nil
+}
+
+// Refund implements chain.Swapper.Refund
+func (s *Swapper) Refund(ctx context.Context, contract chain.Contract) (string, error) {
+	contractTx, err := decodeTransaction(contract.Transaction)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := Refund(ctx, s.sct, contractTx, s.token.Address)
+	if err != nil {
+		return "", err
+	}
+	return txHash.Hex(), nil
+}
+
+// AuditContract implements chain.Swapper.AuditContract
+func (s *Swapper) AuditContract(ctx context.Context, contract chain.Contract) (chain.AuditResult, error) {
+	contractTx, err := decodeTransaction(contract.Transaction)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	output, err := AuditContract(ctx, s.sct, contractTx, s.token.Address)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	return output.AuditResult(), nil
+}
+
+// ExtractSecret implements chain.Swapper.ExtractSecret.
+// If no redemption transaction is given, the secret is extracted
+// using the secret hash alone, see ExtractSecretByHash.
+func (s *Swapper) ExtractSecret(ctx context.Context, contract chain.Contract, redemptionTx string) (chain.Secret, error) {
+	var secret []byte
+	if redemptionTx == "" {
+		var err error
+		secret, err = ExtractSecretByHash(ctx, s.sct, contract.SecretHash)
+		if err == ErrNotRedeemed {
+			return chain.Secret{}, chain.ErrNotRedeemed
+		}
+		if err != nil {
+			return chain.Secret{}, err
+		}
+	} else {
+		tx, err := decodeTransaction(redemptionTx)
+		if err != nil {
+			return chain.Secret{}, err
+		}
+		secret, err = ExtractSecret(ctx, s.sct, tx, contract.SecretHash)
+		if err != nil {
+			return chain.Secret{}, err
+		}
+	}
+	var output chain.Secret
+	copy(output[:], secret)
+	return output, nil
+}
+
+func decodeAddress(name, str string) (common.Address, error) {
+	if !common.IsHexAddress(str) {
+		return common.Address{}, fmt.Errorf("invalid %s address: %s", name, str)
+	}
+	return common.HexToAddress(str), nil
+}
+
+// encodeTransaction returns the hex encoded RLP of a transaction,
+// the format used by the ethatomicswap tool
+func encodeTransaction(tx *types.Transaction) (string, error) {
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func decodeTransaction(str string) (*types.Transaction, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("transaction must be hex encoded")
+	}
+	var tx types.Transaction
+	err = rlp.DecodeBytes(b, &tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %v", err)
+	}
+	return &tx, nil
+}
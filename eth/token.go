@@ -0,0 +1,133 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+)
+
+// TokenInfo describes an ERC-20 token
+type TokenInfo struct {
+	Address  common.Address `json:"address"`
+	Symbol   string         `json:"symbol"`
+	Decimals int            `json:"decimals"`
+}
+
+// GetTokenInfo reads the symbol and decimals of an ERC-20 token.
+// The zero address is Ether.
+func GetTokenInfo(ctx context.Context, client *EthClient, token common.Address) (TokenInfo, error) {
+	if token == (common.Address{}) {
+		return TokenInfo{Symbol: "ETH", Decimals: weiPrecision}, nil
+	}
+	erc20, err := contract.NewERC20Caller(token, client.Backend)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to bind token contract (at %x): %v", token, err)
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	decimals, err := erc20.Decimals(opts)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to get the decimals of token %x: %v", token, err)
+	}
+	symbol, err := erc20.Symbol(opts)
+	if err != nil {
+		// the symbol is optional
+		symbol = ""
+	}
+	return TokenInfo{
+		Address:  token,
+		Symbol:   symbol,
+		Decimals: int(decimals),
+	}, nil
+}
+
+// the ABI of the TokenAtomicSwap contracts is the one of its latest version,
+// the calls of the first version are the same, without the ones of relayers
+var (
+	atomicSwapABI    = mustParseABI(contract.ContractABI)
+	tokenContractABI = mustParseABI(contract.TokenContractV2ABI)
+	relayContractABI = mustParseABI(contract.RelayContractABI)
+	erc20ABI         = mustParseABI(contract.ERC20ABI)
+)
+
+func mustParseABI(str string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(str))
+	if err != nil {
+		panic("invalid contract ABI: " + err.Error())
+	}
+	return parsed
+}
+
+// forToken returns a copy of the transactor for the contracts locking an ERC-20 token,
+// the contract address of the transactor then has to be a deployed TokenAtomicSwap contract.
+// The zero address returns the transactor for Ether as is.
+func (sct SwapContractTransactor) forToken(token common.Address) SwapContractTransactor {
+	if token == (common.Address{}) || token == sct.token {
+		return sct
+	}
+	sct.Abi = tokenContractABI
+	sct.token = token
+	return sct
+}
+
+// approveTokens allows the TokenAtomicSwap contract to transfer
+// the amount of tokens to lock from our account,
+// waiting for the approval to be mined as the contract can only lock the tokens afterwards.
+func (sct *SwapContractTransactor) approveTokens(ctx context.Context, amount *big.Int) error {
+	erc20, err := contract.NewERC20Caller(sct.token, sct.Client.Backend)
+	if err != nil {
+		return fmt.Errorf("failed to bind token contract (at %x): %v", sct.token, err)
+	}
+	allowance, err := erc20.Allowance(&bind.CallOpts{Context: ctx}, sct.FromAddr, sct.ContractAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get the token allowance of the contract: %v", err)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil
+	}
+	if sct.unsigned {
+		// the approval has to be mined before the tokens can be locked
+		return fmt.Errorf("the contract is not approved to transfer %s of the tokens yet, approve it first", amount)
+	}
+	tx, err := sct.approveTx(ctx, amount)
+	if err != nil {
+		return fmt.Errorf("failed to create approve TX: %v", err)
+	}
+	if err = tx.Send(ctx); err != nil {
+		return err
+	}
+	receipt, err := bind.WaitMined(ctx, sct.Client, tx.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to wait for approve TX (%x): %v", tx.Hash(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.New("approve TX failed")
+	}
+	return nil
+}
+
+// approveTx creates the transaction allowing the TokenAtomicSwap contract
+// to transfer the amount of tokens from our account
+func (sct *SwapContractTransactor) approveTx(ctx context.Context, amount *big.Int) (*swapTransaction, error) {
+	input, err := erc20ABI.Pack("approve", sct.ContractAddr, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack input")
+	}
+	return sct.newTransactionWithInput(ctx, nil, &sct.token, input)
+}
+
+// assetName returns ETH or the hex address of a token
+func assetName(token common.Address) string {
+	if token == (common.Address{}) {
+		return "ETH"
+	}
+	return token.Hex()
+}
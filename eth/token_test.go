@@ -0,0 +1,196 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/eth/contract"
+	"github.com/threefoldtech/atomicswap/eth/ethtest"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+func TestUnpackTokenContractInputParams(t *testing.T) {
+	secretHash := sha256Hash([]byte("secret"))
+	participant, token := common.HexToAddress("0xb0b"), common.HexToAddress("0x7043")
+	input, err := tokenContractABI.Pack("initiate", big.NewInt(3600), secretHash, participant, token, big.NewInt(500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTx(&types.LegacyTx{To: &common.Address{1}, Value: new(big.Int), Data: input})
+	params, err := unpackContractInputParams(tokenContractABI, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.LockDuration.Int64() != 3600 || params.SecretHash != secretHash || params.ToAddress != participant ||
+		params.Token != token || params.Value.Int64() != 500 {
+		t.Errorf("unexpected params: %+v", params)
+	}
+
+	var sct SwapContractTransactor
+	if forEther := sct.forToken(common.Address{}); forEther.token != (common.Address{}) {
+		t.Error("transactor for Ether bound to a token")
+	}
+	if forToken := sct.forToken(token); forToken.token != token || len(forToken.Abi.Methods) != len(tokenContractABI.Methods) {
+		t.Error("transactor not bound to the token")
+	}
+
+	output := AuditContractOutput{ContractValue: big.NewInt(500), Token: token}
+	if asset := output.AuditResult().Asset; asset != token.Hex() {
+		t.Errorf("asset is %s, expected %s", asset, token.Hex())
+	}
+	if asset := (AuditContractOutput{ContractValue: big.NewInt(1)}).AuditResult().Asset; asset != "ETH" {
+		t.Errorf("asset is %s, expected ETH", asset)
+	}
+}
+
+// deployer returns the options to deploy contracts from the account of alice
+func (c *testChain) deployer() *bind.TransactOpts {
+	chainID, err := c.client.ChainID(context.Background())
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(c.alice, chainID)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return opts
+}
+
+// deployToken deploys an ERC-20 token with the given decimals,
+// minting 1000 tokens for each of the accounts
+func (c *testChain) deployToken(decimals uint8, accounts ...common.Address) (common.Address, *ethtest.TestToken) {
+	opts := c.deployer()
+	token, _, erc20, err := ethtest.DeployTestToken(opts, c.backend, "Test Token", "TT", decimals)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	amount, err := chain.ParseAmount("1000", int(decimals))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	for _, account := range accounts {
+		if _, err = erc20.Mint(opts, account, amount.BigInt()); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return token, erc20
+}
+
+// tokenBalance returns the balance of the token of the account
+func tokenBalance(t *testing.T, erc20 *ethtest.TestToken, account common.Address) *big.Int {
+	balance, err := erc20.BalanceOf(&bind.CallOpts{}, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestTokenSwap(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	aliceAddr, bobAddr := crypto.PubkeyToAddress(c.alice.PublicKey), crypto.PubkeyToAddress(c.bob.PublicKey)
+	policy := timings.DefaultPolicy
+	token, erc20 := c.deployToken(6, aliceAddr, bobAddr)
+	tokenContract, _, _, err := contract.DeployTokenContract(c.deployer(), c.backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := GetTokenInfo(ctx, c.client, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Symbol != "TT" || info.Decimals != 6 {
+		t.Errorf("unexpected token info: %+v", info)
+	}
+	amount, err := chain.ParseAmount("12.5", info.Decimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount.BigInt().Cmp(big.NewInt(12500000)) != 0 {
+		t.Errorf("12.5 tokens parsed as %s", amount.BigInt())
+	}
+
+	// alice initiates, approving the contract to transfer her tokens first
+	initiation, err := Initiate(ctx, c.transactor(c.alice, tokenContract), bobAddr, amount.BigInt(), policy, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := tokenBalance(t, erc20, aliceAddr); balance.Cmp(big.NewInt(987500000)) != 0 {
+		t.Errorf("alice has %s tokens left after locking 12.5", balance)
+	}
+	audit, err := AuditContract(ctx, c.transactor(c.bob, tokenContract), &initiation.ContractTransaction, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.ContractAddress != tokenContract || audit.ContractValue.Cmp(amount.BigInt()) != 0 || audit.Token != token ||
+		audit.RecipientAddress != bobAddr || audit.RefundAddress != aliceAddr || audit.SecretHash != initiation.SecretHash {
+		t.Errorf("unexpected audit of the initiation: %+v", audit)
+	}
+	if audit.ContractVersion != "TokenAtomicSwap-v1" {
+		t.Errorf("contract version %q audited, expected TokenAtomicSwap-v1", audit.ContractVersion)
+	}
+	if _, err = AuditContract(ctx, c.transactor(c.bob, tokenContract), &initiation.ContractTransaction, common.Address{}); err == nil {
+		t.Error("contract locking tokens audited as locking Ether")
+	}
+
+	// bob participates locking the same token, on the same contract
+	participation, err := Participate(ctx, c.transactor(c.bob, tokenContract), aliceAddr, big.NewInt(5000000), initiation.SecretHash, policy, token)
+	if err == nil {
+		t.Fatal("participated with the secret hash of the initiation on the same contract")
+	}
+	secretHash := sha256Hash([]byte("another secret"))
+	if participation, err = Participate(ctx, c.transactor(c.bob, tokenContract), aliceAddr, big.NewInt(5000000), secretHash, policy, token); err != nil {
+		t.Fatal(err)
+	}
+	if audit, err = AuditContract(ctx, c.transactor(c.alice, tokenContract), &participation.ContractTransaction, token); err != nil {
+		t.Fatal(err)
+	}
+	if audit.RecipientAddress != aliceAddr || audit.ContractValue.Int64() != 5000000 {
+		t.Errorf("unexpected audit of the participation: %+v", audit)
+	}
+
+	// bob redeems the tokens of the initiation
+	if _, err = Redeem(ctx, c.transactor(c.alice, tokenContract), initiation.SecretHash, initiation.Secret, token); err == nil {
+		t.Error("initiation redeemed by the initiator")
+	}
+	balance := tokenBalance(t, erc20, bobAddr)
+	if _, err = Redeem(ctx, c.transactor(c.bob, tokenContract), initiation.SecretHash, initiation.Secret, token); err != nil {
+		t.Fatal(err)
+	}
+	if received := new(big.Int).Sub(tokenBalance(t, erc20, bobAddr), balance); received.Cmp(amount.BigInt()) != 0 {
+		t.Errorf("bob received %s tokens redeeming %s", received, amount.BigInt())
+	}
+	secret, err := ExtractSecretByHash(ctx, c.transactor(c.alice, tokenContract), initiation.SecretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret, initiation.Secret[:]) {
+		t.Errorf("extracted secret %x, expected %x", secret, initiation.Secret)
+	}
+
+	// bob refunds the participation once its lock time passed
+	if _, err = Refund(ctx, c.transactor(c.bob, tokenContract), &participation.ContractTransaction, token); err == nil {
+		t.Error("participation refunded before its lock time")
+	}
+	c.adjustTime(policy.Participant + time.Minute)
+	if _, err = Refund(ctx, c.transactor(c.alice, tokenContract), &participation.ContractTransaction, token); err == nil {
+		t.Error("participation refunded by the initiator")
+	}
+	balance = tokenBalance(t, erc20, bobAddr)
+	if _, err = Refund(ctx, c.transactor(c.bob, tokenContract), &participation.ContractTransaction, token); err != nil {
+		t.Fatal(err)
+	}
+	if refunded := new(big.Int).Sub(tokenBalance(t, erc20, bobAddr), balance); refunded.Int64() != 5000000 {
+		t.Errorf("bob got %s tokens refunding 5", refunded)
+	}
+}
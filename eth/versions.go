@@ -0,0 +1,219 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+)
+
+// ContractKind is the kind of atomic swap contract, named after its Solidity contract
+type ContractKind string
+
+// The kinds of atomic swap contracts, see ./contract/src/contracts
+const (
+	KindAtomicSwap      ContractKind = "AtomicSwap"
+	KindTokenAtomicSwap ContractKind = "TokenAtomicSwap"
+	KindRelayAtomicSwap ContractKind = "RelayAtomicSwap"
+)
+
+// ContractVersion is a compiled version of an atomic swap contract,
+// identified by the hash of its code once deployed
+type ContractVersion struct {
+	// Name of the version, e.g. AtomicSwap-v2
+	Name string       `json:"name"`
+	Kind ContractKind `json:"kind"`
+	// Compiler is the version of solc that compiled the contract
+	Compiler string `json:"compiler"`
+	// CodeHash is the Keccak-256 hash of the runtime code of the deployed contract,
+	// the code returned by eth_getCode
+	CodeHash common.Hash `json:"codeHash"`
+	// DeployCode is the creation code deploying the contract,
+	// empty if the version is only known by its deployments
+	DeployCode hexutil.Bytes `json:"deployCode,omitempty"`
+	// ABI of the version as JSON, empty if it is the ABI of the current contract of its kind.
+	// The swaps of older versions are read using it.
+	ABI string `json:"abi,omitempty"`
+}
+
+// Validate the contract version
+func (v ContractVersion) Validate() error {
+	if v.Name == "" {
+		return errors.New("contract version has no name")
+	}
+	switch v.Kind {
+	case KindAtomicSwap, KindTokenAtomicSwap, KindRelayAtomicSwap:
+	default:
+		return fmt.Errorf("contract version %s has unknown kind %q", v.Name, v.Kind)
+	}
+	if v.CodeHash == (common.Hash{}) {
+		return fmt.Errorf("contract version %s has no code hash", v.Name)
+	}
+	if v.ABI != "" {
+		if _, err := abi.JSON(strings.NewReader(v.ABI)); err != nil {
+			return fmt.Errorf("contract version %s has an invalid ABI: %v", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// Token reports whether contracts of the version lock ERC-20 tokens instead of Ether
+func (v ContractVersion) Token() bool {
+	return v.Kind == KindTokenAtomicSwap
+}
+
+// contractABI returns the ABI of the version
+func (v ContractVersion) contractABI() (abi.ABI, error) {
+	if v.ABI != "" {
+		return abi.JSON(strings.NewReader(v.ABI))
+	}
+	switch v.Kind {
+	case KindTokenAtomicSwap:
+		return tokenContractABI, nil
+	case KindRelayAtomicSwap:
+		return relayContractABI, nil
+	default:
+		return atomicSwapABI, nil
+	}
+}
+
+// ContractVersions is a registry of the known versions of the atomic swap contracts,
+// oldest first
+type ContractVersions []ContractVersion
+
+// DefaultContractVersions returns a registry with the versions built into this tool,
+// the ones compiled from the sources in ./contract/src/contracts.
+// AtomicSwap-v1 is the first version of the AtomicSwap contract deployed by this tool,
+// the older versions deployed by others have to be added, see Load.
+func DefaultContractVersions() ContractVersions {
+	return ContractVersions{
+		{
+			Name:       "AtomicSwap-v1",
+			Kind:       KindAtomicSwap,
+			Compiler:   "0.8.19",
+			CodeHash:   common.HexToHash("44dcf70b2586e0719cd8a9dbde448a985840edfdfe79529ccfbae3702acfacee"),
+			DeployCode: common.FromHex(contract.ContractBin),
+		},
+		{
+			Name:       "TokenAtomicSwap-v1",
+			Kind:       KindTokenAtomicSwap,
+			Compiler:   "0.8.21",
+			CodeHash:   common.HexToHash("bfce0c9bae8573159dfaba2015adabae17bb02c0db46626631f8fa46989cf907"),
+			DeployCode: common.FromHex(contract.TokenContractBin),
+			// the first version has no calls for relayers
+			ABI: contract.TokenContractABI,
+		},
+		{
+			Name:       "RelayAtomicSwap-v1",
+			Kind:       KindRelayAtomicSwap,
+			Compiler:   "0.8.21",
+			CodeHash:   common.HexToHash("943372105075e75b3887e0549ac3cdfefc6f1e2e2783bee8e6ee8f70250a1a1b"),
+			DeployCode: common.FromHex(contract.RelayContractBin),
+		},
+		{
+			Name:       "TokenAtomicSwap-v2",
+			Kind:       KindTokenAtomicSwap,
+			Compiler:   "0.8.21",
+			CodeHash:   common.HexToHash("aadbfd85ae051b9d476d270e68a2313c4e56867e772d0e0169b86171d6fbb4eb"),
+			DeployCode: common.FromHex(contract.TokenContractV2Bin),
+		},
+	}
+}
+
+// Add a version to the registry, replacing the one with the same name
+func (vs *ContractVersions) Add(v ContractVersion) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	for i, known := range *vs {
+		if known.Name == v.Name {
+			(*vs)[i] = v
+			return nil
+		}
+	}
+	*vs = append(*vs, v)
+	return nil
+}
+
+// Load the contract versions of a JSON file, a list of versions such as
+//
+//	[{"name": "AtomicSwap-v0", "kind": "AtomicSwap", "compiler": "0.4.24", "codeHash": "0x...", "abi": "[...]"}]
+//
+// into the registry, replacing the built-in versions with the same name.
+func (vs *ContractVersions) Load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read contract versions file (%s): %v", path, err)
+	}
+	var versions []ContractVersion
+	if err = json.Unmarshal(b, &versions); err != nil {
+		return fmt.Errorf("failed to decode contract versions file (%s): %v", path, err)
+	}
+	for _, v := range versions {
+		if err = vs.Add(v); err != nil {
+			return fmt.Errorf("invalid contract version in contract versions file (%s): %v", path, err)
+		}
+	}
+	return nil
+}
+
+// ByCodeHash returns the version of which the deployed code has the given hash
+func (vs ContractVersions) ByCodeHash(codeHash common.Hash) (ContractVersion, bool) {
+	for _, v := range vs {
+		if v.CodeHash == codeHash {
+			return v, true
+		}
+	}
+	return ContractVersion{}, false
+}
+
+// ByDeployCode returns the version deployed by the creation code
+func (vs ContractVersions) ByDeployCode(deployCode []byte) (ContractVersion, bool) {
+	for _, v := range vs {
+		if len(v.DeployCode) != 0 && string(v.DeployCode) == string(deployCode) {
+			return v, true
+		}
+	}
+	return ContractVersion{}, false
+}
+
+// Latest returns the most recent version of the kind that can be deployed
+func (vs ContractVersions) Latest(kind ContractKind) (ContractVersion, bool) {
+	for i := len(vs) - 1; i >= 0; i-- {
+		if vs[i].Kind == kind && len(vs[i].DeployCode) != 0 {
+			return vs[i], true
+		}
+	}
+	return ContractVersion{}, false
+}
+
+// ErrUnknownContractCode is returned when the code deployed at an address
+// does not match any known version of the atomic swap contracts
+var ErrUnknownContractCode = errors.New("contract code does not match a known version of the atomic swap contracts")
+
+// VerifyContractCode returns the version of the contract deployed at the address,
+// comparing the hash of its code, as returned by the node, with the known versions
+func VerifyContractCode(ctx context.Context, client *EthClient, addr common.Address, versions ContractVersions) (ContractVersion, error) {
+	code, err := client.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return ContractVersion{}, fmt.Errorf("failed to get code of contract (%x): %v", addr, err)
+	}
+	if len(code) == 0 {
+		return ContractVersion{}, fmt.Errorf("no contract deployed at %x", addr)
+	}
+	codeHash := crypto.Keccak256Hash(code)
+	v, ok := versions.ByCodeHash(codeHash)
+	if !ok {
+		return ContractVersion{}, fmt.Errorf("%w: %x has code hash %x", ErrUnknownContractCode, addr, codeHash)
+	}
+	return v, nil
+}
@@ -0,0 +1,108 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/threefoldtech/atomicswap/eth/contract"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+func TestDefaultContractVersions(t *testing.T) {
+	versions := DefaultContractVersions()
+	for _, kind := range []ContractKind{KindAtomicSwap, KindTokenAtomicSwap, KindRelayAtomicSwap} {
+		v, ok := versions.Latest(kind)
+		if !ok {
+			t.Errorf("no %s version to deploy", kind)
+			continue
+		}
+		if err := v.Validate(); err != nil {
+			t.Error(err)
+		}
+		// solc appends the runtime code to the code of the constructor,
+		// which ends copying it to memory and returning it
+		i := bytes.Index(v.DeployCode, common.FromHex("6000396000f3fe"))
+		if i < 0 {
+			t.Errorf("constructor not found in the deploy code of %s", v.Name)
+			continue
+		}
+		runtimeCode := v.DeployCode[i+7:]
+		if codeHash := crypto.Keccak256Hash(runtimeCode); codeHash != v.CodeHash {
+			t.Errorf("code hash of %s is %x, expected %x", v.Name, v.CodeHash, codeHash)
+		}
+		if found, ok := versions.ByDeployCode(v.DeployCode); !ok || found.Name != v.Name {
+			t.Errorf("deploy code of %s not found", v.Name)
+		}
+	}
+	if v, _ := versions.Latest(KindTokenAtomicSwap); v.Name != "TokenAtomicSwap-v2" {
+		t.Errorf("latest TokenAtomicSwap version is %s, expected TokenAtomicSwap-v2", v.Name)
+	}
+	if found, ok := versions.ByDeployCode(common.FromHex(contract.ContractBin)); !ok || found.Name != "AtomicSwap-v1" {
+		t.Error("deploy code of the AtomicSwap contract not found")
+	}
+
+	n := len(versions)
+	if err := versions.Add(ContractVersion{Name: "AtomicSwap-v1", Kind: KindAtomicSwap, CodeHash: common.Hash{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != n || versions[0].CodeHash != (common.Hash{1}) {
+		t.Errorf("version not replaced: %+v", versions)
+	}
+	if err := versions.Add(ContractVersion{Name: "invalid", Kind: "Swap", CodeHash: common.Hash{2}}); err == nil {
+		t.Error("added version of an unknown kind")
+	}
+}
+
+func TestVerifyContractCode(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	aliceAddr, bobAddr := crypto.PubkeyToAddress(c.alice.PublicKey), crypto.PubkeyToAddress(c.bob.PublicKey)
+	token, _ := c.deployToken(18, aliceAddr)
+	v1, _, _, err := contract.DeployTokenContract(c.deployer(), c.backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, _, _, err := contract.DeployTokenContractV2(c.deployer(), c.backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions := DefaultContractVersions()
+	if _, err = VerifyContractCode(ctx, c.client, token, versions); !errors.Is(err, ErrUnknownContractCode) {
+		t.Errorf("unknown code verified: %v", err)
+	}
+	for addr, name := range map[common.Address]string{c.initiatorContract: "AtomicSwap-v1", v1: "TokenAtomicSwap-v1", v2: "TokenAtomicSwap-v2"} {
+		v, err := VerifyContractCode(ctx, c.client, addr, versions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Name != name {
+			t.Errorf("verified version %s, expected %s", v.Name, name)
+		}
+	}
+
+	// the swaps of the first version are read using its ABI,
+	// which has no calls for relayers
+	initiation, err := Initiate(ctx, c.transactor(c.alice, v1), bobAddr, ether(1), timings.DefaultPolicy, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct := c.transactor(c.bob, v1).forToken(token)
+	sc, err := sct.getSwapContract(ctx, initiation.SecretHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.SecretHash != initiation.SecretHash || sc.Initiator != aliceAddr || sc.Participant != bobAddr ||
+		sc.Value.Cmp(ether(1)) != 0 || sc.Token != token || sc.State != swapStateFilled || sc.Kind != swapKindInitiator {
+		t.Errorf("unexpected swap: %+v", sc)
+	}
+	if _, err = RelayRedeem(ctx, c.transactor(c.alice, v1), initiation.SecretHash, initiation.Secret, nil, token); err == nil || !strings.Contains(err.Error(), "does not support relayers") {
+		t.Errorf("relayed a redemption on the first version of the TokenAtomicSwap contract: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultConfirmations is the default amount of blocks,
+	// including the one of the transaction, a transaction is waited for
+	DefaultConfirmations = 1
+	// DefaultBumpPercent is the default percentage by which the fees of a stuck transaction are bumped,
+	// nodes require at least 10% to accept a replacement transaction
+	DefaultBumpPercent = 15
+	// minBumpPercent is the percentage by which nodes require the fees of a replacement to be bumped
+	minBumpPercent = 10
+	// DefaultWaitPollInterval is the default interval at which a transaction is checked
+	DefaultWaitPollInterval = 5 * time.Second
+)
+
+// WaitOptions define how long a transaction is waited for,
+// and when it is replaced with one paying more fees.
+type WaitOptions struct {
+	// Confirmations is the amount of blocks, including the one of the transaction,
+	// the transaction has to be buried under, DefaultConfirmations if zero
+	Confirmations uint64
+	// BumpAfter is the time after which a transaction that is not mined yet
+	// is replaced by one with bumped fees and the same nonce, zero to never replace it
+	BumpAfter time.Duration
+	// BumpPercent is the percentage by which the fees are bumped, DefaultBumpPercent if zero
+	BumpPercent int64
+	// MaxGasFeeCap is the maximum fee per gas (or gas price) the fees are bumped to, nil for no maximum
+	MaxGasFeeCap *big.Int
+	// PollInterval is the interval at which the transactions are checked, DefaultWaitPollInterval if zero
+	PollInterval time.Duration
+}
+
+var (
+	// ErrTxReplaced is returned by WaitMined when the nonce of the transaction
+	// is used by another transaction than the ones it sent
+	ErrTxReplaced = errors.New("transaction replaced by another transaction with the same nonce")
+	// errMaxGasFeeCap is returned by bumpFees when the fees can not be bumped anymore
+	errMaxGasFeeCap = errors.New("maximum fee per gas reached")
+)
+
+// WaitMined waits until the transaction with the given hash, sent by us, is mined
+// and has the requested amount of confirmations.
+// Once it is not mined after BumpAfter, it is rebroadcasted with bumped fees, using the same nonce.
+// The mined transaction, which is a replacement if the original transaction got stuck,
+// is returned together with its receipt. A transaction that is mined but reverted
+// is returned together with an error.
+func (sct *SwapContractTransactor) WaitMined(ctx context.Context, txHash common.Hash, opts WaitOptions) (*types.Transaction, *types.Receipt, error) {
+	if opts.Confirmations == 0 {
+		opts.Confirmations = DefaultConfirmations
+	}
+	if opts.BumpPercent == 0 {
+		opts.BumpPercent = DefaultBumpPercent
+	} else if opts.BumpPercent < minBumpPercent {
+		opts.BumpPercent = minBumpPercent
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultWaitPollInterval
+	}
+	tx, _, err := sct.Client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get transaction (%x): %v", txHash, err)
+	}
+	// all transactions sent with the nonce, any of them can be mined
+	txs := []*types.Transaction{tx}
+	lastSent := time.Now()
+	bump := opts.BumpAfter > 0
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		mined, receipt, err := sct.minedTransaction(ctx, txs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if receipt != nil {
+			head, err := sct.Client.BlockNumber(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get the latest block number: %v", err)
+			}
+			if head+1 >= receipt.BlockNumber.Uint64()+opts.Confirmations {
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					return mined, receipt, fmt.Errorf("transaction (%x) failed", mined.Hash())
+				}
+				return mined, receipt, nil
+			}
+		} else {
+			nonce, err := sct.Client.NonceAt(ctx, sct.FromAddr, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to retrieve account (%x) nonce: %v", sct.FromAddr, err)
+			}
+			if nonce > tx.Nonce() {
+				// the nonce is used, but none of our transactions has a receipt:
+				// check once more as the receipt could be mined in between
+				if _, receipt, err = sct.minedTransaction(ctx, txs); err == nil && receipt == nil {
+					return nil, nil, ErrTxReplaced
+				}
+			} else if bump && time.Since(lastSent) >= opts.BumpAfter {
+				replacement, err := sct.bumpFees(ctx, txs[len(txs)-1], opts)
+				switch err {
+				case nil:
+					err = sct.Client.SendTransaction(ctx, replacement)
+					if err == nil {
+						txs = append(txs, replacement)
+					} else if _, receipt, _ := sct.minedTransaction(ctx, txs); receipt == nil {
+						// the replacement is only expected to fail
+						// if one of the transactions got mined in between
+						return nil, nil, fmt.Errorf("failed to send replacement transaction: %v", err)
+					}
+				case errMaxGasFeeCap:
+					// keep waiting for the transactions sent
+					bump = false
+				default:
+					return nil, nil, err
+				}
+				lastSent = time.Now()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// minedTransaction returns the first transaction that has a receipt
+func (sct *SwapContractTransactor) minedTransaction(ctx context.Context, txs []*types.Transaction) (*types.Transaction, *types.Receipt, error) {
+	for _, tx := range txs {
+		receipt, err := sct.Client.TransactionReceipt(ctx, tx.Hash())
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get receipt of transaction (%x): %v", tx.Hash(), err)
+		}
+		return tx, receipt, nil
+	}
+	return nil, nil, nil
+}
+
+// bumpFees returns a copy of the transaction, signed with its fees bumped
+// by the bump percentage, and at least the fees currently suggested.
+func (sct *SwapContractTransactor) bumpFees(ctx context.Context, tx *types.Transaction, opts WaitOptions) (*types.Transaction, error) {
+	gasPrice, gasFeeCap, gasTipCap, err := sct.gasFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rawTx *types.Transaction
+	if tx.Type() == types.DynamicFeeTxType {
+		if gasFeeCap == nil {
+			return nil, errors.New("chain no longer supports dynamic fee transactions")
+		}
+		tipCap := maxBig(bumpFee(tx.GasTipCap(), opts.BumpPercent), gasTipCap)
+		feeCap := maxBig(bumpFee(tx.GasFeeCap(), opts.BumpPercent), gasFeeCap)
+		if feeCap, err = capFee(feeCap, tx.GasFeeCap(), opts.MaxGasFeeCap); err != nil {
+			return nil, err
+		}
+		if tipCap.Cmp(feeCap) > 0 {
+			tipCap = feeCap
+			// nodes require both fees to be bumped to accept the replacement
+			if tipCap.Cmp(bumpFee(tx.GasTipCap(), minBumpPercent)) < 0 {
+				return nil, errMaxGasFeeCap
+			}
+		}
+		rawTx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   tx.ChainId(),
+			Nonce:     tx.Nonce(),
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Gas:       tx.Gas(),
+			To:        tx.To(),
+			Value:     tx.Value(),
+			Data:      tx.Data(),
+		})
+	} else {
+		if gasPrice == nil {
+			// legacy transactions are still accepted, paying the fee cap as gas price
+			gasPrice = gasFeeCap
+		}
+		price := maxBig(bumpFee(tx.GasPrice(), opts.BumpPercent), gasPrice)
+		if price, err = capFee(price, tx.GasPrice(), opts.MaxGasFeeCap); err != nil {
+			return nil, err
+		}
+		rawTx = types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: price,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+	}
+	return sct.sign(ctx, rawTx)
+}
+
+// bumpFee increases a fee by a percentage, rounding up
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// capFee limits a bumped fee to the maximum,
+// returning errMaxGasFeeCap if it then no longer bumps the previous fee enough to replace it
+func capFee(fee, previous, max *big.Int) (*big.Int, error) {
+	if max == nil || fee.Cmp(max) <= 0 {
+		return fee, nil
+	}
+	if max.Cmp(bumpFee(previous, minBumpPercent)) < 0 {
+		return nil, errMaxGasFeeCap
+	}
+	return new(big.Int).Set(max), nil
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,143 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/threefoldtech/atomicswap/eth/ethtest"
+)
+
+func TestBumpFee(t *testing.T) {
+	testCases := []struct {
+		Fee, Percent, Expected int64
+	}{
+		{100, 10, 110},
+		{100, 15, 115},
+		{101, 10, 112}, // rounded up
+		{0, 10, 0},
+	}
+	for idx, testCase := range testCases {
+		if bumped := bumpFee(big.NewInt(testCase.Fee), testCase.Percent); bumped.Int64() != testCase.Expected {
+			t.Errorf("testCase #%d: bumped fee is %s, expected %d", idx, bumped, testCase.Expected)
+		}
+	}
+}
+
+func TestCapFee(t *testing.T) {
+	if fee, err := capFee(big.NewInt(115), big.NewInt(100), nil); err != nil || fee.Int64() != 115 {
+		t.Errorf("uncapped fee is %v (%v), expected 115", fee, err)
+	}
+	if fee, err := capFee(big.NewInt(115), big.NewInt(100), big.NewInt(112)); err != nil || fee.Int64() != 112 {
+		t.Errorf("capped fee is %v (%v), expected 112", fee, err)
+	}
+	// a replacement has to bump the fee by at least 10%
+	if _, err := capFee(big.NewInt(115), big.NewInt(100), big.NewInt(105)); err != errMaxGasFeeCap {
+		t.Errorf("expected errMaxGasFeeCap, got %v", err)
+	}
+}
+
+// stuckTxBackend never mines the first transactions sent to it, as if they were stuck,
+// and mines an empty block every time the latest block number is requested
+type stuckTxBackend struct {
+	*ethtest.Backend
+	stuck int
+	txs   map[common.Hash]*types.Transaction
+}
+
+func (b *stuckTxBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.stuck > 0 {
+		b.stuck--
+		b.txs[tx.Hash()] = tx
+		return nil
+	}
+	return b.Backend.SendTransaction(ctx, tx)
+}
+
+func (b *stuckTxBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if tx, ok := b.txs[txHash]; ok {
+		return tx, true, nil
+	}
+	return b.Backend.TransactionByHash(ctx, txHash)
+}
+
+func (b *stuckTxBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	b.Commit()
+	return b.Blockchain().CurrentBlock().Number.Uint64(), nil
+}
+
+func TestWaitMined(t *testing.T) {
+	c := newTestChain(t)
+	ctx := context.Background()
+	backend := &stuckTxBackend{Backend: c.backend, txs: make(map[common.Hash]*types.Transaction)}
+	client := NewClient(backend)
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct, err := NewSwapContractTransactor(ctx, client, c.initiatorContract, c.alice, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sendStuck sends an initiate transaction that is never mined
+	sendStuck := func(secret string) *types.Transaction {
+		backend.stuck = 1
+		tx, err := sct.initiateTx(ctx, ether(1), sha256Hash([]byte(secret)), crypto.PubkeyToAddress(c.bob.PublicKey), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tx.Send(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return tx.Transaction
+	}
+	opts := WaitOptions{
+		Confirmations: 3,
+		BumpAfter:     time.Millisecond,
+		PollInterval:  time.Millisecond,
+	}
+
+	// the stuck transaction is replaced by one with bumped fees
+	stuck := sendStuck("replaced")
+	mined, receipt, err := sct.WaitMined(ctx, stuck.Hash(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mined.Hash() == stuck.Hash() || mined.Hash() != receipt.TxHash {
+		t.Fatalf("mined transaction %x, expected a replacement of %x", receipt.TxHash, stuck.Hash())
+	}
+	if mined.Nonce() != stuck.Nonce() {
+		t.Errorf("replacement has nonce %d, expected %d", mined.Nonce(), stuck.Nonce())
+	}
+	if mined.GasTipCap().Cmp(bumpFee(stuck.GasTipCap(), minBumpPercent)) < 0 ||
+		mined.GasFeeCap().Cmp(bumpFee(stuck.GasFeeCap(), minBumpPercent)) < 0 {
+		t.Errorf("replacement fees (%s, %s) not bumped from (%s, %s)",
+			mined.GasTipCap(), mined.GasFeeCap(), stuck.GasTipCap(), stuck.GasFeeCap())
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head < receipt.BlockNumber.Uint64()+opts.Confirmations-1 {
+		t.Errorf("transaction mined in block %d returned at block %d, before %d confirmations",
+			receipt.BlockNumber, head, opts.Confirmations)
+	}
+
+	// the nonce of a stuck transaction can be used by a transaction we did not wait for
+	stuck = sendStuck("other")
+	other, err := sct.initiateTx(ctx, ether(1), sha256Hash([]byte("other")), crypto.PubkeyToAddress(c.bob.PublicKey), 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = other.Send(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = sct.WaitMined(ctx, stuck.Hash(), WaitOptions{PollInterval: time.Millisecond}); err != ErrTxReplaced {
+		t.Errorf("expected ErrTxReplaced, got %v", err)
+	}
+}
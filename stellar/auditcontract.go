@@ -5,10 +5,12 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/stellar/go/amount"
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/txnbuild"
+	"github.com/threefoldtech/atomicswap/chain"
 )
 
 type (
@@ -130,3 +132,33 @@ func AuditContract(network string, refundTx txnbuild.Transaction, holdingAccount
 	}
 	return output, nil
 }
+
+// AuditResult converts the output of an audit of a holding account of asset
+// to the chain agnostic chain.AuditResult
+func (o AuditContractOutput) AuditResult(asset txnbuild.Asset) (chain.AuditResult, error) {
+	value, err := amount.ParseInt64(o.ContractValue)
+	if err != nil {
+		return chain.AuditResult{}, fmt.Errorf("invalid contract value %q: %v", o.ContractValue, err)
+	}
+	secretHash, err := chain.ParseSecretHash(o.SecretHash)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	return chain.AuditResult{
+		ContractAddress:  o.ContractAddress,
+		ContractValue:    chain.AmountFromInt64(value),
+		RecipientAddress: o.RecipientAddress,
+		RefundAddress:    o.RefundAddress,
+		SecretHash:       secretHash,
+		LockTime:         chain.LockTime(o.Locktime),
+		Asset:            AssetName(asset),
+	}, nil
+}
+
+// AssetName returns XLM for lumens and code:issuer for other assets
+func AssetName(asset txnbuild.Asset) string {
+	if asset.IsNative() {
+		return "XLM"
+	}
+	return asset.GetCode() + ":" + asset.GetIssuer()
+}
@@ -10,13 +10,16 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
+// ErrNotRedeemed is returned by ExtractSecret when the holding account has not been debited yet
+var ErrNotRedeemed = errors.New("The holdingaccount has not been redeemed yet")
+
 func ExtractSecret(network string, holdingAccountAdress string, secretHash string, client horizonclient.ClientInterface) ([]byte, error) {
 	transactions, err := GetAccountDebitediTransactions(holdingAccountAdress, client)
 	if err != nil {
 		return nil, fmt.Errorf("Error getting the transaction that debited the holdingAccount: %v", err)
 	}
 	if len(transactions) == 0 {
-		return nil, errors.New("The holdingaccount has not been redeemed yet")
+		return nil, ErrNotRedeemed
 	}
 	var extractedSecret []byte
 transactionsLoop:
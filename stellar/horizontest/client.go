@@ -0,0 +1,94 @@
+package horizontest
+
+import (
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/txnbuild"
+)
+
+// Root implements horizonclient.ClientInterface.Root
+func (h *Horizon) Root() (horizon.Root, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return horizon.Root{
+		NetworkPassphrase: h.network,
+	}, nil
+}
+
+// AccountDetail implements horizonclient.ClientInterface.AccountDetail
+func (h *Horizon) AccountDetail(request horizonclient.AccountRequest) (horizon.Account, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	a, ok := h.accounts[request.AccountID]
+	if !ok {
+		return horizon.Account{}, notFound()
+	}
+	return a.horizonAccount(), nil
+}
+
+// Effects implements horizonclient.ClientInterface.Effects,
+// only effects for an account are supported
+func (h *Horizon) Effects(request horizonclient.EffectRequest) (effects.EffectsPage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	records := make([]effects.Effect, 0)
+	for _, record := range h.effects {
+		if record.account == request.ForAccount {
+			records = append(records, record.effect)
+		}
+	}
+	if request.Order == horizonclient.OrderDesc {
+		for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+			records[i], records[j] = records[j], records[i]
+		}
+	}
+	if request.Cursor != "" {
+		for i, record := range records {
+			if record.PagingToken() == request.Cursor {
+				records = records[i+1:]
+				break
+			}
+		}
+	}
+	limit := int(request.Limit)
+	if limit == 0 {
+		limit = 10
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	var page effects.EffectsPage
+	page.Embedded.Records = records
+	return page, nil
+}
+
+// OperationDetail implements horizonclient.ClientInterface.OperationDetail
+func (h *Horizon) OperationDetail(id string) (operations.Operation, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	op, ok := h.operations[id]
+	if !ok {
+		return nil, notFound()
+	}
+	return op, nil
+}
+
+// TransactionDetail implements horizonclient.ClientInterface.TransactionDetail
+func (h *Horizon) TransactionDetail(txHash string) (horizon.Transaction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tx, ok := h.transactions[txHash]
+	if !ok {
+		return horizon.Transaction{}, notFound()
+	}
+	return tx, nil
+}
+
+// SubmitTransaction implements horizonclient.ClientInterface.SubmitTransaction
+func (h *Horizon) SubmitTransaction(tx *txnbuild.Transaction) (horizon.Transaction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.submit(tx)
+}
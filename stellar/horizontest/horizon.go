@@ -0,0 +1,332 @@
+// Package horizontest provides an in-process fake Horizon server,
+// keeping the state of the accounts of a ledger it applies submitted transactions to,
+// so Stellar atomic swaps can be tested without a network.
+package horizontest
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/txnbuild"
+)
+
+// baseURL of the links in the resources of the fake Horizon
+const baseURL = "https://horizon.test/"
+
+// Horizon is a fake Horizon server applying transactions to an in-memory ledger.
+// It implements the horizonclient.ClientInterface methods used by the atomic swaps,
+// calling any other method panics.
+type Horizon struct {
+	horizonclient.ClientInterface
+
+	network string
+
+	mu           sync.Mutex
+	offset       time.Duration
+	ledgerSeq    int32
+	accounts     ledger
+	transactions map[string]horizon.Transaction
+	operations   map[string]operations.Operation
+	effects      []effectRecord
+}
+
+// effectRecord is an effect on an account
+type effectRecord struct {
+	account string
+	effect  effects.Effect
+}
+
+var _ horizonclient.ClientInterface = (*Horizon)(nil)
+
+// NewHorizon creates a fake Horizon server of the network with the given passphrase
+func NewHorizon(network string) *Horizon {
+	return &Horizon{
+		network:      network,
+		ledgerSeq:    1,
+		accounts:     make(ledger),
+		transactions: make(map[string]horizon.Transaction),
+		operations:   make(map[string]operations.Operation),
+	}
+}
+
+// NewAccount creates an account with the balance in lumens,
+// as friendbot does on the test network
+func (h *Horizon) NewAccount(balance string) *keypair.Full {
+	kp := keypair.MustRandom()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.accounts[kp.Address()] = newAccount(kp.Address(), int64(amount.MustParse(balance)), h.ledgerSeq)
+	return kp
+}
+
+// Issue credits an amount of a credit asset to an account,
+// adding a trust line without limit to the account if it does not trust the asset yet.
+func (h *Horizon) Issue(creditAsset txnbuild.Asset, address string, value string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	a, ok := h.accounts[address]
+	if !ok {
+		panic(fmt.Sprintf("account %s does not exist", address))
+	}
+	key := assetOf(creditAsset)
+	tl, ok := a.trustlines[key]
+	if !ok {
+		tl = &trustline{limit: math.MaxInt64}
+		a.trustlines[key] = tl
+	}
+	tl.balance += int64(amount.MustParse(value))
+}
+
+// Balance returns the balance of the account in the asset,
+// or an empty string if the account does not exist or does not trust the asset.
+func (h *Horizon) Balance(address string, a txnbuild.Asset) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	acc, ok := h.accounts[address]
+	if !ok {
+		return ""
+	}
+	if a.IsNative() {
+		return amount.StringFromInt64(acc.balance)
+	}
+	tl, ok := acc.trustlines[assetOf(a)]
+	if !ok {
+		return ""
+	}
+	return amount.StringFromInt64(tl.balance)
+}
+
+// AdjustTime moves the clock of the ledger,
+// which is used to validate the time bounds of transactions
+func (h *Horizon) AdjustTime(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.offset += d
+}
+
+// Now returns the time of the ledger
+func (h *Horizon) Now() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.now()
+}
+
+func (h *Horizon) now() time.Time {
+	return time.Now().Add(h.offset)
+}
+
+// submit validates the transaction and applies it to the ledger.
+// A transaction with a failed operation is recorded, consuming its fee and sequence number,
+// but none of its operations is applied.
+func (h *Horizon) submit(tx *txnbuild.Transaction) (horizon.Transaction, error) {
+	hash, err := tx.Hash(h.network)
+	if err != nil {
+		return horizon.Transaction{}, err
+	}
+	now := h.now()
+
+	sourceAccount := tx.SourceAccount()
+	source, ok := h.accounts[sourceAccount.AccountID]
+	if !ok {
+		return horizon.Transaction{}, transactionFailed("tx_no_source_account")
+	}
+	if sourceAccount.Sequence != source.sequence+1 {
+		return horizon.Transaction{}, transactionFailed("tx_bad_seq")
+	}
+	bounds := tx.Timebounds()
+	if bounds.MinTime > now.Unix() {
+		return horizon.Transaction{}, transactionFailed("tx_too_early")
+	}
+	if bounds.MaxTime != 0 && bounds.MaxTime < now.Unix() {
+		return horizon.Transaction{}, transactionFailed("tx_too_late")
+	}
+	ops := tx.Operations()
+	if len(ops) == 0 {
+		return horizon.Transaction{}, transactionFailed("tx_missing_operation")
+	}
+	if tx.BaseFee() < BaseFee {
+		return horizon.Transaction{}, transactionFailed("tx_insufficient_fee")
+	}
+	fee := int64(BaseFee * len(ops))
+	if source.balance-fee < source.reserve() {
+		return horizon.Transaction{}, transactionFailed("tx_insufficient_balance")
+	}
+
+	signatures := newSignatureChecker(hash, tx.Signatures())
+	if !signatures.check(source, low) {
+		return horizon.Transaction{}, transactionFailed("tx_bad_auth")
+	}
+	opSources := make([]string, len(ops))
+	for i, op := range ops {
+		opSources[i] = op.GetSourceAccount()
+		if opSources[i] == "" {
+			opSources[i] = source.id
+		}
+		opSource, ok := h.accounts[opSources[i]]
+		if !ok {
+			return horizon.Transaction{}, transactionFailed("tx_failed", "op_no_source_account")
+		}
+		if !signatures.check(opSource, thresholdLevel(op)) {
+			return horizon.Transaction{}, transactionFailed("tx_bad_auth")
+		}
+	}
+	if signatures.unused() {
+		return horizon.Transaction{}, transactionFailed("tx_bad_auth_extra")
+	}
+
+	// the transaction is valid, consume its fee, sequence number and pre-authorization
+	source.balance -= fee
+	source.sequence++
+	preAuthSigner, err := strkey.Encode(strkey.VersionByteHashTx, hash[:])
+	if err != nil {
+		return horizon.Transaction{}, err
+	}
+	for _, a := range h.accounts {
+		a.removeSigner(preAuthSigner)
+	}
+	h.ledgerSeq++
+
+	state := h.accounts.copy()
+	codes := make([]string, len(ops))
+	changes := make([][]change, len(ops))
+	successful := true
+	for i, op := range ops {
+		codes[i], changes[i] = state.apply(op, opSources[i], h.ledgerSeq)
+		successful = successful && codes[i] == "op_success"
+	}
+
+	txHash := hex.EncodeToString(hash[:])
+	envelope, err := tx.Base64()
+	if err != nil {
+		return horizon.Transaction{}, err
+	}
+	txSignatures := make([]string, 0, len(tx.Signatures()))
+	for _, sig := range tx.Signatures() {
+		txSignatures = append(txSignatures, base64.StdEncoding.EncodeToString(sig.Signature))
+	}
+	record := horizon.Transaction{
+		ID:              txHash,
+		Successful:      successful,
+		Hash:            txHash,
+		Ledger:          h.ledgerSeq,
+		LedgerCloseTime: now,
+		Account:         source.id,
+		FeeCharged:      fee,
+		OperationCount:  int32(len(ops)),
+		EnvelopeXdr:     envelope,
+		Signatures:      txSignatures,
+	}
+	h.transactions[txHash] = record
+	if !successful {
+		return horizon.Transaction{}, transactionFailed("tx_failed", codes...)
+	}
+
+	h.accounts = state
+	for i, op := range ops {
+		opID := fmt.Sprint(toid(h.ledgerSeq, i))
+		h.operations[opID] = operations.Base{
+			ID:                    opID,
+			PT:                    opID,
+			TransactionSuccessful: true,
+			SourceAccount:         opSources[i],
+			Type:                  operationType(op),
+			LedgerCloseTime:       now,
+			TransactionHash:       txHash,
+		}
+		for j, c := range changes[i] {
+			h.effects = append(h.effects, effectRecord{
+				account: c.account,
+				effect:  newEffect(c, opID, j, now),
+			})
+		}
+	}
+	return record, nil
+}
+
+// toid returns the ID of an operation of the single transaction of a ledger
+func toid(ledgerSeq int32, opIndex int) int64 {
+	return int64(ledgerSeq)<<32 | 1<<12 | int64(opIndex+1)
+}
+
+func operationType(op txnbuild.Operation) string {
+	switch op.(type) {
+	case *txnbuild.CreateAccount:
+		return "create_account"
+	case *txnbuild.Payment:
+		return "payment"
+	case *txnbuild.ChangeTrust:
+		return "change_trust"
+	case *txnbuild.SetOptions:
+		return "set_options"
+	case *txnbuild.AccountMerge:
+		return "account_merge"
+	default:
+		return "unknown"
+	}
+}
+
+func newEffect(c change, opID string, index int, closeTime time.Time) effects.Effect {
+	b := effects.Base{
+		ID:              fmt.Sprintf("%s-%d", opID, index+1),
+		PT:              fmt.Sprintf("%s-%d", opID, index+1),
+		Account:         c.account,
+		Type:            effects.EffectTypeNames[c.kind],
+		TypeI:           int32(c.kind),
+		LedgerCloseTime: closeTime,
+	}
+	b.Links.Operation.Href = baseURL + "operations/" + opID
+	value := amount.StringFromInt64(c.amount)
+	switch c.kind {
+	case effects.EffectAccountCreated:
+		return effects.AccountCreated{Base: b, StartingBalance: value}
+	case effects.EffectAccountCredited:
+		return effects.AccountCredited{Base: b, Asset: c.asset.balanceAsset(), Amount: value}
+	case effects.EffectAccountDebited:
+		return effects.AccountDebited{Base: b, Asset: c.asset.balanceAsset(), Amount: value}
+	default:
+		return effects.AccountRemoved{Base: b}
+	}
+}
+
+// transactionFailed returns the error Horizon responds with to a failed transaction submission
+func transactionFailed(txCode string, opCodes ...string) error {
+	return &horizonclient.Error{
+		Problem: problem.P{
+			Type:   "https://stellar.org/horizon-errors/transaction_failed",
+			Title:  "Transaction Failed",
+			Status: http.StatusBadRequest,
+			Detail: "The transaction failed when submitted to the stellar network.",
+			Extras: map[string]interface{}{
+				"result_codes": horizon.TransactionResultCodes{
+					TransactionCode: txCode,
+					OperationCodes:  opCodes,
+				},
+			},
+		},
+	}
+}
+
+// notFound returns the error Horizon responds with to a request for a missing resource
+func notFound() error {
+	return &horizonclient.Error{
+		Problem: problem.P{
+			Type:   "https://stellar.org/horizon-errors/not_found",
+			Title:  "Resource Missing",
+			Status: http.StatusNotFound,
+			Detail: "The resource at the url requested was not found.",
+		},
+	}
+}
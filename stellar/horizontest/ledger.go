@@ -0,0 +1,448 @@
+package horizontest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// BaseFee is the fee charged per operation, in stroops
+	BaseFee = 100
+	// BaseReserve is the balance an account must keep per ledger entry, in stroops
+	BaseReserve = 5000000
+	// maxSigners is the maximum number of additional signers of an account
+	maxSigners = 20
+)
+
+// threshold levels of operations
+const (
+	low = iota
+	medium
+	high
+)
+
+type asset struct {
+	code, issuer string
+}
+
+// assetOf returns the credit asset of a txnbuild asset or trust line
+func assetOf(a interface {
+	GetCode() string
+	GetIssuer() string
+}) asset {
+	return asset{code: a.GetCode(), issuer: a.GetIssuer()}
+}
+
+// balanceAsset is the Horizon representation of the asset, native if code is empty
+func (a asset) balanceAsset() base.Asset {
+	switch {
+	case a.code == "":
+		return base.Asset{Type: "native"}
+	case len(a.code) <= 4:
+		return base.Asset{Type: "credit_alphanum4", Code: a.code, Issuer: a.issuer}
+	default:
+		return base.Asset{Type: "credit_alphanum12", Code: a.code, Issuer: a.issuer}
+	}
+}
+
+type trustline struct {
+	balance, limit int64
+}
+
+type account struct {
+	id           string
+	sequence     int64
+	balance      int64
+	masterWeight uint8
+	// low, medium and high thresholds
+	thresholds [3]uint8
+	// signers besides the master key
+	signers    []horizon.Signer
+	trustlines map[asset]*trustline
+}
+
+func newAccount(id string, balance int64, ledger int32) *account {
+	return &account{
+		id:           id,
+		sequence:     int64(ledger) << 32,
+		balance:      balance,
+		masterWeight: 1,
+		trustlines:   make(map[asset]*trustline),
+	}
+}
+
+func (a *account) copy() *account {
+	cpy := *a
+	cpy.signers = append([]horizon.Signer(nil), a.signers...)
+	cpy.trustlines = make(map[asset]*trustline, len(a.trustlines))
+	for k, tl := range a.trustlines {
+		tlCopy := *tl
+		cpy.trustlines[k] = &tlCopy
+	}
+	return &cpy
+}
+
+func (a *account) subentries() int {
+	return len(a.signers) + len(a.trustlines)
+}
+
+// reserve is the minimum native balance of the account
+func (a *account) reserve() int64 {
+	return int64(2+a.subentries()) * BaseReserve
+}
+
+// allSigners returns the signers including the master key
+func (a *account) allSigners() []horizon.Signer {
+	return append(append([]horizon.Signer(nil), a.signers...), horizon.Signer{
+		Weight: int32(a.masterWeight),
+		Key:    a.id,
+		Type:   horizon.KeyTypeNames[strkey.VersionByteAccountID],
+	})
+}
+
+func (a *account) removeSigner(key string) {
+	signers := a.signers[:0]
+	for _, s := range a.signers {
+		if s.Key != key {
+			signers = append(signers, s)
+		}
+	}
+	a.signers = signers
+}
+
+// horizonAccount renders the account as returned by Horizon
+func (a *account) horizonAccount() horizon.Account {
+	balances := make([]horizon.Balance, 0, len(a.trustlines)+1)
+	for k, tl := range a.trustlines {
+		balances = append(balances, horizon.Balance{
+			Balance: amount.StringFromInt64(tl.balance),
+			Limit:   amount.StringFromInt64(tl.limit),
+			Asset:   k.balanceAsset(),
+		})
+	}
+	// Horizon lists the native balance last
+	balances = append(balances, horizon.Balance{
+		Balance: amount.StringFromInt64(a.balance),
+		Asset:   asset{}.balanceAsset(),
+	})
+	return horizon.Account{
+		ID:            a.id,
+		AccountID:     a.id,
+		Sequence:      a.sequence,
+		SubentryCount: int32(a.subentries()),
+		Thresholds: horizon.AccountThresholds{
+			LowThreshold:  a.thresholds[low],
+			MedThreshold:  a.thresholds[medium],
+			HighThreshold: a.thresholds[high],
+		},
+		Balances: balances,
+		Signers:  a.allSigners(),
+	}
+}
+
+// ledger holds the accounts by address
+type ledger map[string]*account
+
+func (l ledger) copy() ledger {
+	cpy := make(ledger, len(l))
+	for id, a := range l {
+		cpy[id] = a.copy()
+	}
+	return cpy
+}
+
+// change to an account, recorded as an effect
+type change struct {
+	kind    effects.EffectType
+	account string
+	asset   asset
+	amount  int64
+}
+
+// signatureChecker checks the signatures of a transaction against the signers of accounts,
+// recording which signatures are used
+type signatureChecker struct {
+	hash       [32]byte
+	signatures []xdr.DecoratedSignature
+	used       []bool
+}
+
+func newSignatureChecker(hash [32]byte, signatures []xdr.DecoratedSignature) *signatureChecker {
+	return &signatureChecker{
+		hash:       hash,
+		signatures: signatures,
+		used:       make([]bool, len(signatures)),
+	}
+}
+
+// check if the signatures meet the threshold of the given level for the account
+func (c *signatureChecker) check(a *account, level int) bool {
+	weight := 0
+	for _, s := range a.allSigners() {
+		if s.Weight > 0 && c.signed(s) {
+			weight += int(s.Weight)
+		}
+	}
+	return weight > 0 && weight >= int(a.thresholds[level])
+}
+
+func (c *signatureChecker) signed(s horizon.Signer) bool {
+	switch s.Type {
+	case horizon.KeyTypeNames[strkey.VersionByteHashTx]:
+		hash, err := strkey.Decode(strkey.VersionByteHashTx, s.Key)
+		return err == nil && bytes.Equal(hash, c.hash[:])
+	case horizon.KeyTypeNames[strkey.VersionByteHashX]:
+		hash, err := strkey.Decode(strkey.VersionByteHashX, s.Key)
+		if err != nil {
+			return false
+		}
+		for i, sig := range c.signatures {
+			if h := sha256.Sum256(sig.Signature); bytes.Equal(h[:], hash) {
+				c.used[i] = true
+				return true
+			}
+		}
+	case horizon.KeyTypeNames[strkey.VersionByteAccountID]:
+		kp, err := keypair.ParseAddress(s.Key)
+		if err != nil {
+			return false
+		}
+		hint := xdr.SignatureHint(kp.Hint())
+		for i, sig := range c.signatures {
+			if sig.Hint == hint && kp.Verify(c.hash[:], sig.Signature) == nil {
+				c.used[i] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unused reports if some signatures were not needed by any signer
+func (c *signatureChecker) unused() bool {
+	for _, used := range c.used {
+		if !used {
+			return true
+		}
+	}
+	return false
+}
+
+// thresholdLevel returns the threshold level needed by the operation
+func thresholdLevel(op txnbuild.Operation) int {
+	switch op := op.(type) {
+	case *txnbuild.AccountMerge:
+		return high
+	case *txnbuild.SetOptions:
+		if op.Signer != nil || op.MasterWeight != nil || op.LowThreshold != nil || op.MediumThreshold != nil || op.HighThreshold != nil {
+			return high
+		}
+	}
+	return medium
+}
+
+// apply the operation of the source account to the ledger,
+// returning its result code and the changes to record as effects
+func (l ledger) apply(op txnbuild.Operation, source string, ledgerSeq int32) (string, []change) {
+	switch op := op.(type) {
+	case *txnbuild.CreateAccount:
+		return l.createAccount(op, source, ledgerSeq)
+	case *txnbuild.Payment:
+		return l.payment(op, source)
+	case *txnbuild.ChangeTrust:
+		return l.changeTrust(op, source)
+	case *txnbuild.SetOptions:
+		return l.setOptions(op, source)
+	case *txnbuild.AccountMerge:
+		return l.accountMerge(op, source)
+	default:
+		return "op_not_supported", nil
+	}
+}
+
+func (l ledger) createAccount(op *txnbuild.CreateAccount, source string, ledgerSeq int32) (string, []change) {
+	startingBalance, err := amount.ParseInt64(op.Amount)
+	if err != nil || startingBalance <= 0 {
+		return "op_malformed", nil
+	}
+	if _, ok := l[op.Destination]; ok {
+		return "op_already_exists", nil
+	}
+	if startingBalance < 2*BaseReserve {
+		return "op_low_reserve", nil
+	}
+	src := l[source]
+	if src.balance-startingBalance < src.reserve() {
+		return "op_underfunded", nil
+	}
+	src.balance -= startingBalance
+	l[op.Destination] = newAccount(op.Destination, startingBalance, ledgerSeq)
+	return "op_success", []change{
+		{kind: effects.EffectAccountCreated, account: op.Destination, amount: startingBalance},
+		{kind: effects.EffectAccountDebited, account: source, amount: startingBalance},
+	}
+}
+
+func (l ledger) payment(op *txnbuild.Payment, source string) (string, []change) {
+	value, err := amount.ParseInt64(op.Amount)
+	if err != nil || value <= 0 || op.Asset == nil {
+		return "op_malformed", nil
+	}
+	src := l[source]
+	dst, ok := l[op.Destination]
+	if !ok {
+		return "op_no_destination", nil
+	}
+	if op.Asset.IsNative() {
+		if src.balance-value < src.reserve() {
+			return "op_underfunded", nil
+		}
+		src.balance -= value
+		dst.balance += value
+		return "op_success", []change{
+			{kind: effects.EffectAccountCredited, account: dst.id, amount: value},
+			{kind: effects.EffectAccountDebited, account: src.id, amount: value},
+		}
+	}
+	a := assetOf(op.Asset)
+	// the issuer does not hold its own asset but issues and burns it
+	var srcLine, dstLine *trustline
+	if src.id != a.issuer {
+		if srcLine = src.trustlines[a]; srcLine == nil {
+			return "op_src_no_trust", nil
+		}
+		if srcLine.balance < value {
+			return "op_underfunded", nil
+		}
+	}
+	if dst.id != a.issuer {
+		if dstLine = dst.trustlines[a]; dstLine == nil {
+			return "op_no_trust", nil
+		}
+		if dstLine.balance+value > dstLine.limit {
+			return "op_line_full", nil
+		}
+	}
+	if srcLine != nil {
+		srcLine.balance -= value
+	}
+	if dstLine != nil {
+		dstLine.balance += value
+	}
+	return "op_success", []change{
+		{kind: effects.EffectAccountCredited, account: dst.id, asset: a, amount: value},
+		{kind: effects.EffectAccountDebited, account: src.id, asset: a, amount: value},
+	}
+}
+
+func (l ledger) changeTrust(op *txnbuild.ChangeTrust, source string) (string, []change) {
+	if op.Line == nil || op.Line.IsNative() {
+		return "op_malformed", nil
+	}
+	a := assetOf(op.Line)
+	if a.issuer == source {
+		return "op_self_not_allowed", nil
+	}
+	if _, ok := l[a.issuer]; !ok {
+		return "op_no_issuer", nil
+	}
+	limit := int64(math.MaxInt64)
+	if op.Limit != "" {
+		var err error
+		if limit, err = amount.ParseInt64(op.Limit); err != nil || limit < 0 {
+			return "op_malformed", nil
+		}
+	}
+	src := l[source]
+	tl := src.trustlines[a]
+	switch {
+	case limit == 0:
+		if tl == nil || tl.balance > 0 {
+			return "op_invalid_limit", nil
+		}
+		delete(src.trustlines, a)
+	case tl == nil:
+		if src.balance < src.reserve()+BaseReserve {
+			return "op_low_reserve", nil
+		}
+		src.trustlines[a] = &trustline{limit: limit}
+	default:
+		if limit < tl.balance {
+			return "op_invalid_limit", nil
+		}
+		tl.limit = limit
+	}
+	return "op_success", nil
+}
+
+func (l ledger) setOptions(op *txnbuild.SetOptions, source string) (string, []change) {
+	src := l[source]
+	if op.MasterWeight != nil {
+		src.masterWeight = uint8(*op.MasterWeight)
+	}
+	for level, threshold := range []*txnbuild.Threshold{op.LowThreshold, op.MediumThreshold, op.HighThreshold} {
+		if threshold != nil {
+			src.thresholds[level] = uint8(*threshold)
+		}
+	}
+	if op.Signer == nil {
+		return "op_success", nil
+	}
+	version, err := strkey.Version(op.Signer.Address)
+	if err != nil || op.Signer.Address == source {
+		return "op_bad_signer", nil
+	}
+	if op.Signer.Weight == 0 {
+		src.removeSigner(op.Signer.Address)
+		return "op_success", nil
+	}
+	for i := range src.signers {
+		if src.signers[i].Key == op.Signer.Address {
+			src.signers[i].Weight = int32(op.Signer.Weight)
+			return "op_success", nil
+		}
+	}
+	if len(src.signers) >= maxSigners {
+		return "op_too_many_signers", nil
+	}
+	if src.balance < src.reserve()+BaseReserve {
+		return "op_low_reserve", nil
+	}
+	src.signers = append(src.signers, horizon.Signer{
+		Weight: int32(op.Signer.Weight),
+		Key:    op.Signer.Address,
+		Type:   horizon.KeyTypeNames[version],
+	})
+	return "op_success", nil
+}
+
+func (l ledger) accountMerge(op *txnbuild.AccountMerge, source string) (string, []change) {
+	src := l[source]
+	dst, ok := l[op.Destination]
+	if op.Destination == source {
+		return "op_malformed", nil
+	}
+	if !ok {
+		return "op_no_account", nil
+	}
+	if len(src.trustlines) > 0 {
+		return "op_has_sub_entries", nil
+	}
+	dst.balance += src.balance
+	delete(l, source)
+	return "op_success", []change{
+		{kind: effects.EffectAccountDebited, account: source, amount: src.balance},
+		{kind: effects.EffectAccountCredited, account: dst.id, amount: src.balance},
+		{kind: effects.EffectAccountRemoved, account: source},
+	}
+}
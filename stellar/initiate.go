@@ -1,6 +1,7 @@
 package stellar
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/txnbuild"
+	"github.com/threefoldtech/atomicswap/chain"
 	"github.com/threefoldtech/atomicswap/timings"
 )
 
@@ -34,28 +36,42 @@ const (
 	secretSize = 32
 )
 
-func Initiate(network string, initiatorKeyPair *keypair.Full, destination string, amount string, asset txnbuild.Asset, client horizonclient.ClientInterface) (InitiateOutput, error) {
-	if _, err := keypair.ParseAddress(destination); err != nil {
-		return InitiateOutput{}, errors.Wrap(err, "could not decode destination address")
-	}
-
+// Initiate an atomic swap by creating a holding account
+// which can be redeemed by the destination using the secret,
+// and refunded after the initiator lock time of the policy.
+// Use InitiateWithHoldingAccount to recover the funds if an error occurs
+// after the holding account has been created.
+func Initiate(network string, initiatorKeyPair *keypair.Full, destination string, amount string, policy timings.Policy, asset txnbuild.Asset, client horizonclient.ClientInterface) (InitiateOutput, error) {
 	var secret [secretSize]byte
 	_, err := rand.Read(secret[:])
 	if err != nil {
 		return InitiateOutput{}, err
 	}
-	secretHash := sha256Hash(secret[:])
-	fundingAccountAddress := initiatorKeyPair.Address()
 	holdingAccountKeyPair, err := GenerateKeyPair()
 	if err != nil {
 		return InitiateOutput{}, errors.Wrap(err, "failed to create holding account keypair")
 	}
+	return InitiateWithHoldingAccount(context.Background(), network, initiatorKeyPair, holdingAccountKeyPair, destination, amount, secret, policy, asset, client)
+}
+
+// InitiateWithHoldingAccount is Initiate using a secret and holding account keypair
+// generated by the caller, so they can be stored before the holding account is created.
+// The contract, with its refund transaction, is passed to the chain.Recorder of ctx
+// before the holding account is locked, see lockAtomicSwapHoldingAccount.
+func InitiateWithHoldingAccount(ctx context.Context, network string, initiatorKeyPair *keypair.Full, holdingAccountKeyPair *keypair.Full, destination string, amount string, secret [secretSize]byte, policy timings.Policy, asset txnbuild.Asset, client horizonclient.ClientInterface) (InitiateOutput, error) {
+	if err := policy.Validate(); err != nil {
+		return InitiateOutput{}, err
+	}
+	if _, err := keypair.ParseAddress(destination); err != nil {
+		return InitiateOutput{}, errors.Wrap(err, "could not decode destination address")
+	}
+
+	secretHash := sha256Hash(secret[:])
+	fundingAccountAddress := initiatorKeyPair.Address()
 	holdingAccountAddress := holdingAccountKeyPair.Address()
-	//TODO: print the holding account private key in case of an error further down this function
-	//to recover the funds
 
-	locktime := time.Now().Add(timings.LockTime)
-	refundTransaction, err := createAtomicSwapHoldingAccount(network, initiatorKeyPair, holdingAccountKeyPair, destination, amount, secretHash, locktime, asset, client)
+	locktime := time.Now().Add(policy.Initiator)
+	refundTransaction, err := createAtomicSwapHoldingAccount(network, initiatorKeyPair, holdingAccountKeyPair, amount, locktime, asset, client)
 	if err != nil {
 		return InitiateOutput{}, err
 	}
@@ -64,6 +80,13 @@ func Initiate(network string, initiatorKeyPair *keypair.Full, destination string
 	if err != nil {
 		return InitiateOutput{}, err
 	}
+	if err = recordHoldingAccount(ctx, holdingAccountAddress, secretHash, serializedRefundTx); err != nil {
+		return InitiateOutput{}, err
+	}
+	err = lockAtomicSwapHoldingAccount(network, holdingAccountKeyPair, destination, secretHash, refundTransaction, client)
+	if err != nil {
+		return InitiateOutput{}, err
+	}
 
 	output := InitiateOutput{
 		Secret:                secret,
@@ -80,7 +103,10 @@ func sha256Hash(x []byte) []byte {
 	return h[:]
 }
 
-func createAtomicSwapHoldingAccount(network string, fundingKeyPair *keypair.Full, holdingAccountKeyPair *keypair.Full, counterPartyAddress string, amount string, secretHash []byte, locktime time.Time, asset txnbuild.Asset, client horizonclient.ClientInterface) (refundTransaction *txnbuild.Transaction, err error) {
+// createAtomicSwapHoldingAccount creates and funds the holding account,
+// and creates the transaction refunding it after the lock time.
+// The holding account is still controlled by its own key, until it is locked.
+func createAtomicSwapHoldingAccount(network string, fundingKeyPair *keypair.Full, holdingAccountKeyPair *keypair.Full, amount string, locktime time.Time, asset txnbuild.Asset, client horizonclient.ClientInterface) (refundTransaction *txnbuild.Transaction, err error) {
 	holdingAccountAddress := holdingAccountKeyPair.Address()
 
 	xlmAmount := "10"
@@ -104,16 +130,26 @@ func createAtomicSwapHoldingAccount(network string, fundingKeyPair *keypair.Full
 	refundTransaction, err = createRefundTransaction(holdingAccountAddress, fundingKeyPair.Address(), locktime, client)
 	if err != nil {
 		err = errors.Wrap(err, "could not create refund transaction")
-		return
 	}
+	return
+}
+
+// recordHoldingAccount records the contract of the holding account,
+// as the refund transaction is the only way to recover the funds once it is locked.
+func recordHoldingAccount(ctx context.Context, holdingAccountAddress string, secretHash []byte, refundTransaction string) error {
+	contract := chain.Contract{Address: holdingAccountAddress, RefundTransaction: refundTransaction}
+	copy(contract.SecretHash[:], secretHash)
+	return chain.RecordContract(ctx, contract)
+}
+
+// lockAtomicSwapHoldingAccount removes the key of the holding account as its signer,
+// leaving the counterparty with the secret and the refund transaction as its only signers.
+func lockAtomicSwapHoldingAccount(network string, holdingAccountKeyPair *keypair.Full, counterPartyAddress string, secretHash []byte, refundTransaction *txnbuild.Transaction, client horizonclient.ClientInterface) error {
 	refundTransactionHash, err := refundTransaction.Hash(network)
 	if err != nil {
-		err = fmt.Errorf("Failed to Hash the refund transaction: %s", err)
-		return
+		return fmt.Errorf("Failed to Hash the refund transaction: %s", err)
 	}
-	err = setHoldingAccountSigningOptions(holdingAccountKeyPair, counterPartyAddress, secretHash, refundTransactionHash[:], network, client)
-
-	return
+	return setHoldingAccountSigningOptions(holdingAccountKeyPair, counterPartyAddress, secretHash, refundTransactionHash[:], network, client)
 }
 
 // createHoldingAccount creates a new account to hold the atomic swap balance
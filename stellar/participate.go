@@ -1,6 +1,7 @@
 package stellar
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -19,19 +20,31 @@ type (
 	}
 )
 
-// Participate as the second party in an atomic swap
-func Participate(network string, participatorKeyPair *keypair.Full, cp1Addr string, amount string, secretHash []byte, asset txnbuild.Asset, client horizonclient.ClientInterface) (ParticipateOutput, error) {
-	fundingAccountAddress := participatorKeyPair.Address()
+// Participate as the second party in an atomic swap,
+// the holding account can be refunded after the participant lock time of the policy.
+// Use ParticipateWithHoldingAccount to recover the funds if an error occurs
+// after the holding account has been created.
+func Participate(network string, participatorKeyPair *keypair.Full, cp1Addr string, amount string, secretHash []byte, policy timings.Policy, asset txnbuild.Asset, client horizonclient.ClientInterface) (ParticipateOutput, error) {
 	holdingAccountKeyPair, err := GenerateKeyPair()
 	if err != nil {
 		return ParticipateOutput{}, fmt.Errorf("Failed to create holding account keypair: %s", err)
 	}
+	return ParticipateWithHoldingAccount(context.Background(), network, participatorKeyPair, holdingAccountKeyPair, cp1Addr, amount, secretHash, policy, asset, client)
+}
+
+// ParticipateWithHoldingAccount is Participate using a holding account keypair
+// generated by the caller, so it can be stored before the holding account is created.
+// The contract, with its refund transaction, is passed to the chain.Recorder of ctx
+// before the holding account is locked, see lockAtomicSwapHoldingAccount.
+func ParticipateWithHoldingAccount(ctx context.Context, network string, participatorKeyPair *keypair.Full, holdingAccountKeyPair *keypair.Full, cp1Addr string, amount string, secretHash []byte, policy timings.Policy, asset txnbuild.Asset, client horizonclient.ClientInterface) (ParticipateOutput, error) {
+	if err := policy.Validate(); err != nil {
+		return ParticipateOutput{}, err
+	}
+	fundingAccountAddress := participatorKeyPair.Address()
 	holdingAccountAddress := holdingAccountKeyPair.Address()
-	//TODO: print the holding account private key in case of an error further down this function
-	//to recover the funds
 
-	locktime := time.Now().Add(timings.LockTime / 2)
-	refundTransaction, err := createAtomicSwapHoldingAccount(network, participatorKeyPair, holdingAccountKeyPair, cp1Addr, amount, secretHash, locktime, asset, client)
+	locktime := time.Now().Add(policy.Participant)
+	refundTransaction, err := createAtomicSwapHoldingAccount(network, participatorKeyPair, holdingAccountKeyPair, amount, locktime, asset, client)
 	if err != nil {
 		return ParticipateOutput{}, errors.Wrap(err, "could not create holding account")
 	}
@@ -40,6 +53,13 @@ func Participate(network string, participatorKeyPair *keypair.Full, cp1Addr stri
 	if err != nil {
 		return ParticipateOutput{}, errors.Wrap(err, "can't encode refund transaction")
 	}
+	if err = recordHoldingAccount(ctx, holdingAccountAddress, secretHash, serializedRefundTx); err != nil {
+		return ParticipateOutput{}, err
+	}
+	err = lockAtomicSwapHoldingAccount(network, holdingAccountKeyPair, cp1Addr, secretHash, refundTransaction, client)
+	if err != nil {
+		return ParticipateOutput{}, errors.Wrap(err, "could not lock holding account")
+	}
 
 	output := ParticipateOutput{
 		InitiatorAddress:      fundingAccountAddress,
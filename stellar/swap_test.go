@@ -0,0 +1,209 @@
+package stellar
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/stellar/horizontest"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+const testNetwork = network.TestNetworkPassphrase
+
+func TestSwap(t *testing.T) {
+	h := horizontest.NewHorizon(testNetwork)
+	alice, bob := h.NewAccount("1000"), h.NewAccount("1000")
+	native := txnbuild.NativeAsset{}
+	policy := timings.DefaultPolicy
+
+	// alice initiates, bob audits the holding account
+	initiation, err := Initiate(testNetwork, alice, bob.Address(), "100", policy, native, h)
+	require.NoError(t, err)
+	initiationRefundTx, err := decodeRefundTransaction(initiation.RefundTransaction)
+	require.NoError(t, err)
+	audit, err := AuditContract(testNetwork, *initiationRefundTx, initiation.HoldingAccountAddress, native, h)
+	require.NoError(t, err)
+	// the holding account paid for setting its signers
+	assert.Equal(t, "99.9999600", audit.ContractValue)
+	assert.Equal(t, bob.Address(), audit.RecipientAddress)
+	assert.Equal(t, hex.EncodeToString(initiation.SecretHash), audit.SecretHash)
+	assert.InDelta(t, h.Now().Add(policy.Initiator).Unix(), audit.Locktime, 5)
+
+	// bob participates, alice audits the holding account
+	participation, err := Participate(testNetwork, bob, alice.Address(), "50", initiation.SecretHash, policy, native, h)
+	require.NoError(t, err)
+	participationRefundTx, err := decodeRefundTransaction(participation.RefundTransaction)
+	require.NoError(t, err)
+	audit, err = AuditContract(testNetwork, *participationRefundTx, participation.HoldingAccountAddress, native, h)
+	require.NoError(t, err)
+	assert.Equal(t, "49.9999600", audit.ContractValue)
+	assert.Equal(t, alice.Address(), audit.RecipientAddress)
+	assert.Equal(t, hex.EncodeToString(initiation.SecretHash), audit.SecretHash)
+
+	// the audit of a holding account rejects the refund transaction of another one
+	_, err = AuditContract(testNetwork, *initiationRefundTx, participation.HoldingAccountAddress, native, h)
+	assert.Error(t, err)
+
+	_, err = ExtractSecret(testNetwork, participation.HoldingAccountAddress, hex.EncodeToString(initiation.SecretHash), h)
+	assert.Equal(t, ErrNotRedeemed, err)
+
+	// only alice can redeem, and only with the secret
+	_, err = Redeem(testNetwork, bob, participation.HoldingAccountAddress, initiation.Secret[:], h)
+	assert.Error(t, err)
+	wrongSecret := initiation.Secret
+	wrongSecret[0] ^= 1
+	_, err = Redeem(testNetwork, alice, participation.HoldingAccountAddress, wrongSecret[:], h)
+	assert.Error(t, err)
+	_, err = Redeem(testNetwork, alice, participation.HoldingAccountAddress, initiation.Secret[:], h)
+	require.NoError(t, err)
+
+	// bob extracts the secret from alice's redemption and redeems
+	secret, err := ExtractSecret(testNetwork, participation.HoldingAccountAddress, hex.EncodeToString(initiation.SecretHash), h)
+	require.NoError(t, err)
+	assert.Equal(t, initiation.Secret[:], secret)
+	_, err = Redeem(testNetwork, bob, initiation.HoldingAccountAddress, secret, h)
+	require.NoError(t, err)
+
+	// the holding accounts are merged into the accounts of the recipients
+	_, err = GetAccount(initiation.HoldingAccountAddress, h)
+	assert.Error(t, err)
+	_, err = GetAccount(participation.HoldingAccountAddress, h)
+	assert.Error(t, err)
+	assert.Equal(t, "949.9999400", h.Balance(alice.Address(), native))
+	assert.Equal(t, "1049.9999400", h.Balance(bob.Address(), native))
+
+	// the refund transactions can no longer be used
+	_, err = Refund(testNetwork, *initiationRefundTx, h)
+	assert.Error(t, err)
+}
+
+func TestSwapCreditAsset(t *testing.T) {
+	h := horizontest.NewHorizon(testNetwork)
+	issuer := h.NewAccount("10")
+	alice, bob := h.NewAccount("1000"), h.NewAccount("1000")
+	tft := txnbuild.CreditAsset{Code: "TFT", Issuer: issuer.Address()}
+	h.Issue(tft, alice.Address(), "1000")
+	h.Issue(tft, bob.Address(), "0")
+	native := txnbuild.NativeAsset{}
+	policy := timings.DefaultPolicy
+
+	initiation, err := Initiate(testNetwork, alice, bob.Address(), "250", policy, tft, h)
+	require.NoError(t, err)
+	refundTx, err := decodeRefundTransaction(initiation.RefundTransaction)
+	require.NoError(t, err)
+	audit, err := AuditContract(testNetwork, *refundTx, initiation.HoldingAccountAddress, tft, h)
+	require.NoError(t, err)
+	assert.Equal(t, "250.0000000", audit.ContractValue)
+	assert.Equal(t, bob.Address(), audit.RecipientAddress)
+	assert.Equal(t, "750.0000000", h.Balance(alice.Address(), tft))
+
+	participation, err := Participate(testNetwork, bob, alice.Address(), "100", initiation.SecretHash, policy, native, h)
+	require.NoError(t, err)
+	_, err = Redeem(testNetwork, alice, participation.HoldingAccountAddress, initiation.Secret[:], h)
+	require.NoError(t, err)
+	secret, err := ExtractSecret(testNetwork, participation.HoldingAccountAddress, hex.EncodeToString(initiation.SecretHash), h)
+	require.NoError(t, err)
+	_, err = Redeem(testNetwork, bob, initiation.HoldingAccountAddress, secret, h)
+	require.NoError(t, err)
+
+	assert.Equal(t, "250.0000000", h.Balance(bob.Address(), tft))
+	_, err = GetAccount(initiation.HoldingAccountAddress, h)
+	assert.Error(t, err)
+}
+
+func TestRefund(t *testing.T) {
+	h := horizontest.NewHorizon(testNetwork)
+	alice, bob := h.NewAccount("1000"), h.NewAccount("1000")
+	native := txnbuild.NativeAsset{}
+	policy := timings.DefaultPolicy
+
+	initiation, err := Initiate(testNetwork, alice, bob.Address(), "100", policy, native, h)
+	require.NoError(t, err)
+	refundTx, err := decodeRefundTransaction(initiation.RefundTransaction)
+	require.NoError(t, err)
+
+	// the refund transaction is only valid after the lock time
+	_, err = Refund(testNetwork, *refundTx, h)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "tx_too_early")
+	}
+	h.AdjustTime(policy.Initiator)
+	_, err = Refund(testNetwork, *refundTx, h)
+	require.NoError(t, err)
+	assert.Equal(t, "999.9999400", h.Balance(alice.Address(), native))
+
+	// the holding account is gone and was not redeemed
+	_, err = Redeem(testNetwork, bob, initiation.HoldingAccountAddress, initiation.Secret[:], h)
+	assert.Error(t, err)
+	_, err = ExtractSecret(testNetwork, initiation.HoldingAccountAddress, hex.EncodeToString(initiation.SecretHash), h)
+	assert.Error(t, err)
+}
+
+// holdingAccountRecorder records the contract of a holding account,
+// along with the signers of the holding account at that time
+type holdingAccountRecorder struct {
+	h        *horizontest.Horizon
+	contract chain.Contract
+	signers  []horizon.Signer
+	err      error
+}
+
+func (r *holdingAccountRecorder) RecordKey(name, key string) error {
+	return nil
+}
+
+func (r *holdingAccountRecorder) RecordContract(contract chain.Contract) error {
+	r.contract = contract
+	account, err := GetAccount(contract.Address, r.h)
+	if err != nil {
+		return err
+	}
+	r.signers = account.Signers
+	return r.err
+}
+
+// masterWeight returns the weight of the key of the account among its signers
+func masterWeight(address string, signers []horizon.Signer) int32 {
+	for _, s := range signers {
+		if s.Key == address {
+			return s.Weight
+		}
+	}
+	return 0
+}
+
+func TestSwapperRecordsRefundTransaction(t *testing.T) {
+	h := horizontest.NewHorizon(testNetwork)
+	alice, bob := h.NewAccount("1000"), h.NewAccount("1000")
+	swapper := NewSwapper(testNetwork, alice, txnbuild.NativeAsset{}, h)
+	secret, err := chain.NewSecret()
+	require.NoError(t, err)
+
+	// the refund transaction is recorded while the holding account can still be recovered with its seed
+	r := &holdingAccountRecorder{h: h}
+	contract, err := swapper.Initiate(chain.WithRecorder(context.Background(), r), bob.Address(), chain.AmountFromInt64(100_0000000), secret, timings.DefaultPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, contract, r.contract)
+	assert.NotZero(t, masterWeight(contract.Address, r.signers))
+	account, err := GetAccount(contract.Address, h)
+	require.NoError(t, err)
+	assert.Zero(t, masterWeight(contract.Address, account.Signers))
+
+	// the holding account is not locked if its refund transaction can not be recorded
+	r = &holdingAccountRecorder{h: h, err: errors.New("disk full")}
+	_, err = swapper.Initiate(chain.WithRecorder(context.Background(), r), bob.Address(), chain.AmountFromInt64(100_0000000), secret, timings.DefaultPolicy)
+	require.Error(t, err)
+	require.NotEmpty(t, r.contract.RefundTransaction)
+	account, err = GetAccount(r.contract.Address, h)
+	require.NoError(t, err)
+	assert.NotZero(t, masterWeight(r.contract.Address, account.Signers))
+}
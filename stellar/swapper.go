@@ -0,0 +1,168 @@
+package stellar
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// Swapper implements chain.Swapper using holding accounts
+type Swapper struct {
+	network string
+	keyPair *keypair.Full
+	asset   txnbuild.Asset
+	client  horizonclient.ClientInterface
+}
+
+var _ chain.Swapper = (*Swapper)(nil)
+
+// NewSwapper creates a chain.Swapper for Stellar,
+// swapping the given asset from and to the account of the keypair.
+func NewSwapper(network string, keyPair *keypair.Full, asset txnbuild.Asset, client horizonclient.ClientInterface) *Swapper {
+	return &Swapper{
+		network: network,
+		keyPair: keyPair,
+		asset:   asset,
+		client:  client,
+	}
+}
+
+// Chain implements chain.Swapper.Chain
+func (s *Swapper) Chain() string {
+	return "xlm"
+}
+
+// Decimals implements chain.Swapper.Decimals
+func (s *Swapper) Decimals() int {
+	return 7
+}
+
+// Address implements chain.Swapper.Address
+func (s *Swapper) Address(ctx context.Context) (string, error) {
+	return s.keyPair.Address(), nil
+}
+
+// Initiate implements chain.Swapper.Initiate.
+// The seed of the holding account is recorded before it is created,
+// the contract with its refund transaction before the holding account is locked.
+func (s *Swapper) Initiate(ctx context.Context, participant string, value chain.Amount, secret chain.Secret, policy timings.Policy) (chain.Contract, error) {
+	if err := policy.Validate(); err != nil {
+		return chain.Contract{}, err
+	}
+	holdingAccountKeyPair, err := s.newHoldingAccount(ctx)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	output, err := InitiateWithHoldingAccount(ctx, s.network, s.keyPair, holdingAccountKeyPair, participant, amount.StringFromInt64(value.Int64()), secret, policy, s.asset, s.client)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	return chain.Contract{
+		SecretHash:        secret.Hash(),
+		Address:           output.HoldingAccountAddress,
+		RefundTransaction: output.RefundTransaction,
+	}, nil
+}
+
+// Participate implements chain.Swapper.Participate.
+// The seed of the holding account is recorded before it is created,
+// the contract with its refund transaction before the holding account is locked.
+func (s *Swapper) Participate(ctx context.Context, initiator string, value chain.Amount, secretHash chain.SecretHash, policy timings.Policy) (chain.Contract, error) {
+	if err := policy.Validate(); err != nil {
+		return chain.Contract{}, err
+	}
+	holdingAccountKeyPair, err := s.newHoldingAccount(ctx)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	output, err := ParticipateWithHoldingAccount(ctx, s.network, s.keyPair, holdingAccountKeyPair, initiator, amount.StringFromInt64(value.Int64()), secretHash[:], policy, s.asset, s.client)
+	if err != nil {
+		return chain.Contract{}, err
+	}
+	return chain.Contract{
+		SecretHash:        secretHash,
+		Address:           output.HoldingAccountAddress,
+		RefundTransaction: output.RefundTransaction,
+	}, nil
+}
+
+func (s *Swapper) newHoldingAccount(ctx context.Context) (*keypair.Full, error) {
+	holdingAccountKeyPair, err := GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create holding account keypair: %v", err)
+	}
+	err = chain.RecordKey(ctx, "holdingaccount:"+holdingAccountKeyPair.Address(), holdingAccountKeyPair.Seed())
+	if err != nil {
+		return nil, err
+	}
+	return holdingAccountKeyPair, nil
+}
+
+// Redeem implements chain.Swapper.Redeem
+func (s *Swapper) Redeem(ctx context.Context, contract chain.Contract, secret chain.Secret) (string, error) {
+	output, err := Redeem(s.network, s.keyPair, contract.Address, secret[:], s.client)
+	if err != nil {
+		return "", err
+	}
+	return output.RedeemTransactionTxHash, nil
+}
+
+// Refund implements chain.Swapper.Refund
+func (s *Swapper) Refund(ctx context.Context, contract chain.Contract) (string, error) {
+	refundTx, err := decodeRefundTransaction(contract.RefundTransaction)
+	if err != nil {
+		return "", err
+	}
+	return Refund(s.network, *refundTx, s.client)
+}
+
+// AuditContract implements chain.Swapper.AuditContract
+func (s *Swapper) AuditContract(ctx context.Context, contract chain.Contract) (chain.AuditResult, error) {
+	refundTx, err := decodeRefundTransaction(contract.RefundTransaction)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	output, err := AuditContract(s.network, *refundTx, contract.Address, s.asset, s.client)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	return output.AuditResult(s.asset)
+}
+
+// ExtractSecret implements chain.Swapper.ExtractSecret,
+// the redemption transaction is looked up using the holding account.
+func (s *Swapper) ExtractSecret(ctx context.Context, contract chain.Contract, redemptionTx string) (chain.Secret, error) {
+	secret, err := ExtractSecret(s.network, contract.Address, hex.EncodeToString(contract.SecretHash[:]), s.client)
+	if err == ErrNotRedeemed {
+		return chain.Secret{}, chain.ErrNotRedeemed
+	}
+	if err != nil {
+		return chain.Secret{}, err
+	}
+	if len(secret) != chain.SecretSize {
+		return chain.Secret{}, fmt.Errorf("extracted secret has wrong size: %d", len(secret))
+	}
+	var output chain.Secret
+	copy(output[:], secret)
+	return output, nil
+}
+
+func decodeRefundTransaction(xdr string) (*txnbuild.Transaction, error) {
+	genericTransaction, err := txnbuild.TransactionFromXDR(xdr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode refund transaction: %v", err)
+	}
+	refundTransaction, ok := genericTransaction.Transaction()
+	if !ok {
+		return nil, errors.New("transaction XDR does not contain an actual transaction")
+	}
+	return refundTransaction, nil
+}
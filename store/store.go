@@ -0,0 +1,194 @@
+// Package store keeps the state of swaps in a directory,
+// one file per swap, encrypted with a key derived from a passphrase.
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/threefoldtech/atomicswap/swap"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltFile  = "salt"
+	checkFile = "check"
+	swapExt   = ".swap"
+
+	saltSize  = 32
+	nonceSize = 24
+	keySize   = 32
+
+	// scrypt parameters recommended for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// checkValue is encrypted in the check file to verify the passphrase
+var checkValue = []byte("atomicswap store")
+
+var (
+	// ErrNotFound is returned when a swap is not in the store
+	ErrNotFound = errors.New("swap not found")
+	// ErrWrongPassphrase is returned when the store can not be decrypted
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted store")
+)
+
+// Store keeps encrypted swap states in a directory.
+// It implements swap.Journal.
+type Store struct {
+	dir string
+	key [keySize]byte
+}
+
+var _ swap.Journal = (*Store)(nil)
+
+// Open opens the store in dir, creating it if it does not exist yet.
+// The passphrase of an existing store is verified.
+func Open(dir string, passphrase []byte) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %v", err)
+	}
+	salt, err := ioutil.ReadFile(filepath.Join(dir, saltFile))
+	if os.IsNotExist(err) {
+		salt = make([]byte, saltSize)
+		if _, err = rand.Read(salt); err != nil {
+			return nil, err
+		}
+		if err = writeFile(filepath.Join(dir, saltFile), salt); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read store salt: %v", err)
+	}
+
+	k, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{dir: dir}
+	copy(s.key[:], k)
+
+	check, err := s.read(checkFile)
+	if os.IsNotExist(err) {
+		return s, s.write(checkFile, checkValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(check, checkValue) {
+		return nil, ErrWrongPassphrase
+	}
+	return s, nil
+}
+
+// Save implements swap.Journal.Save
+func (s *Store) Save(state *swap.State) error {
+	if state.ID == "" || strings.ContainsAny(state.ID, `/\.`) {
+		return fmt.Errorf("invalid swap ID %q", state.ID)
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.write(state.ID+swapExt, b)
+}
+
+// Load returns the state of a swap
+func (s *Store) Load(id string) (*swap.State, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, ErrNotFound
+	}
+	b, err := s.read(id + swapExt)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := new(swap.State)
+	if err = json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("failed to decode swap %s: %v", id, err)
+	}
+	return state, nil
+}
+
+// List returns the states of all swaps, the most recently updated first
+func (s *Store) List() ([]*swap.State, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+swapExt))
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*swap.State, 0, len(files))
+	for _, file := range files {
+		state, err := s.Load(strings.TrimSuffix(filepath.Base(file), swapExt))
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].UpdatedAt.After(states[j].UpdatedAt)
+	})
+	return states, nil
+}
+
+// write encrypts and writes a file of the store
+func (s *Store) write(name string, plaintext []byte) error {
+	var nonce [nonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+	box := secretbox.Seal(nonce[:], plaintext, &nonce, &s.key)
+	return writeFile(filepath.Join(s.dir, name), box)
+}
+
+// read reads and decrypts a file of the store
+func (s *Store) read(name string) ([]byte, error) {
+	box, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	if len(box) < nonceSize {
+		return nil, ErrWrongPassphrase
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], box)
+	plaintext, ok := secretbox.Open(nil, box[nonceSize:], &nonce, &s.key)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// writeFile atomically replaces a file,
+// so a crash never leaves a partially written state behind
+func writeFile(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
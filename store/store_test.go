@@ -0,0 +1,62 @@
+package store
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/swap"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, []byte("passphrase"))
+	require.NoError(t, err)
+
+	secret, err := chain.NewSecret()
+	require.NoError(t, err)
+	state := &swap.State{
+		ID: "0123456789abcdef",
+		Spec: swap.Spec{
+			Role:                swap.RoleInitiator,
+			MyChain:             "btc",
+			TheirChain:          "xlm",
+			MyAmount:            chain.AmountFromInt64(1000),
+			TheirAmount:         chain.AmountFromInt64(50000000),
+			CounterpartyAddress: "mzuVmEBjqmDpYSB3wiRpLcc9EeSDSUbfoG",
+		},
+		Step:       swap.StepLocking,
+		Secret:     &secret,
+		SecretHash: secret.Hash(),
+		Keys:       map[string]string{"holdingaccount": "SBXXX"},
+	}
+	require.NoError(t, s.Save(state))
+
+	// nothing is stored in plain text
+	b, err := ioutil.ReadFile(filepath.Join(dir, state.ID+swapExt))
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), secret.String())
+	assert.NotContains(t, string(b), "SBXXX")
+
+	s, err = Open(dir, []byte("passphrase"))
+	require.NoError(t, err)
+	loaded, err := s.Load(state.ID)
+	require.NoError(t, err)
+	assert.Equal(t, state.Spec, loaded.Spec)
+	assert.Equal(t, state.Step, loaded.Step)
+	assert.Equal(t, state.Secret, loaded.Secret)
+	assert.Equal(t, state.Keys, loaded.Keys)
+
+	states, err := s.List()
+	require.NoError(t, err)
+	assert.Len(t, states, 1)
+
+	_, err = s.Load("fedcba9876543210")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = Open(dir, []byte("wrong"))
+	assert.Equal(t, ErrWrongPassphrase, err)
+}
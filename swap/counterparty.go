@@ -0,0 +1,63 @@
+package swap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/atomicswap/chain"
+)
+
+// StreamCounterparty exchanges contracts as JSON lines,
+// e.g. on the standard input and output of an interactive command,
+// leaving it up to the user to pass them along to the counterparty.
+type StreamCounterparty struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+var _ Counterparty = (*StreamCounterparty)(nil)
+
+// NewStreamCounterparty creates a Counterparty reading the
+// counterparty's contract from r and writing ours to w.
+func NewStreamCounterparty(r io.Reader, w io.Writer) *StreamCounterparty {
+	return &StreamCounterparty{
+		r: bufio.NewReader(r),
+		w: w,
+	}
+}
+
+// SendContract implements Counterparty.SendContract
+func (c *StreamCounterparty) SendContract(ctx context.Context, contract chain.Contract) error {
+	b, err := json.Marshal(contract)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.w, "Send this contract to the counterparty:\n%s\n", b)
+	return err
+}
+
+// ReceiveContract implements Counterparty.ReceiveContract.
+// The read is not interrupted when ctx is done.
+func (c *StreamCounterparty) ReceiveContract(ctx context.Context) (chain.Contract, error) {
+	for {
+		if _, err := fmt.Fprintln(c.w, "Paste the contract of the counterparty:"); err != nil {
+			return chain.Contract{}, err
+		}
+		line, err := c.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return chain.Contract{}, err
+		}
+		if err = ctx.Err(); err != nil {
+			return chain.Contract{}, err
+		}
+		var contract chain.Contract
+		if err = json.Unmarshal(line, &contract); err != nil {
+			fmt.Fprintf(c.w, "invalid contract: %v\n", err)
+			continue
+		}
+		return contract, nil
+	}
+}
@@ -0,0 +1,104 @@
+package swap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/threefoldtech/atomicswap/chain"
+)
+
+// Step is the last persisted step of a swap
+type Step string
+
+const (
+	// StepNew is a swap for which nothing has been published yet
+	StepNew Step = "new"
+	// StepLocking is a swap for which our contract is being created,
+	// it may or may not have been published.
+	StepLocking Step = "locking"
+	// StepLocked is a swap for which our contract is published
+	StepLocked Step = "locked"
+	// StepAudited is a swap for which the counterparty's contract has been audited
+	StepAudited Step = "audited"
+	// StepRefunding is an aborted swap waiting for our contract to expire
+	StepRefunding Step = "refunding"
+	// StepRedeemed is a completed swap
+	StepRedeemed Step = "redeemed"
+	// StepRefunded is an aborted swap for which our contract has been refunded
+	StepRefunded Step = "refunded"
+	// StepAborted is a swap aborted before we locked any funds
+	StepAborted Step = "aborted"
+)
+
+// Done returns true if nothing is left to do for the swap
+func (s Step) Done() bool {
+	return s == StepRedeemed || s == StepRefunded || s == StepAborted
+}
+
+// State is the state of a swap, as persisted by a Journal.
+// It holds the secret and keys of the swap, so it has to be kept private.
+type State struct {
+	ID   string `json:"id"`
+	Spec Spec   `json:"spec"`
+	Step Step   `json:"step"`
+	// Secret is set by the initiator when the swap is created,
+	// and by the participant once it is extracted.
+	Secret     *chain.Secret    `json:"secret,omitempty"`
+	SecretHash chain.SecretHash `json:"secretHash"`
+	// Keys are the chain specific keys recorded while creating our contract.
+	Keys       map[string]string `json:"keys,omitempty"`
+	MyContract *chain.Contract   `json:"myContract,omitempty"`
+	// MyLockTime is the lock time of our contract, once it is audited.
+	MyLockTime    chain.LockTime  `json:"myLockTime,omitempty"`
+	TheirContract *chain.Contract `json:"theirContract,omitempty"`
+	// TheirLockTime is the lock time of the counterparty's contract, once it is audited.
+	TheirLockTime chain.LockTime `json:"theirLockTime,omitempty"`
+	// RedeemAttempts counts the failed attempts to redeem the counterparty's contract,
+	// RedeemError is the error of the last one.
+	RedeemAttempts int    `json:"redeemAttempts,omitempty"`
+	RedeemError    string `json:"redeemError,omitempty"`
+	// RedeemTransaction is the ID of the transaction redeeming the counterparty's contract.
+	RedeemTransaction string `json:"redeemTransaction,omitempty"`
+	// RefundTransaction is the ID of the transaction refunding our contract,
+	// if the swap had to be aborted.
+	RefundTransaction string `json:"refundTransaction,omitempty"`
+	// Error is the reason the swap was aborted.
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Journal persists the state of swaps
+type Journal interface {
+	// Save persists the state, overwriting the previous state with the same ID.
+	Save(state *State) error
+}
+
+// NewID generates a random swap ID
+func NewID() (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// recorder records the keys and contract of a swapper in the state of the swap
+type recorder struct {
+	s *Swap
+}
+
+// RecordKey implements chain.Recorder.RecordKey
+func (r recorder) RecordKey(name, key string) error {
+	if r.s.State.Keys == nil {
+		r.s.State.Keys = make(map[string]string)
+	}
+	r.s.State.Keys[name] = key
+	return r.s.save()
+}
+
+// RecordContract implements chain.Recorder.RecordContract
+func (r recorder) RecordContract(contract chain.Contract) error {
+	r.s.State.MyContract = &contract
+	return r.s.save()
+}
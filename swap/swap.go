@@ -0,0 +1,522 @@
+// Package swap runs both legs of a cross-chain atomic swap,
+// driving the chain.Swapper of our chain and the one of the counterparty's chain:
+//
+//	initiator                          participant
+//	initiate (my chain)         --->
+//	                                   audit the initiator's contract
+//	                            <---   participate (their chain)
+//	audit the participant's contract
+//	redeem (their chain), revealing the secret
+//	                                   extract the secret
+//	                                   redeem (my chain)
+//
+// If the counterparty does not act before our contract expires,
+// our contract is refunded. Once the initiator has the participant's contract,
+// it keeps trying to redeem it until it expires, as the participant can
+// redeem ours as soon as the secret is revealed.
+//
+// The state of a swap is persisted by a Journal before every step,
+// so an interrupted swap can be resumed.
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// DefaultPollInterval is the interval between audits and secret lookups
+// when none is configured.
+const DefaultPollInterval = 30 * time.Second
+
+// maxRedeemInterval bounds the interval between the attempts to redeem
+// the counterparty's contract, which doubles after every failed attempt.
+const maxRedeemInterval = 10 * time.Minute
+
+// Role is our role in an atomic swap
+type Role string
+
+const (
+	// RoleInitiator creates the secret and the first contract
+	RoleInitiator Role = "initiator"
+	// RoleParticipant locks funds using the initiator's secret hash
+	RoleParticipant Role = "participant"
+)
+
+// ParseRole parses the name of a Role
+func ParseRole(str string) (Role, error) {
+	switch r := Role(str); r {
+	case RoleInitiator, RoleParticipant:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid role %q: must be %q or %q", str, RoleInitiator, RoleParticipant)
+}
+
+// Spec describes our side of an atomic swap.
+//
+// Amounts are expressed in the smallest unit of their chain.
+type Spec struct {
+	Role Role `json:"role"`
+	// MyChain is the chain we lock funds on.
+	MyChain string `json:"myChain"`
+	// TheirChain is the chain the counterparty locks funds on.
+	TheirChain string `json:"theirChain"`
+	// MyAmount is the amount we lock on our chain.
+	MyAmount chain.Amount `json:"myAmount"`
+	// TheirAmount is the minimum amount the counterparty has to lock on their chain.
+	TheirAmount chain.Amount `json:"theirAmount"`
+	// CounterpartyAddress is the address of the counterparty on our chain,
+	// our contract locks the funds to.
+	CounterpartyAddress string `json:"counterpartyAddress"`
+	// RedeemAddress is our address on their chain,
+	// the counterparty's contract has to lock the funds to.
+	// If empty, an address of the swapper of their chain is used.
+	RedeemAddress string `json:"redeemAddress,omitempty"`
+	// LockTimes of the contracts, both parties have to agree on them.
+	// timings.DefaultPolicy is used if empty.
+	LockTimes timings.Policy `json:"lockTimes"`
+}
+
+// Validate checks that the spec is complete
+func (spec *Spec) Validate() error {
+	if _, err := ParseRole(string(spec.Role)); err != nil {
+		return err
+	}
+	if spec.MyChain == "" || spec.TheirChain == "" {
+		return errors.New("both chains of the swap are required")
+	}
+	if spec.MyChain == spec.TheirChain {
+		return fmt.Errorf("can not swap %s for %s", spec.MyChain, spec.TheirChain)
+	}
+	if spec.MyAmount.Sign() <= 0 || spec.TheirAmount.Sign() <= 0 {
+		return errors.New("the amounts of the swap must be positive")
+	}
+	if spec.CounterpartyAddress == "" {
+		return errors.New("the counterparty address is required")
+	}
+	if spec.LockTimes != (timings.Policy{}) {
+		return spec.LockTimes.Validate()
+	}
+	return nil
+}
+
+// Counterparty exchanges contracts with the other party of the swap.
+type Counterparty interface {
+	// SendContract hands our contract to the counterparty.
+	SendContract(ctx context.Context, contract chain.Contract) error
+	// ReceiveContract blocks until the counterparty hands us its contract.
+	ReceiveContract(ctx context.Context) (chain.Contract, error)
+}
+
+// Swap runs our side of an atomic swap
+type Swap struct {
+	Spec Spec
+	// State of a resumed swap, Run creates a new one if nil.
+	// The Spec of the state is used when resuming.
+	State *State
+	// Mine is the swapper of the chain we lock funds on.
+	Mine chain.Swapper
+	// Theirs is the swapper of the chain the counterparty locks funds on.
+	Theirs       chain.Swapper
+	Counterparty Counterparty
+	// Journal persists the state before every step, optional.
+	Journal Journal
+	// PollInterval is the interval between audits and secret lookups,
+	// DefaultPollInterval is used if zero.
+	PollInterval time.Duration
+	// Logf reports the progress of the swap, optional.
+	Logf func(format string, args ...interface{})
+}
+
+// Run performs the atomic swap until both contracts are redeemed,
+// or until our contract is refunded because the counterparty did not act in time.
+// A resumed swap continues from the last step of its state.
+// The state is returned along with the error, as it may hold a contract
+// that still has to be refunded.
+func (s *Swap) Run(ctx context.Context) (*State, error) {
+	if s.State != nil {
+		s.Spec = s.State.Spec
+	}
+	if err := s.Spec.Validate(); err != nil {
+		return nil, err
+	}
+	if s.Spec.LockTimes == (timings.Policy{}) {
+		s.Spec.LockTimes = timings.DefaultPolicy
+	}
+	if s.Mine.Chain() != s.Spec.MyChain {
+		return nil, fmt.Errorf("swapper for %s given for my chain %s", s.Mine.Chain(), s.Spec.MyChain)
+	}
+	if s.Theirs.Chain() != s.Spec.TheirChain {
+		return nil, fmt.Errorf("swapper for %s given for their chain %s", s.Theirs.Chain(), s.Spec.TheirChain)
+	}
+	if s.State == nil {
+		if err := s.create(ctx); err != nil {
+			return nil, err
+		}
+	}
+	st := s.State
+	if st.Step.Done() {
+		s.logf("swap %s is already %s", st.ID, st.Step)
+		return st, nil
+	}
+	if st.Step == StepRefunding {
+		return s.refund(ctx)
+	}
+	if st.Spec.Role == RoleInitiator {
+		return s.runInitiator(ctx)
+	}
+	return s.runParticipant(ctx)
+}
+
+// create creates and persists the state of a new swap,
+// before anything is published.
+func (s *Swap) create(ctx context.Context) error {
+	id, err := NewID()
+	if err != nil {
+		return err
+	}
+	spec := s.Spec
+	// the redeem address is persisted, so a resumed swap audits against the same one
+	if spec.RedeemAddress == "" {
+		spec.RedeemAddress, err = s.Theirs.Address(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get our %s address: %v", spec.TheirChain, err)
+		}
+	}
+	st := &State{
+		ID:   id,
+		Spec: spec,
+		Step: StepNew,
+	}
+	if spec.Role == RoleInitiator {
+		secret, err := chain.NewSecret()
+		if err != nil {
+			return err
+		}
+		st.Secret, st.SecretHash = &secret, secret.Hash()
+	}
+	s.State, s.Spec = st, spec
+	s.logf("created swap %s", id)
+	return s.save()
+}
+
+func (s *Swap) runInitiator(ctx context.Context) (*State, error) {
+	st := s.State
+	err := s.lock(ctx, func(ctx context.Context) (chain.Contract, error) {
+		return s.Mine.Initiate(ctx, s.Spec.CounterpartyAddress, s.Spec.MyAmount, *st.Secret, s.Spec.LockTimes)
+	})
+	if err != nil {
+		return st, err
+	}
+
+	if st.TheirContract == nil {
+		// the participant has to act before our contract expires
+		deadlineCtx, cancel := context.WithDeadline(ctx, st.MyLockTime.Time())
+		defer cancel()
+		theirContract, theirAudit, err := s.exchangeInitiator(deadlineCtx)
+		if err != nil {
+			return s.abort(ctx, err)
+		}
+		st.TheirContract, st.TheirLockTime, st.Step = &theirContract, theirAudit.LockTime, StepAudited
+		if err = s.save(); err != nil {
+			return st, err
+		}
+	}
+	return s.redeemInitiator(ctx)
+}
+
+func (s *Swap) exchangeInitiator(ctx context.Context) (chain.Contract, chain.AuditResult, error) {
+	if err := s.Counterparty.SendContract(ctx, *s.State.MyContract); err != nil {
+		return chain.Contract{}, chain.AuditResult{}, fmt.Errorf("failed to send our contract: %v", err)
+	}
+	s.logf("waiting for the participant's %s contract", s.Spec.TheirChain)
+	theirContract, err := s.Counterparty.ReceiveContract(ctx)
+	if err != nil {
+		return chain.Contract{}, chain.AuditResult{}, fmt.Errorf("failed to receive the participant's contract: %v", err)
+	}
+	theirAudit, err := s.waitAudit(ctx, s.Theirs, theirContract)
+	if err != nil {
+		return chain.Contract{}, chain.AuditResult{}, err
+	}
+	// the participant's contract must expire before ours by the margin
+	err = s.checkContract(theirAudit, chain.Terms{Initiator: true, OurLockTime: s.State.MyLockTime})
+	if err != nil {
+		return chain.Contract{}, chain.AuditResult{}, err
+	}
+	return theirContract, theirAudit, nil
+}
+
+// redeemInitiator redeems the participant's contract, revealing the secret.
+// Once the secret may be revealed, the participant can redeem our contract,
+// so a failed redeem is retried, less and less often, until the participant's contract expires,
+// and only then our contract is refunded.
+// An interrupted swap resumes retrying.
+func (s *Swap) redeemInitiator(ctx context.Context) (*State, error) {
+	st := s.State
+	deadlineCtx, cancel := context.WithDeadline(ctx, st.TheirLockTime.Time())
+	defer cancel()
+	interval := s.pollInterval()
+	for {
+		redeemTx, err := s.Theirs.Redeem(ctx, *st.TheirContract, *st.Secret)
+		if err == nil {
+			return st, s.redeemed(redeemTx)
+		}
+		st.RedeemAttempts, st.RedeemError = st.RedeemAttempts+1, err.Error()
+		if err = s.save(); err != nil {
+			return st, err
+		}
+		s.logf("failed to redeem the %s contract (attempt %d): %s", s.Spec.TheirChain, st.RedeemAttempts, st.RedeemError)
+		if err = s.sleepFor(deadlineCtx, interval); err != nil {
+			if ctx.Err() != nil {
+				return st, fmt.Errorf("failed to redeem %s contract: %s", s.Spec.TheirChain, st.RedeemError)
+			}
+			return s.abort(ctx, fmt.Errorf("failed to redeem %s contract before it expired: %s", s.Spec.TheirChain, st.RedeemError))
+		}
+		if interval *= 2; interval > maxRedeemInterval {
+			interval = maxRedeemInterval
+		}
+	}
+}
+
+func (s *Swap) runParticipant(ctx context.Context) (*State, error) {
+	st := s.State
+	if st.TheirContract == nil {
+		s.logf("waiting for the initiator's %s contract", s.Spec.TheirChain)
+		theirContract, err := s.Counterparty.ReceiveContract(ctx)
+		if err != nil {
+			return st, fmt.Errorf("failed to receive the initiator's contract: %v", err)
+		}
+		st.TheirContract, st.SecretHash = &theirContract, theirContract.SecretHash
+		if err = s.save(); err != nil {
+			return st, err
+		}
+	}
+	if st.Step == StepNew {
+		theirAudit, err := s.waitAudit(ctx, s.Theirs, *st.TheirContract)
+		if err != nil {
+			return st, err
+		}
+		// the initiator's contract must outlive ours by the margin,
+		// so we can still redeem it once the secret is revealed
+		lockTimes := s.Spec.LockTimes
+		err = s.checkContract(theirAudit, chain.Terms{MinRemaining: lockTimes.Participant + lockTimes.Margin})
+		if err != nil {
+			st.Step, st.Error = StepAborted, err.Error()
+			if saveErr := s.save(); saveErr != nil {
+				return st, saveErr
+			}
+			return st, err
+		}
+	}
+
+	err := s.lock(ctx, func(ctx context.Context) (chain.Contract, error) {
+		return s.Mine.Participate(ctx, s.Spec.CounterpartyAddress, s.Spec.MyAmount, st.SecretHash, s.Spec.LockTimes)
+	})
+	if err != nil {
+		return st, err
+	}
+
+	if st.Secret == nil {
+		// the initiator has to redeem before our contract expires
+		deadlineCtx, cancel := context.WithDeadline(ctx, st.MyLockTime.Time())
+		defer cancel()
+		if err = s.Counterparty.SendContract(deadlineCtx, *st.MyContract); err != nil {
+			return s.abort(ctx, fmt.Errorf("failed to send our contract: %v", err))
+		}
+		s.logf("waiting for the initiator to redeem the %s contract", s.Spec.MyChain)
+		secret, err := s.waitSecret(deadlineCtx, *st.MyContract)
+		if err != nil {
+			return s.abort(ctx, err)
+		}
+		st.Secret = &secret
+		if err = s.save(); err != nil {
+			return st, err
+		}
+		s.logf("extracted the secret from the %s contract", s.Spec.MyChain)
+	}
+
+	redeemTx, err := s.Theirs.Redeem(ctx, *st.TheirContract, *st.Secret)
+	if err != nil {
+		return st, fmt.Errorf("failed to redeem %s contract: %v", s.Spec.TheirChain, err)
+	}
+	return st, s.redeemed(redeemTx)
+}
+
+// lock creates and audits our contract, unless it already has been.
+func (s *Swap) lock(ctx context.Context, create func(ctx context.Context) (chain.Contract, error)) error {
+	st := s.State
+	switch st.Step {
+	case StepNew:
+		st.Step = StepLocking
+		if err := s.save(); err != nil {
+			return err
+		}
+		contract, err := create(chain.WithRecorder(ctx, recorder{s}))
+		if err != nil {
+			if st.MyContract == nil && len(st.Keys) == 0 {
+				// nothing was recorded, so nothing was published
+				st.Step = StepNew
+				if saveErr := s.save(); saveErr != nil {
+					return saveErr
+				}
+				return fmt.Errorf("failed to create our %s contract: %v", s.Spec.MyChain, err)
+			}
+			return fmt.Errorf("failed to create our %s contract, it may have been partially published: %v", s.Spec.MyChain, err)
+		}
+		st.MyContract, st.Step = &contract, StepLocked
+		if err = s.save(); err != nil {
+			return err
+		}
+		s.logf("created %s contract %s", s.Spec.MyChain, contract.Address)
+
+	case StepLocking:
+		// the creation of our contract was interrupted,
+		// it can only be continued if it was recorded and published
+		if st.MyContract == nil {
+			return fmt.Errorf("creating our %s contract was interrupted before it was recorded, check the keys of swap %s", s.Spec.MyChain, st.ID)
+		}
+		if _, err := s.Mine.AuditContract(ctx, *st.MyContract); err != nil {
+			return fmt.Errorf("creating our %s contract was interrupted, it can not be found: %v", s.Spec.MyChain, err)
+		}
+		st.Step = StepLocked
+		if err := s.save(); err != nil {
+			return err
+		}
+	}
+
+	if st.MyLockTime == 0 {
+		myAudit, err := s.waitAudit(ctx, s.Mine, *st.MyContract)
+		if err != nil {
+			return err
+		}
+		st.MyLockTime = myAudit.LockTime
+		return s.save()
+	}
+	return nil
+}
+
+// redeemed completes the swap once the counterparty's contract is redeemed
+func (s *Swap) redeemed(redeemTx string) error {
+	st := s.State
+	st.RedeemTransaction, st.Step = redeemTx, StepRedeemed
+	s.logf("redeemed %s contract in transaction %s", s.Spec.TheirChain, redeemTx)
+	return s.save()
+}
+
+// abort refunds our contract once its lock time has passed.
+func (s *Swap) abort(ctx context.Context, cause error) (*State, error) {
+	st := s.State
+	s.logf("aborting the swap: %v", cause)
+	st.Step, st.Error = StepRefunding, cause.Error()
+	if err := s.save(); err != nil {
+		return st, err
+	}
+	return s.refund(ctx)
+}
+
+func (s *Swap) refund(ctx context.Context) (*State, error) {
+	st := s.State
+	s.logf("refunding the %s contract after %s", s.Spec.MyChain, st.MyLockTime.Time().Format(time.RFC3339))
+	for {
+		if st.MyLockTime.Expired() {
+			refundTx, err := s.Mine.Refund(ctx, *st.MyContract)
+			if err == nil {
+				st.RefundTransaction, st.Step = refundTx, StepRefunded
+				s.logf("refunded the %s contract in transaction %s", s.Spec.MyChain, refundTx)
+				if err = s.save(); err != nil {
+					return st, err
+				}
+				return st, fmt.Errorf("swap aborted: %s", st.Error)
+			}
+			s.logf("failed to refund the %s contract: %v", s.Spec.MyChain, err)
+		}
+		if err := s.sleep(ctx); err != nil {
+			return st, fmt.Errorf("swap aborted: %s, refund of the %s contract failed: %v", st.Error, s.Spec.MyChain, err)
+		}
+	}
+}
+
+// checkContract verifies the audit of the counterparty's contract
+// against the terms of the swap
+func (s *Swap) checkContract(audit chain.AuditResult, terms chain.Terms) error {
+	terms.Amount, terms.Decimals = s.Spec.TheirAmount, s.Theirs.Decimals()
+	terms.Recipient, terms.SecretHash = s.Spec.RedeemAddress, s.State.SecretHash
+	terms.Margin = s.Spec.LockTimes.Margin
+	if err := chain.VerifySwapTerms(audit, terms); err != nil {
+		return fmt.Errorf("%s %v", s.Spec.TheirChain, err)
+	}
+	return nil
+}
+
+// waitAudit audits a contract until it can be found on chain
+func (s *Swap) waitAudit(ctx context.Context, swapper chain.Swapper, contract chain.Contract) (chain.AuditResult, error) {
+	for {
+		audit, err := swapper.AuditContract(ctx, contract)
+		if err == nil {
+			return audit, nil
+		}
+		s.logf("%s contract %s can not be audited yet: %v", swapper.Chain(), contract.Address, err)
+		if err = s.sleep(ctx); err != nil {
+			return chain.AuditResult{}, fmt.Errorf("failed to audit %s contract: %v", swapper.Chain(), err)
+		}
+	}
+}
+
+// waitSecret waits until the counterparty redeems our contract
+func (s *Swap) waitSecret(ctx context.Context, myContract chain.Contract) (chain.Secret, error) {
+	for {
+		secret, err := s.Mine.ExtractSecret(ctx, myContract, "")
+		if err == nil {
+			return secret, nil
+		}
+		if err != chain.ErrNotRedeemed {
+			s.logf("failed to extract the secret from the %s contract: %v", s.Spec.MyChain, err)
+		}
+		if err = s.sleep(ctx); err != nil {
+			return chain.Secret{}, fmt.Errorf("%s contract was not redeemed: %v", s.Spec.MyChain, err)
+		}
+	}
+}
+
+func (s *Swap) pollInterval() time.Duration {
+	if s.PollInterval <= 0 {
+		return DefaultPollInterval
+	}
+	return s.PollInterval
+}
+
+func (s *Swap) sleep(ctx context.Context) error {
+	return s.sleepFor(ctx, s.pollInterval())
+}
+
+func (s *Swap) sleepFor(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (s *Swap) save() error {
+	s.State.UpdatedAt = time.Now()
+	if s.Journal == nil {
+		return nil
+	}
+	if err := s.Journal.Save(s.State); err != nil {
+		return fmt.Errorf("failed to save the state of swap %s: %v", s.State.ID, err)
+	}
+	return nil
+}
+
+func (s *Swap) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
@@ -0,0 +1,485 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/timings"
+)
+
+// fakeChain keeps the contracts of a chain in memory,
+// shared by the swappers of both parties.
+type fakeChain struct {
+	name      string
+	mu        sync.Mutex
+	contracts map[string]*fakeContract
+	n         int
+}
+
+type fakeContract struct {
+	audit    chain.AuditResult
+	secret   *chain.Secret
+	refunded bool
+}
+
+func newFakeChain(name string) *fakeChain {
+	return &fakeChain{name: name, contracts: make(map[string]*fakeContract)}
+}
+
+func (c *fakeChain) create(refund, recipient string, amount chain.Amount, secretHash chain.SecretHash, lockTime time.Duration) chain.Contract {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+	address := fmt.Sprintf("%s-contract-%d", c.name, c.n)
+	c.contracts[address] = &fakeContract{audit: chain.AuditResult{
+		ContractAddress:  address,
+		ContractValue:    amount,
+		RecipientAddress: recipient,
+		RefundAddress:    refund,
+		SecretHash:       secretHash,
+		LockTime:         chain.LockTime(time.Now().Add(lockTime).Unix()),
+	}}
+	return chain.Contract{SecretHash: secretHash, Address: address}
+}
+
+func (c *fakeChain) get(address string) (*fakeContract, error) {
+	contract, ok := c.contracts[address]
+	if !ok {
+		return nil, errors.New("contract not found")
+	}
+	return contract, nil
+}
+
+// fakeSwapper acts on a fakeChain on behalf of address
+type fakeSwapper struct {
+	*fakeChain
+	address string
+}
+
+func (s *fakeSwapper) Chain() string { return s.name }
+func (s *fakeSwapper) Decimals() int { return 8 }
+func (s *fakeSwapper) Address(ctx context.Context) (string, error) {
+	return s.address, nil
+}
+
+func (s *fakeSwapper) Initiate(ctx context.Context, participant string, amount chain.Amount, secret chain.Secret, policy timings.Policy) (chain.Contract, error) {
+	contract := s.create(s.address, participant, amount, secret.Hash(), policy.Initiator)
+	return contract, chain.RecordContract(ctx, contract)
+}
+
+func (s *fakeSwapper) Participate(ctx context.Context, initiator string, amount chain.Amount, secretHash chain.SecretHash, policy timings.Policy) (chain.Contract, error) {
+	contract := s.create(s.address, initiator, amount, secretHash, policy.Participant)
+	return contract, chain.RecordContract(ctx, contract)
+}
+
+func (s *fakeSwapper) Redeem(ctx context.Context, contract chain.Contract, secret chain.Secret) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.get(contract.Address)
+	if err != nil {
+		return "", err
+	}
+	if c.audit.RecipientAddress != s.address || secret.Hash() != c.audit.SecretHash || c.secret != nil || c.refunded {
+		return "", errors.New("can not redeem")
+	}
+	c.secret = &secret
+	return "redeem-" + contract.Address, nil
+}
+
+func (s *fakeSwapper) Refund(ctx context.Context, contract chain.Contract) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.get(contract.Address)
+	if err != nil {
+		return "", err
+	}
+	if c.audit.RefundAddress != s.address || !c.audit.LockTime.Expired() || c.secret != nil || c.refunded {
+		return "", errors.New("can not refund")
+	}
+	c.refunded = true
+	return "refund-" + contract.Address, nil
+}
+
+func (s *fakeSwapper) AuditContract(ctx context.Context, contract chain.Contract) (chain.AuditResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.get(contract.Address)
+	if err != nil {
+		return chain.AuditResult{}, err
+	}
+	return c.audit, nil
+}
+
+func (s *fakeSwapper) ExtractSecret(ctx context.Context, contract chain.Contract, redemptionTx string) (chain.Secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.get(contract.Address)
+	if err != nil {
+		return chain.Secret{}, err
+	}
+	if c.secret == nil {
+		return chain.Secret{}, chain.ErrNotRedeemed
+	}
+	return *c.secret, nil
+}
+
+// chanCounterparty passes contracts between 2 swaps running in the same process
+type chanCounterparty struct {
+	send    chan<- chain.Contract
+	receive <-chan chain.Contract
+}
+
+func newChanCounterparties() (*chanCounterparty, *chanCounterparty) {
+	a, b := make(chan chain.Contract, 1), make(chan chain.Contract, 1)
+	return &chanCounterparty{send: a, receive: b}, &chanCounterparty{send: b, receive: a}
+}
+
+func (c *chanCounterparty) SendContract(ctx context.Context, contract chain.Contract) error {
+	c.send <- contract
+	return nil
+}
+
+func (c *chanCounterparty) ReceiveContract(ctx context.Context) (chain.Contract, error) {
+	select {
+	case contract := <-c.receive:
+		return contract, nil
+	case <-ctx.Done():
+		return chain.Contract{}, ctx.Err()
+	}
+}
+
+func TestSwap(t *testing.T) {
+	btc, eth := newFakeChain("btc"), newFakeChain("eth")
+	cp1, cp2 := newChanCounterparties()
+	lockTimes := timings.Policy{Initiator: 4 * time.Hour, Participant: 2 * time.Hour, Margin: time.Hour}
+	initiator := &Swap{
+		Spec: Spec{
+			Role:                RoleInitiator,
+			MyChain:             "btc",
+			TheirChain:          "eth",
+			MyAmount:            chain.AmountFromInt64(100),
+			TheirAmount:         chain.AmountFromInt64(2000),
+			CounterpartyAddress: "bob-btc",
+			LockTimes:           lockTimes,
+		},
+		Mine:         &fakeSwapper{btc, "alice-btc"},
+		Theirs:       &fakeSwapper{eth, "alice-eth"},
+		Counterparty: cp1,
+		PollInterval: time.Millisecond,
+	}
+	participant := &Swap{
+		Spec: Spec{
+			Role:                RoleParticipant,
+			MyChain:             "eth",
+			TheirChain:          "btc",
+			MyAmount:            chain.AmountFromInt64(2000),
+			TheirAmount:         chain.AmountFromInt64(100),
+			CounterpartyAddress: "alice-eth",
+			LockTimes:           lockTimes,
+		},
+		Mine:         &fakeSwapper{eth, "bob-eth"},
+		Theirs:       &fakeSwapper{btc, "bob-btc"},
+		Counterparty: cp2,
+		PollInterval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	var participantRes *State
+	var participantErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		participantRes, participantErr = participant.Run(ctx)
+	}()
+	initiatorRes, err := initiator.Run(ctx)
+	wg.Wait()
+	require.NoError(t, err)
+	require.NoError(t, participantErr)
+
+	assert.Equal(t, StepRedeemed, initiatorRes.Step)
+	assert.Equal(t, StepRedeemed, participantRes.Step)
+	assert.Equal(t, initiatorRes.Secret, participantRes.Secret)
+	assert.Equal(t, initiatorRes.MyContract, participantRes.TheirContract)
+	assert.Equal(t, initiatorRes.TheirContract, participantRes.MyContract)
+	assert.Equal(t, "redeem-"+initiatorRes.TheirContract.Address, initiatorRes.RedeemTransaction)
+	assert.Equal(t, "redeem-"+participantRes.TheirContract.Address, participantRes.RedeemTransaction)
+	assert.InDelta(t, lockTimes.Initiator.Seconds(), initiatorRes.MyLockTime.Remaining().Seconds(), 5)
+	assert.InDelta(t, lockTimes.Participant.Seconds(), participantRes.MyLockTime.Remaining().Seconds(), 5)
+}
+
+func TestSwapRejectsContract(t *testing.T) {
+	btc, eth := newFakeChain("btc"), newFakeChain("eth")
+	cp1, cp2 := newChanCounterparties()
+	participant := &Swap{
+		Spec: Spec{
+			Role:                RoleParticipant,
+			MyChain:             "eth",
+			TheirChain:          "btc",
+			MyAmount:            chain.AmountFromInt64(2000),
+			TheirAmount:         chain.AmountFromInt64(100),
+			CounterpartyAddress: "alice-eth",
+		},
+		Mine:         &fakeSwapper{eth, "bob-eth"},
+		Theirs:       &fakeSwapper{btc, "bob-btc"},
+		Counterparty: cp2,
+		PollInterval: time.Millisecond,
+	}
+
+	// the initiator locks less than agreed upon
+	alice := &fakeSwapper{btc, "alice-btc"}
+	secret, err := chain.NewSecret()
+	require.NoError(t, err)
+	contract, err := alice.Initiate(context.Background(), "bob-btc", chain.AmountFromInt64(99), secret, timings.DefaultPolicy)
+	require.NoError(t, err)
+	require.NoError(t, cp1.SendContract(context.Background(), contract))
+
+	res, err := participant.Run(context.Background())
+	assert.Error(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, StepAborted, res.Step)
+	assert.Nil(t, res.MyContract, "participant must not lock funds")
+}
+
+// memoryJournal keeps a copy of the last saved state of every swap
+type memoryJournal map[string]State
+
+func (j memoryJournal) Save(state *State) error {
+	j[state.ID] = *state
+	return nil
+}
+
+// failingCounterparty fails to receive the counterparty's contract
+type failingCounterparty struct{}
+
+func (failingCounterparty) SendContract(ctx context.Context, contract chain.Contract) error {
+	return nil
+}
+
+func (failingCounterparty) ReceiveContract(ctx context.Context) (chain.Contract, error) {
+	return chain.Contract{}, context.Canceled
+}
+
+func TestSwapResume(t *testing.T) {
+	btc, eth := newFakeChain("btc"), newFakeChain("eth")
+	journal := make(memoryJournal)
+	spec := Spec{
+		Role:                RoleInitiator,
+		MyChain:             "btc",
+		TheirChain:          "eth",
+		MyAmount:            chain.AmountFromInt64(100),
+		TheirAmount:         chain.AmountFromInt64(2000),
+		CounterpartyAddress: "bob-btc",
+	}
+	alice := &fakeSwapper{btc, "alice-btc"}
+	ctx, cancel := context.WithCancel(context.Background())
+	initiator := &Swap{
+		Spec:         spec,
+		Mine:         alice,
+		Theirs:       &fakeSwapper{eth, "alice-eth"},
+		Counterparty: failingCounterparty{},
+		Journal:      journal,
+		PollInterval: time.Millisecond,
+		// the process stops while waiting for the refund
+		Logf: func(format string, args ...interface{}) {
+			if format == "aborting the swap: %v" {
+				cancel()
+			}
+		},
+	}
+	_, err := initiator.Run(ctx)
+	require.Error(t, err)
+	require.Len(t, journal, 1)
+	var state State
+	for _, state = range journal {
+	}
+	assert.Equal(t, StepRefunding, state.Step)
+	require.NotNil(t, state.Secret)
+	require.NotNil(t, state.MyContract)
+	assert.Equal(t, state.Secret.Hash(), state.MyContract.SecretHash)
+
+	// resume once the contract expired
+	btc.contracts[state.MyContract.Address].audit.LockTime = chain.LockTime(time.Now().Add(-time.Minute).Unix())
+	state.MyLockTime = btc.contracts[state.MyContract.Address].audit.LockTime
+	resumed := &Swap{
+		State:        &state,
+		Mine:         alice,
+		Theirs:       &fakeSwapper{eth, "alice-eth"},
+		Counterparty: failingCounterparty{},
+		Journal:      journal,
+		PollInterval: time.Millisecond,
+	}
+	res, err := resumed.Run(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, StepRefunded, res.Step)
+	assert.Equal(t, "refund-"+state.MyContract.Address, res.RefundTransaction)
+	assert.Equal(t, StepRefunded, journal[state.ID].Step)
+}
+
+// flakySwapper fails to redeem a number of times first, always if negative
+type flakySwapper struct {
+	*fakeSwapper
+	failures int
+}
+
+func (s *flakySwapper) Redeem(ctx context.Context, contract chain.Contract, secret chain.Secret) (string, error) {
+	if s.failures != 0 {
+		s.failures--
+		return "", errors.New("node unavailable")
+	}
+	return s.fakeSwapper.Redeem(ctx, contract, secret)
+}
+
+func TestSwapRetriesRedeem(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+	}{
+		{"recovers", 3},
+		{"expires", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			btc, eth := newFakeChain("btc"), newFakeChain("eth")
+			cp1, cp2 := newChanCounterparties()
+			journal := make(memoryJournal)
+			// the participant's contract expires within a second
+			lockTimes := timings.Policy{Initiator: 4 * time.Hour, Participant: time.Second, Margin: time.Hour}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			initiatorCtx, stop := context.WithCancel(ctx)
+			defer stop()
+			var abortedAt time.Time
+			initiator := &Swap{
+				Spec: Spec{
+					Role:                RoleInitiator,
+					MyChain:             "btc",
+					TheirChain:          "eth",
+					MyAmount:            chain.AmountFromInt64(100),
+					TheirAmount:         chain.AmountFromInt64(2000),
+					CounterpartyAddress: "bob-btc",
+					LockTimes:           lockTimes,
+				},
+				Mine:         &fakeSwapper{btc, "alice-btc"},
+				Theirs:       &flakySwapper{&fakeSwapper{eth, "alice-eth"}, tt.failures},
+				Counterparty: cp1,
+				Journal:      journal,
+				PollInterval: time.Millisecond,
+				// the process stops while waiting for the refund
+				Logf: func(format string, args ...interface{}) {
+					if format == "aborting the swap: %v" {
+						abortedAt = time.Now()
+						stop()
+					}
+				},
+			}
+			participant := &Swap{
+				Spec: Spec{
+					Role:                RoleParticipant,
+					MyChain:             "eth",
+					TheirChain:          "btc",
+					MyAmount:            chain.AmountFromInt64(2000),
+					TheirAmount:         chain.AmountFromInt64(100),
+					CounterpartyAddress: "alice-eth",
+					LockTimes:           lockTimes,
+				},
+				Mine:         &fakeSwapper{eth, "bob-eth"},
+				Theirs:       &fakeSwapper{btc, "bob-btc"},
+				Counterparty: cp2,
+				PollInterval: time.Millisecond,
+			}
+
+			var wg sync.WaitGroup
+			var participantRes *State
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				participantRes, _ = participant.Run(ctx)
+			}()
+			res, err := initiator.Run(initiatorCtx)
+			wg.Wait()
+			require.NotNil(t, res)
+			require.NotNil(t, participantRes)
+			require.NotNil(t, res.TheirContract)
+			assert.Equal(t, participantRes.MyLockTime, res.TheirLockTime)
+			assert.Equal(t, *res, journal[res.ID])
+
+			if tt.failures >= 0 {
+				require.NoError(t, err)
+				assert.Equal(t, StepRedeemed, res.Step)
+				assert.Equal(t, StepRedeemed, participantRes.Step)
+				assert.Equal(t, tt.failures, res.RedeemAttempts)
+				assert.Equal(t, "node unavailable", res.RedeemError)
+				return
+			}
+			// our contract is only refunded once the participant's contract expired
+			require.Error(t, err)
+			assert.Equal(t, StepRefunding, res.Step)
+			assert.Contains(t, res.Error, "before it expired")
+			assert.Greater(t, res.RedeemAttempts, 1)
+			assert.False(t, abortedAt.Before(res.TheirLockTime.Time()), "aborted before the participant's contract expired")
+			assert.Equal(t, StepRefunded, participantRes.Step)
+		})
+	}
+}
+
+func TestSwapRedeemAddressCase(t *testing.T) {
+	btc, eth := newFakeChain("btc"), newFakeChain("eth")
+	cp1, cp2 := newChanCounterparties()
+	// the participant's contract is audited with the checksummed address,
+	// the initiator gave it in lower case
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	initiator := &Swap{
+		Spec: Spec{
+			Role:                RoleInitiator,
+			MyChain:             "btc",
+			TheirChain:          "eth",
+			MyAmount:            chain.AmountFromInt64(100),
+			TheirAmount:         chain.AmountFromInt64(2000),
+			CounterpartyAddress: "bob-btc",
+			RedeemAddress:       strings.ToLower(checksummed),
+		},
+		Mine:         &fakeSwapper{btc, "alice-btc"},
+		Theirs:       &fakeSwapper{eth, checksummed},
+		Counterparty: cp1,
+		PollInterval: time.Millisecond,
+	}
+	participant := &Swap{
+		Spec: Spec{
+			Role:                RoleParticipant,
+			MyChain:             "eth",
+			TheirChain:          "btc",
+			MyAmount:            chain.AmountFromInt64(2000),
+			TheirAmount:         chain.AmountFromInt64(100),
+			CounterpartyAddress: checksummed,
+		},
+		Mine:         &fakeSwapper{eth, "bob-eth"},
+		Theirs:       &fakeSwapper{btc, "bob-btc"},
+		Counterparty: cp2,
+		PollInterval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	var participantErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, participantErr = participant.Run(ctx)
+	}()
+	res, err := initiator.Run(ctx)
+	wg.Wait()
+	require.NoError(t, err)
+	require.NoError(t, participantErr)
+	assert.Equal(t, StepRedeemed, res.Step)
+}
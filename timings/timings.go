@@ -1,6 +1,62 @@
 package timings
 
-import "time"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
 
 //LockTime is the default time an atomic swap is locked before a refund can be issued
 const LockTime = 48 * time.Hour
+
+// DefaultMargin is the default minimum time by which the participant's contract
+// expires before the initiator's contract
+const DefaultMargin = time.Hour
+
+// DefaultPolicy locks the initiator's contract for LockTime
+// and the participant's contract for half of it
+var DefaultPolicy = Policy{
+	Initiator:   LockTime,
+	Participant: LockTime / 2,
+	Margin:      DefaultMargin,
+}
+
+// Policy defines the lock times of the contracts of an atomic swap.
+// Both parties of a swap need to agree on it.
+type Policy struct {
+	// Initiator is the time the initiator's contract is locked
+	Initiator time.Duration `json:"initiator"`
+	// Participant is the time the participant's contract is locked
+	Participant time.Duration `json:"participant"`
+	// Margin is the minimum time by which the participant's contract has to expire
+	// before the initiator's contract, so the participant has the time to redeem
+	// the initiator's contract once the secret is revealed.
+	Margin time.Duration `json:"margin"`
+}
+
+// Validate returns an error if the participant's contract
+// does not expire before the initiator's contract by at least the margin.
+func (p Policy) Validate() error {
+	if p.Initiator <= 0 || p.Participant <= 0 {
+		return errors.New("lock times must be positive")
+	}
+	if p.Margin < 0 {
+		return errors.New("lock time margin can not be negative")
+	}
+	if p.Participant >= p.Initiator {
+		return fmt.Errorf("participant lock time %s must be shorter than initiator lock time %s", p.Participant, p.Initiator)
+	}
+	if p.Initiator-p.Participant < p.Margin {
+		return fmt.Errorf("participant lock time %s must be shorter than initiator lock time %s by at least %s", p.Participant, p.Initiator, p.Margin)
+	}
+	return nil
+}
+
+// AddFlags defines the flags of the policy on a flagset,
+// using the current values of the policy as defaults.
+func (p *Policy) AddFlags(flagset *flag.FlagSet) {
+	flagset.DurationVar(&p.Initiator, "initiatorlocktime", p.Initiator, "time the initiator's contract is locked")
+	flagset.DurationVar(&p.Participant, "participantlocktime", p.Participant, "time the participant's contract is locked")
+	flagset.DurationVar(&p.Margin, "locktimemargin", p.Margin, "minimum time by which the participant's contract expires before the initiator's contract")
+}
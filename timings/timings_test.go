@@ -0,0 +1,31 @@
+package timings
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		Policy Policy
+		Valid  bool
+	}{
+		{DefaultPolicy, true},
+		{Policy{Initiator: 4 * time.Hour, Participant: 2 * time.Hour, Margin: time.Hour}, true},
+		{Policy{Initiator: 4 * time.Hour, Participant: 3 * time.Hour, Margin: time.Hour}, true},
+		{Policy{Initiator: 4 * time.Hour, Participant: 3*time.Hour + 1, Margin: time.Hour}, false},
+		{Policy{Initiator: 4 * time.Hour, Participant: 4 * time.Hour}, false},
+		{Policy{Initiator: 2 * time.Hour, Participant: 4 * time.Hour}, false},
+		{Policy{Initiator: 4 * time.Hour, Participant: 2 * time.Hour, Margin: -time.Hour}, false},
+		{Policy{Initiator: 4 * time.Hour}, false},
+		{Policy{}, false},
+	}
+	for idx, testCase := range testCases {
+		err := testCase.Policy.Validate()
+		if testCase.Valid && err != nil {
+			t.Errorf("testCase #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("testCase #%d: expected an error", idx)
+		}
+	}
+}
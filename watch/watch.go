@@ -0,0 +1,274 @@
+// Package watch implements a watchtower, which refunds our contracts
+// as soon as their lock time has passed, so no refund window is missed.
+// When we participate in a swap, it also redeems the initiator's contract
+// as soon as the secret is revealed by the redemption of ours.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/swap"
+)
+
+// DefaultInterval is the interval between checks when none is configured.
+const DefaultInterval = time.Minute
+
+// Store holds the swaps to watch, such as *store.Store.
+type Store interface {
+	swap.Journal
+	// List returns the states of all swaps.
+	List() ([]*swap.State, error)
+}
+
+// Action is what the watcher did for a swap
+type Action string
+
+const (
+	// ActionRefunded reports our contract has been refunded
+	ActionRefunded Action = "refunded"
+	// ActionRedeemed reports the counterparty redeemed our contract,
+	// so it can no longer be refunded and the secret is known.
+	ActionRedeemed Action = "redeemed"
+	// ActionRedeemedTheirs reports we redeemed the counterparty's contract
+	// with the secret revealed by the redemption of ours.
+	ActionRedeemedTheirs Action = "redeemed theirs"
+	// ActionFailed reports a refund or redeem failed, it is retried on the next check.
+	ActionFailed Action = "failed"
+)
+
+// Event reports an action of the watcher
+type Event struct {
+	Time   time.Time `json:"time"`
+	SwapID string    `json:"swapId"`
+	Chain  string    `json:"chain"`
+	Action Action    `json:"action"`
+	// Transaction is the ID of the refund or redeem transaction
+	Transaction string `json:"transaction,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// String implements fmt.Stringer
+func (e Event) String() string {
+	str := fmt.Sprintf("%s swap %s: %s contract %s", e.Time.Format(time.RFC3339), e.SwapID, e.Chain, e.Action)
+	if e.Transaction != "" {
+		str += " in transaction " + e.Transaction
+	}
+	if e.Error != "" {
+		str += ": " + e.Error
+	}
+	return str
+}
+
+// Watcher refunds the expired contracts of the swaps in a store,
+// and redeems the counterparty's contract of swaps we participate in
+// once the initiator redeemed ours.
+//
+// It only updates swaps it refunds or redeems, or of which our contract was redeemed,
+// these should not be run at the same time.
+type Watcher struct {
+	Store Store
+	// Swapper returns the swapper of a chain,
+	// it is called once for every chain a swap needs, the swapper is reused by later checks.
+	// Swappers implementing io.Closer are closed when Run returns.
+	Swapper func(ctx context.Context, chain string) (chain.Swapper, error)
+	// Interval between checks, DefaultInterval is used if zero.
+	Interval time.Duration
+	// Report is called for every action of the watcher, optional.
+	Report func(Event)
+
+	// lastErrors avoids reporting the same failure on every check
+	lastErrors map[string]string
+	// swappers created by Swapper, by chain
+	swappers map[string]chain.Swapper
+}
+
+// Run checks the swaps of the store until ctx is done
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer w.closeSwappers()
+	for {
+		if err := w.Check(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check refunds the expired contracts and redeems the contracts
+// of which the secret is revealed of the swaps in the store once.
+func (w *Watcher) Check(ctx context.Context) error {
+	states, err := w.Store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list swaps: %v", err)
+	}
+	for _, state := range states {
+		if state.Step.Done() || state.MyContract == nil {
+			continue
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if state.Spec.Role == swap.RoleParticipant && state.TheirContract != nil {
+			if w.redeem(ctx, state) {
+				continue
+			}
+		}
+		if state.MyLockTime != 0 && !state.MyLockTime.Expired() {
+			continue
+		}
+		swapper, err := w.swapper(ctx, state, state.Spec.MyChain)
+		if err != nil {
+			continue
+		}
+		w.refund(ctx, swapper, state)
+	}
+	return nil
+}
+
+// swapper returns the swapper of a chain, creating it the first time it is needed,
+// or again on a later check if it could not be created.
+func (w *Watcher) swapper(ctx context.Context, state *swap.State, name string) (chain.Swapper, error) {
+	if swapper, ok := w.swappers[name]; ok {
+		return swapper, nil
+	}
+	swapper, err := w.Swapper(ctx, name)
+	if err != nil {
+		w.fail(state, fmt.Errorf("no %s swapper: %v", name, err))
+		return nil, err
+	}
+	if w.swappers == nil {
+		w.swappers = make(map[string]chain.Swapper)
+	}
+	w.swappers[name] = swapper
+	return swapper, nil
+}
+
+// closeSwappers closes the swappers created by Swapper
+func (w *Watcher) closeSwappers() {
+	for name, swapper := range w.swappers {
+		if closer, ok := swapper.(io.Closer); ok {
+			closer.Close()
+		}
+		delete(w.swappers, name)
+	}
+}
+
+// redeem redeems the counterparty's contract once the initiator
+// revealed the secret by redeeming ours.
+// It returns false if the secret has not been revealed yet.
+func (w *Watcher) redeem(ctx context.Context, state *swap.State) bool {
+	if state.Secret == nil {
+		mine, err := w.swapper(ctx, state, state.Spec.MyChain)
+		if err != nil {
+			return false
+		}
+		secret, err := mine.ExtractSecret(ctx, *state.MyContract, "")
+		if err == chain.ErrNotRedeemed {
+			return false
+		}
+		if err != nil {
+			w.fail(state, fmt.Errorf("failed to extract the secret: %v", err))
+			return false
+		}
+		state.Secret = &secret
+		w.save(state, Event{Action: ActionRedeemed})
+	}
+
+	theirs, err := w.swapper(ctx, state, state.Spec.TheirChain)
+	if err != nil {
+		return true
+	}
+	redeemTx, err := theirs.Redeem(ctx, *state.TheirContract, *state.Secret)
+	if err != nil {
+		w.fail(state, fmt.Errorf("failed to redeem their contract: %v", err))
+		return true
+	}
+	state.RedeemTransaction, state.Step = redeemTx, swap.StepRedeemed
+	w.save(state, Event{Action: ActionRedeemedTheirs, Chain: state.Spec.TheirChain, Transaction: redeemTx})
+	return true
+}
+
+// refund refunds our contract of a swap if it has expired
+func (w *Watcher) refund(ctx context.Context, swapper chain.Swapper, state *swap.State) {
+	if state.MyLockTime == 0 {
+		// the swap was interrupted before our contract was audited
+		audit, err := swapper.AuditContract(ctx, *state.MyContract)
+		if err != nil {
+			w.fail(state, fmt.Errorf("failed to audit: %v", err))
+			return
+		}
+		if !audit.LockTime.Expired() {
+			return
+		}
+	}
+
+	refundTx, refundErr := swapper.Refund(ctx, *state.MyContract)
+	if refundErr == nil {
+		state.RefundTransaction, state.Step = refundTx, swap.StepRefunded
+		if state.Error == "" {
+			state.Error = "refunded by the watcher"
+		}
+		w.save(state, Event{Action: ActionRefunded, Transaction: refundTx})
+		return
+	}
+
+	// a contract redeemed by the counterparty can no longer be refunded,
+	// in which case the secret is stored to redeem their contract
+	secret, err := swapper.ExtractSecret(ctx, *state.MyContract, "")
+	if err != nil {
+		w.fail(state, fmt.Errorf("failed to refund: %v", refundErr))
+		return
+	}
+	if state.Secret == nil {
+		state.Secret = &secret
+		w.save(state, Event{Action: ActionRedeemed})
+		return
+	}
+	w.fail(state, fmt.Errorf("contract redeemed by the counterparty, resume the swap to redeem theirs"))
+}
+
+func (w *Watcher) save(state *swap.State, e Event) {
+	state.UpdatedAt = time.Now()
+	if err := w.Store.Save(state); err != nil {
+		e.Error = fmt.Sprintf("failed to save the swap: %v", err)
+	}
+	w.report(state, e)
+}
+
+func (w *Watcher) fail(state *swap.State, err error) {
+	if w.lastErrors == nil {
+		w.lastErrors = make(map[string]string)
+	}
+	if w.lastErrors[state.ID] == err.Error() {
+		return
+	}
+	w.lastErrors[state.ID] = err.Error()
+	w.report(state, Event{Action: ActionFailed, Error: err.Error()})
+}
+
+func (w *Watcher) report(state *swap.State, e Event) {
+	if e.Action != ActionFailed {
+		delete(w.lastErrors, state.ID)
+	}
+	if w.Report == nil {
+		return
+	}
+	e.Time, e.SwapID = time.Now(), state.ID
+	if e.Chain == "" {
+		e.Chain = state.Spec.MyChain
+	}
+	w.Report(e)
+}
@@ -0,0 +1,161 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/threefoldtech/atomicswap/chain"
+	"github.com/threefoldtech/atomicswap/swap"
+)
+
+// memoryStore keeps the swaps in memory
+type memoryStore map[string]*swap.State
+
+func (s memoryStore) Save(state *swap.State) error {
+	s[state.ID] = state
+	return nil
+}
+
+func (s memoryStore) List() ([]*swap.State, error) {
+	states := make([]*swap.State, 0, len(s))
+	for _, state := range s {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// fakeSwapper refunds expired contracts, unless they have been redeemed
+type fakeSwapper struct {
+	chain.Swapper
+	lockTimes map[string]chain.LockTime
+	secrets   map[string]chain.Secret
+	refunds   int
+	redeems   map[string]chain.Secret
+	closed    int
+}
+
+func (s *fakeSwapper) Close() error {
+	s.closed++
+	return nil
+}
+
+func (s *fakeSwapper) Redeem(ctx context.Context, contract chain.Contract, secret chain.Secret) (string, error) {
+	if _, ok := s.redeems[contract.Address]; ok {
+		return "", errors.New("already redeemed")
+	}
+	s.redeems[contract.Address] = secret
+	return "redeem-" + contract.Address, nil
+}
+
+func (s *fakeSwapper) AuditContract(ctx context.Context, contract chain.Contract) (chain.AuditResult, error) {
+	return chain.AuditResult{LockTime: s.lockTimes[contract.Address]}, nil
+}
+
+func (s *fakeSwapper) Refund(ctx context.Context, contract chain.Contract) (string, error) {
+	if _, ok := s.secrets[contract.Address]; ok {
+		return "", errors.New("already redeemed")
+	}
+	if !s.lockTimes[contract.Address].Expired() {
+		return "", errors.New("not expired")
+	}
+	s.refunds++
+	return "refund-" + contract.Address, nil
+}
+
+func (s *fakeSwapper) ExtractSecret(ctx context.Context, contract chain.Contract, redemptionTx string) (chain.Secret, error) {
+	secret, ok := s.secrets[contract.Address]
+	if !ok {
+		return chain.Secret{}, chain.ErrNotRedeemed
+	}
+	return secret, nil
+}
+
+func TestWatcher(t *testing.T) {
+	secret, err := chain.NewSecret()
+	require.NoError(t, err)
+	past, future := chain.LockTime(time.Now().Add(-time.Minute).Unix()), chain.LockTime(time.Now().Add(time.Hour).Unix())
+	swapper := &fakeSwapper{
+		lockTimes: map[string]chain.LockTime{"expired": past, "unaudited": past, "locked": future, "redeemed": past, "revealed": future},
+		secrets:   map[string]chain.Secret{"redeemed": secret, "revealed": secret},
+		redeems:   make(map[string]chain.Secret),
+	}
+	newState := func(id, contract string, step swap.Step, lockTime chain.LockTime) *swap.State {
+		return &swap.State{
+			ID:         id,
+			Spec:       swap.Spec{MyChain: "btc"},
+			Step:       step,
+			MyContract: &chain.Contract{Address: contract},
+			MyLockTime: lockTime,
+		}
+	}
+	st := memoryStore{
+		"1": newState("1", "expired", swap.StepAudited, past),
+		"2": newState("2", "unaudited", swap.StepLocking, 0),
+		"3": newState("3", "locked", swap.StepLocked, future),
+		"4": newState("4", "redeemed", swap.StepLocked, past),
+		"5": newState("5", "expired", swap.StepRedeemed, past),
+		"6": newState("6", "revealed", swap.StepLocked, future),
+		"7": newState("7", "locked", swap.StepLocked, future),
+	}
+	// we participate in swaps 6 and 7, the initiator only redeemed our contract of swap 6
+	for _, id := range []string{"6", "7"} {
+		st[id].Spec.Role, st[id].Spec.TheirChain = swap.RoleParticipant, "eth"
+		st[id].TheirContract = &chain.Contract{Address: "theirs" + id}
+	}
+
+	var events []Event
+	created := make(map[string]int)
+	w := &Watcher{
+		Store: st,
+		Swapper: func(ctx context.Context, name string) (chain.Swapper, error) {
+			if name != "btc" && name != "eth" {
+				return nil, errors.New("unknown chain")
+			}
+			created[name]++
+			return swapper, nil
+		},
+		Report: func(e Event) { events = append(events, e) },
+	}
+	require.NoError(t, w.Check(context.Background()))
+
+	assert.Equal(t, 2, swapper.refunds)
+	assert.Equal(t, swap.StepRefunded, st["1"].Step)
+	assert.Equal(t, "refund-expired", st["1"].RefundTransaction)
+	assert.Equal(t, swap.StepRefunded, st["2"].Step)
+	assert.Equal(t, swap.StepLocked, st["3"].Step)
+	assert.Equal(t, swap.StepLocked, st["4"].Step)
+	require.NotNil(t, st["4"].Secret)
+	assert.Equal(t, secret, *st["4"].Secret)
+	assert.Equal(t, swap.StepRedeemed, st["5"].Step)
+	assert.Equal(t, swap.StepRedeemed, st["6"].Step)
+	assert.Equal(t, "redeem-theirs6", st["6"].RedeemTransaction)
+	assert.Equal(t, map[string]chain.Secret{"theirs6": secret}, swapper.redeems)
+	assert.Equal(t, swap.StepLocked, st["7"].Step)
+	assert.Nil(t, st["7"].Secret)
+
+	actions := make(map[string]Action)
+	for _, e := range events {
+		actions[e.SwapID] = e.Action
+	}
+	assert.Equal(t, map[string]Action{"1": ActionRefunded, "2": ActionRefunded, "4": ActionRedeemed, "6": ActionRedeemedTheirs}, actions)
+
+	// nothing is left to do, a known failure is reported once
+	events = nil
+	require.NoError(t, w.Check(context.Background()))
+	require.NoError(t, w.Check(context.Background()))
+	assert.Equal(t, 2, swapper.refunds)
+	require.Len(t, events, 1)
+	assert.Equal(t, ActionFailed, events[0].Action)
+	assert.Equal(t, "4", events[0].SwapID)
+
+	// the swapper of each chain is created once, and closed once the watcher stops
+	assert.Equal(t, map[string]int{"btc": 1, "eth": 1}, created)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, w.Run(ctx))
+	assert.Equal(t, 2, swapper.closed)
+}